@@ -0,0 +1,209 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"taxi_service/internal/backup"
+	"taxi_service/internal/cripto"
+	"taxi_service/repositories"
+)
+
+const usoAdmin = `uso: taxi_service admin <comando> [opções]
+
+comandos:
+  backup   grava o diretório de dados em um único arquivo com manifesto e checksums
+  restore  valida um backup e o coloca no lugar do diretório de dados
+  export   copia os motoristas de um armazenamento para outro (ex.: json para sqlite)
+
+Use "taxi_service admin <comando> -h" para as opções de cada comando.
+`
+
+// executarAdmin trata "taxi_service admin ..." e retorna o código de saída do processo
+func executarAdmin(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usoAdmin)
+		return 2
+	}
+	comandos := map[string]func([]string) error{
+		"backup":  adminBackup,
+		"restore": adminRestore,
+		"export":  adminExport,
+	}
+	comando, ok := comandos[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "comando desconhecido: %s\n\n%s", args[0], usoAdmin)
+		return 2
+	}
+	if err := comando(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func adminBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	dados := flags.String("dados", "./data", "diretório de dados")
+	saida := flags.String("saida", "", "arquivo gerado (padrão taxi_service-backup-<data>.tar.gz)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *saida == "" {
+		*saida = fmt.Sprintf("taxi_service-backup-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	}
+	avisarForaDoBackup(*dados)
+
+	// O backup é gravado em um temporário e só renomeado quando completo
+	temporario, err := os.CreateTemp(filepath.Dir(*saida), "."+filepath.Base(*saida)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo: %w", err)
+	}
+	defer os.Remove(temporario.Name())
+	h := sha256.New()
+	manifesto, err := backup.Criar(*dados, io.MultiWriter(temporario, h))
+	if err != nil {
+		temporario.Close()
+		return err
+	}
+	if err := temporario.Sync(); err != nil {
+		temporario.Close()
+		return fmt.Errorf("erro ao gravar backup: %w", err)
+	}
+	if err := temporario.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar backup: %w", err)
+	}
+	if err := os.Rename(temporario.Name(), *saida); err != nil {
+		return fmt.Errorf("erro ao gravar backup: %w", err)
+	}
+	fmt.Printf("Backup gravado em %s (%d arquivos)\nsha256: %s\n", *saida, len(manifesto.Arquivos), hex.EncodeToString(h.Sum(nil)))
+	return nil
+}
+
+// avisarForaDoBackup o backup copia apenas o diretório de dados; avisa o que fica de fora
+func avisarForaDoBackup(dados string) {
+	if os.Getenv("MOTORISTA_STORAGE") == "postgres" {
+		fmt.Fprintln(os.Stderr, "AVISO: MOTORISTA_STORAGE=postgres: os motoristas não estão no diretório de dados e não entram no backup (use pg_dump)")
+	}
+	switch os.Getenv("FILE_STORAGE") {
+	case "s3":
+		fmt.Fprintln(os.Stderr, "AVISO: FILE_STORAGE=s3: documentos e fotos ficam no bucket e não entram no backup")
+	case "", "local":
+		raiz := os.Getenv("FILE_STORAGE_PATH")
+		if raiz == "" {
+			return
+		}
+		if relativo, err := filepath.Rel(dados, raiz); err != nil || !filepath.IsLocal(relativo) {
+			fmt.Fprintf(os.Stderr, "AVISO: documentos e fotos estão em %s, fora de %s, e não entram no backup\n", raiz, dados)
+		}
+	}
+	if caminho := os.Getenv("SQLITE_PATH"); caminho != "" {
+		if relativo, err := filepath.Rel(dados, caminho); err != nil || !filepath.IsLocal(relativo) {
+			fmt.Fprintf(os.Stderr, "AVISO: o banco SQLite %s está fora de %s e não entra no backup\n", caminho, dados)
+		}
+	}
+}
+
+func adminRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	arquivo := flags.String("arquivo", "", "backup gerado por \"admin backup\" (obrigatório)")
+	dados := flags.String("dados", "./data", "diretório de dados substituído")
+	verificar := flags.Bool("verificar", false, "apenas valida o backup, sem restaurar")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *arquivo == "" {
+		flags.Usage()
+		return errors.New("informe o backup com -arquivo")
+	}
+	f, err := os.Open(*arquivo)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir backup: %w", err)
+	}
+	defer f.Close()
+
+	if *verificar {
+		manifesto, err := backup.Verificar(f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backup válido: %d arquivos, criado em %s\n", len(manifesto.Arquivos), manifesto.CriadoEm.Format(time.RFC3339))
+		return nil
+	}
+	// O servidor mantém o banco SQLite aberto e regrava motoristas.json; restaurar com ele
+	// em execução faria as próximas escritas irem para o diretório substituído
+	fmt.Fprintln(os.Stderr, "AVISO: o servidor deve estar parado durante a restauração")
+	anterior, manifesto, err := backup.Restaurar(f, *dados)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup restaurado em %s (%d arquivos, criado em %s)\n", *dados, len(manifesto.Arquivos), manifesto.CriadoEm.Format(time.RFC3339))
+	if anterior != "" {
+		fmt.Printf("Dados anteriores preservados em %s\n", anterior)
+	}
+	return nil
+}
+
+func adminExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	de := flags.String("de", "", "armazenamento de origem: json, sqlite ou postgres, opcionalmente com :caminho (obrigatório)")
+	para := flags.String("para", "", "armazenamento de destino, vazio, no mesmo formato (obrigatório)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *de == "" || *para == "" {
+		flags.Usage()
+		return errors.New("informe a origem com -de e o destino com -para")
+	}
+	// Origem e destino usam as mesmas chaves (DATA_ENCRYPTION_KEYS): os dados são
+	// decifrados na leitura e cifrados de novo na gravação
+	cifrador, err := cripto.NewCifradorFromEnv()
+	if err != nil {
+		return err
+	}
+	// A origem é aberta somente para leitura: sem recuperação de snapshots, recifragem
+	// ou migrações, a exportação não altera os dados de onde eles são lidos
+	origem, err := abrirArmazenamento(*de, cifrador, true)
+	if err != nil {
+		return err
+	}
+	defer fechar(origem)
+	destino, err := abrirArmazenamento(*para, cifrador, false)
+	if err != nil {
+		return err
+	}
+	defer fechar(destino)
+
+	n, err := repositories.CopiarMotoristas(origem, destino)
+	if err != nil {
+		return fmt.Errorf("%w (%d motorista(s) copiados antes do erro)", err, n)
+	}
+	fmt.Printf("%d motorista(s) copiados de %s para %s\n", n, *de, *para)
+	return nil
+}
+
+// abrirArmazenamento abre "tipo[:caminho]", como json:./data/motoristas.json
+func abrirArmazenamento(especificacao string, cifrador *cripto.Cifrador, somenteLeitura bool) (repositories.MotoristaRepository, error) {
+	tipo, caminho, _ := strings.Cut(especificacao, ":")
+	if somenteLeitura {
+		return repositories.AbrirMotoristaRepositoryLeitura(tipo, caminho, cifrador)
+	}
+	return repositories.AbrirMotoristaRepository(tipo, caminho, cifrador)
+}
+
+func fechar(repo repositories.MotoristaRepository) {
+	if closer, ok := repo.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"taxi_service/routes"
 
@@ -29,6 +30,9 @@ _,,_,*^____      _____''*g*\"*,          Welcome to your app!
 |                           |`
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(executarAdmin(os.Args[2:]))
+	}
 	app := fiber.New()
 	routes.SetupRoutes(app)
 	log.Println(gopherDraw)
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/services"
+)
+
+// ExclusaoController gerencia as rotas de exclusão de conta do motorista
+type ExclusaoController struct {
+	exclusaoService services.ExclusaoService
+}
+
+// NewExclusaoController cria uma nova instância do controller
+func NewExclusaoController(exclusaoService services.ExclusaoService) *ExclusaoController {
+	return &ExclusaoController{
+		exclusaoService: exclusaoService,
+	}
+}
+
+// SolicitarExclusao POST /api/profile/:id/request-deletion
+func (c *ExclusaoController) SolicitarExclusao(ctx *fiber.Ctx) error {
+	if err := c.exclusaoService.SolicitarExclusao(ctx.Params("id")); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Enviamos para o seu email um link para confirmar a exclusão da conta"})
+}
+
+// ConfirmarExclusao POST /api/auth/confirm-deletion
+func (c *ExclusaoController) ConfirmarExclusao(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if _, err := c.exclusaoService.ConfirmarExclusao(req.Token); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Sua conta foi encerrada e será excluida permanentemente em 72h"})
+}
+
+// CancelarExclusao POST /api/profile/:id/cancel-deletion
+func (c *ExclusaoController) CancelarExclusao(ctx *fiber.Ctx) error {
+	if err := c.exclusaoService.CancelarExclusao(ctx.Params("id")); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Exclusão da conta cancelada"})
+}
+
+// CancelarExclusaoPorLink POST /api/auth/cancel-deletion
+func (c *ExclusaoController) CancelarExclusaoPorLink(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.exclusaoService.CancelarExclusaoPorLink(req.Token); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Exclusão da conta cancelada"})
+}
@@ -1,27 +1,52 @@
 package controllers
 
 import (
-	"os"
-	"path/filepath"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
 	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/internal/conteudo"
+	"taxi_service/internal/imagem"
+	"taxi_service/internal/storage"
+	"taxi_service/middlewares"
 	"taxi_service/models"
+	"taxi_service/repositories"
 	"taxi_service/services"
 )
 
 // MotoristaController gerencia as rotas relacionadas a motoristas
 type MotoristaController struct {
-	motoristaService services.MotoristaService
+	motoristaService   services.MotoristaService
+	verificacaoService services.VerificacaoEmailService
+	sessaoService      services.SessaoService
+	tokenService       *auth.TokenService
+	arquivos           storage.FileStore
+	varreduraService   services.VarreduraService
 }
 
-// NewMotoristaController cria uma nova instância do controller
-func NewMotoristaController(motoristaService services.MotoristaService) *MotoristaController {
+// NewMotoristaController cria uma nova instância do controller.
+// arquivos armazena os documentos e fotos enviados; varreduraService verifica os
+// documentos com o antivírus antes que fiquem disponíveis para revisão.
+func NewMotoristaController(motoristaService services.MotoristaService, verificacaoService services.VerificacaoEmailService, sessaoService services.SessaoService, tokenService *auth.TokenService, arquivos storage.FileStore, varreduraService services.VarreduraService) *MotoristaController {
 	return &MotoristaController{
-		motoristaService: motoristaService,
+		motoristaService:   motoristaService,
+		verificacaoService: verificacaoService,
+		sessaoService:      sessaoService,
+		tokenService:       tokenService,
+		arquivos:           arquivos,
+		varreduraService:   varreduraService,
 	}
 }
 
@@ -35,6 +60,10 @@ func (c *MotoristaController) CadastrarMotorista(ctx *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	// Falha no envio não desfaz o cadastro; o motorista pode pedir o reenvio do link
+	if err := c.verificacaoService.EnviarVerificacao(motorista.ID); err != nil {
+		log.Printf("erro ao enviar verificação de email: %v", err)
+	}
 	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Cadastro realizado com sucesso", "motorista": resumoMotorista(motorista)})
 }
 
@@ -57,37 +86,70 @@ func (c *MotoristaController) UploadDocumentosArquivos(ctx *fiber.Ctx) error {
 		return apperrors.ErrLimiteArquivosExcedido
 	}
 
-	// criar diretório do motorista
-	baseDir := filepath.Join("data", motoristaID)
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return apperrors.ErrFalhaCriarDiretorio
+	// tipo vem de campo tipo_0, tipo_1...; conferidos antes de gravar qualquer arquivo,
+	// porque os arquivos gravados de um lote recusado não seriam referenciados por nenhum
+	// documento
+	tipos := make([]string, len(files))
+	for idx := range files {
+		valores := form.Value["tipo_"+strconv.Itoa(idx)]
+		if len(valores) == 0 {
+			return apperrors.ErrCampoObrigatorio
+		}
+		tipos[idx] = valores[0]
+	}
+	if err := services.ValidarTiposDocumentos(tipos); err != nil {
+		return err
 	}
 
-	var uploadRequests []services.UploadDocumentoRequest
+	// Todos os arquivos são lidos e validados antes de o primeiro ser gravado
+	type arquivoValidado struct {
+		dados []byte
+		info  *conteudo.Info
+	}
+	validados := make([]arquivoValidado, len(files))
 	for idx, fh := range files {
-		// tipo vem de campo tipo_0, tipo_1...
-		tipoField := "tipo_" + strconv.Itoa(idx)
-		tipos := form.Value[tipoField]
-		if len(tipos) == 0 {
-			return apperrors.ErrCampoObrigatorio
+		dados, info, err := lerArquivo(fh, models.ValidarDocumento)
+		if err != nil {
+			return err
 		}
-		tipo := tipos[0]
-		ext := filepath.Ext(fh.Filename)
-		destino := filepath.Join(baseDir, tipo+ext)
-		if err := ctx.SaveFile(fh, destino); err != nil {
-			return apperrors.ErrFalhaSalvarArquivo
+		// Selfies são fotos de celular: gravadas sem EXIF/GPS e com a orientação aplicada.
+		// Mesma normalização do serviço, para que toda variação de selfie seja limpa.
+		if services.NormalizarTipoDocumento(tipos[idx]) == "selfie_cnh" && info.Formato != conteudo.FormatoPDF {
+			if dados, info, err = limparImagem(dados); err != nil {
+				return err
+			}
+		}
+		validados[idx] = arquivoValidado{dados, info}
+	}
+
+	var uploadRequests []services.UploadDocumentoRequest
+	for idx, a := range validados {
+		chave, checksum, err := c.gravarDocumento(motoristaID, a.dados, a.info)
+		if err != nil {
+			return err
 		}
 		uploadRequests = append(uploadRequests, services.UploadDocumentoRequest{
-			TipoDocumento:  tipo,
-			CaminhoArquivo: destino,
-			Formato:        ext[1:],
-			Tamanho:        fh.Size,
+			TipoDocumento: services.NormalizarTipoDocumento(tipos[idx]),
+			ChaveArquivo:  chave,
+			Formato:       a.info.Formato,
+			Tamanho:       int64(len(a.dados)),
+			Checksum:      checksum,
+			EnviadoPor:    middlewares.Subject(ctx),
 		})
 	}
 
 	if err := c.motoristaService.UploadDocumentosLote(motoristaID, uploadRequests); err != nil {
 		return err
 	}
+	// Os documentos ficam em verificação até o antivírus concluir; arquivos iguais
+	// (mesma chave) são verificados uma vez
+	agendadas := map[string]bool{}
+	for _, r := range uploadRequests {
+		if !agendadas[r.ChaveArquivo] {
+			agendadas[r.ChaveArquivo] = true
+			c.varreduraService.Agendar(motoristaID, r.ChaveArquivo)
+		}
+	}
 
 	return ctx.JSON(fiber.Map{"message": "Arquivos enviados", "quantidade": len(uploadRequests)})
 }
@@ -103,28 +165,130 @@ func (c *MotoristaController) DownloadDocumento(ctx *fiber.Ctx) error {
 	}
 	for _, doc := range motorista.Documentos {
 		if doc.TipoDocumento == tipo {
-			// Verifica se arquivo existe
-			if _, err := os.Stat(doc.CaminhoArquivo); err == nil {
-				return ctx.SendFile(doc.CaminhoArquivo)
-			}
-			return apperrors.ErrArquivoNaoEncontrado
+			return c.enviarDocumento(ctx, motoristaID, doc)
 		}
 	}
 	return apperrors.ErrDocumentoNaoEncontrado
 }
 
+// HistoricoDocumento GET /api/documents/:id/history/:tipo
+// Lista as versões enviadas do documento, da atual para a mais antiga, com o link de download de cada uma
+func (c *MotoristaController) HistoricoDocumento(ctx *fiber.Ctx) error {
+	motoristaID := ctx.Params("id")
+	tipo := ctx.Params("tipo")
+
+	versoes, err := c.motoristaService.HistoricoDocumento(motoristaID, tipo)
+	if err != nil {
+		return err
+	}
+	type versaoDocumento struct {
+		models.Documento
+		Download string `json:"download"`
+	}
+	resposta := make([]versaoDocumento, 0, len(versoes))
+	for _, doc := range versoes {
+		resposta = append(resposta, versaoDocumento{
+			Documento: doc,
+			Download:  fmt.Sprintf("/api/documents/%s/history/%s/%d", url.PathEscape(motoristaID), url.PathEscape(tipo), doc.Versao),
+		})
+	}
+	return ctx.JSON(fiber.Map{"tipo_documento": tipo, "versoes": resposta})
+}
+
+// DownloadVersaoDocumento GET /api/documents/:id/history/:tipo/:versao
+func (c *MotoristaController) DownloadVersaoDocumento(ctx *fiber.Ctx) error {
+	motoristaID := ctx.Params("id")
+	versao, err := strconv.Atoi(ctx.Params("versao"))
+	if err != nil || versao < 1 {
+		return apperrors.ErrDocumentoNaoEncontrado
+	}
+	doc, err := c.motoristaService.VersaoDocumento(motoristaID, ctx.Params("tipo"), versao)
+	if err != nil {
+		return err
+	}
+	return c.enviarDocumento(ctx, motoristaID, *doc)
+}
+
 // BuscarMotorista GET /api/motoristas/:id
 func (c *MotoristaController) BuscarMotorista(ctx *fiber.Ctx) error {
 	motorista, err := c.motoristaService.BuscarMotorista(ctx.Params("id"))
 	if err != nil {
 		return err
 	}
+	ctx.Set(fiber.HeaderETag, etagMotorista(motorista))
 	return ctx.JSON(fiber.Map{"motorista": detalhesMotorista(motorista)})
 }
 
-// FotoPerfil GET /api/profile/:id/photo
+// ListarMotoristas GET /api/admin/motoristas
+// Query: status (separados por vírgula), criado_de, criado_ate (RFC3339 ou AAAA-MM-DD),
+// categoria_cnh, placa, nome (prefixos), ordem, cursor e limite.
+func (c *MotoristaController) ListarMotoristas(ctx *fiber.Ctx) error {
+	filtro := repositories.FiltroMotoristas{
+		CategoriaCNH: models.CategoriaCNH(strings.ToUpper(ctx.Query("categoria_cnh"))),
+		PrefixoPlaca: ctx.Query("placa"),
+		PrefixoNome:  ctx.Query("nome"),
+		Ordem:        repositories.OrdemMotoristas(ctx.Query("ordem")),
+		Cursor:       ctx.Query("cursor"),
+	}
+	for _, s := range strings.Split(ctx.Query("status"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			filtro.Status = append(filtro.Status, models.StatusMotorista(s))
+		}
+	}
+	var err error
+	if filtro.CriadoDe, err = dataFiltro(ctx.Query("criado_de")); err != nil {
+		return err
+	}
+	if filtro.CriadoAte, err = dataFiltro(ctx.Query("criado_ate")); err != nil {
+		return err
+	}
+	if limite := ctx.Query("limite"); limite != "" {
+		if filtro.Limite, err = strconv.Atoi(limite); err != nil || filtro.Limite <= 0 {
+			return apperrors.ErrFiltroInvalido
+		}
+	}
+
+	pagina, err := c.motoristaService.ListarMotoristas(filtro)
+	if err != nil {
+		return err
+	}
+	resumos := make([]fiber.Map, 0, len(pagina.Motoristas))
+	for _, m := range pagina.Motoristas {
+		resumos = append(resumos, fiber.Map{
+			"id":            m.ID,
+			"nome":          m.Nome,
+			"email":         m.Email,
+			"status":        m.Status,
+			"categoria_cnh": m.CategoriaCNH,
+			"placa_veiculo": m.PlacaVeiculo,
+			"criado_em":     m.CriadoEm,
+		})
+	}
+	return ctx.JSON(fiber.Map{"motoristas": resumos, "proximo_cursor": pagina.ProximoCursor})
+}
+
+// dataFiltro interpreta datas da query; AAAA-MM-DD corresponde à meia-noite UTC
+func dataFiltro(valor string) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, valor); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, apperrors.ErrFiltroInvalido
+}
+
+// FotoPerfil GET /api/profile/:id/photo?size=thumb|small|medium
+// Sem size serve a foto inteira. Fotos enviadas antes das rendições as têm geradas na
+// primeira requisição.
 func (c *MotoristaController) FotoPerfil(ctx *fiber.Ctx) error {
 	motoristaID := ctx.Params("id")
+	tamanho := ctx.Query("size")
+	if tamanho != "" && !imagem.TamanhoValido(tamanho) {
+		return apperrors.ErrTamanhoFotoInvalido
+	}
 	motorista, err := c.motoristaService.BuscarMotorista(motoristaID)
 	if err != nil {
 		return err
@@ -132,10 +296,213 @@ func (c *MotoristaController) FotoPerfil(ctx *fiber.Ctx) error {
 	if motorista.FotoPerfil == "" {
 		return apperrors.ErrFotoNaoEncontrada
 	}
-	if _, err := os.Stat(motorista.FotoPerfil); err != nil {
+	chave := motorista.FotoPerfil
+	if tamanho != "" {
+		chave = imagem.ChaveTamanho(motorista.FotoPerfil, tamanho)
+		if _, err := c.arquivos.Info(chave); errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+			if err := c.gerarRendicoes(motorista.FotoPerfil); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	return c.enviarFoto(ctx, chave)
+}
+
+// gerarRendicoes grava as rendições de uma foto já armazenada
+func (c *MotoristaController) gerarRendicoes(chave string) error {
+	dados, err := c.arquivos.Ler(chave)
+	if errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+		return apperrors.ErrArquivoNaoEncontrado
+	}
+	if err != nil {
+		return err
+	}
+	_, rendicoes, err := imagem.Processar(dados)
+	if err != nil {
+		log.Printf("erro ao gerar rendições da foto %s: %v", chave, err)
+		return erroConteudo(err)
+	}
+	for nome, r := range rendicoes {
+		if err := c.gravarArquivo(imagem.ChaveTamanho(chave, nome), r.Dados, r.ContentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gravarFoto grava as rendições e a foto de perfil processadas
+func (c *MotoristaController) gravarFoto(chave string, foto *imagem.Imagem, rendicoes map[string]*imagem.Imagem) error {
+	for nome, r := range rendicoes {
+		if err := c.gravarArquivo(imagem.ChaveTamanho(chave, nome), r.Dados, r.ContentType); err != nil {
+			return err
+		}
+	}
+	return c.gravarArquivo(chave, foto.Dados, foto.ContentType)
+}
+
+// removerFoto apaga a foto e as rendições dela; falhas ficam só no log
+func (c *MotoristaController) removerFoto(chave string) {
+	chaves := []string{chave}
+	for _, t := range imagem.Tamanhos {
+		chaves = append(chaves, imagem.ChaveTamanho(chave, t.Nome))
+	}
+	for _, k := range chaves {
+		if err := c.arquivos.Deletar(k); err != nil {
+			log.Printf("erro ao remover foto %s: %v", k, err)
+		}
+	}
+}
+
+// enviarFoto serve a foto com ETag pelo conteúdo e Last-Modified. A rota exige
+// autenticação, então o cache é privado e revalidado a cada uso: a chave da foto não
+// muda quando uma nova é enviada, e a revalidação com If-None-Match recebe 304.
+func (c *MotoristaController) enviarFoto(ctx *fiber.Ctx, chave string) error {
+	leitor, info, err := c.arquivos.Abrir(chave)
+	if errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+		return apperrors.ErrArquivoNaoEncontrado
+	}
+	if err != nil {
+		return err
+	}
+	defer leitor.Close()
+	dados, err := io.ReadAll(leitor)
+	if err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderCacheControl, "private, no-cache")
+	ctx.Set(fiber.HeaderETag, `"`+storage.Checksum(dados)+`"`)
+	if !info.ModificadoEm.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, info.ModificadoEm.UTC().Format(http.TimeFormat))
+	}
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+	// O tipo vem do conteúdo: fotos antigas podem ter extensão diferente do formato
+	ctx.Set(fiber.HeaderContentType, http.DetectContentType(dados))
+	return ctx.Send(dados)
+}
+
+// lerArquivo lê o arquivo recebido no multipart identificando o formato pelo conteúdo;
+// a extensão do nome é ignorada. validar recebe o formato detectado e o tamanho antes
+// da leitura completa, e a estrutura do arquivo é conferida em seguida.
+func lerArquivo(fh *multipart.FileHeader, validar func(formato string, tamanho int64) error) ([]byte, *conteudo.Info, error) {
+	arquivo, err := fh.Open()
+	if err != nil {
+		return nil, nil, apperrors.ErrFalhaSalvarArquivo
+	}
+	defer arquivo.Close()
+	cabecalho := make([]byte, conteudo.TamanhoCabecalho)
+	n, err := io.ReadFull(arquivo, cabecalho)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, nil, apperrors.ErrFalhaSalvarArquivo
+	}
+	formato := conteudo.Detectar(cabecalho[:n])
+	if formato == "" {
+		return nil, nil, apperrors.ErrConteudoDesconhecido
+	}
+	if err := validar(formato, fh.Size); err != nil {
+		return nil, nil, err
+	}
+	dados, err := io.ReadAll(io.MultiReader(bytes.NewReader(cabecalho[:n]), arquivo))
+	if err != nil {
+		return nil, nil, apperrors.ErrFalhaSalvarArquivo
+	}
+	info, err := conteudo.Inspecionar(dados, conteudo.LimitesPadrao)
+	if err != nil {
+		return nil, nil, erroConteudo(err)
+	}
+	return dados, info, nil
+}
+
+// limparImagem recodifica a imagem sem metadados e com a orientação aplicada
+func limparImagem(dados []byte) ([]byte, *conteudo.Info, error) {
+	img, err := imagem.Limpar(dados)
+	if err != nil {
+		return nil, nil, erroConteudo(err)
+	}
+	return img.Dados, &conteudo.Info{Formato: img.Formato, ContentType: img.ContentType, Extensao: img.Extensao, Largura: img.Largura, Altura: img.Altura}, nil
+}
+
+// erroConteudo traduz as falhas da inspeção de conteúdo para os erros da API
+func erroConteudo(err error) error {
+	switch {
+	case errors.Is(err, conteudo.ErrFormatoDesconhecido):
+		return apperrors.ErrConteudoDesconhecido
+	case errors.Is(err, conteudo.ErrPDFInvalido):
+		return apperrors.ErrPDFInvalido
+	case errors.Is(err, conteudo.ErrPDFCriptografado):
+		return apperrors.ErrPDFCriptografado
+	case errors.Is(err, conteudo.ErrPDFPaginas):
+		return apperrors.ErrPDFPaginasExcedidas
+	case errors.Is(err, conteudo.ErrImagemInvalida), errors.Is(err, imagem.ErrImagemInvalida):
+		return apperrors.ErrImagemInvalida
+	case errors.Is(err, conteudo.ErrImagemResolucao):
+		return apperrors.ErrImagemResolucao
+	}
+	return err
+}
+
+// gravarArquivo grava o conteúdo no FileStore
+func (c *MotoristaController) gravarArquivo(chave string, dados []byte, contentType string) error {
+	if err := c.arquivos.Gravar(chave, bytes.NewReader(dados), int64(len(dados)), contentType); err != nil {
+		log.Printf("erro ao gravar arquivo %s: %v", chave, err)
+		return apperrors.ErrFalhaSalvarArquivo
+	}
+	return nil
+}
+
+// gravarDocumento grava o documento endereçado pelo SHA-256 do conteúdo
+// (<id>/documentos/<sha256><ext>): cada versão enviada fica preservada e
+// reenviar os mesmos bytes reaproveita o arquivo existente
+func (c *MotoristaController) gravarDocumento(motoristaID string, dados []byte, info *conteudo.Info) (string, string, error) {
+	chave, checksum, err := storage.GravarPorConteudo(c.arquivos, motoristaID+"/documentos/", bytes.NewReader(dados), int64(len(dados)), info.Extensao, info.ContentType)
+	if err != nil {
+		log.Printf("erro ao gravar documento do motorista %s: %v", motoristaID, err)
+		return "", "", apperrors.ErrFalhaSalvarArquivo
+	}
+	return chave, checksum, nil
+}
+
+// enviarDocumento confere o SHA-256 do arquivo com o registrado no documento antes
+// de servi-lo; bytes divergentes nunca são enviados. Documentos anteriores ao
+// checksum são servidos sem conferência. Arquivos em verificação antivírus ou
+// infectados nunca são servidos.
+func (c *MotoristaController) enviarDocumento(ctx *fiber.Ctx, motoristaID string, doc models.Documento) error {
+	if err := doc.ValidarDisponibilidade(); err != nil {
+		return err
+	}
+	if doc.Checksum == "" {
+		return c.enviarArquivo(ctx, doc.ChaveArquivo)
+	}
+	conteudo, info, err := storage.LerVerificado(c.arquivos, doc.ChaveArquivo, doc.Checksum)
+	switch {
+	case errors.Is(err, storage.ErrArquivoNaoEncontrado):
 		return apperrors.ErrArquivoNaoEncontrado
+	case errors.Is(err, storage.ErrChecksumDivergente):
+		log.Printf("ERRO: documento %s (%s) do motorista %s não confere com o checksum %s; download recusado",
+			doc.ID, doc.ChaveArquivo, motoristaID, doc.Checksum)
+		return apperrors.ErrArquivoCorrompido
+	case err != nil:
+		return err
 	}
-	return ctx.SendFile(motorista.FotoPerfil)
+	ctx.Set(fiber.HeaderContentType, info.ContentType)
+	ctx.Set(fiber.HeaderETag, `"`+doc.Checksum+`"`)
+	return ctx.Send(conteudo)
+}
+
+// enviarArquivo transmite o arquivo do FileStore na resposta, sem carregá-lo inteiro em memória
+func (c *MotoristaController) enviarArquivo(ctx *fiber.Ctx, chave string) error {
+	leitor, info, err := c.arquivos.Abrir(chave)
+	if errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+		return apperrors.ErrArquivoNaoEncontrado
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, info.ContentType)
+	return ctx.SendStream(leitor, int(info.Tamanho))
 }
 
 // (Removido endpoint de validação automática)
@@ -144,7 +511,7 @@ func (c *MotoristaController) FotoPerfil(ctx *fiber.Ctx) error {
 func (c *MotoristaController) AprovarMotorista(ctx *fiber.Ctx) error {
 	motoristaID := ctx.Params("id")
 
-	if err := c.motoristaService.AprovarMotorista(motoristaID); err != nil {
+	if err := c.motoristaService.AprovarMotorista(motoristaID, middlewares.Subject(ctx)); err != nil {
 		return err
 	}
 
@@ -169,7 +536,7 @@ func (c *MotoristaController) RejeitarMotorista(ctx *fiber.Ctx) error {
 		return apperrors.ErrCampoObrigatorio
 	}
 
-	if err := c.motoristaService.RejeitarMotorista(motoristaID, request.Motivo); err != nil {
+	if err := c.motoristaService.RejeitarMotorista(motoristaID, middlewares.Subject(ctx), request.Motivo); err != nil {
 		return err
 	}
 
@@ -178,17 +545,69 @@ func (c *MotoristaController) RejeitarMotorista(ctx *fiber.Ctx) error {
 	})
 }
 
+// AprovarDocumento PUT /api/documents/:id/review/:documento/approve
+// :documento é o ID ou o tipo do documento (CNH, CRLV, selfie_cnh)
+func (c *MotoristaController) AprovarDocumento(ctx *fiber.Ctx) error {
+	m, err := c.motoristaService.AprovarDocumento(ctx.Params("id"), ctx.Params("documento"), middlewares.Subject(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{
+		"message":    "Documento aprovado",
+		"status":     m.Status,
+		"documentos": m.Documentos,
+	})
+}
+
+// RejeitarDocumento PUT /api/documents/:id/review/:documento/reject
+// Body: motivo (código, ex.: ilegivel, vencido) e observacao (obrigatória com o motivo "outro")
+func (c *MotoristaController) RejeitarDocumento(ctx *fiber.Ctx) error {
+	var request struct {
+		Motivo     string `json:"motivo"`
+		Observacao string `json:"observacao"`
+	}
+	if err := ctx.BodyParser(&request); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if request.Motivo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+
+	m, err := c.motoristaService.RejeitarDocumento(ctx.Params("id"), ctx.Params("documento"), middlewares.Subject(ctx), request.Motivo, request.Observacao)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{
+		"message":    "Documento rejeitado",
+		"status":     m.Status,
+		"documentos": m.Documentos,
+	})
+}
+
 // AtualizarPerfil PUT /api/profile/:id
+// Com If-Match (ETag obtido no GET) a alteração é recusada se o cadastro mudou desde a leitura.
 func (c *MotoristaController) AtualizarPerfil(ctx *fiber.Ctx) error {
 	var body struct{ Telefone, Email string }
 	if err := ctx.BodyParser(&body); err != nil {
 		return apperrors.ErrCampoObrigatorio
 	}
-	m, err := c.motoristaService.AtualizarPerfil(ctx.Params("id"), body.Telefone, body.Email)
+	versao, err := versaoIfMatch(ctx.Get(fiber.HeaderIfMatch))
 	if err != nil {
 		return err
 	}
-	return ctx.JSON(fiber.Map{"message": "Perfil atualizado com sucesso", "motorista": detalhesMotorista(m)})
+	m, err := c.motoristaService.AtualizarPerfil(ctx.Params("id"), versao, body.Telefone, body.Email)
+	if err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderETag, etagMotorista(m))
+	message := "Perfil atualizado com sucesso"
+	if body.Email != "" && m.EmailPendente != "" {
+		if err := c.verificacaoService.EnviarVerificacao(m.ID); err != nil {
+			return err
+		}
+		message = "Perfil atualizado. Confirme o novo email pelo link enviado para " + m.EmailPendente
+	}
+	return ctx.JSON(fiber.Map{"message": message, "motorista": detalhesMotorista(m)})
 }
 
 // AlterarSenha PUT /api/profile/:id/password
@@ -201,48 +620,45 @@ func (c *MotoristaController) AlterarSenha(ctx *fiber.Ctx) error {
 	if err := c.motoristaService.AlterarSenha(id, body.SenhaAtual, body.NovaSenha, body.Confirmacao); err != nil {
 		return err
 	}
+	// Os demais dispositivos precisam entrar novamente com a nova senha
+	if err := c.sessaoService.RevogarTodas(id, middlewares.Sessao(ctx)); err != nil {
+		return err
+	}
 	return ctx.JSON(fiber.Map{"message": "Senha alterada com sucesso"})
 }
 
 // UploadFotoPerfil POST /api/profile/:id/photo multipart campo 'foto'
+// A foto é recodificada sem metadados (EXIF, GPS), com a orientação aplicada, e gravada
+// com as rendições reduzidas; a foto anterior e as rendições dela são removidas.
 func (c *MotoristaController) UploadFotoPerfil(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 	fh, err := ctx.FormFile("foto")
 	if err != nil {
 		return apperrors.ErrCampoObrigatorio
 	}
-	ext := strings.ToLower(filepath.Ext(fh.Filename))
-	formato := strings.TrimPrefix(ext, ".")
-	baseDir := filepath.Join("data", id, "profile")
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return apperrors.ErrFalhaCriarDiretorio
+	dados, _, err := lerArquivo(fh, models.ValidarFotoPerfil)
+	if err != nil {
+		return err
 	}
-	destino := filepath.Join(baseDir, "foto"+ext)
-	if err := ctx.SaveFile(fh, destino); err != nil {
-		return apperrors.ErrFalhaSalvarArquivo
+	foto, rendicoes, err := imagem.Processar(dados)
+	if err != nil {
+		return erroConteudo(err)
 	}
-	if err := c.motoristaService.UploadFotoPerfil(id, destino, formato, fh.Size); err != nil {
+	motorista, err := c.motoristaService.BuscarMotorista(id)
+	if err != nil {
 		return err
 	}
-	return ctx.JSON(fiber.Map{"message": "Foto de perfil atualizada com sucesso", "caminho": destino})
-}
-
-// SolicitarExclusao POST /api/profile/:id/request-deletion
-func (c *MotoristaController) SolicitarExclusao(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if err := c.motoristaService.SolicitarExclusao(id); err != nil {
+	chave := id + "/profile/foto" + foto.Extensao
+	if err := c.gravarFoto(chave, foto, rendicoes); err != nil {
 		return err
 	}
-	return ctx.JSON(fiber.Map{"message": "Solicitação de exclusão registrada"})
-}
-
-// ConfirmarExclusao POST /api/profile/:id/confirm-deletion
-func (c *MotoristaController) ConfirmarExclusao(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if err := c.motoristaService.ConfirmarExclusao(id); err != nil {
+	if err := c.motoristaService.UploadFotoPerfil(id, chave, foto.Formato, int64(len(foto.Dados))); err != nil {
 		return err
 	}
-	return ctx.JSON(fiber.Map{"message": "Sua conta foi encerrada e será excluida permanentemente em 72h"})
+	if motorista.FotoPerfil != "" && motorista.FotoPerfil != chave {
+		c.removerFoto(motorista.FotoPerfil)
+	}
+	return ctx.JSON(fiber.Map{"message": "Foto de perfil atualizada com sucesso", "foto_perfil_url": "/api/profile/" + id + "/photo"})
 }
 
 // VerificarForcaSenha POST /api/motoristas/verificar-senha
@@ -265,15 +681,113 @@ func (c *MotoristaController) VerificarForcaSenha(ctx *fiber.Ctx) error {
 
 // LoginMotorista POST /api/auth/login
 func (c *MotoristaController) LoginMotorista(ctx *fiber.Ctx) error {
-	var req struct{ Email, Senha string }
+	var req struct{ Email, Senha, Dispositivo string }
 	if err := ctx.BodyParser(&req); err != nil {
 		return apperrors.ErrCampoObrigatorio
 	}
-	m, err := c.motoristaService.LoginMotorista(req.Email, req.Senha)
+	m, err := c.motoristaService.LoginMotorista(req.Email, req.Senha, ctx.IP())
+	if err != nil {
+		return err
+	}
+	if m.DoisFatores.Ativo {
+		// Senha conferida; os tokens só são emitidos após o segundo fator
+		desafio, err := c.tokenService.GerarDesafio(m.ID, string(models.PapelMotorista))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(fiber.Map{
+			"message":   "Informe o código de verificação",
+			"desafio":   desafio,
+			"next_step": services.ProximoPassoDoisFatores,
+		})
+	}
+	return c.concluirLogin(ctx, m, req.Dispositivo)
+}
+
+// LoginSegundoFator POST /api/auth/login/2fa
+func (c *MotoristaController) LoginSegundoFator(ctx *fiber.Ctx) error {
+	var req struct{ Desafio, Codigo, Dispositivo string }
+	if err := ctx.BodyParser(&req); err != nil || req.Desafio == "" || req.Codigo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	claims, err := c.tokenService.ValidarToken(req.Desafio, auth.TokenDesafio)
+	if err != nil {
+		return err
+	}
+	if claims.Papel != string(models.PapelMotorista) {
+		return apperrors.ErrTokenInvalido
+	}
+	m, err := c.motoristaService.VerificarSegundoFator(claims.Subject, req.Codigo, ctx.IP())
+	if err != nil {
+		return err
+	}
+	return c.concluirLogin(ctx, m, req.Dispositivo)
+}
+
+// concluirLogin abre a sessão do dispositivo, emite os tokens e indica o próximo passo do motorista.
+// Sem nome informado pelo app, o dispositivo é identificado pelo User-Agent.
+func (c *MotoristaController) concluirLogin(ctx *fiber.Ctx, m *models.Motorista, dispositivo string) error {
+	if strings.TrimSpace(dispositivo) == "" {
+		dispositivo = ctx.Get(fiber.HeaderUserAgent)
+	}
+	tokens, _, err := c.sessaoService.Criar(m.ID, dispositivo, ctx.IP())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{
+		"message":   "Login realizado com sucesso",
+		"motorista": resumoMotorista(m),
+		"tokens":    tokens,
+		"next_step": services.ProximoPassoLogin(m.Status),
+	})
+}
+
+// IniciarDoisFatores POST /api/profile/:id/2fa/enroll
+func (c *MotoristaController) IniciarDoisFatores(ctx *fiber.Ctx) error {
+	inscricao, err := c.motoristaService.IniciarDoisFatores(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Escaneie o QR code e confirme com um código do aplicativo", "dois_fatores": inscricao})
+}
+
+// ConfirmarDoisFatores POST /api/profile/:id/2fa/verify
+func (c *MotoristaController) ConfirmarDoisFatores(ctx *fiber.Ctx) error {
+	var req struct{ Codigo string }
+	if err := ctx.BodyParser(&req); err != nil || req.Codigo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.motoristaService.ConfirmarDoisFatores(ctx.Params("id"), req.Codigo); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Autenticação em dois fatores ativada"})
+}
+
+// DesativarDoisFatores DELETE /api/profile/:id/2fa
+func (c *MotoristaController) DesativarDoisFatores(ctx *fiber.Ctx) error {
+	var req struct{ Codigo string }
+	if err := ctx.BodyParser(&req); err != nil || req.Codigo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.motoristaService.DesativarDoisFatores(ctx.Params("id"), req.Codigo); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Autenticação em dois fatores desativada"})
+}
+
+// RenovarToken POST /api/auth/refresh
+func (c *MotoristaController) RenovarToken(ctx *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	tokens, err := c.sessaoService.Renovar(req.RefreshToken, ctx.IP())
 	if err != nil {
 		return err
 	}
-	return ctx.JSON(fiber.Map{"message": "Login realizado com sucesso", "motorista": resumoMotorista(m)})
+	return ctx.JSON(fiber.Map{"tokens": tokens})
 }
 
 // --- helpers de serialização ---
@@ -281,25 +795,54 @@ func resumoMotorista(m *models.Motorista) fiber.Map {
 	return fiber.Map{"id": m.ID, "nome": m.Nome, "email": m.Email, "status": m.Status}
 }
 
+// etagMotorista identifica a versão do cadastro retornada ao cliente
+func etagMotorista(m *models.Motorista) string {
+	return `"` + strconv.FormatInt(m.Versao, 10) + `"`
+}
+
+// versaoIfMatch extrai a versão esperada do cabeçalho If-Match; ausente ou "*" não restringe (0)
+func versaoIfMatch(ifMatch string) (int64, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	// ETags fracas são aceitas: a versão é a mesma em qualquer representação
+	etag := strings.TrimPrefix(ifMatch, "W/")
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, apperrors.ErrIfMatchInvalido
+	}
+	versao, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 64)
+	if err != nil || versao < 0 {
+		return 0, apperrors.ErrIfMatchInvalido
+	}
+	return versao, nil
+}
+
 func detalhesMotorista(m *models.Motorista) fiber.Map {
 	fotoURL := ""
 	if m.FotoPerfil != "" {
 		fotoURL = "/api/profile/" + m.ID + "/photo"
 	}
 	return fiber.Map{
-		"id":              m.ID,
-		"nome":            m.Nome,
-		"email":           m.Email,
-		"telefone":        m.Telefone,
-		"cpf":             m.CPF,
-		"cnh":             m.CNH,
-		"categoria_cnh":   m.CategoriaCNH,
-		"validade_cnh":    m.ValidadeCNH,
-		"status":          m.Status,
-		"modelo_veiculo":  m.ModeloVeiculo,
-		"placa_veiculo":   m.PlacaVeiculo,
-		"criado_em":       m.CriadoEm,
-		"documentos":      m.Documentos,
-		"foto_perfil_url": fotoURL,
+		"id":               m.ID,
+		"nome":             m.Nome,
+		"email":            m.Email,
+		"email_verificado": m.EmailVerificado,
+		"email_pendente":   m.EmailPendente,
+		"telefone":         m.Telefone,
+		"cpf":              m.CPF,
+		"cnh":              m.CNH,
+		"categoria_cnh":    m.CategoriaCNH,
+		"validade_cnh":     m.ValidadeCNH,
+		"status":           m.Status,
+		"exclusao_em":      m.ExclusaoEm,
+		"modelo_veiculo":   m.ModeloVeiculo,
+		"placa_veiculo":    m.PlacaVeiculo,
+		"criado_em":        m.CriadoEm,
+		"versao":           m.Versao,
+		"documentos":       m.Documentos,
+		"foto_perfil_url":  fotoURL,
+		"revisoes":         m.Revisoes,
+		"dois_fatores":     m.DoisFatores,
 	}
 }
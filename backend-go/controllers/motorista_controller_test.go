@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"taxi_service/internal/auth"
+	"taxi_service/internal/storage"
 	"taxi_service/models"
 	"taxi_service/services"
 )
@@ -44,13 +46,50 @@ func (m *MockMotoristaService) ValidarDocumentos(motoristaID string) error {
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) AprovarMotorista(motoristaID string) error {
-	args := m.Called(motoristaID)
+func (m *MockMotoristaService) AprovarMotorista(motoristaID, operadorID string) error {
+	args := m.Called(motoristaID, operadorID)
+	return args.Error(0)
+}
+
+func (m *MockMotoristaService) RejeitarMotorista(motoristaID, operadorID, motivo string) error {
+	args := m.Called(motoristaID, operadorID, motivo)
 	return args.Error(0)
 }
 
-func (m *MockMotoristaService) RejeitarMotorista(motoristaID string, motivo string) error {
-	args := m.Called(motoristaID, motivo)
+func (m *MockMotoristaService) HistoricoDocumento(motoristaID, tipo string) ([]models.Documento, error) {
+	args := m.Called(motoristaID, tipo)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Documento), args.Error(1)
+}
+
+func (m *MockMotoristaService) VersaoDocumento(motoristaID, tipo string, versao int) (*models.Documento, error) {
+	args := m.Called(motoristaID, tipo, versao)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Documento), args.Error(1)
+}
+
+func (m *MockMotoristaService) AprovarDocumento(motoristaID, documento, operadorID string) (*models.Motorista, error) {
+	args := m.Called(motoristaID, documento, operadorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Motorista), args.Error(1)
+}
+
+func (m *MockMotoristaService) RejeitarDocumento(motoristaID, documento, operadorID, codigoMotivo, observacao string) (*models.Motorista, error) {
+	args := m.Called(motoristaID, documento, operadorID, codigoMotivo, observacao)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Motorista), args.Error(1)
+}
+
+func (m *MockMotoristaService) RegistrarVarredura(motoristaID, chaveArquivo string, infectado bool, ameaca string) error {
+	args := m.Called(motoristaID, chaveArquivo, infectado, ameaca)
 	return args.Error(0)
 }
 
@@ -67,12 +106,73 @@ func (m *MockMotoristaService) VerificarForcaSenha(senha string) (string, error)
 	return args.String(0), args.Error(1)
 }
 
+// MockVerificacaoEmailService is a mock implementation of services.VerificacaoEmailService
+type MockVerificacaoEmailService struct {
+	mock.Mock
+}
+
+func (m *MockVerificacaoEmailService) EnviarVerificacao(motoristaID string) error {
+	args := m.Called(motoristaID)
+	return args.Error(0)
+}
+
+func (m *MockVerificacaoEmailService) ConfirmarEmail(token string) (*models.Motorista, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Motorista), args.Error(1)
+}
+
+// MockSessaoService is a mock implementation of services.SessaoService
+type MockSessaoService struct {
+	mock.Mock
+}
+
+func (m *MockSessaoService) Criar(motoristaID, dispositivo, ip string) (*auth.TokenPair, string, error) {
+	args := m.Called(motoristaID, dispositivo, ip)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*auth.TokenPair), args.String(1), args.Error(2)
+}
+
+func (m *MockSessaoService) Renovar(refreshToken, ip string) (*auth.TokenPair, error) {
+	args := m.Called(refreshToken, ip)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.TokenPair), args.Error(1)
+}
+
+func (m *MockSessaoService) Listar(motoristaID string) ([]*models.Sessao, error) {
+	args := m.Called(motoristaID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Sessao), args.Error(1)
+}
+
+func (m *MockSessaoService) Revogar(motoristaID, sessaoID string) error {
+	args := m.Called(motoristaID, sessaoID)
+	return args.Error(0)
+}
+
+func (m *MockSessaoService) RevogarTodas(motoristaID, exceto string) error {
+	args := m.Called(motoristaID, exceto)
+	return args.Error(0)
+}
+
 func TestMotoristaController(t *testing.T) {
 	// setup fornece um novo app e mockService com as rotas registradas
 	setup := func() (*fiber.App, *MockMotoristaService) {
 		app := fiber.New()
 		mockService := new(MockMotoristaService)
-		controller := NewMotoristaController(mockService)
+		mockVerificacao := new(MockVerificacaoEmailService)
+		mockVerificacao.On("EnviarVerificacao", mock.Anything).Return(nil)
+		mockSessao := new(MockSessaoService)
+		mockSessao.On("RevogarTodas", mock.Anything, mock.Anything).Return(nil)
+		controller := NewMotoristaController(mockService, mockVerificacao, mockSessao, auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste"}), storage.NewLocalFileStore(t.TempDir()), nil)
 		// Registrar rotas
 		app.Post("/api/motoristas", controller.CadastrarMotorista)
 		app.Get("/api/motoristas/:id", controller.BuscarMotorista)
@@ -198,10 +298,10 @@ func TestMotoristaController(t *testing.T) {
 	t.Run("Upload documento", func(t *testing.T) {
 		app, mockService := setup()
 		uploadRequest := services.UploadDocumentoRequest{
-			TipoDocumento:  "CNH",
-			CaminhoArquivo: "/uploads/cnh.jpg",
-			Formato:        "JPG",
-			Tamanho:        2 * 1024 * 1024,
+			TipoDocumento: "CNH",
+			ChaveArquivo:  "/uploads/cnh.jpg",
+			Formato:       "JPG",
+			Tamanho:       2 * 1024 * 1024,
 		}
 
 		mockService.On("UploadDocumento", "123", uploadRequest).Return(nil)
@@ -242,7 +342,7 @@ func TestMotoristaController(t *testing.T) {
 
 	t.Run("Aprovar motorista", func(t *testing.T) {
 		app, mockService := setup()
-		mockService.On("AprovarMotorista", "123").Return(nil)
+		mockService.On("AprovarMotorista", "123", "").Return(nil)
 
 		req := httptest.NewRequest("PUT", "/api/motoristas/123/aprovar", nil)
 		resp, err := app.Test(req)
@@ -264,7 +364,7 @@ func TestMotoristaController(t *testing.T) {
 			"motivo": "Documentos com problemas de qualidade",
 		}
 
-		mockService.On("RejeitarMotorista", "123", "Documentos com problemas de qualidade").Return(nil)
+		mockService.On("RejeitarMotorista", "123", "", "Documentos com problemas de qualidade").Return(nil)
 
 		body, _ := json.Marshal(rejectRequest)
 		req := httptest.NewRequest("PUT", "/api/motoristas/123/rejeitar", bytes.NewReader(body))
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/middlewares"
+	"taxi_service/models"
+	"taxi_service/services"
+)
+
+// OperadorController gerencia as rotas de operadores internos (revisores, suporte e admins)
+type OperadorController struct {
+	operadorService services.OperadorService
+	sessaoService   services.SessaoService
+	tokenService    *auth.TokenService
+}
+
+// NewOperadorController cria uma nova instância do controller
+func NewOperadorController(operadorService services.OperadorService, sessaoService services.SessaoService, tokenService *auth.TokenService) *OperadorController {
+	return &OperadorController{
+		operadorService: operadorService,
+		sessaoService:   sessaoService,
+		tokenService:    tokenService,
+	}
+}
+
+// LoginOperador POST /api/operators/login
+func (c *OperadorController) LoginOperador(ctx *fiber.Ctx) error {
+	var req struct{ Email, Senha string }
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	o, err := c.operadorService.LoginOperador(req.Email, req.Senha, ctx.IP())
+	if err != nil {
+		return err
+	}
+	if o.DoisFatores.Ativo {
+		desafio, err := c.tokenService.GerarDesafio(o.ID, string(o.Papel))
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(fiber.Map{
+			"message":   "Informe o código de verificação",
+			"desafio":   desafio,
+			"next_step": services.ProximoPassoDoisFatores,
+		})
+	}
+	return c.concluirLogin(ctx, o)
+}
+
+// LoginSegundoFator POST /api/operators/login/2fa
+func (c *OperadorController) LoginSegundoFator(ctx *fiber.Ctx) error {
+	var req struct{ Desafio, Codigo string }
+	if err := ctx.BodyParser(&req); err != nil || req.Desafio == "" || req.Codigo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	claims, err := c.tokenService.ValidarToken(req.Desafio, auth.TokenDesafio)
+	if err != nil {
+		return err
+	}
+	if !models.PapelOperadorValido(models.Papel(claims.Papel)) {
+		return apperrors.ErrTokenInvalido
+	}
+	o, err := c.operadorService.VerificarSegundoFator(claims.Subject, req.Codigo, ctx.IP())
+	if err != nil {
+		return err
+	}
+	return c.concluirLogin(ctx, o)
+}
+
+// concluirLogin abre a sessão do dispositivo (identificado pelo User-Agent) e emite os
+// tokens do operador
+func (c *OperadorController) concluirLogin(ctx *fiber.Ctx, o *models.Operador) error {
+	tokens, _, err := c.sessaoService.Criar(o.ID, ctx.Get(fiber.HeaderUserAgent), ctx.IP())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Login realizado com sucesso", "operador": o, "tokens": tokens})
+}
+
+// IniciarDoisFatores POST /api/operators/me/2fa/enroll
+func (c *OperadorController) IniciarDoisFatores(ctx *fiber.Ctx) error {
+	inscricao, err := c.operadorService.IniciarDoisFatores(middlewares.Subject(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Escaneie o QR code e confirme com um código do aplicativo", "dois_fatores": inscricao})
+}
+
+// ConfirmarDoisFatores POST /api/operators/me/2fa/verify
+func (c *OperadorController) ConfirmarDoisFatores(ctx *fiber.Ctx) error {
+	var req struct{ Codigo string }
+	if err := ctx.BodyParser(&req); err != nil || req.Codigo == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.operadorService.ConfirmarDoisFatores(middlewares.Subject(ctx), req.Codigo); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Autenticação em dois fatores ativada"})
+}
+
+// RenovarToken POST /api/operators/refresh
+func (c *OperadorController) RenovarToken(ctx *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	// O refresh é rotacionado na sessão; o papel é relido para refletir alterações e desativações
+	tokens, err := c.sessaoService.Renovar(req.RefreshToken, ctx.IP())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"tokens": tokens})
+}
+
+// OperadorAtual GET /api/operators/me
+func (c *OperadorController) OperadorAtual(ctx *fiber.Ctx) error {
+	o, err := c.operadorService.BuscarOperador(middlewares.Subject(ctx))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"operador": o})
+}
+
+// CriarOperador POST /api/operators (somente admin)
+func (c *OperadorController) CriarOperador(ctx *fiber.Ctx) error {
+	var request services.CriarOperadorRequest
+	if err := ctx.BodyParser(&request); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	o, err := c.operadorService.CriarOperador(request)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Operador criado com sucesso", "operador": o})
+}
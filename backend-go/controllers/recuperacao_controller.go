@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/services"
+)
+
+// RecuperacaoController gerencia as rotas de recuperação de conta
+type RecuperacaoController struct {
+	recuperacaoService services.RecuperacaoService
+}
+
+// NewRecuperacaoController cria uma nova instância do controller
+func NewRecuperacaoController(recuperacaoService services.RecuperacaoService) *RecuperacaoController {
+	return &RecuperacaoController{
+		recuperacaoService: recuperacaoService,
+	}
+}
+
+// SolicitarRecuperacao POST /api/auth/recover
+func (c *RecuperacaoController) SolicitarRecuperacao(ctx *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.recuperacaoService.SolicitarRecuperacao(req.Email, ctx.IP()); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Instruções de recuperação enviadas para " + req.Email})
+}
+
+// ValidarLink POST /api/auth/reset/validate
+func (c *RecuperacaoController) ValidarLink(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrLinkInvalido
+	}
+	if err := c.recuperacaoService.ValidarLinkRedefinicao(req.Token); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"valido": true})
+}
+
+// RedefinirSenha POST /api/auth/reset
+func (c *RecuperacaoController) RedefinirSenha(ctx *fiber.Ctx) error {
+	var req struct {
+		Token       string `json:"token"`
+		NovaSenha   string `json:"nova_senha"`
+		Confirmacao string `json:"confirmacao"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := c.recuperacaoService.RedefinirSenha(req.Token, req.NovaSenha, req.Confirmacao); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Senha redefinida com sucesso"})
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"taxi_service/middlewares"
+	"taxi_service/models"
+	"taxi_service/services"
+)
+
+// SessaoController gerencia as rotas de sessões (dispositivos logados) do motorista
+type SessaoController struct {
+	sessaoService services.SessaoService
+}
+
+// NewSessaoController cria uma nova instância do controller
+func NewSessaoController(sessaoService services.SessaoService) *SessaoController {
+	return &SessaoController{
+		sessaoService: sessaoService,
+	}
+}
+
+// ListarSessoes GET /api/profile/:id/sessions
+func (c *SessaoController) ListarSessoes(ctx *fiber.Ctx) error {
+	sessoes, err := c.sessaoService.Listar(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+	atual := middlewares.Sessao(ctx)
+	resp := make([]fiber.Map, 0, len(sessoes))
+	for _, s := range sessoes {
+		resp = append(resp, resumoSessao(s, atual))
+	}
+	return ctx.JSON(fiber.Map{"sessoes": resp})
+}
+
+// RevogarSessao DELETE /api/profile/:id/sessions/:sid
+func (c *SessaoController) RevogarSessao(ctx *fiber.Ctx) error {
+	if err := c.sessaoService.Revogar(ctx.Params("id"), ctx.Params("sid")); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Sessão encerrada"})
+}
+
+// RevogarTodasSessoes DELETE /api/profile/:id/sessions (sair de todos os dispositivos)
+func (c *SessaoController) RevogarTodasSessoes(ctx *fiber.Ctx) error {
+	if err := c.sessaoService.RevogarTodas(ctx.Params("id"), ""); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Todas as sessões foram encerradas"})
+}
+
+func resumoSessao(s *models.Sessao, atual string) fiber.Map {
+	return fiber.Map{
+		"id":            s.ID,
+		"dispositivo":   s.Dispositivo,
+		"ip":            s.IP,
+		"criada_em":     s.CriadaEm,
+		"ultimo_acesso": s.UltimoAcesso,
+		"atual":         s.ID == atual,
+	}
+}
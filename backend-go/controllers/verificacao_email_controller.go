@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/services"
+)
+
+// VerificacaoEmailController gerencia as rotas de confirmação de email
+type VerificacaoEmailController struct {
+	verificacaoService services.VerificacaoEmailService
+}
+
+// NewVerificacaoEmailController cria uma nova instância do controller
+func NewVerificacaoEmailController(verificacaoService services.VerificacaoEmailService) *VerificacaoEmailController {
+	return &VerificacaoEmailController{
+		verificacaoService: verificacaoService,
+	}
+}
+
+// ConfirmarEmail POST /api/auth/verify-email
+func (c *VerificacaoEmailController) ConfirmarEmail(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return apperrors.ErrCampoObrigatorio
+	}
+	m, err := c.verificacaoService.ConfirmarEmail(req.Token)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Email confirmado com sucesso", "motorista": resumoMotorista(m)})
+}
+
+// ReenviarVerificacao POST /api/profile/:id/email/verification
+func (c *VerificacaoEmailController) ReenviarVerificacao(ctx *fiber.Ctx) error {
+	if err := c.verificacaoService.EnviarVerificacao(ctx.Params("id")); err != nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{"message": "Link de confirmação enviado"})
+}
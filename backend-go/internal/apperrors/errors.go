@@ -48,6 +48,49 @@ var (
 	ErrDocumentoNaoEncontrado   = New("documento.nao_encontrado", "documento não encontrado", fiber.StatusNotFound)
 	ErrArquivoNaoEncontrado     = New("arquivo.nao_encontrado", "arquivo não encontrado", fiber.StatusNotFound)
 	ErrFotoNaoEncontrada        = New("foto.nao_encontrada", "foto não encontrada", fiber.StatusNotFound)
+	ErrTokenAusente             = New("auth.token_ausente", "token de acesso ausente", fiber.StatusUnauthorized)
+	ErrTokenInvalido            = New("auth.token_invalido", "token inválido", fiber.StatusUnauthorized)
+	ErrTokenExpirado            = New("auth.token_expirado", "token expirado", fiber.StatusUnauthorized)
+	ErrAcessoNegado             = New("auth.acesso_negado", "acesso negado", fiber.StatusForbidden)
+	ErrTokenRevogado            = New("auth.token_revogado", "sessão encerrada. Faça login novamente", fiber.StatusUnauthorized)
+	ErrEmailNaoCadastrado       = New("recuperacao.email_nao_cadastrado", "O E-mail informado não está cadastrado.", fiber.StatusNotFound)
+	ErrLinkInvalido             = New("recuperacao.link_invalido", "Este link não é válido.", fiber.StatusBadRequest)
+	ErrEmailNaoVerificado       = New("motorista.email_nao_verificado", "e-mail ainda não verificado", fiber.StatusBadRequest)
+	ErrEmailJaVerificado        = New("verificacao.email_ja_verificado", "e-mail já verificado", fiber.StatusConflict)
+	ErrLoginDocumentosEmAnalise = New("login.documentos_em_analise", "Seus documentos ainda estão em análise.", fiber.StatusForbidden)
+	ErrLoginAguardandoExclusao  = New("login.aguardando_exclusao", "Sua conta está em processo de exclusão. Contate o suporte se isso for um erro.", fiber.StatusForbidden)
+	ErrLoginContaEncerrada      = New("login.conta_encerrada", "Sua conta foi encerrada. Em caso de dúvidas, contate o suporte.", fiber.StatusForbidden)
+	ErrLoginBloqueado           = New("auth.login_bloqueado", "Muitas tentativas de login. Tente novamente mais tarde.", fiber.StatusTooManyRequests)
+	ErrCredenciaisInvalidas     = New("auth.credenciais_invalidas", "credenciais inválidas", fiber.StatusUnauthorized)
+	ErrCodigo2FAInvalido        = New("2fa.codigo_invalido", "código de verificação inválido", fiber.StatusUnauthorized)
+	ErrDoisFatoresJaAtivo       = New("2fa.ja_ativo", "autenticação em dois fatores já está ativa", fiber.StatusConflict)
+	ErrDoisFatoresNaoIniciado   = New("2fa.nao_iniciado", "inicie a configuração da autenticação em dois fatores", fiber.StatusBadRequest)
+	ErrDoisFatoresInativo       = New("2fa.inativo", "autenticação em dois fatores não está ativa", fiber.StatusBadRequest)
+	ErrSessaoNaoEncontrada      = New("sessao.nao_encontrada", "sessão não encontrada", fiber.StatusNotFound)
+	ErrExclusaoNaoSolicitada    = New("exclusao.nao_solicitada", "exclusão de conta não solicitada", fiber.StatusConflict)
+	ErrConflitoVersao           = New("motorista.conflito_versao", "o cadastro foi alterado por outra requisição; recarregue e tente novamente", fiber.StatusConflict)
+	ErrVersaoDesatualizada      = New("motorista.versao_desatualizada", "o cadastro foi alterado desde a última leitura (If-Match)", fiber.StatusPreconditionFailed)
+	ErrIfMatchInvalido          = New("requisicao.if_match_invalido", "cabeçalho If-Match inválido", fiber.StatusBadRequest)
+	ErrFiltroInvalido           = New("requisicao.filtro_invalido", "parâmetros de filtro inválidos", fiber.StatusBadRequest)
+	ErrCursorInvalido           = New("requisicao.cursor_invalido", "cursor de paginação inválido", fiber.StatusBadRequest)
+	ErrArquivoCorrompido        = New("arquivo.integridade_violada", "o arquivo armazenado não confere com o checksum registrado", fiber.StatusInternalServerError)
+	ErrMotivoRejeicaoInvalido   = New("documento.motivo_invalido", "motivo de rejeição inválido", fiber.StatusBadRequest)
+	ErrRevisaoNaoPermitida      = New("documento.revisao_nao_permitida", "o cadastro não está em revisão de documentos", fiber.StatusConflict)
+	ErrDocumentosRejeitados     = New("documento.rejeitados_pendentes", "há documentos rejeitados aguardando reenvio", fiber.StatusConflict)
+	ErrConteudoDesconhecido     = New("validation.conteudo_desconhecido", "o conteúdo do arquivo não é de um formato aceito", fiber.StatusBadRequest)
+	ErrPDFInvalido              = New("validation.pdf_invalido", "PDF corrompido ou malformado", fiber.StatusBadRequest)
+	ErrPDFCriptografado         = New("validation.pdf_criptografado", "PDF protegido por senha ou criptografado não é aceito", fiber.StatusBadRequest)
+	ErrPDFPaginasExcedidas      = New("validation.pdf_paginas", "PDF excede o limite de páginas", fiber.StatusBadRequest)
+	ErrImagemInvalida           = New("validation.imagem_invalida", "imagem corrompida ou malformada", fiber.StatusBadRequest)
+	ErrImagemResolucao          = New("validation.imagem_resolucao", "imagem excede a resolução máxima", fiber.StatusBadRequest)
+	ErrTamanhoFotoInvalido      = New("requisicao.tamanho_foto_invalido", "tamanho de foto inválido. Use thumb, small ou medium", fiber.StatusBadRequest)
+	ErrDocumentoEmVerificacao   = New("documento.em_verificacao", "o arquivo do documento ainda está em verificação antivírus", fiber.StatusConflict)
+	ErrDocumentoInfectado       = New("documento.infectado", "o arquivo do documento foi bloqueado pela verificação antivírus", fiber.StatusForbidden)
+	ErrRecuperacaoBloqueada     = New("recuperacao.bloqueada", "Muitas solicitações de recuperação. Tente novamente mais tarde.", fiber.StatusTooManyRequests)
+	ErrExclusaoConcluida        = New("exclusao.concluida", "o período para cancelar a exclusão da conta terminou", fiber.StatusConflict)
+	ErrOperadorNaoEncontrado    = New("operador.nao_encontrado", "operador não encontrado", fiber.StatusNotFound)
+	ErrOperadorInativo          = New("operador.inativo", "operador inativo", fiber.StatusForbidden)
+	ErrPapelInvalido            = New("operador.papel_invalido", "papel de operador inválido", fiber.StatusBadRequest)
 )
 
 // HTTPStatus retorna status adequado.
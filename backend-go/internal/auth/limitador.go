@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"taxi_service/internal/apperrors"
+)
+
+// TentativasStore guarda as falhas de login e os bloqueios temporários por chave.
+// A implementação em memória atende uma única instância; uma store compartilhada
+// pode substituí-la sem alterar o LimitadorLogin.
+type TentativasStore interface {
+	// RegistrarFalha adiciona uma falha e retorna quantas existem dentro da janela
+	RegistrarFalha(chave string, agora time.Time, janela time.Duration) (int, error)
+	LimparFalhas(chave string) error
+	Bloquear(chave string, ate time.Time) error
+	// BloqueadoAte retorna o fim do bloqueio (zero se a chave nunca foi bloqueada)
+	BloqueadoAte(chave string) (time.Time, error)
+}
+
+// LimitadorConfig parâmetros da proteção contra força bruta
+type LimitadorConfig struct {
+	Janela         time.Duration // janela deslizante de contagem (padrão 15min)
+	MaxFalhasConta int           // falhas por email antes do bloqueio (padrão 5)
+	MaxFalhasIP    int           // falhas por IP antes do bloqueio (padrão 20)
+	Bloqueio       time.Duration // duração do bloqueio (padrão 15min)
+}
+
+// LimitadorLogin aplica limite de falhas de login por conta e por IP
+type LimitadorLogin struct {
+	config LimitadorConfig
+	store  TentativasStore
+	now    func() time.Time
+}
+
+// NewLimitadorLogin cria o limitador preenchendo os valores padrão ausentes
+func NewLimitadorLogin(config LimitadorConfig, store TentativasStore) *LimitadorLogin {
+	if config.Janela <= 0 {
+		config.Janela = 15 * time.Minute
+	}
+	if config.MaxFalhasConta <= 0 {
+		config.MaxFalhasConta = 5
+	}
+	if config.MaxFalhasIP <= 0 {
+		config.MaxFalhasIP = 20
+	}
+	if config.Bloqueio <= 0 {
+		config.Bloqueio = 15 * time.Minute
+	}
+	return &LimitadorLogin{config: config, store: store, now: time.Now}
+}
+
+// Verificar retorna ErrLoginBloqueado se a conta ou o IP estiverem bloqueados
+func (l *LimitadorLogin) Verificar(email, ip string) error {
+	agora := l.now()
+	for _, chave := range chavesLogin(email, ip) {
+		ate, err := l.store.BloqueadoAte(chave)
+		if err != nil {
+			return err
+		}
+		if agora.Before(ate) {
+			return apperrors.ErrLoginBloqueado
+		}
+	}
+	return nil
+}
+
+// RegistrarFalha contabiliza uma falha para o email e o IP.
+// Retorna o fim do bloqueio quando a conta acabou de ser bloqueada (zero caso contrário).
+func (l *LimitadorLogin) RegistrarFalha(email, ip string) (time.Time, error) {
+	agora := l.now()
+	ate := agora.Add(l.config.Bloqueio)
+
+	if ip != "" {
+		chave := chaveIP(ip)
+		falhas, err := l.store.RegistrarFalha(chave, agora, l.config.Janela)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if falhas >= l.config.MaxFalhasIP {
+			if err := l.bloquear(chave, ate); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+
+	chave := chaveConta(email)
+	falhas, err := l.store.RegistrarFalha(chave, agora, l.config.Janela)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if falhas < l.config.MaxFalhasConta {
+		return time.Time{}, nil
+	}
+	if err := l.bloquear(chave, ate); err != nil {
+		return time.Time{}, err
+	}
+	return ate, nil
+}
+
+// RegistrarTentativaIP contabiliza no limite do IP uma requisição que revela se um email
+// está cadastrado (ex.: recuperação de conta), compartilhando o orçamento com as falhas
+// de login. Retorna ErrLoginBloqueado se o IP já estava bloqueado.
+func (l *LimitadorLogin) RegistrarTentativaIP(ip string) error {
+	if ip == "" {
+		return nil
+	}
+	agora := l.now()
+	chave := chaveIP(ip)
+	ate, err := l.store.BloqueadoAte(chave)
+	if err != nil {
+		return err
+	}
+	if agora.Before(ate) {
+		return apperrors.ErrLoginBloqueado
+	}
+	tentativas, err := l.store.RegistrarFalha(chave, agora, l.config.Janela)
+	if err != nil {
+		return err
+	}
+	if tentativas >= l.config.MaxFalhasIP {
+		return l.bloquear(chave, agora.Add(l.config.Bloqueio))
+	}
+	return nil
+}
+
+// RegistrarSucesso zera as falhas da conta após um login bem-sucedido
+func (l *LimitadorLogin) RegistrarSucesso(email string) error {
+	return l.store.LimparFalhas(chaveConta(email))
+}
+
+// bloquear aplica o bloqueio e reinicia a contagem, para que a janela recomece ao fim dele
+func (l *LimitadorLogin) bloquear(chave string, ate time.Time) error {
+	if err := l.store.Bloquear(chave, ate); err != nil {
+		return err
+	}
+	return l.store.LimparFalhas(chave)
+}
+
+func chaveConta(email string) string { return "conta:" + strings.ToLower(strings.TrimSpace(email)) }
+
+func chaveIP(ip string) string { return "ip:" + ip }
+
+func chavesLogin(email, ip string) []string {
+	chaves := []string{chaveConta(email)}
+	if ip != "" {
+		chaves = append(chaves, chaveIP(ip))
+	}
+	return chaves
+}
+
+// TentativasMemoria implementação de TentativasStore em memória do processo. Chaves sem
+// falhas na janela e com o bloqueio vencido são descartadas, para que emails e IPs
+// tentados uma única vez não fiquem em memória indefinidamente.
+type TentativasMemoria struct {
+	mu            sync.Mutex
+	falhas        map[string][]time.Time
+	bloqueios     map[string]time.Time
+	ultimaLimpeza time.Time
+}
+
+// NewTentativasMemoria cria uma store vazia
+func NewTentativasMemoria() *TentativasMemoria {
+	return &TentativasMemoria{
+		falhas:    make(map[string][]time.Time),
+		bloqueios: make(map[string]time.Time),
+	}
+}
+
+// RegistrarFalha descarta as falhas fora da janela e adiciona a atual
+func (t *TentativasMemoria) RegistrarFalha(chave string, agora time.Time, janela time.Duration) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limite := agora.Add(-janela)
+	recentes := append(falhasRecentes(t.falhas[chave], limite), agora)
+	t.falhas[chave] = recentes
+	// Varredura completa no máximo uma vez por janela, mantendo o custo amortizado constante
+	if agora.Sub(t.ultimaLimpeza) >= janela {
+		t.limpar(agora, limite)
+		t.ultimaLimpeza = agora
+	}
+	return len(recentes), nil
+}
+
+// limpar descarta as chaves sem falhas depois de limite e os bloqueios vencidos
+func (t *TentativasMemoria) limpar(agora, limite time.Time) {
+	for chave, falhas := range t.falhas {
+		if recentes := falhasRecentes(falhas, limite); len(recentes) > 0 {
+			t.falhas[chave] = recentes
+		} else {
+			delete(t.falhas, chave)
+		}
+	}
+	for chave, ate := range t.bloqueios {
+		if !agora.Before(ate) {
+			delete(t.bloqueios, chave)
+		}
+	}
+}
+
+// falhasRecentes mantém, no mesmo slice, as falhas posteriores a limite
+func falhasRecentes(falhas []time.Time, limite time.Time) []time.Time {
+	recentes := falhas[:0]
+	for _, f := range falhas {
+		if f.After(limite) {
+			recentes = append(recentes, f)
+		}
+	}
+	return recentes
+}
+
+// LimparFalhas remove o histórico de falhas da chave
+func (t *TentativasMemoria) LimparFalhas(chave string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.falhas, chave)
+	return nil
+}
+
+// Bloquear registra o bloqueio da chave até o instante informado
+func (t *TentativasMemoria) Bloquear(chave string, ate time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bloqueios[chave] = ate
+	return nil
+}
+
+// BloqueadoAte retorna o fim do bloqueio da chave
+func (t *TentativasMemoria) BloqueadoAte(chave string) (time.Time, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bloqueios[chave], nil
+}
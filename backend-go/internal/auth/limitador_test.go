@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+)
+
+func TestLimitadorLogin(t *testing.T) {
+	agora := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	novo := func() *LimitadorLogin {
+		l := NewLimitadorLogin(LimitadorConfig{MaxFalhasConta: 3, MaxFalhasIP: 5}, NewTentativasMemoria())
+		l.now = func() time.Time { return agora }
+		return l
+	}
+
+	t.Run("Bloqueia a conta após o limite de falhas", func(t *testing.T) {
+		l := novo()
+		for i := 0; i < 2; i++ {
+			ate, err := l.RegistrarFalha("joao@email.com", "10.0.0.1")
+			require.NoError(t, err)
+			assert.True(t, ate.IsZero())
+		}
+		require.NoError(t, l.Verificar("joao@email.com", "10.0.0.1"))
+
+		ate, err := l.RegistrarFalha("JOAO@email.com", "10.0.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, agora.Add(15*time.Minute), ate)
+		assert.Equal(t, apperrors.ErrLoginBloqueado, l.Verificar("joao@email.com", "10.0.0.3"))
+		assert.NoError(t, l.Verificar("maria@email.com", "10.0.0.3"))
+	})
+
+	t.Run("Bloqueio expira", func(t *testing.T) {
+		l := novo()
+		for i := 0; i < 3; i++ {
+			_, err := l.RegistrarFalha("joao@email.com", "")
+			require.NoError(t, err)
+		}
+		l.now = func() time.Time { return agora.Add(16 * time.Minute) }
+		assert.NoError(t, l.Verificar("joao@email.com", ""))
+	})
+
+	t.Run("Falhas fora da janela não contam", func(t *testing.T) {
+		l := novo()
+		for i := 0; i < 2; i++ {
+			_, err := l.RegistrarFalha("joao@email.com", "")
+			require.NoError(t, err)
+		}
+		l.now = func() time.Time { return agora.Add(20 * time.Minute) }
+		ate, err := l.RegistrarFalha("joao@email.com", "")
+		require.NoError(t, err)
+		assert.True(t, ate.IsZero())
+	})
+
+	t.Run("Tentativas por IP compartilham o limite das falhas de login", func(t *testing.T) {
+		l := novo()
+		for i := 0; i < 4; i++ {
+			require.NoError(t, l.RegistrarTentativaIP("10.0.0.1"))
+		}
+		_, err := l.RegistrarFalha("joao@email.com", "10.0.0.1")
+		require.NoError(t, err)
+
+		assert.Equal(t, apperrors.ErrLoginBloqueado, l.RegistrarTentativaIP("10.0.0.1"))
+		assert.Equal(t, apperrors.ErrLoginBloqueado, l.Verificar("maria@email.com", "10.0.0.1"))
+		assert.NoError(t, l.RegistrarTentativaIP("10.0.0.2"))
+		assert.NoError(t, l.RegistrarTentativaIP(""))
+	})
+
+	t.Run("Sucesso zera as falhas da conta", func(t *testing.T) {
+		l := novo()
+		for i := 0; i < 2; i++ {
+			_, err := l.RegistrarFalha("joao@email.com", "")
+			require.NoError(t, err)
+		}
+		require.NoError(t, l.RegistrarSucesso("joao@email.com"))
+		ate, err := l.RegistrarFalha("joao@email.com", "")
+		require.NoError(t, err)
+		assert.True(t, ate.IsZero())
+	})
+
+	t.Run("Bloqueia o IP que tenta várias contas", func(t *testing.T) {
+		l := novo()
+		for _, email := range []string{"a@email.com", "b@email.com", "c@email.com", "d@email.com", "e@email.com"} {
+			_, err := l.RegistrarFalha(email, "10.0.0.9")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, apperrors.ErrLoginBloqueado, l.Verificar("f@email.com", "10.0.0.9"))
+		assert.NoError(t, l.Verificar("f@email.com", "10.0.0.10"))
+	})
+}
+
+func TestTentativasMemoriaDescartaChavesVencidas(t *testing.T) {
+	store := NewTentativasMemoria()
+	agora := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	janela := 15 * time.Minute
+
+	for _, chave := range []string{"conta:a@email.com", "conta:b@email.com", "ip:10.0.0.1"} {
+		_, err := store.RegistrarFalha(chave, agora, janela)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.Bloquear("ip:10.0.0.1", agora.Add(janela)))
+
+	// Depois de uma janela, só a chave com falha recente permanece
+	depois := agora.Add(janela + time.Minute)
+	_, err := store.RegistrarFalha("conta:c@email.com", depois, janela)
+	require.NoError(t, err)
+	assert.Len(t, store.falhas, 1)
+	assert.Contains(t, store.falhas, "conta:c@email.com")
+	assert.Empty(t, store.bloqueios)
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher define a interface para hash e verificação de senhas
+type PasswordHasher interface {
+	Hash(senha string) (string, error)
+	Comparar(hash, senha string) bool
+	PrecisaRehash(hash string) bool
+}
+
+// HashFicticio hash bcrypt (custo padrão) de uma senha aleatória descartada. Quando o
+// usuário não existe, a senha informada é comparada com ele para que a resposta leve
+// o mesmo tempo de uma senha incorreta e não revele quais emails estão cadastrados.
+const HashFicticio = "$2a$10$1JcDnVXtQQc8wMfxkIN4KuGiIxjwfzUzfVMBCIdWqOi/tf3BXbjZa"
+
+// BcryptHasher implementa PasswordHasher usando bcrypt
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher cria um hasher bcrypt com o custo informado
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash gera o hash bcrypt da senha
+func (h *BcryptHasher) Hash(senha string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(senha), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Comparar verifica a senha contra o hash armazenado.
+// Registros antigos gravados em texto puro são comparados em tempo constante
+// para permitir a migração transparente no próximo login.
+func (h *BcryptHasher) Comparar(hash, senha string) bool {
+	if !ehBcrypt(hash) {
+		return hash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(senha)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(senha)) == nil
+}
+
+// PrecisaRehash indica se o valor armazenado é texto puro ou usa custo diferente do atual
+func (h *BcryptHasher) PrecisaRehash(hash string) bool {
+	if !ehBcrypt(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// ehBcrypt verifica se o valor tem o formato de um hash bcrypt ($2a$, $2b$, $2y$)
+func ehBcrypt(hash string) bool {
+	return len(hash) == 60 && (strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$"))
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	t.Run("Hash e comparação", func(t *testing.T) {
+		hash, err := hasher.Hash("MinhaSenh@123")
+		require.NoError(t, err)
+		assert.NotEqual(t, "MinhaSenh@123", hash)
+		assert.True(t, hasher.Comparar(hash, "MinhaSenh@123"))
+		assert.False(t, hasher.Comparar(hash, "OutraSenh@123"))
+		assert.False(t, hasher.PrecisaRehash(hash))
+	})
+
+	t.Run("Senha legada em texto puro", func(t *testing.T) {
+		assert.True(t, hasher.Comparar("MinhaSenh@123", "MinhaSenh@123"))
+		assert.False(t, hasher.Comparar("MinhaSenh@123", "OutraSenh@123"))
+		assert.True(t, hasher.PrecisaRehash("MinhaSenh@123"))
+	})
+
+	t.Run("Senha vazia nunca confere", func(t *testing.T) {
+		assert.False(t, hasher.Comparar("", ""))
+	})
+
+	t.Run("Custo diferente exige rehash", func(t *testing.T) {
+		outro := NewBcryptHasher(bcrypt.MinCost + 1)
+		hash, err := outro.Hash("MinhaSenh@123")
+		require.NoError(t, err)
+		assert.True(t, hasher.Comparar(hash, "MinhaSenh@123"))
+		assert.True(t, hasher.PrecisaRehash(hash))
+	})
+
+	t.Run("Hash fictício tem o custo padrão", func(t *testing.T) {
+		custo, err := bcrypt.Cost([]byte(HashFicticio))
+		require.NoError(t, err)
+		assert.Equal(t, bcrypt.DefaultCost, custo)
+		assert.False(t, hasher.Comparar(HashFicticio, ""))
+	})
+}
@@ -0,0 +1,15 @@
+package auth
+
+// RevogacaoStore mantém a geração atual de tokens de cada subject.
+// Tokens carregam a geração vigente no momento da emissão; revogar
+// incrementa a geração e invalida todos os tokens emitidos antes.
+type RevogacaoStore interface {
+	Geracao(subject string) (int, error)
+	Revogar(subject string) error
+}
+
+// SessaoStore informa se uma sessão (família de refresh tokens) continua ativa.
+// Tokens vinculados a uma sessão revogada ou expirada são recusados.
+type SessaoStore interface {
+	SessaoAtiva(id string) (bool, error)
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"taxi_service/internal/apperrors"
+)
+
+// Tipos de token emitidos pelo TokenService
+const (
+	TokenAcesso  = "access"
+	TokenRefresh = "refresh"
+	TokenDesafio = "2fa" // emitido após a senha quando o principal tem 2FA ativo
+)
+
+// validadeDesafio tempo para informar o segundo fator após a senha
+const validadeDesafio = 5 * time.Minute
+
+// Claims representa o conteúdo dos tokens emitidos
+type Claims struct {
+	Tipo    string `json:"typ"`
+	Papel   string `json:"role"`
+	Geracao int    `json:"gen,omitempty"`
+	Sessao  string `json:"sid,omitempty"` // sessão (família de refresh tokens) a que o token pertence
+	jwt.RegisteredClaims
+}
+
+// TokenPair representa o par de tokens devolvido no login
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+
+	RefreshID       string    `json:"-"` // jti do refresh token, usado na rotação da sessão
+	RefreshExpiraEm time.Time `json:"-"`
+}
+
+// TokenConfig configuração para o serviço de tokens
+type TokenConfig struct {
+	Secret     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	Revogacoes RevogacaoStore // opcional; sem ele tokens só expiram pelo TTL
+	Sessoes    SessaoStore    // opcional; sem ele a revogação de sessões não é verificada
+}
+
+// TokenService emite e valida tokens JWT assinados com HMAC-SHA256
+type TokenService struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	revogacoes RevogacaoStore
+	sessoes    SessaoStore
+	now        func() time.Time
+}
+
+// NewTokenService cria uma nova instância do serviço de tokens
+func NewTokenService(config TokenConfig) *TokenService {
+	if config.AccessTTL == 0 {
+		config.AccessTTL = 15 * time.Minute
+	}
+	if config.RefreshTTL == 0 {
+		config.RefreshTTL = 7 * 24 * time.Hour
+	}
+	return &TokenService{
+		secret:     []byte(config.Secret),
+		accessTTL:  config.AccessTTL,
+		refreshTTL: config.RefreshTTL,
+		revogacoes: config.Revogacoes,
+		sessoes:    config.Sessoes,
+		now:        time.Now,
+	}
+}
+
+// NewTokenServiceFromEnv cria uma instância usando variáveis de ambiente
+func NewTokenServiceFromEnv(revogacoes RevogacaoStore, sessoes SessaoStore) *TokenService {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		// Sem segredo configurado (modo desenvolvimento) os tokens valem apenas até o próximo restart
+		log.Print("JWT_SECRET não configurado; usando segredo aleatório")
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			log.Fatalf("falha ao gerar segredo JWT: %v", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	config := TokenConfig{Secret: secret, Revogacoes: revogacoes, Sessoes: sessoes}
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TTL")); err == nil {
+		config.AccessTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_REFRESH_TTL")); err == nil {
+		config.RefreshTTL = ttl
+	}
+	return NewTokenService(config)
+}
+
+// GerarTokens emite um novo par de tokens de acesso e refresh para o principal com o papel informado
+func (s *TokenService) GerarTokens(subject, papel string) (*TokenPair, error) {
+	return s.GerarTokensSessao(subject, papel, "")
+}
+
+// GerarTokensSessao emite o par de tokens vinculado à sessão informada.
+// Depois que a sessão é revogada, seus tokens deixam de ser aceitos por ValidarToken.
+func (s *TokenService) GerarTokensSessao(subject, papel, sessao string) (*TokenPair, error) {
+	geracao, err := s.geracao(subject)
+	if err != nil {
+		return nil, err
+	}
+	access, _, err := s.assinar(subject, papel, TokenAcesso, sessao, geracao, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, claims, err := s.assinar(subject, papel, TokenRefresh, sessao, geracao, s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:     access,
+		RefreshToken:    refresh,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(s.accessTTL.Seconds()),
+		RefreshID:       claims.ID,
+		RefreshExpiraEm: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// GerarDesafio emite o token de curta duração que liga a senha já validada à etapa do segundo fator
+func (s *TokenService) GerarDesafio(subject, papel string) (string, error) {
+	geracao, err := s.geracao(subject)
+	if err != nil {
+		return "", err
+	}
+	token, _, err := s.assinar(subject, papel, TokenDesafio, "", geracao, validadeDesafio)
+	return token, err
+}
+
+// ValidarToken verifica assinatura, expiração e tipo do token
+func (s *TokenService) ValidarToken(token, tipo string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithTimeFunc(s.now))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, apperrors.ErrTokenExpirado
+		}
+		return nil, apperrors.ErrTokenInvalido
+	}
+	if claims.Tipo != tipo || claims.Subject == "" || claims.Papel == "" {
+		return nil, apperrors.ErrTokenInvalido
+	}
+	geracao, err := s.geracao(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Geracao != geracao {
+		return nil, apperrors.ErrTokenRevogado
+	}
+	if claims.Sessao != "" && s.sessoes != nil {
+		ativa, err := s.sessoes.SessaoAtiva(claims.Sessao)
+		if err != nil {
+			return nil, err
+		}
+		if !ativa {
+			return nil, apperrors.ErrTokenRevogado
+		}
+	}
+	return claims, nil
+}
+
+// RevogarTokens invalida todos os tokens já emitidos para o subject
+func (s *TokenService) RevogarTokens(subject string) error {
+	if s.revogacoes == nil {
+		return nil
+	}
+	return s.revogacoes.Revogar(subject)
+}
+
+// GeracaoAtual retorna a geração de tokens vigente para o subject
+func (s *TokenService) GeracaoAtual(subject string) (int, error) {
+	return s.geracao(subject)
+}
+
+// geracao retorna a geração vigente do subject (0 sem RevogacaoStore)
+func (s *TokenService) geracao(subject string) (int, error) {
+	if s.revogacoes == nil {
+		return 0, nil
+	}
+	return s.revogacoes.Geracao(subject)
+}
+
+// assinar monta e assina um token do tipo informado
+func (s *TokenService) assinar(subject, papel, tipo, sessao string, geracao int, ttl time.Duration) (string, *Claims, error) {
+	agora := s.now()
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	claims := &Claims{
+		Tipo:    tipo,
+		Papel:   papel,
+		Geracao: geracao,
+		Sessao:  sessao,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(agora),
+			ExpiresAt: jwt.NewNumericDate(agora.Add(ttl)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
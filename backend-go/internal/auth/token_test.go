@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+)
+
+func TestTokenService(t *testing.T) {
+	service := NewTokenService(TokenConfig{Secret: "segredo-de-teste"})
+
+	t.Run("Gerar e validar tokens", func(t *testing.T) {
+		tokens, err := service.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, int64(15*60), tokens.ExpiresIn)
+
+		claims, err := service.ValidarToken(tokens.AccessToken, TokenAcesso)
+		require.NoError(t, err)
+		assert.Equal(t, "motorista-1", claims.Subject)
+		assert.Equal(t, "motorista", claims.Papel)
+
+		claims, err = service.ValidarToken(tokens.RefreshToken, TokenRefresh)
+		require.NoError(t, err)
+		assert.Equal(t, "motorista-1", claims.Subject)
+	})
+
+	t.Run("Erro ao usar refresh como token de acesso", func(t *testing.T) {
+		tokens, err := service.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+
+		_, err = service.ValidarToken(tokens.RefreshToken, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+
+	t.Run("Erro com assinatura de outro segredo", func(t *testing.T) {
+		outro := NewTokenService(TokenConfig{Secret: "outro-segredo"})
+		tokens, err := outro.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+
+		_, err = service.ValidarToken(tokens.AccessToken, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+
+	t.Run("Erro com token expirado", func(t *testing.T) {
+		expirado := NewTokenService(TokenConfig{Secret: "segredo-de-teste"})
+		expirado.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		tokens, err := expirado.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+
+		_, err = service.ValidarToken(tokens.AccessToken, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenExpirado, err)
+	})
+
+	t.Run("Revogação invalida tokens emitidos antes", func(t *testing.T) {
+		store := &revogacoesMemoria{geracoes: map[string]int{}}
+		comRevogacao := NewTokenService(TokenConfig{Secret: "segredo-de-teste", Revogacoes: store})
+
+		antigos, err := comRevogacao.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+		require.NoError(t, comRevogacao.RevogarTokens("motorista-1"))
+
+		_, err = comRevogacao.ValidarToken(antigos.AccessToken, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		_, err = comRevogacao.ValidarToken(antigos.RefreshToken, TokenRefresh)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+
+		novos, err := comRevogacao.GerarTokens("motorista-1", "motorista")
+		require.NoError(t, err)
+		_, err = comRevogacao.ValidarToken(novos.AccessToken, TokenAcesso)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Sessão revogada invalida seus tokens", func(t *testing.T) {
+		sessoes := sessoesMemoria{"sessao-1": true, "sessao-2": true}
+		comSessoes := NewTokenService(TokenConfig{Secret: "segredo-de-teste", Sessoes: sessoes})
+
+		tokens, err := comSessoes.GerarTokensSessao("motorista-1", "motorista", "sessao-1")
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.RefreshID)
+		claims, err := comSessoes.ValidarToken(tokens.RefreshToken, TokenRefresh)
+		require.NoError(t, err)
+		assert.Equal(t, "sessao-1", claims.Sessao)
+		assert.Equal(t, tokens.RefreshID, claims.ID)
+
+		outra, err := comSessoes.GerarTokensSessao("motorista-1", "motorista", "sessao-2")
+		require.NoError(t, err)
+		sessoes["sessao-1"] = false
+
+		_, err = comSessoes.ValidarToken(tokens.AccessToken, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		_, err = comSessoes.ValidarToken(outra.AccessToken, TokenAcesso)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Desafio de 2FA não autentica como acesso", func(t *testing.T) {
+		desafio, err := service.GerarDesafio("motorista-1", "motorista")
+		require.NoError(t, err)
+
+		claims, err := service.ValidarToken(desafio, TokenDesafio)
+		require.NoError(t, err)
+		assert.Equal(t, "motorista-1", claims.Subject)
+		_, err = service.ValidarToken(desafio, TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+
+	t.Run("Erro com token malformado", func(t *testing.T) {
+		_, err := service.ValidarToken("nao.e.jwt", TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+}
+
+// revogacoesMemoria implementação em memória de RevogacaoStore para testes
+type revogacoesMemoria struct {
+	geracoes map[string]int
+}
+
+func (r *revogacoesMemoria) Geracao(subject string) (int, error) { return r.geracoes[subject], nil }
+
+func (r *revogacoesMemoria) Revogar(subject string) error {
+	r.geracoes[subject]++
+	return nil
+}
+
+// sessoesMemoria implementação em memória de SessaoStore para testes
+type sessoesMemoria map[string]bool
+
+func (s sessoesMemoria) SessaoAtiva(id string) (bool, error) { return s[id], nil }
+
+func TestGerarTokenOpaco(t *testing.T) {
+	token, hash, err := GerarTokenOpaco()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, HashToken(token), hash)
+	assert.NotEqual(t, token, hash)
+
+	outro, _, err := GerarTokenOpaco()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, outro)
+}
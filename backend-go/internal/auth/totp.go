@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros TOTP (RFC 6238) compatíveis com os aplicativos autenticadores comuns
+const (
+	totpDigitos    = 6
+	totpModulo     = 1_000_000 // 10^totpDigitos
+	totpPeriodo    = 30        // segundos
+	totpTolerancia = 1         // passos aceitos antes e depois do atual (diferença de relógio)
+)
+
+var base32SemPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GerarSegredoTOTP gera um segredo aleatório de 160 bits codificado em base32
+func GerarSegredoTOTP() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32SemPadding.EncodeToString(buf), nil
+}
+
+// URIOTPAuth monta a URI otpauth:// lida pelos aplicativos autenticadores (via QR code)
+func URIOTPAuth(emissor, conta, segredo string) string {
+	params := url.Values{}
+	params.Set("secret", segredo)
+	params.Set("issuer", emissor)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigitos))
+	params.Set("period", fmt.Sprint(totpPeriodo))
+	return "otpauth://totp/" + url.PathEscape(emissor+":"+conta) + "?" + params.Encode()
+}
+
+// CodigoTOTP calcula o código do segredo para o instante informado
+func CodigoTOTP(segredo string, t time.Time) (string, error) {
+	chave, err := base32SemPadding.DecodeString(strings.ToUpper(segredo))
+	if err != nil {
+		return "", err
+	}
+	return codigoPasso(chave, passoTOTP(t)), nil
+}
+
+// ValidarTOTP confere o código dentro da tolerância de relógio.
+// Passos já usados (até ultimoPasso) são recusados para impedir a reutilização do código.
+// Retorna o passo aceito, que deve ser persistido como novo ultimoPasso.
+func ValidarTOTP(segredo, codigo string, agora time.Time, ultimoPasso int64) (int64, bool) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) != totpDigitos {
+		return 0, false
+	}
+	chave, err := base32SemPadding.DecodeString(strings.ToUpper(segredo))
+	if err != nil {
+		return 0, false
+	}
+	atual := passoTOTP(agora)
+	for passo := atual - totpTolerancia; passo <= atual+totpTolerancia; passo++ {
+		if passo <= ultimoPasso {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(codigoPasso(chave, passo)), []byte(codigo)) == 1 {
+			return passo, true
+		}
+	}
+	return 0, false
+}
+
+// GerarCodigosRecuperacao gera códigos de uso único no formato xxxxx-xxxxx.
+// Retorna os códigos para exibir uma única vez e os hashes a persistir.
+func GerarCodigosRecuperacao(quantidade int) (codigos, hashes []string, err error) {
+	for i := 0; i < quantidade; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		bruto := strings.ToLower(base32SemPadding.EncodeToString(buf))[:10]
+		codigo := bruto[:5] + "-" + bruto[5:]
+		codigos = append(codigos, codigo)
+		hashes = append(hashes, HashCodigoRecuperacao(codigo))
+	}
+	return codigos, hashes, nil
+}
+
+// HashCodigoRecuperacao normaliza o código digitado (caixa, espaços e hífen) antes do hash
+func HashCodigoRecuperacao(codigo string) string {
+	normalizado := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(codigo))
+	return HashToken(normalizado)
+}
+
+func passoTOTP(t time.Time) int64 {
+	return t.Unix() / totpPeriodo
+}
+
+// codigoPasso implementa o HOTP (RFC 4226) para o contador informado
+func codigoPasso(chave []byte, passo int64) string {
+	var contador [8]byte
+	binary.BigEndian.PutUint64(contador[:], uint64(passo))
+	mac := hmac.New(sha1.New, chave)
+	mac.Write(contador[:])
+	soma := mac.Sum(nil)
+
+	offset := soma[len(soma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(soma[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigitos, valor%totpModulo)
+}
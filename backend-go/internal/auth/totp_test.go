@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodigoTOTP(t *testing.T) {
+	// Vetores de teste da RFC 6238 (SHA1), truncados para 6 dígitos
+	segredo := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vetores := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, esperado := range vetores {
+		codigo, err := CodigoTOTP(segredo, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, esperado, codigo)
+	}
+}
+
+func TestValidarTOTP(t *testing.T) {
+	segredo, err := GerarSegredoTOTP()
+	require.NoError(t, err)
+	agora := time.Unix(1_700_000_000, 0)
+
+	t.Run("Aceita o código atual e o do passo anterior", func(t *testing.T) {
+		atual, err := CodigoTOTP(segredo, agora)
+		require.NoError(t, err)
+		passo, ok := ValidarTOTP(segredo, atual, agora, 0)
+		assert.True(t, ok)
+		assert.Equal(t, agora.Unix()/30, passo)
+
+		anterior, err := CodigoTOTP(segredo, agora.Add(-30*time.Second))
+		require.NoError(t, err)
+		_, ok = ValidarTOTP(segredo, anterior, agora, 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("Recusa código fora da tolerância", func(t *testing.T) {
+		antigo, err := CodigoTOTP(segredo, agora.Add(-2*time.Minute))
+		require.NoError(t, err)
+		_, ok := ValidarTOTP(segredo, antigo, agora, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Recusa reutilização do mesmo passo", func(t *testing.T) {
+		atual, err := CodigoTOTP(segredo, agora)
+		require.NoError(t, err)
+		passo, ok := ValidarTOTP(segredo, atual, agora, 0)
+		require.True(t, ok)
+		_, ok = ValidarTOTP(segredo, atual, agora, passo)
+		assert.False(t, ok)
+	})
+
+	t.Run("Recusa formato inválido", func(t *testing.T) {
+		_, ok := ValidarTOTP(segredo, "12345", agora, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestURIOTPAuth(t *testing.T) {
+	uri := URIOTPAuth("Taxi Service", "joao@email.com", "ABCDEF")
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Taxi Service:joao@email.com", u.Path)
+	assert.Equal(t, "ABCDEF", u.Query().Get("secret"))
+	assert.Equal(t, "Taxi Service", u.Query().Get("issuer"))
+}
+
+func TestGerarCodigosRecuperacao(t *testing.T) {
+	codigos, hashes, err := GerarCodigosRecuperacao(10)
+	require.NoError(t, err)
+	require.Len(t, codigos, 10)
+	require.Len(t, hashes, 10)
+
+	assert.Len(t, codigos[0], 11)
+	assert.Equal(t, hashes[0], HashCodigoRecuperacao(codigos[0]))
+	assert.Equal(t, hashes[0], HashCodigoRecuperacao(" "+strings.ToUpper(strings.ReplaceAll(codigos[0], "-", ""))))
+	assert.NotEqual(t, codigos[0], codigos[1])
+}
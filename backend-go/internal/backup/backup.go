@@ -0,0 +1,347 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // cópia consistente e verificação de bancos SQLite
+)
+
+// O backup é um tar.gz com os arquivos do diretório de dados em dados/<caminho> e,
+// por último, manifest.json com o tamanho e o SHA-256 de cada um.
+const (
+	formatoManifesto = "taxi_service-backup"
+	versaoManifesto  = 1
+	nomeManifesto    = "manifest.json"
+	prefixoDados     = "dados/"
+)
+
+// ErrBackupInvalido o arquivo não é um backup íntegro: manifesto ausente, checksum
+// divergente, arquivo faltando ou sobrando, caminho inseguro ou conteúdo ilegível
+var ErrBackupInvalido = errors.New("backup inválido")
+
+// Manifesto descreve o conteúdo do backup
+type Manifesto struct {
+	Formato  string             `json:"formato"`
+	Versao   int                `json:"versao"`
+	CriadoEm time.Time          `json:"criado_em"`
+	Arquivos []ArquivoManifesto `json:"arquivos"`
+}
+
+// ArquivoManifesto arquivo do diretório de dados incluído no backup
+type ArquivoManifesto struct {
+	Caminho string `json:"caminho"` // relativo ao diretório de dados, separado por "/"
+	Tamanho int64  `json:"tamanho"`
+	SHA256  string `json:"sha256"`
+}
+
+// Criar grava em destino o backup do diretório dir. Temporários de escrita,
+// arquivos corrompidos preservados pela recuperação e arquivos auxiliares do SQLite
+// (-wal, -shm) ficam de fora; bancos SQLite são copiados com VACUUM INTO, o que
+// produz uma cópia consistente mesmo com o servidor em execução.
+func Criar(dir string, destino io.Writer) (*Manifesto, error) {
+	gz := gzip.NewWriter(destino)
+	tw := tar.NewWriter(gz)
+	manifesto := &Manifesto{Formato: formatoManifesto, Versao: versaoManifesto, CriadoEm: time.Now().UTC(), Arquivos: []ArquivoManifesto{}}
+
+	temporario, err := os.MkdirTemp("", "taxi_service-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório temporário: %w", err)
+	}
+	defer os.RemoveAll(temporario)
+
+	err = filepath.WalkDir(dir, func(caminho string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relativo, err := filepath.Rel(dir, caminho)
+		if err != nil {
+			return err
+		}
+		relativo = filepath.ToSlash(relativo)
+		if ignorado(relativo) {
+			return nil
+		}
+		origem := caminho
+		if strings.HasSuffix(relativo, ".db") {
+			origem = filepath.Join(temporario, fmt.Sprintf("%d.db", len(manifesto.Arquivos)))
+			if err := copiarSQLite(caminho, origem); err != nil {
+				return fmt.Errorf("erro ao copiar banco %s: %w", relativo, err)
+			}
+		}
+		arquivo, err := adicionar(tw, origem, relativo)
+		if err != nil {
+			return err
+		}
+		manifesto.Arquivos = append(manifesto.Arquivos, *arquivo)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao copiar %s: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(manifesto, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar manifesto: %w", err)
+	}
+	cabecalho := &tar.Header{Name: nomeManifesto, Mode: 0600, Size: int64(len(data)), ModTime: manifesto.CriadoEm}
+	if err := tw.WriteHeader(cabecalho); err != nil {
+		return nil, fmt.Errorf("erro ao gravar manifesto: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("erro ao gravar manifesto: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar backup: %w", err)
+	}
+	return manifesto, nil
+}
+
+// ignorado arquivos do diretório de dados que não entram no backup
+func ignorado(relativo string) bool {
+	nome := path.Base(relativo)
+	switch {
+	case strings.HasPrefix(nome, ".") && strings.Contains(nome, ".tmp-"):
+		return true
+	case strings.Contains(nome, ".corrompido-"):
+		return true
+	case strings.HasSuffix(nome, ".db-wal"), strings.HasSuffix(nome, ".db-shm"), strings.HasSuffix(nome, ".db-journal"):
+		return true
+	}
+	return false
+}
+
+// adicionar grava o arquivo no tar calculando o SHA-256 do que foi efetivamente gravado
+func adicionar(tw *tar.Writer, origem, relativo string) (*ArquivoManifesto, error) {
+	arquivo, err := os.Open(origem)
+	if err != nil {
+		return nil, err
+	}
+	defer arquivo.Close()
+	info, err := arquivo.Stat()
+	if err != nil {
+		return nil, err
+	}
+	cabecalho := &tar.Header{Name: prefixoDados + relativo, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(cabecalho); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	// Um arquivo que encolha durante a cópia falha aqui em vez de gerar um backup truncado
+	if _, err := io.CopyN(io.MultiWriter(tw, h), arquivo, info.Size()); err != nil {
+		return nil, fmt.Errorf("erro ao copiar %s: %w", relativo, err)
+	}
+	return &ArquivoManifesto{Caminho: relativo, Tamanho: info.Size(), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func copiarSQLite(origem, destino string) error {
+	db, err := sql.Open("sqlite", "file:"+origem+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	_, err = db.ExecContext(ctx, `VACUUM INTO $1`, destino)
+	return err
+}
+
+// Verificar lê o backup por inteiro e confere o manifesto, sem alterar nada
+func Verificar(origem io.Reader) (*Manifesto, error) {
+	temporario, err := os.MkdirTemp("", "taxi_service-verificacao-*")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório temporário: %w", err)
+	}
+	defer os.RemoveAll(temporario)
+	return extrair(origem, temporario)
+}
+
+// Restaurar valida o backup e só então o coloca no lugar do diretório dir. O conteúdo
+// é extraído e verificado em um diretório ao lado de dir; o diretório atual, se
+// existir, é preservado como dir.anterior-<timestamp> (caminho retornado). Em caso
+// de erro dir não é alterado. O servidor deve estar parado durante a restauração.
+func Restaurar(origem io.Reader, dir string) (anterior string, manifesto *Manifesto, err error) {
+	dir = filepath.Clean(dir)
+	temporario, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".restauracao-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao criar diretório temporário: %w", err)
+	}
+	defer os.RemoveAll(temporario)
+
+	manifesto, err = extrair(origem, temporario)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.Chmod(temporario, 0755); err != nil {
+		return "", nil, fmt.Errorf("erro ao ajustar permissões: %w", err)
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		anterior = fmt.Sprintf("%s.anterior-%s", dir, time.Now().UTC().Format("20060102T150405Z"))
+		if err := os.Rename(dir, anterior); err != nil {
+			return "", nil, fmt.Errorf("erro ao preservar diretório atual: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", nil, err
+	}
+	if err := os.Rename(temporario, dir); err != nil {
+		if anterior != "" {
+			if errVolta := os.Rename(anterior, dir); errVolta != nil {
+				return "", nil, fmt.Errorf("erro ao substituir diretório (%v) e ao restaurar o anterior, mantido em %s: %w", err, anterior, errVolta)
+			}
+		}
+		return "", nil, fmt.Errorf("erro ao substituir diretório: %w", err)
+	}
+	return anterior, manifesto, nil
+}
+
+// extrair descompacta o backup em dir e confere cada arquivo com o manifesto
+func extrair(origem io.Reader, dir string) (*Manifesto, error) {
+	gz, err := gzip.NewReader(origem)
+	if err != nil {
+		return nil, fmt.Errorf("%w: não é um arquivo gzip: %v", ErrBackupInvalido, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var manifesto *Manifesto
+	extraidos := map[string]ArquivoManifesto{}
+	for {
+		cabecalho, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBackupInvalido, err)
+		}
+		switch {
+		case cabecalho.Typeflag == tar.TypeDir:
+			continue
+		case cabecalho.Typeflag != tar.TypeReg:
+			return nil, fmt.Errorf("%w: entrada %s não é um arquivo comum", ErrBackupInvalido, cabecalho.Name)
+		case cabecalho.Name == nomeManifesto:
+			manifesto = &Manifesto{}
+			if err := json.NewDecoder(tr).Decode(manifesto); err != nil {
+				return nil, fmt.Errorf("%w: manifesto ilegível: %v", ErrBackupInvalido, err)
+			}
+			continue
+		}
+		relativo, ok := strings.CutPrefix(cabecalho.Name, prefixoDados)
+		if !ok || !filepath.IsLocal(filepath.FromSlash(relativo)) {
+			return nil, fmt.Errorf("%w: caminho inseguro %q", ErrBackupInvalido, cabecalho.Name)
+		}
+		if _, repetido := extraidos[relativo]; repetido {
+			return nil, fmt.Errorf("%w: %s aparece mais de uma vez", ErrBackupInvalido, relativo)
+		}
+		arquivo, err := gravar(tr, filepath.Join(dir, filepath.FromSlash(relativo)), relativo, fs.FileMode(cabecalho.Mode).Perm())
+		if err != nil {
+			return nil, err
+		}
+		extraidos[relativo] = *arquivo
+	}
+
+	if manifesto == nil {
+		return nil, fmt.Errorf("%w: manifesto ausente", ErrBackupInvalido)
+	}
+	if manifesto.Formato != formatoManifesto || manifesto.Versao != versaoManifesto {
+		return nil, fmt.Errorf("%w: formato %q versão %d não suportado", ErrBackupInvalido, manifesto.Formato, manifesto.Versao)
+	}
+	for _, esperado := range manifesto.Arquivos {
+		extraido, ok := extraidos[esperado.Caminho]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s consta no manifesto mas não no arquivo", ErrBackupInvalido, esperado.Caminho)
+		}
+		if extraido != esperado {
+			return nil, fmt.Errorf("%w: checksum ou tamanho de %s não confere", ErrBackupInvalido, esperado.Caminho)
+		}
+		delete(extraidos, esperado.Caminho)
+		if err := validarConteudo(filepath.Join(dir, filepath.FromSlash(esperado.Caminho))); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrBackupInvalido, esperado.Caminho, err)
+		}
+	}
+	if len(extraidos) > 0 {
+		sobrando := make([]string, 0, len(extraidos))
+		for caminho := range extraidos {
+			sobrando = append(sobrando, caminho)
+		}
+		sort.Strings(sobrando)
+		return nil, fmt.Errorf("%w: arquivos fora do manifesto: %s", ErrBackupInvalido, strings.Join(sobrando, ", "))
+	}
+	return manifesto, nil
+}
+
+func gravar(conteudo io.Reader, destino, relativo string, perm fs.FileMode) (*ArquivoManifesto, error) {
+	if err := os.MkdirAll(filepath.Dir(destino), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	if perm == 0 {
+		perm = 0600
+	}
+	arquivo, err := os.OpenFile(destino, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao extrair %s: %w", relativo, err)
+	}
+	h := sha256.New()
+	tamanho, err := io.Copy(io.MultiWriter(arquivo, h), conteudo)
+	if err != nil {
+		arquivo.Close()
+		return nil, fmt.Errorf("%w: erro ao extrair %s: %v", ErrBackupInvalido, relativo, err)
+	}
+	if err := arquivo.Sync(); err != nil {
+		arquivo.Close()
+		return nil, fmt.Errorf("erro ao extrair %s: %w", relativo, err)
+	}
+	if err := arquivo.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao extrair %s: %w", relativo, err)
+	}
+	return &ArquivoManifesto{Caminho: relativo, Tamanho: tamanho, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// validarConteudo além do checksum, confere que arquivos JSON e bancos SQLite são legíveis
+func validarConteudo(caminho string) error {
+	switch filepath.Ext(caminho) {
+	case ".json":
+		data, err := os.ReadFile(caminho)
+		if err != nil {
+			return err
+		}
+		if len(data) > 0 && !json.Valid(data) {
+			return errors.New("JSON inválido")
+		}
+	case ".db":
+		db, err := sql.Open("sqlite", "file:"+caminho+"?mode=ro")
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		var resultado string
+		if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&resultado); err != nil {
+			return err
+		}
+		if resultado != "ok" {
+			return fmt.Errorf("banco SQLite corrompido: %s", resultado)
+		}
+	}
+	return nil
+}
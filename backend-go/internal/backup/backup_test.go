@@ -0,0 +1,173 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// novoDiretorioDados diretório de dados com o que o servidor grava normalmente
+func novoDiretorioDados(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "data")
+	arquivos := map[string]string{
+		"motoristas.json":                       `[{"id":"m1"}]`,
+		"motoristas.json.1":                     `[]`,
+		"documentos/m1/ab/abcdef.pdf":           "%PDF-1.4 conteúdo",
+		".motoristas.json.tmp-123":              "escrita interrompida",
+		"motoristas.json.corrompido-20260101T0": "{",
+	}
+	for caminho, conteudo := range arquivos {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, caminho)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, caminho), []byte(conteudo), 0600))
+	}
+	db, err := sql.Open("sqlite", filepath.Join(dir, "taxi_service.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE motoristas (id TEXT PRIMARY KEY); INSERT INTO motoristas VALUES ('m1')`)
+	require.NoError(t, err)
+	return dir
+}
+
+// reescrever aplica alterar a cada entrada do backup, que pode ser descartada com nil
+func reescrever(t *testing.T, backup []byte, alterar func(*tar.Header, []byte) (*tar.Header, []byte)) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(backup))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var saida bytes.Buffer
+	gzSaida := gzip.NewWriter(&saida)
+	tw := tar.NewWriter(gzSaida)
+	for {
+		cabecalho, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		conteudo, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		cabecalho, conteudo = alterar(cabecalho, conteudo)
+		if cabecalho == nil {
+			continue
+		}
+		cabecalho.Size = int64(len(conteudo))
+		require.NoError(t, tw.WriteHeader(cabecalho))
+		_, err = tw.Write(conteudo)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzSaida.Close())
+	return saida.Bytes()
+}
+
+func TestBackup(t *testing.T) {
+	origem := novoDiretorioDados(t)
+	var backup bytes.Buffer
+	manifesto, err := Criar(origem, &backup)
+	require.NoError(t, err)
+
+	caminhos := []string{}
+	for _, arquivo := range manifesto.Arquivos {
+		caminhos = append(caminhos, arquivo.Caminho)
+		assert.Len(t, arquivo.SHA256, 64)
+	}
+	assert.ElementsMatch(t, []string{"motoristas.json", "motoristas.json.1", "documentos/m1/ab/abcdef.pdf", "taxi_service.db"}, caminhos,
+		"temporários e arquivos corrompidos ficam de fora")
+
+	verificado, err := Verificar(bytes.NewReader(backup.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, manifesto.Arquivos, verificado.Arquivos)
+
+	t.Run("Restauração preserva o diretório atual", func(t *testing.T) {
+		destino := filepath.Join(t.TempDir(), "data")
+		require.NoError(t, os.MkdirAll(destino, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(destino, "motoristas.json"), []byte(`[]`), 0600))
+
+		anterior, _, err := Restaurar(bytes.NewReader(backup.Bytes()), destino)
+		require.NoError(t, err)
+		require.NotEmpty(t, anterior)
+		antigo, err := os.ReadFile(filepath.Join(anterior, "motoristas.json"))
+		require.NoError(t, err)
+		assert.Equal(t, `[]`, string(antigo))
+
+		restaurado, err := os.ReadFile(filepath.Join(destino, "documentos/m1/ab/abcdef.pdf"))
+		require.NoError(t, err)
+		assert.Equal(t, "%PDF-1.4 conteúdo", string(restaurado))
+		db, err := sql.Open("sqlite", filepath.Join(destino, "taxi_service.db"))
+		require.NoError(t, err)
+		defer db.Close()
+		var id string
+		require.NoError(t, db.QueryRow(`SELECT id FROM motoristas`).Scan(&id))
+		assert.Equal(t, "m1", id)
+	})
+
+	t.Run("Restauração sem diretório atual", func(t *testing.T) {
+		destino := filepath.Join(t.TempDir(), "data")
+		anterior, _, err := Restaurar(bytes.NewReader(backup.Bytes()), destino)
+		require.NoError(t, err)
+		assert.Empty(t, anterior)
+		assert.FileExists(t, filepath.Join(destino, "motoristas.json"))
+	})
+
+	invalidos := map[string][]byte{
+		"Conteúdo adulterado": reescrever(t, backup.Bytes(), func(h *tar.Header, c []byte) (*tar.Header, []byte) {
+			if h.Name == "dados/motoristas.json" {
+				return h, []byte(`[{"id":"m2"}]`)
+			}
+			return h, c
+		}),
+		"Arquivo faltando": reescrever(t, backup.Bytes(), func(h *tar.Header, c []byte) (*tar.Header, []byte) {
+			if h.Name == "dados/motoristas.json.1" {
+				return nil, nil
+			}
+			return h, c
+		}),
+		"Arquivo fora do manifesto": reescrever(t, backup.Bytes(), func(h *tar.Header, c []byte) (*tar.Header, []byte) {
+			if h.Name == "dados/motoristas.json.1" {
+				h.Name = "dados/extra.json"
+			}
+			return h, c
+		}),
+		"Caminho inseguro": reescrever(t, backup.Bytes(), func(h *tar.Header, c []byte) (*tar.Header, []byte) {
+			if h.Name == "dados/motoristas.json.1" {
+				h.Name = "dados/../../fora.json"
+			}
+			return h, c
+		}),
+		"Sem manifesto": reescrever(t, backup.Bytes(), func(h *tar.Header, c []byte) (*tar.Header, []byte) {
+			if h.Name == nomeManifesto {
+				return nil, nil
+			}
+			return h, c
+		}),
+		"Não é um backup": []byte("qualquer coisa"),
+	}
+	for nome, invalido := range invalidos {
+		t.Run(nome, func(t *testing.T) {
+			_, err := Verificar(bytes.NewReader(invalido))
+			assert.ErrorIs(t, err, ErrBackupInvalido)
+
+			destino := filepath.Join(t.TempDir(), "data")
+			require.NoError(t, os.MkdirAll(destino, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(destino, "motoristas.json"), []byte(`[]`), 0600))
+			_, _, err = Restaurar(bytes.NewReader(invalido), destino)
+			assert.ErrorIs(t, err, ErrBackupInvalido)
+
+			atual, err := os.ReadFile(filepath.Join(destino, "motoristas.json"))
+			require.NoError(t, err)
+			assert.Equal(t, `[]`, string(atual), "diretório atual intacto")
+			entradas, err := os.ReadDir(filepath.Dir(destino))
+			require.NoError(t, err)
+			assert.Len(t, entradas, 1, "nenhum temporário deixado para trás")
+			assert.NoFileExists(t, filepath.Join(filepath.Dir(filepath.Dir(destino)), "fora.json"))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package conteudo identifica o formato dos arquivos enviados pelo conteúdo (assinatura
+// nos primeiros bytes), sem confiar na extensão do nome, e confere a estrutura de PDFs e
+// imagens antes que sejam aceitos.
+package conteudo
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Formatos reconhecidos, com os mesmos nomes aceitos por models.ValidarDocumento
+const (
+	FormatoPDF  = "PDF"
+	FormatoJPEG = "JPEG"
+	FormatoPNG  = "PNG"
+	FormatoWEBP = "WEBP"
+)
+
+var (
+	ErrFormatoDesconhecido = errors.New("conteúdo não corresponde a nenhum formato reconhecido")
+	ErrPDFInvalido         = errors.New("PDF malformado ou truncado")
+	ErrPDFCriptografado    = errors.New("PDF criptografado")
+	ErrPDFPaginas          = errors.New("PDF excede o limite de páginas")
+	ErrImagemInvalida      = errors.New("imagem malformada ou truncada")
+	ErrImagemResolucao     = errors.New("imagem excede o limite de pixels")
+)
+
+// TamanhoCabecalho bytes do início do arquivo suficientes para Detectar
+const TamanhoCabecalho = 512
+
+// Limites da inspeção. Zero desativa o limite correspondente.
+type Limites struct {
+	MaxPaginas int   // páginas de um PDF
+	MaxPixels  int64 // largura × altura de uma imagem
+}
+
+// LimitesPadrao comporta documentos digitalizados e fotos de celular (até 6000×4000)
+var LimitesPadrao = Limites{MaxPaginas: 10, MaxPixels: 24_000_000}
+
+// Info resultado da inspeção de um arquivo
+type Info struct {
+	Formato     string
+	ContentType string
+	Extensao    string // com o ponto, ex.: ".pdf"
+	Paginas     int    // apenas PDF
+	Largura     int    // apenas imagens
+	Altura      int
+}
+
+type assinatura struct {
+	formato, contentType, extensao string
+	confere                        func(cabecalho []byte) bool
+}
+
+var assinaturas = []assinatura{
+	{FormatoPDF, "application/pdf", ".pdf", func(b []byte) bool { return bytes.HasPrefix(b, []byte("%PDF-")) }},
+	{FormatoPNG, "image/png", ".png", func(b []byte) bool { return bytes.HasPrefix(b, []byte("\x89PNG\r\n\x1a\n")) }},
+	{FormatoJPEG, "image/jpeg", ".jpg", func(b []byte) bool { return bytes.HasPrefix(b, []byte{0xFF, 0xD8, 0xFF}) }},
+	{FormatoWEBP, "image/webp", ".webp", func(b []byte) bool {
+		return len(b) >= 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == "WEBP"
+	}},
+}
+
+// Detectar retorna o formato indicado pela assinatura no cabeçalho (os primeiros
+// TamanhoCabecalho bytes) ou "" se não for um formato reconhecido
+func Detectar(cabecalho []byte) string {
+	for _, a := range assinaturas {
+		if a.confere(cabecalho) {
+			return a.formato
+		}
+	}
+	return ""
+}
+
+// Inspecionar detecta o formato do conteúdo e confere sua estrutura: PDFs devem ser
+// legíveis, não criptografados e ter no máximo limites.MaxPaginas páginas; imagens
+// devem ser decodificáveis e ter no máximo limites.MaxPixels pixels
+func Inspecionar(dados []byte, limites Limites) (*Info, error) {
+	for _, a := range assinaturas {
+		if !a.confere(dados) {
+			continue
+		}
+		info := &Info{Formato: a.formato, ContentType: a.contentType, Extensao: a.extensao}
+		var err error
+		if a.formato == FormatoPDF {
+			info.Paginas, err = inspecionarPDF(dados, limites.MaxPaginas)
+		} else {
+			info.Largura, info.Altura, err = inspecionarImagem(dados, a.formato, limites.MaxPixels)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+	return nil, ErrFormatoDesconhecido
+}
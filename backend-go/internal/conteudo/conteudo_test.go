@@ -0,0 +1,201 @@
+package conteudo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// montarPDF gera um PDF com os objetos informados (numerados a partir de 1), tabela
+// xref com os offsets corretos e o trailer com as entradas extras
+func montarPDF(objetos []string, trailerExtra string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objetos))
+	for i, o := range objetos {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s>>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, trailerExtra, xref)
+	return b.Bytes()
+}
+
+func pdfComPaginas(n int) []byte {
+	kids := make([]string, n)
+	objetos := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	for i := range n {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+		objetos = append(objetos, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>")
+	}
+	objetos[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n)
+	return montarPDF(objetos, "")
+}
+
+func imagemTeste(t *testing.T, formato string, largura, altura int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, largura, altura))
+	for x := range largura {
+		img.Set(x, x%altura, color.RGBA{R: 200, A: 255})
+	}
+	var b bytes.Buffer
+	if formato == FormatoPNG {
+		require.NoError(t, png.Encode(&b, img))
+	} else {
+		require.NoError(t, jpeg.Encode(&b, img, nil))
+	}
+	return b.Bytes()
+}
+
+func webp(chunk string, corpo []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+len(corpo)))
+	b.WriteString("WEBP" + chunk)
+	binary.Write(&b, binary.LittleEndian, uint32(len(corpo)))
+	b.Write(corpo)
+	return b.Bytes()
+}
+
+func TestDetectar(t *testing.T) {
+	assert.Equal(t, FormatoPDF, Detectar([]byte("%PDF-1.4\n")))
+	assert.Equal(t, FormatoPNG, Detectar([]byte("\x89PNG\r\n\x1a\n\x00")))
+	assert.Equal(t, FormatoJPEG, Detectar([]byte{0xFF, 0xD8, 0xFF, 0xE0}))
+	assert.Equal(t, FormatoWEBP, Detectar([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	// Executável renomeado para .pdf, texto e arquivo vazio
+	assert.Empty(t, Detectar([]byte("MZ\x90\x00\x03\x00\x00\x00")))
+	assert.Empty(t, Detectar([]byte("Olá, mundo")))
+	assert.Empty(t, Detectar(nil))
+}
+
+func TestInspecionarPDF(t *testing.T) {
+	t.Run("PDF válido", func(t *testing.T) {
+		info, err := Inspecionar(pdfComPaginas(2), LimitesPadrao)
+		require.NoError(t, err)
+		assert.Equal(t, FormatoPDF, info.Formato)
+		assert.Equal(t, "application/pdf", info.ContentType)
+		assert.Equal(t, ".pdf", info.Extensao)
+		assert.Equal(t, 2, info.Paginas)
+	})
+
+	t.Run("Páginas em object stream compactado são contadas", func(t *testing.T) {
+		var compactado bytes.Buffer
+		w := zlib.NewWriter(&compactado)
+		w.Write([]byte("3 0 4 60 << /Type /Page /Parent 2 0 R >>" + strings.Repeat(" ", 200) + "<< /Type /Page /Parent 2 0 R >>"))
+		w.Close()
+		require.NotContains(t, compactado.String(), "/Page", "o conteúdo deve estar de fato compactado")
+		dados := montarPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+			fmt.Sprintf("<< /Type /ObjStm /N 2 /First 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream", compactado.Len(), compactado.Bytes()),
+		}, "")
+		info, err := Inspecionar(dados, LimitesPadrao)
+		require.NoError(t, err)
+		assert.Equal(t, 2, info.Paginas)
+
+		_, err = Inspecionar(dados, Limites{MaxPaginas: 1})
+		assert.ErrorIs(t, err, ErrPDFPaginas)
+	})
+
+	t.Run("Limite de páginas", func(t *testing.T) {
+		_, err := Inspecionar(pdfComPaginas(11), LimitesPadrao)
+		assert.ErrorIs(t, err, ErrPDFPaginas)
+		_, err = Inspecionar(pdfComPaginas(11), Limites{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("PDF criptografado", func(t *testing.T) {
+		dados := montarPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R >>",
+			"<< /Filter /Standard /V 2 /R 3 /O <00> /U <00> /P -44 >>",
+		}, "/Encrypt 4 0 R ")
+		_, err := Inspecionar(dados, LimitesPadrao)
+		assert.ErrorIs(t, err, ErrPDFCriptografado)
+	})
+
+	t.Run("PDF malformado", func(t *testing.T) {
+		valido := pdfComPaginas(1)
+		casos := map[string][]byte{
+			"truncado":            valido[:len(valido)/2],
+			"sem versão":          append([]byte("%PDF-x"), valido[6:]...),
+			"startxref inválido":  bytes.Replace(valido, []byte("startxref\n"), []byte("startxref\n9"), 1),
+			"sem páginas":         montarPDF([]string{"<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>"}, ""),
+			"object stream falso": montarPDF([]string{"<< /Type /Page >>", "<< /Type /ObjStm /Filter /FlateDecode >>\nstream\nnão é zlib\nendstream"}, ""),
+		}
+		for nome, dados := range casos {
+			_, err := Inspecionar(dados, LimitesPadrao)
+			assert.ErrorIs(t, err, ErrPDFInvalido, nome)
+		}
+	})
+}
+
+func TestInspecionarImagem(t *testing.T) {
+	for _, formato := range []string{FormatoPNG, FormatoJPEG} {
+		t.Run(formato, func(t *testing.T) {
+			dados := imagemTeste(t, formato, 40, 30)
+			info, err := Inspecionar(dados, LimitesPadrao)
+			require.NoError(t, err)
+			assert.Equal(t, formato, info.Formato)
+			assert.Equal(t, 40, info.Largura)
+			assert.Equal(t, 30, info.Altura)
+
+			_, err = Inspecionar(dados, Limites{MaxPixels: 40*30 - 1})
+			assert.ErrorIs(t, err, ErrImagemResolucao)
+
+			_, err = Inspecionar(dados[:len(dados)/2], LimitesPadrao)
+			assert.ErrorIs(t, err, ErrImagemInvalida)
+		})
+	}
+
+	t.Run("WEBP", func(t *testing.T) {
+		// Imagens 1×1 reais, com e sem perda
+		for nome, b64 := range map[string]string{
+			"lossy":    "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA",
+			"lossless": "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==",
+		} {
+			dados, err := base64.StdEncoding.DecodeString(b64)
+			require.NoError(t, err)
+			info, err := Inspecionar(dados, LimitesPadrao)
+			require.NoError(t, err, nome)
+			assert.Equal(t, "image/webp", info.ContentType, nome)
+			assert.Equal(t, [2]int{1, 1}, [2]int{info.Largura, info.Altura}, nome)
+
+			_, err = Inspecionar(dados[:len(dados)-4], LimitesPadrao)
+			assert.ErrorIs(t, err, ErrImagemInvalida, nome)
+		}
+
+		// Cabeçalho VP8 válido de 640×480 sem os dados do quadro
+		vp8 := []byte{0x10, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01}
+		_, err := Inspecionar(webp("VP8 ", vp8), LimitesPadrao)
+		assert.ErrorIs(t, err, ErrImagemInvalida)
+
+		// VP8X de 10000×5000 excede o limite de pixels antes da decodificação
+		vp8x := []byte{0, 0, 0, 0, 0x0f, 0x27, 0x00, 0x87, 0x13, 0x00}
+		_, err = Inspecionar(webp("VP8X", vp8x), LimitesPadrao)
+		assert.ErrorIs(t, err, ErrImagemResolucao)
+
+		_, err = Inspecionar(webp("ABCD", vp8), LimitesPadrao)
+		assert.ErrorIs(t, err, ErrImagemInvalida)
+	})
+
+	t.Run("Conteúdo desconhecido", func(t *testing.T) {
+		_, err := Inspecionar([]byte("MZ\x90\x00 executável renomeado"), LimitesPadrao)
+		assert.ErrorIs(t, err, ErrFormatoDesconhecido)
+	})
+}
@@ -0,0 +1,32 @@
+package conteudo
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+// inspecionarImagem confere as dimensões antes de decodificar (o limite de pixels também
+// limita a memória da decodificação) e então decodifica a imagem inteira, o que recusa
+// arquivos truncados ou corrompidos. O decodificador usado precisa ser o do formato
+// detectado pela assinatura.
+func inspecionarImagem(dados []byte, formato string, maxPixels int64) (int, int, error) {
+	cfg, nome, err := image.DecodeConfig(bytes.NewReader(dados))
+	if err != nil || !strings.EqualFold(nome, formato) {
+		return 0, 0, ErrImagemInvalida
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return 0, 0, ErrImagemInvalida
+	}
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return cfg.Width, cfg.Height, ErrImagemResolucao
+	}
+	if _, _, err := image.Decode(bytes.NewReader(dados)); err != nil {
+		return 0, 0, ErrImagemInvalida
+	}
+	return cfg.Width, cfg.Height, nil
+}
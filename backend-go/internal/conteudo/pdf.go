@@ -0,0 +1,111 @@
+package conteudo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// maxDescompactado limite do conteúdo extraído dos object streams de um PDF; arquivos de
+// até 5MB com mais que isso em objetos compactados são tratados como malformados
+const maxDescompactado = 32 << 20
+
+var (
+	reVersaoPDF = regexp.MustCompile(`^%PDF-\d\.\d`)
+	reStartxref = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	reObjeto    = regexp.MustCompile(`^\d+\s+\d+\s+obj\b`)
+	reEncrypt   = regexp.MustCompile(`/Encrypt\s*(?:\d+\s+\d+\s+R|<<)`)
+	reObjStm    = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	rePagina    = regexp.MustCompile(`/Type\s*/Page\b`)
+)
+
+// inspecionarPDF confere a estrutura do PDF sem interpretá-lo por completo: cabeçalho
+// com versão, startxref final apontando para uma tabela xref ou um xref stream, ausência
+// de /Encrypt no trailer e quantidade de páginas (objetos /Type /Page, inclusive os
+// guardados em object streams compactados). Retorna o número de páginas.
+func inspecionarPDF(dados []byte, maxPaginas int) (int, error) {
+	if !reVersaoPDF.Match(dados) {
+		return 0, ErrPDFInvalido
+	}
+	// O trailer fica no fim do arquivo; arquivos truncados perdem o %%EOF
+	fim := dados[max(0, len(dados)-1024):]
+	m := reStartxref.FindSubmatch(fim)
+	if m == nil {
+		return 0, ErrPDFInvalido
+	}
+	offset, err := strconv.Atoi(string(m[1]))
+	if err != nil || offset >= len(dados) {
+		return 0, ErrPDFInvalido
+	}
+	xref := bytes.TrimLeft(dados[offset:], " \t\r\n")
+	if !bytes.HasPrefix(xref, []byte("xref")) && !reObjeto.Match(xref) {
+		return 0, ErrPDFInvalido
+	}
+
+	// O dicionário de criptografia é referenciado no trailer ou no xref stream, que
+	// nunca são compactados
+	if reEncrypt.Match(dados) {
+		return 0, ErrPDFCriptografado
+	}
+
+	objetos, err := objectStreams(dados)
+	if err != nil {
+		return 0, err
+	}
+	paginas := len(rePagina.FindAllIndex(dados, -1))
+	for _, o := range objetos {
+		paginas += len(rePagina.FindAllIndex(o, -1))
+	}
+	if paginas == 0 {
+		return 0, ErrPDFInvalido
+	}
+	if maxPaginas > 0 && paginas > maxPaginas {
+		return paginas, ErrPDFPaginas
+	}
+	return paginas, nil
+}
+
+// objectStreams descompacta os object streams (/Type /ObjStm com /FlateDecode), onde
+// PDFs 1.5+ costumam guardar os dicionários das páginas
+func objectStreams(dados []byte) ([][]byte, error) {
+	var objetos [][]byte
+	restante := int64(maxDescompactado)
+	for _, loc := range reObjStm.FindAllIndex(dados, -1) {
+		inicioDict := bytes.LastIndex(dados[:loc[0]], []byte("obj"))
+		inicioStream := bytes.Index(dados[loc[1]:], []byte("stream"))
+		if inicioDict < 0 || inicioStream < 0 {
+			return nil, ErrPDFInvalido
+		}
+		inicioStream += loc[1]
+		if !bytes.Contains(dados[inicioDict:inicioStream], []byte("/FlateDecode")) {
+			continue
+		}
+		inicio := inicioStream + len("stream")
+		if bytes.HasPrefix(dados[inicio:], []byte("\r\n")) {
+			inicio += 2
+		} else if bytes.HasPrefix(dados[inicio:], []byte("\n")) {
+			inicio++
+		}
+		fim := bytes.Index(dados[inicio:], []byte("endstream"))
+		if fim < 0 {
+			return nil, ErrPDFInvalido
+		}
+		leitor, err := zlib.NewReader(bytes.NewReader(dados[inicio : inicio+fim]))
+		if err != nil {
+			return nil, ErrPDFInvalido
+		}
+		objeto, err := io.ReadAll(io.LimitReader(leitor, restante+1))
+		leitor.Close()
+		if err != nil {
+			return nil, ErrPDFInvalido
+		}
+		restante -= int64(len(objeto))
+		if restante < 0 {
+			return nil, ErrPDFInvalido
+		}
+		objetos = append(objetos, objeto)
+	}
+	return objetos, nil
+}
@@ -0,0 +1,285 @@
+package cripto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Erros de configuração e de decifragem
+var (
+	ErrConfiguracaoInvalida = errors.New("configuração de criptografia inválida")
+	ErrSemChave             = errors.New("valor cifrado, mas nenhuma chave de criptografia foi configurada")
+	ErrChaveDesconhecida    = errors.New("valor cifrado com uma chave mestra não configurada")
+	ErrValorCorrompido      = errors.New("valor cifrado corrompido ou adulterado")
+)
+
+// prefixo identifica valores cifrados (e a versão do formato); valores sem ele são texto puro
+const prefixo = "enc:v1:"
+
+// tamanhoChave chaves mestras, chaves de dados e a chave dos índices têm 256 bits
+const tamanhoChave = 32
+
+var formatoIDChave = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Config chaves usadas pelo Cifrador
+type Config struct {
+	Chaves      map[string][]byte // chaves mestras por identificador; as inativas servem só para decifrar
+	Ativa       string            // identificador da chave mestra usada para cifrar
+	ChaveIndice []byte            // chave HMAC dos índices cegos; trocá-la exige recalcular todos os índices
+}
+
+// Cifrador cifra campos sensíveis com envelope: cada valor recebe uma chave de dados
+// aleatória (AES-256-GCM), que é cifrada pela chave mestra ativa e guardada junto ao
+// valor no formato enc:v1:<id da chave mestra>:<chave de dados cifrada>:<valor cifrado>.
+// Trocar a chave mestra exige apenas recifrar as chaves de dados (Recifrar), sem
+// tocar no conteúdo. Um Cifrador nil não cifra nada (modo desenvolvimento).
+type Cifrador struct {
+	mestras     map[string]cipher.AEAD
+	ativa       string
+	chaveIndice []byte
+}
+
+// NewCifrador valida as chaves e cria o cifrador
+func NewCifrador(config Config) (*Cifrador, error) {
+	if len(config.Chaves) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma chave mestra", ErrConfiguracaoInvalida)
+	}
+	if _, ok := config.Chaves[config.Ativa]; !ok {
+		return nil, fmt.Errorf("%w: chave ativa %q não está entre as chaves mestras", ErrConfiguracaoInvalida, config.Ativa)
+	}
+	if len(config.ChaveIndice) != tamanhoChave {
+		return nil, fmt.Errorf("%w: a chave dos índices deve ter %d bytes", ErrConfiguracaoInvalida, tamanhoChave)
+	}
+	c := &Cifrador{mestras: map[string]cipher.AEAD{}, ativa: config.Ativa, chaveIndice: config.ChaveIndice}
+	for id, chave := range config.Chaves {
+		if !formatoIDChave.MatchString(id) {
+			return nil, fmt.Errorf("%w: identificador de chave %q (use letras, números, '.', '_' ou '-')", ErrConfiguracaoInvalida, id)
+		}
+		if len(chave) != tamanhoChave {
+			return nil, fmt.Errorf("%w: a chave mestra %q deve ter %d bytes", ErrConfiguracaoInvalida, id, tamanhoChave)
+		}
+		aead, err := novoAEAD(chave)
+		if err != nil {
+			return nil, err
+		}
+		c.mestras[id] = aead
+	}
+	return c, nil
+}
+
+// NewCifradorFromEnv lê as chaves de DATA_ENCRYPTION_KEYS ("id:base64,id:base64"),
+// DATA_ENCRYPTION_ACTIVE_KEY (padrão: a primeira da lista) e BLIND_INDEX_KEY (base64).
+// Sem DATA_ENCRYPTION_KEYS retorna nil, e os dados são gravados sem criptografia.
+func NewCifradorFromEnv() (*Cifrador, error) {
+	lista := strings.TrimSpace(os.Getenv("DATA_ENCRYPTION_KEYS"))
+	if lista == "" {
+		return nil, nil
+	}
+	config := Config{Chaves: map[string][]byte{}, Ativa: os.Getenv("DATA_ENCRYPTION_ACTIVE_KEY")}
+	for _, item := range strings.Split(lista, ",") {
+		id, valor, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: DATA_ENCRYPTION_KEYS deve ter o formato id:chave-base64", ErrConfiguracaoInvalida)
+		}
+		chave, err := base64.StdEncoding.DecodeString(valor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: chave mestra %q não está em base64", ErrConfiguracaoInvalida, id)
+		}
+		if config.Ativa == "" {
+			config.Ativa = id
+		}
+		config.Chaves[id] = chave
+	}
+	chaveIndice, err := base64.StdEncoding.DecodeString(os.Getenv("BLIND_INDEX_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: BLIND_INDEX_KEY não está em base64", ErrConfiguracaoInvalida)
+	}
+	config.ChaveIndice = chaveIndice
+	return NewCifrador(config)
+}
+
+// Ativo informa se os dados são de fato cifrados
+func (c *Cifrador) Ativo() bool {
+	return c != nil
+}
+
+// Cifrado informa se o valor está no formato cifrado
+func Cifrado(valor string) bool {
+	return strings.HasPrefix(valor, prefixo)
+}
+
+// Cifrar cifra texto com uma chave de dados nova, protegida pela chave mestra ativa.
+// contexto (ex.: "cpf:<id do motorista>") é autenticado junto com o valor, de modo
+// que um valor copiado para outro campo ou registro não é aceito por Decifrar.
+// Texto vazio continua vazio.
+func (c *Cifrador) Cifrar(texto, contexto string) (string, error) {
+	if c == nil || texto == "" {
+		return texto, nil
+	}
+	dek, err := aleatorio(tamanhoChave)
+	if err != nil {
+		return "", err
+	}
+	conteudo, err := selar(dek, []byte(texto), []byte(contexto))
+	if err != nil {
+		return "", err
+	}
+	dekCifrada, err := selarCom(c.mestras[c.ativa], dek, []byte(c.ativa))
+	if err != nil {
+		return "", err
+	}
+	return montar(c.ativa, dekCifrada, conteudo), nil
+}
+
+// Decifrar devolve o texto original. Valores sem o prefixo de cifragem (gravados
+// antes da criptografia ser configurada) são devolvidos como estão.
+func (c *Cifrador) Decifrar(valor, contexto string) (string, error) {
+	if !Cifrado(valor) {
+		return valor, nil
+	}
+	if c == nil {
+		return "", ErrSemChave
+	}
+	id, dekCifrada, conteudo, err := separar(valor)
+	if err != nil {
+		return "", err
+	}
+	dek, err := c.abrirDEK(id, dekCifrada)
+	if err != nil {
+		return "", err
+	}
+	aead, err := novoAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	texto, err := abrirCom(aead, conteudo, []byte(contexto))
+	if err != nil {
+		return "", err
+	}
+	return string(texto), nil
+}
+
+// Recifrar traz o valor para a chave mestra ativa: texto puro é cifrado e valores
+// de outra chave mestra têm apenas a chave de dados recifrada. O segundo retorno
+// indica se o valor mudou.
+func (c *Cifrador) Recifrar(valor, contexto string) (string, bool, error) {
+	if c == nil || valor == "" {
+		return valor, false, nil
+	}
+	if !Cifrado(valor) {
+		cifrado, err := c.Cifrar(valor, contexto)
+		return cifrado, err == nil, err
+	}
+	id, dekCifrada, conteudo, err := separar(valor)
+	if err != nil {
+		return "", false, err
+	}
+	if id == c.ativa {
+		return valor, false, nil
+	}
+	dek, err := c.abrirDEK(id, dekCifrada)
+	if err != nil {
+		return "", false, err
+	}
+	dekCifrada, err = selarCom(c.mestras[c.ativa], dek, []byte(c.ativa))
+	if err != nil {
+		return "", false, err
+	}
+	return montar(c.ativa, dekCifrada, conteudo), true, nil
+}
+
+// Indice calcula o índice cego do valor: um HMAC-SHA256 determinístico que permite
+// buscas por igualdade sem decifrar os registros. dominio separa os índices de
+// campos diferentes (o mesmo número em "cpf" e "cnh" gera índices distintos).
+// Sem cifrador retorna vazio.
+func (c *Cifrador) Indice(dominio, valor string) string {
+	if c == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.chaveIndice)
+	mac.Write([]byte(dominio))
+	mac.Write([]byte{0})
+	mac.Write([]byte(valor))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Cifrador) abrirDEK(id string, dekCifrada []byte) ([]byte, error) {
+	mestra, ok := c.mestras[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrChaveDesconhecida, id)
+	}
+	return abrirCom(mestra, dekCifrada, []byte(id))
+}
+
+func montar(id string, dekCifrada, conteudo []byte) string {
+	return prefixo + id + ":" + base64.RawURLEncoding.EncodeToString(dekCifrada) + ":" + base64.RawURLEncoding.EncodeToString(conteudo)
+}
+
+func separar(valor string) (id string, dekCifrada, conteudo []byte, err error) {
+	partes := strings.Split(strings.TrimPrefix(valor, prefixo), ":")
+	if len(partes) != 3 {
+		return "", nil, nil, ErrValorCorrompido
+	}
+	if dekCifrada, err = base64.RawURLEncoding.DecodeString(partes[1]); err != nil {
+		return "", nil, nil, ErrValorCorrompido
+	}
+	if conteudo, err = base64.RawURLEncoding.DecodeString(partes[2]); err != nil {
+		return "", nil, nil, ErrValorCorrompido
+	}
+	return partes[0], dekCifrada, conteudo, nil
+}
+
+func novoAEAD(chave []byte) (cipher.AEAD, error) {
+	bloco, err := aes.NewCipher(chave)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inicializar AES: %w", err)
+	}
+	return cipher.NewGCM(bloco)
+}
+
+// selar cifra com uma chave de dados; o nonce aleatório precede o texto cifrado
+func selar(chave, texto, dadosAdicionais []byte) ([]byte, error) {
+	aead, err := novoAEAD(chave)
+	if err != nil {
+		return nil, err
+	}
+	return selarCom(aead, texto, dadosAdicionais)
+}
+
+func selarCom(aead cipher.AEAD, texto, dadosAdicionais []byte) ([]byte, error) {
+	nonce, err := aleatorio(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, texto, dadosAdicionais), nil
+}
+
+func abrirCom(aead cipher.AEAD, cifrado, dadosAdicionais []byte) ([]byte, error) {
+	if len(cifrado) < aead.NonceSize() {
+		return nil, ErrValorCorrompido
+	}
+	nonce, conteudo := cifrado[:aead.NonceSize()], cifrado[aead.NonceSize():]
+	texto, err := aead.Open(nil, nonce, conteudo, dadosAdicionais)
+	if err != nil {
+		return nil, ErrValorCorrompido
+	}
+	return texto, nil
+}
+
+func aleatorio(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("erro ao gerar bytes aleatórios: %w", err)
+	}
+	return buf, nil
+}
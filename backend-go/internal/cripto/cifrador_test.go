@@ -0,0 +1,185 @@
+package cripto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func novoCifradorTeste(t *testing.T, ativa string, ids ...string) *Cifrador {
+	t.Helper()
+	config := Config{Chaves: map[string][]byte{}, Ativa: ativa, ChaveIndice: bytes.Repeat([]byte{0xAA}, 32)}
+	for _, id := range ids {
+		config.Chaves[id] = bytes.Repeat([]byte(id[len(id)-1:]), 32)
+	}
+	c, err := NewCifrador(config)
+	require.NoError(t, err)
+	return c
+}
+
+func TestCifrador(t *testing.T) {
+	c := novoCifradorTeste(t, "k1", "k1")
+
+	cifrado, err := c.Cifrar("12345678909", "cpf:m1")
+	require.NoError(t, err)
+	assert.True(t, Cifrado(cifrado))
+	assert.True(t, strings.HasPrefix(cifrado, "enc:v1:k1:"))
+	assert.NotContains(t, cifrado, "12345678909")
+
+	texto, err := c.Decifrar(cifrado, "cpf:m1")
+	require.NoError(t, err)
+	assert.Equal(t, "12345678909", texto)
+
+	t.Run("Cada cifragem usa uma chave de dados nova", func(t *testing.T) {
+		outro, err := c.Cifrar("12345678909", "cpf:m1")
+		require.NoError(t, err)
+		assert.NotEqual(t, cifrado, outro)
+	})
+
+	t.Run("Valor copiado para outro campo ou motorista é recusado", func(t *testing.T) {
+		_, err := c.Decifrar(cifrado, "cnh:m1")
+		assert.ErrorIs(t, err, ErrValorCorrompido)
+		_, err = c.Decifrar(cifrado, "cpf:m2")
+		assert.ErrorIs(t, err, ErrValorCorrompido)
+	})
+
+	t.Run("Valor adulterado é recusado", func(t *testing.T) {
+		adulterado := cifrado[:len(cifrado)-2] + "AA"
+		if adulterado == cifrado {
+			adulterado = cifrado[:len(cifrado)-2] + "BB"
+		}
+		_, err := c.Decifrar(adulterado, "cpf:m1")
+		assert.ErrorIs(t, err, ErrValorCorrompido)
+		_, err = c.Decifrar("enc:v1:k1:lixo", "cpf:m1")
+		assert.ErrorIs(t, err, ErrValorCorrompido)
+	})
+
+	t.Run("Texto puro e vazio passam sem alteração", func(t *testing.T) {
+		texto, err := c.Decifrar("11999999999", "telefone:m1")
+		require.NoError(t, err)
+		assert.Equal(t, "11999999999", texto)
+		vazio, err := c.Cifrar("", "email_pendente:m1")
+		require.NoError(t, err)
+		assert.Empty(t, vazio)
+	})
+
+	t.Run("Sem cifrador nada é cifrado, e valores cifrados não podem ser lidos", func(t *testing.T) {
+		var nenhum *Cifrador
+		assert.False(t, nenhum.Ativo())
+		texto, err := nenhum.Cifrar("12345678909", "cpf:m1")
+		require.NoError(t, err)
+		assert.Equal(t, "12345678909", texto)
+		assert.Empty(t, nenhum.Indice("cpf", "12345678909"))
+		_, err = nenhum.Decifrar(cifrado, "cpf:m1")
+		assert.ErrorIs(t, err, ErrSemChave)
+	})
+}
+
+func TestCifradorRotacao(t *testing.T) {
+	antiga := novoCifradorTeste(t, "k1", "k1")
+	cifrado, err := antiga.Cifrar("joao@email.com", "email:m1")
+	require.NoError(t, err)
+
+	// Nova chave ativa; a anterior continua disponível para decifrar
+	transicao := novoCifradorTeste(t, "k2", "k1", "k2")
+	texto, err := transicao.Decifrar(cifrado, "email:m1")
+	require.NoError(t, err)
+	assert.Equal(t, "joao@email.com", texto)
+
+	recifrado, mudou, err := transicao.Recifrar(cifrado, "email:m1")
+	require.NoError(t, err)
+	assert.True(t, mudou)
+	assert.True(t, strings.HasPrefix(recifrado, "enc:v1:k2:"))
+	// Só a chave de dados é recifrada; o conteúdo cifrado é o mesmo
+	assert.Equal(t, cifrado[strings.LastIndex(cifrado, ":"):], recifrado[strings.LastIndex(recifrado, ":"):])
+
+	_, mudou, err = transicao.Recifrar(recifrado, "email:m1")
+	require.NoError(t, err)
+	assert.False(t, mudou)
+
+	// Depois da rotação a chave antiga pode ser retirada
+	nova := novoCifradorTeste(t, "k2", "k2")
+	texto, err = nova.Decifrar(recifrado, "email:m1")
+	require.NoError(t, err)
+	assert.Equal(t, "joao@email.com", texto)
+	_, err = nova.Decifrar(cifrado, "email:m1")
+	assert.ErrorIs(t, err, ErrChaveDesconhecida)
+
+	t.Run("Texto puro é cifrado", func(t *testing.T) {
+		recifrado, mudou, err := nova.Recifrar("11999999999", "telefone:m1")
+		require.NoError(t, err)
+		assert.True(t, mudou)
+		texto, err := nova.Decifrar(recifrado, "telefone:m1")
+		require.NoError(t, err)
+		assert.Equal(t, "11999999999", texto)
+	})
+}
+
+func TestCifradorIndice(t *testing.T) {
+	c := novoCifradorTeste(t, "k1", "k1")
+	indice := c.Indice("cpf", "12345678909")
+	assert.Len(t, indice, 64)
+	assert.Equal(t, indice, c.Indice("cpf", "12345678909"), "determinístico")
+	assert.NotEqual(t, indice, c.Indice("cnh", "12345678909"), "separado por domínio")
+	assert.NotEqual(t, indice, c.Indice("cpf", "12345678900"))
+
+	// Trocar apenas as chaves mestras não altera os índices
+	assert.Equal(t, indice, novoCifradorTeste(t, "k2", "k1", "k2").Indice("cpf", "12345678909"))
+}
+
+func TestNewCifrador(t *testing.T) {
+	chave := bytes.Repeat([]byte{1}, 32)
+	indice := bytes.Repeat([]byte{2}, 32)
+	casos := map[string]Config{
+		"Sem chaves":            {Ativa: "k1", ChaveIndice: indice},
+		"Ativa inexistente":     {Chaves: map[string][]byte{"k1": chave}, Ativa: "k2", ChaveIndice: indice},
+		"Chave curta":           {Chaves: map[string][]byte{"k1": chave[:16]}, Ativa: "k1", ChaveIndice: indice},
+		"Sem chave de índice":   {Chaves: map[string][]byte{"k1": chave}, Ativa: "k1"},
+		"Identificador com ':'": {Chaves: map[string][]byte{"k:1": chave}, Ativa: "k:1", ChaveIndice: indice},
+	}
+	for nome, config := range casos {
+		t.Run(nome, func(t *testing.T) {
+			_, err := NewCifrador(config)
+			assert.ErrorIs(t, err, ErrConfiguracaoInvalida)
+		})
+	}
+}
+
+func TestNewCifradorFromEnv(t *testing.T) {
+	t.Run("Sem chaves a criptografia fica desligada", func(t *testing.T) {
+		t.Setenv("DATA_ENCRYPTION_KEYS", "")
+		c, err := NewCifradorFromEnv()
+		require.NoError(t, err)
+		assert.Nil(t, c)
+	})
+
+	t.Run("Primeira chave da lista é a ativa por padrão", func(t *testing.T) {
+		k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+		k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+		t.Setenv("DATA_ENCRYPTION_KEYS", "2026-10:"+k2+", 2025-01:"+k1)
+		t.Setenv("DATA_ENCRYPTION_ACTIVE_KEY", "")
+		t.Setenv("BLIND_INDEX_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32)))
+		c, err := NewCifradorFromEnv()
+		require.NoError(t, err)
+		cifrado, err := c.Cifrar("x", "")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(cifrado, "enc:v1:2026-10:"))
+
+		t.Setenv("DATA_ENCRYPTION_ACTIVE_KEY", "2025-01")
+		c, err = NewCifradorFromEnv()
+		require.NoError(t, err)
+		cifrado, err = c.Cifrar("x", "")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(cifrado, "enc:v1:2025-01:"))
+	})
+
+	t.Run("Chave fora do formato", func(t *testing.T) {
+		t.Setenv("DATA_ENCRYPTION_KEYS", "sem-separador")
+		_, err := NewCifradorFromEnv()
+		assert.ErrorIs(t, err, ErrConfiguracaoInvalida)
+	})
+}
@@ -0,0 +1,141 @@
+// Package imagem processa as fotos enviadas pelos motoristas (foto de perfil e selfie):
+// decodifica, aplica a orientação indicada no EXIF, recodifica sem nenhum metadado
+// (EXIF, GPS, XMP) e gera as rendições reduzidas servidas como miniaturas.
+package imagem
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"taxi_service/internal/conteudo"
+)
+
+// ErrImagemInvalida a imagem não pôde ser decodificada
+var ErrImagemInvalida = errors.New("imagem não pôde ser decodificada")
+
+// LadoMaximo maior lado da foto de perfil gravada; fotos maiores são reduzidas
+const LadoMaximo = 2048
+
+const qualidadeJPEG = 85
+
+// Tamanho rendição derivada, reduzida para caber em Lado×Lado pixels
+type Tamanho struct {
+	Nome string
+	Lado int
+}
+
+// Tamanhos rendições geradas para a foto de perfil
+var Tamanhos = []Tamanho{{"thumb", 128}, {"small", 320}, {"medium", 640}}
+
+// TamanhoValido indica se o nome é de uma das rendições em Tamanhos
+func TamanhoValido(nome string) bool {
+	for _, t := range Tamanhos {
+		if t.Nome == nome {
+			return true
+		}
+	}
+	return false
+}
+
+// ChaveTamanho chave da rendição a partir da chave da foto: <id>/profile/foto.jpg
+// resulta em <id>/profile/foto-thumb.jpg
+func ChaveTamanho(chave, nome string) string {
+	base, ext := chave, ""
+	if i := strings.LastIndex(chave, "."); i > strings.LastIndex(chave, "/") {
+		base, ext = chave[:i], chave[i:]
+	}
+	return base + "-" + nome + ext
+}
+
+// Imagem imagem recodificada, sem metadados
+type Imagem struct {
+	Dados       []byte
+	Formato     string // conteudo.FormatoJPEG ou conteudo.FormatoPNG
+	ContentType string
+	Extensao    string
+	Largura     int
+	Altura      int
+}
+
+// Normalizar decodifica a imagem (JPEG, PNG ou WEBP) e aplica a orientação do EXIF,
+// de modo que os pixels fiquem na posição em que a foto deve ser exibida
+func Normalizar(dados []byte) (*image.NRGBA, error) {
+	img, _, err := image.Decode(bytes.NewReader(dados))
+	if err != nil {
+		return nil, ErrImagemInvalida
+	}
+	return orientar(img, orientacaoEXIF(dados)), nil
+}
+
+// Codificar reduz a imagem para caber em lado×lado (lado 0 mantém o tamanho; imagens
+// menores nunca são ampliadas) e a codifica em JPEG, ou em PNG se tiver transparência
+func Codificar(img *image.NRGBA, lado int) (*Imagem, error) {
+	largura, altura := ajustar(img.Bounds().Dx(), img.Bounds().Dy(), lado)
+	if largura != img.Bounds().Dx() || altura != img.Bounds().Dy() {
+		reduzida := image.NewNRGBA(image.Rect(0, 0, largura, altura))
+		draw.CatmullRom.Scale(reduzida, reduzida.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = reduzida
+	}
+	var buf bytes.Buffer
+	resultado := &Imagem{Largura: largura, Altura: altura}
+	if img.Opaque() {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: qualidadeJPEG}); err != nil {
+			return nil, err
+		}
+		resultado.Formato, resultado.ContentType, resultado.Extensao = conteudo.FormatoJPEG, "image/jpeg", ".jpg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		resultado.Formato, resultado.ContentType, resultado.Extensao = conteudo.FormatoPNG, "image/png", ".png"
+	}
+	resultado.Dados = buf.Bytes()
+	return resultado, nil
+}
+
+// Limpar recodifica a imagem no tamanho original, sem metadados e com a orientação aplicada
+func Limpar(dados []byte) (*Imagem, error) {
+	img, err := Normalizar(dados)
+	if err != nil {
+		return nil, err
+	}
+	return Codificar(img, 0)
+}
+
+// Processar gera a foto de perfil (limitada a LadoMaximo) e as rendições de Tamanhos,
+// indexadas pelo nome
+func Processar(dados []byte) (*Imagem, map[string]*Imagem, error) {
+	img, err := Normalizar(dados)
+	if err != nil {
+		return nil, nil, err
+	}
+	foto, err := Codificar(img, LadoMaximo)
+	if err != nil {
+		return nil, nil, err
+	}
+	rendicoes := make(map[string]*Imagem, len(Tamanhos))
+	for _, t := range Tamanhos {
+		if rendicoes[t.Nome], err = Codificar(img, t.Lado); err != nil {
+			return nil, nil, err
+		}
+	}
+	return foto, rendicoes, nil
+}
+
+// ajustar dimensões que cabem em lado×lado mantendo a proporção
+func ajustar(largura, altura, lado int) (int, int) {
+	if lado <= 0 || (largura <= lado && altura <= lado) {
+		return largura, altura
+	}
+	if largura >= altura {
+		return lado, max(1, altura*lado/largura)
+	}
+	return max(1, largura*lado/altura), lado
+}
@@ -0,0 +1,192 @@
+package imagem
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/conteudo"
+)
+
+var (
+	vermelho = color.NRGBA{R: 255, A: 255}
+	azul     = color.NRGBA{B: 255, A: 255}
+)
+
+// fotoTeste imagem com a metade esquerda vermelha e a direita azul
+func fotoTeste(largura, altura int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, largura, altura))
+	for y := range altura {
+		for x := range largura {
+			if x < largura/2 {
+				img.Set(x, y, vermelho)
+			} else {
+				img.Set(x, y, azul)
+			}
+		}
+	}
+	return img
+}
+
+// exifTIFF bloco EXIF (little-endian) com Orientation e um ponteiro para o IFD de GPS
+func exifTIFF(orientacao uint16) []byte {
+	var b bytes.Buffer
+	b.WriteString("Exif\x00\x00II*\x00")
+	binary.Write(&b, binary.LittleEndian, uint32(8))
+	binary.Write(&b, binary.LittleEndian, uint16(2))
+	binary.Write(&b, binary.LittleEndian, []uint16{tagOrientacao, 3, 1, 0, orientacao, 0})
+	binary.Write(&b, binary.LittleEndian, []uint16{0x8825, 4, 1, 0, 38, 0})
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	b.WriteString("GPS-23.5505S-46.6333W")
+	return b.Bytes()
+}
+
+// jpegComEXIF codifica a imagem em JPEG e insere o segmento APP1 logo após o SOI
+func jpegComEXIF(t *testing.T, img image.Image, orientacao uint16) []byte {
+	var b bytes.Buffer
+	require.NoError(t, jpeg.Encode(&b, img, &jpeg.Options{Quality: 95}))
+	exif := exifTIFF(orientacao)
+	app1 := []byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}
+	dados := append([]byte{0xFF, 0xD8}, app1...)
+	dados = append(dados, exif...)
+	return append(dados, b.Bytes()[2:]...)
+}
+
+func corAproximada(t *testing.T, esperada color.NRGBA, c color.Color) {
+	r, g, b, _ := c.RGBA()
+	assert.InDelta(t, float64(esperada.R), float64(r>>8), 40)
+	assert.InDelta(t, float64(esperada.G), float64(g>>8), 40)
+	assert.InDelta(t, float64(esperada.B), float64(b>>8), 40)
+}
+
+func TestOrientacaoEXIF(t *testing.T) {
+	for o := uint16(1); o <= 8; o++ {
+		assert.Equal(t, int(o), orientacaoEXIF(jpegComEXIF(t, fotoTeste(8, 8), o)))
+	}
+	var semEXIF bytes.Buffer
+	require.NoError(t, png.Encode(&semEXIF, fotoTeste(4, 4)))
+	assert.Equal(t, 1, orientacaoEXIF(semEXIF.Bytes()))
+	assert.Equal(t, 1, orientacaoEXIF([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}), "segmento truncado")
+}
+
+func TestOrientar(t *testing.T) {
+	// 2×1: vermelho à esquerda, azul à direita
+	img := fotoTeste(2, 1)
+	casos := map[int][2]color.NRGBA{
+		1: {vermelho, azul}, // primeiro e último pixel, em ordem de leitura
+		2: {azul, vermelho},
+		3: {azul, vermelho},
+		4: {vermelho, azul},
+		5: {vermelho, azul},
+		6: {vermelho, azul},
+		7: {azul, vermelho},
+		8: {azul, vermelho},
+	}
+	for o, esperado := range casos {
+		r := orientar(img, o)
+		if o >= 5 {
+			require.Equal(t, image.Rect(0, 0, 1, 2), r.Bounds(), "orientação %d", o)
+			assert.Equal(t, esperado, [2]color.NRGBA{r.NRGBAAt(0, 0), r.NRGBAAt(0, 1)}, "orientação %d", o)
+		} else {
+			require.Equal(t, image.Rect(0, 0, 2, 1), r.Bounds(), "orientação %d", o)
+			assert.Equal(t, esperado, [2]color.NRGBA{r.NRGBAAt(0, 0), r.NRGBAAt(1, 0)}, "orientação %d", o)
+		}
+	}
+}
+
+func TestProcessar(t *testing.T) {
+	t.Run("Remove EXIF e GPS e aplica a orientação", func(t *testing.T) {
+		// Orientação 6: a foto deve ser girada 90° no sentido horário para exibição
+		original := jpegComEXIF(t, fotoTeste(64, 32), 6)
+		foto, rendicoes, err := Processar(original)
+		require.NoError(t, err)
+
+		assert.Equal(t, conteudo.FormatoJPEG, foto.Formato)
+		assert.Equal(t, ".jpg", foto.Extensao)
+		assert.Equal(t, [2]int{32, 64}, [2]int{foto.Largura, foto.Altura})
+		assert.NotContains(t, string(foto.Dados), "Exif")
+		assert.NotContains(t, string(foto.Dados), "GPS")
+		assert.Equal(t, 1, orientacaoEXIF(foto.Dados))
+
+		decodificada, err := jpeg.Decode(bytes.NewReader(foto.Dados))
+		require.NoError(t, err)
+		// A metade esquerda (vermelha) passa a ser a de cima
+		corAproximada(t, vermelho, decodificada.At(16, 8))
+		corAproximada(t, azul, decodificada.At(16, 56))
+
+		require.Len(t, rendicoes, len(Tamanhos))
+		for _, r := range rendicoes {
+			assert.NotContains(t, string(r.Dados), "Exif")
+		}
+	})
+
+	t.Run("Rendições cabem no lado de cada tamanho sem ampliar", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, png.Encode(&b, fotoTeste(1000, 500)))
+		foto, rendicoes, err := Processar(b.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, [2]int{1000, 500}, [2]int{foto.Largura, foto.Altura})
+		assert.Equal(t, [2]int{128, 64}, [2]int{rendicoes["thumb"].Largura, rendicoes["thumb"].Altura})
+		assert.Equal(t, [2]int{320, 160}, [2]int{rendicoes["small"].Largura, rendicoes["small"].Altura})
+		assert.Equal(t, [2]int{640, 320}, [2]int{rendicoes["medium"].Largura, rendicoes["medium"].Altura})
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(rendicoes["thumb"].Dados))
+		require.NoError(t, err)
+		assert.Equal(t, 128, cfg.Width)
+	})
+
+	t.Run("Foto maior que o limite é reduzida", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, jpeg.Encode(&b, fotoTeste(LadoMaximo+400, 100), nil))
+		foto, _, err := Processar(b.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, LadoMaximo, foto.Largura)
+	})
+
+	t.Run("Transparência é preservada em PNG", func(t *testing.T) {
+		img := fotoTeste(10, 10)
+		img.Set(0, 0, color.NRGBA{})
+		var b bytes.Buffer
+		require.NoError(t, png.Encode(&b, img))
+		foto, err := Limpar(b.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, conteudo.FormatoPNG, foto.Formato)
+		assert.Equal(t, "image/png", foto.ContentType)
+	})
+
+	t.Run("WEBP é decodificado e recodificado", func(t *testing.T) {
+		for nome, b64 := range map[string]string{
+			"lossy":    "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA",
+			"lossless": "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==",
+		} {
+			dados, err := base64.StdEncoding.DecodeString(b64)
+			require.NoError(t, err)
+			foto, rendicoes, err := Processar(dados)
+			require.NoError(t, err, nome)
+			assert.Equal(t, [2]int{1, 1}, [2]int{foto.Largura, foto.Altura}, nome)
+			assert.Contains(t, []string{conteudo.FormatoJPEG, conteudo.FormatoPNG}, foto.Formato, nome)
+			assert.Len(t, rendicoes, len(Tamanhos))
+		}
+	})
+
+	t.Run("Imagem corrompida", func(t *testing.T) {
+		_, _, err := Processar([]byte("\x89PNG\r\n\x1a\nlixo"))
+		assert.ErrorIs(t, err, ErrImagemInvalida)
+	})
+}
+
+func TestChaveTamanho(t *testing.T) {
+	assert.Equal(t, "m1/profile/foto-thumb.jpg", ChaveTamanho("m1/profile/foto.jpg", "thumb"))
+	assert.Equal(t, "m1/profile/foto-small", ChaveTamanho("m1/profile/foto", "small"))
+	assert.Equal(t, "m1.d/profile/foto-medium", ChaveTamanho("m1.d/profile/foto", "medium"))
+	assert.True(t, TamanhoValido("thumb"))
+	assert.False(t, TamanhoValido("original"))
+}
@@ -0,0 +1,154 @@
+package imagem
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+const tagOrientacao = 0x0112
+
+// orientacaoEXIF valor da tag Orientation (1 a 8) do EXIF da imagem; 1 (normal) quando
+// não há EXIF ou a tag é inválida. O bloco EXIF fica no segmento APP1 do JPEG, no chunk
+// eXIf do PNG ou no chunk EXIF do WEBP.
+func orientacaoEXIF(dados []byte) int {
+	tiff := blocoEXIF(dados)
+	tiff = bytes.TrimPrefix(tiff, []byte("Exif\x00\x00"))
+	if len(tiff) < 8 {
+		return 1
+	}
+	var ordem binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		ordem = binary.LittleEndian
+	case "MM":
+		ordem = binary.BigEndian
+	default:
+		return 1
+	}
+	if ordem.Uint16(tiff[2:4]) != 42 {
+		return 1
+	}
+	ifd := int64(ordem.Uint32(tiff[4:8]))
+	if ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	entradas := int64(ordem.Uint16(tiff[ifd:]))
+	for i := range entradas {
+		e := ifd + 2 + 12*i
+		if e+12 > int64(len(tiff)) {
+			break
+		}
+		// Entrada: tag, tipo (3 = SHORT), quantidade e valor
+		if ordem.Uint16(tiff[e:]) == tagOrientacao && ordem.Uint16(tiff[e+2:]) == 3 {
+			if o := int(ordem.Uint16(tiff[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// blocoEXIF localiza o bloco TIFF com o EXIF nos formatos aceitos; nil se não houver
+func blocoEXIF(dados []byte) []byte {
+	switch {
+	case bytes.HasPrefix(dados, []byte{0xFF, 0xD8}):
+		for i := 2; i+4 <= len(dados); {
+			if dados[i] != 0xFF {
+				return nil
+			}
+			marcador := dados[i+1]
+			switch {
+			case marcador == 0xFF:
+				i++
+				continue
+			case marcador == 0xDA || marcador == 0xD9:
+				// Início dos dados da imagem: os metadados vêm antes
+				return nil
+			case marcador >= 0xD0 && marcador <= 0xD7 || marcador == 0x01:
+				i += 2
+				continue
+			}
+			tamanho := int(binary.BigEndian.Uint16(dados[i+2:]))
+			if tamanho < 2 || i+2+tamanho > len(dados) {
+				return nil
+			}
+			segmento := dados[i+4 : i+2+tamanho]
+			if marcador == 0xE1 && bytes.HasPrefix(segmento, []byte("Exif\x00\x00")) {
+				return segmento
+			}
+			i += 2 + tamanho
+		}
+	case bytes.HasPrefix(dados, []byte("\x89PNG\r\n\x1a\n")):
+		// Chunks: tamanho (4 bytes), tipo (4), dados e CRC (4)
+		for i := 8; i+8 <= len(dados); {
+			tamanho := int64(binary.BigEndian.Uint32(dados[i:]))
+			fim := int64(i) + 8 + tamanho
+			if fim+4 > int64(len(dados)) {
+				return nil
+			}
+			if string(dados[i+4:i+8]) == "eXIf" {
+				return dados[i+8 : fim]
+			}
+			i = int(fim + 4)
+		}
+	case len(dados) >= 12 && string(dados[:4]) == "RIFF" && string(dados[8:12]) == "WEBP":
+		// Chunks: tipo (4 bytes), tamanho (4, little-endian) e dados com tamanho par
+		for i := 12; i+8 <= len(dados); {
+			tamanho := int64(binary.LittleEndian.Uint32(dados[i+4:]))
+			fim := int64(i) + 8 + tamanho
+			if fim > int64(len(dados)) {
+				return nil
+			}
+			if string(dados[i:i+4]) == "EXIF" {
+				return dados[i+8 : fim]
+			}
+			i = int(fim + fim%2)
+		}
+	}
+	return nil
+}
+
+// orientar copia a imagem para um NRGBA aplicando a orientação EXIF: 2 espelha na
+// horizontal, 3 gira 180°, 4 espelha na vertical, 5 transpõe, 6 gira 90° no sentido
+// horário, 7 transpõe pela diagonal secundária e 8 gira 90° no sentido anti-horário
+func orientar(img image.Image, orientacao int) *image.NRGBA {
+	b := img.Bounds()
+	origem := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(origem, origem.Bounds(), img, b.Min, draw.Src)
+	if orientacao <= 1 || orientacao > 8 {
+		return origem
+	}
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientacao >= 5 {
+		dw, dh = h, w
+	}
+	destino := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for dy := range dh {
+		for dx := range dw {
+			var sx, sy int
+			switch orientacao {
+			case 2:
+				sx, sy = w-1-dx, dy
+			case 3:
+				sx, sy = w-1-dx, h-1-dy
+			case 4:
+				sx, sy = dx, h-1-dy
+			case 5:
+				sx, sy = dy, dx
+			case 6:
+				sx, sy = dy, h-1-dx
+			case 7:
+				sx, sy = w-1-dy, h-1-dx
+			case 8:
+				sx, sy = w-1-dy, dx
+			}
+			copy(destino.Pix[destino.PixOffset(dx, dy):][:4], origem.Pix[origem.PixOffset(sx, sy):][:4])
+		}
+	}
+	return destino
+}
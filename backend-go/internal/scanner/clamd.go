@@ -0,0 +1,155 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	timeoutClamd      = 60 * time.Second
+	tamanhoBlocoClamd = 64 << 10
+)
+
+// ClamdConfig endereço e limites de acesso ao clamd
+type ClamdConfig struct {
+	Endereco string        // tcp://host:porta, unix:///caminho/clamd.sock ou host:porta
+	Timeout  time.Duration // limite de cada verificação (padrão 60s)
+}
+
+// ClamdConfigFromEnv lê a configuração das variáveis CLAMD_* do .env
+func ClamdConfigFromEnv() ClamdConfig {
+	config := ClamdConfig{Endereco: os.Getenv("CLAMD_ADDRESS")}
+	if config.Endereco == "" {
+		config.Endereco = "tcp://127.0.0.1:3310"
+	}
+	if t, err := time.ParseDuration(os.Getenv("CLAMD_TIMEOUT")); err == nil && t > 0 {
+		config.Timeout = t
+	}
+	return config
+}
+
+// ClamdScanner verifica os arquivos em um daemon do ClamAV (clamd) pelo comando
+// INSTREAM: o conteúdo é enviado em blocos, sem que o daemon precise acessar o disco
+// da aplicação. Cada verificação usa uma conexão nova.
+type ClamdScanner struct {
+	rede     string
+	endereco string
+	timeout  time.Duration
+}
+
+// NewClamdScanner valida o endereço; a conexão só é aberta em cada verificação
+func NewClamdScanner(config ClamdConfig) (*ClamdScanner, error) {
+	s := &ClamdScanner{rede: "tcp", endereco: config.Endereco, timeout: config.Timeout}
+	switch {
+	case strings.HasPrefix(s.endereco, "tcp://"):
+		s.endereco = strings.TrimPrefix(s.endereco, "tcp://")
+	case strings.HasPrefix(s.endereco, "unix://"):
+		s.rede, s.endereco = "unix", strings.TrimPrefix(s.endereco, "unix://")
+	case strings.HasPrefix(s.endereco, "/"):
+		s.rede = "unix"
+	}
+	if s.endereco == "" {
+		return nil, fmt.Errorf("endereço do clamd não configurado")
+	}
+	if s.rede == "tcp" {
+		if _, _, err := net.SplitHostPort(s.endereco); err != nil {
+			return nil, fmt.Errorf("endereço do clamd inválido %q: %w", config.Endereco, err)
+		}
+	}
+	if s.timeout <= 0 {
+		s.timeout = timeoutClamd
+	}
+	return s, nil
+}
+
+// Ping confere se o daemon está respondendo
+func (s *ClamdScanner) Ping() error {
+	resposta, err := s.executar(func(conn net.Conn) error {
+		_, err := conn.Write([]byte("zPING\x00"))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if resposta != "PONG" {
+		return fmt.Errorf("%w: resposta inesperada do clamd: %q", ErrScannerIndisponivel, resposta)
+	}
+	return nil
+}
+
+// Verificar envia o conteúdo pelo comando INSTREAM: blocos precedidos do tamanho (4
+// bytes, big-endian) e um bloco vazio no fim. A resposta é "stream: OK",
+// "stream: <assinatura> FOUND" ou "<mensagem> ERROR" (ex.: tamanho acima do
+// StreamMaxLength do daemon).
+func (s *ClamdScanner) Verificar(conteudo io.Reader) (*Resultado, error) {
+	resposta, err := s.executar(func(conn net.Conn) error {
+		if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+			return err
+		}
+		bloco := make([]byte, 4+tamanhoBlocoClamd)
+		for {
+			n, err := io.ReadFull(conteudo, bloco[4:])
+			if n > 0 {
+				binary.BigEndian.PutUint32(bloco, uint32(n))
+				if _, errEscrita := conn.Write(bloco[:4+n]); errEscrita != nil {
+					return errEscrita
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("erro ao ler arquivo: %w", err)
+			}
+		}
+		_, err := conn.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return interpretarResposta(resposta)
+}
+
+// executar abre a conexão, envia o comando e lê a resposta terminada em NUL
+func (s *ClamdScanner) executar(enviar func(conn net.Conn) error) (string, error) {
+	conn, err := net.DialTimeout(s.rede, s.endereco, s.timeout)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrScannerIndisponivel, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.timeout))
+
+	errEnvio := enviar(conn)
+	// Ao recusar o conteúdo (limite de tamanho) o daemon responde e fecha a conexão
+	// antes do fim do envio; a resposta explica o erro melhor que a escrita interrompida
+	resposta, errLeitura := bufio.NewReader(conn).ReadBytes(0)
+	if errLeitura != nil && (errEnvio != nil || len(resposta) == 0) {
+		if errEnvio == nil {
+			errEnvio = errLeitura
+		}
+		return "", fmt.Errorf("%w: %v", ErrScannerIndisponivel, errEnvio)
+	}
+	return string(bytes.TrimSpace(bytes.TrimSuffix(resposta, []byte{0}))), nil
+}
+
+func interpretarResposta(resposta string) (*Resultado, error) {
+	corpo := resposta
+	if i := strings.Index(resposta, ": "); i >= 0 {
+		corpo = resposta[i+2:]
+	}
+	switch {
+	case corpo == "OK":
+		return &Resultado{}, nil
+	case strings.HasSuffix(corpo, " FOUND"):
+		return &Resultado{Infectado: true, Assinatura: strings.TrimSuffix(corpo, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrScannerIndisponivel, resposta)
+}
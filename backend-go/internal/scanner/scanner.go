@@ -0,0 +1,36 @@
+// Package scanner verifica os arquivos enviados pelos motoristas em busca de malware
+// antes que fiquem disponíveis para revisão. O antivírus é plugável: um cliente do
+// protocolo do clamd, um scanner nulo para desenvolvimento e um simulado para testes.
+package scanner
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrScannerIndisponivel o antivírus não respondeu ou não concluiu a verificação
+var ErrScannerIndisponivel = errors.New("antivírus indisponível")
+
+// Resultado resultado da verificação de um arquivo
+type Resultado struct {
+	Infectado  bool
+	Assinatura string // nome da ameaça encontrada, quando infectado
+}
+
+// FileScanner verifica o conteúdo de um arquivo. Um erro indica que a verificação não
+// foi concluída (o arquivo não deve ser considerado limpo); um arquivo infectado não é erro.
+type FileScanner interface {
+	Verificar(conteudo io.Reader) (*Resultado, error)
+}
+
+// Nulo considera todos os arquivos limpos. Para desenvolvimento ou ambientes em que a
+// verificação é feita fora da aplicação.
+type Nulo struct{}
+
+// Verificar consome o conteúdo e o considera limpo
+func (Nulo) Verificar(conteudo io.Reader) (*Resultado, error) {
+	if _, err := io.Copy(io.Discard, conteudo); err != nil {
+		return nil, err
+	}
+	return &Resultado{}, nil
+}
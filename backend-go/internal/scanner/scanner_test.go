@@ -0,0 +1,186 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clamdFalso servidor que fala o protocolo do clamd (PING e INSTREAM): responde FOUND
+// quando o conteúdo recebido contém o EICAR e recusa conteúdos acima de limite bytes
+type clamdFalso struct {
+	limite   int
+	recebido chan []byte
+}
+
+func (c *clamdFalso) atender(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			comando, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			switch comando {
+			case "zPING\x00":
+				conn.Write([]byte("PONG\x00"))
+			case "zINSTREAM\x00":
+				var dados []byte
+				for {
+					var tamanho uint32
+					if err := binary.Read(r, binary.BigEndian, &tamanho); err != nil {
+						return
+					}
+					if tamanho == 0 {
+						break
+					}
+					if len(dados)+int(tamanho) > c.limite {
+						conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+						return
+					}
+					bloco := make([]byte, tamanho)
+					if _, err := io.ReadFull(r, bloco); err != nil {
+						return
+					}
+					dados = append(dados, bloco...)
+				}
+				c.recebido <- dados
+				if bytes.Contains(dados, []byte(EICAR)) {
+					conn.Write([]byte("stream: Win.Test.EICAR_HDB-1 FOUND\x00"))
+				} else {
+					conn.Write([]byte("stream: OK\x00"))
+				}
+			default:
+				conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			}
+		}()
+	}
+}
+
+func iniciarClamd(t *testing.T, rede, endereco string) *clamdFalso {
+	l, err := net.Listen(rede, endereco)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	c := &clamdFalso{limite: 1 << 20, recebido: make(chan []byte, 10)}
+	go c.atender(l)
+	return c
+}
+
+func TestClamdScanner(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	endereco := l.Addr().String()
+	l.Close()
+	clamd := iniciarClamd(t, "tcp", endereco)
+
+	s, err := NewClamdScanner(ClamdConfig{Endereco: "tcp://" + endereco, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.NoError(t, s.Ping())
+
+	t.Run("Arquivo limpo enviado em blocos", func(t *testing.T) {
+		// Maior que um bloco, para exercitar a divisão do conteúdo
+		dados := bytes.Repeat([]byte("%PDF-1.7 limpo "), tamanhoBlocoClamd/8)
+		resultado, err := s.Verificar(bytes.NewReader(dados))
+		require.NoError(t, err)
+		assert.False(t, resultado.Infectado)
+		assert.Equal(t, dados, <-clamd.recebido)
+	})
+
+	t.Run("Arquivo infectado", func(t *testing.T) {
+		resultado, err := s.Verificar(strings.NewReader("início " + EICAR + " fim"))
+		require.NoError(t, err)
+		assert.True(t, resultado.Infectado)
+		assert.Equal(t, "Win.Test.EICAR_HDB-1", resultado.Assinatura)
+		<-clamd.recebido
+	})
+
+	t.Run("Conteúdo acima do limite do daemon", func(t *testing.T) {
+		_, err := s.Verificar(bytes.NewReader(make([]byte, clamd.limite+tamanhoBlocoClamd)))
+		assert.ErrorIs(t, err, ErrScannerIndisponivel)
+		assert.ErrorContains(t, err, "size limit exceeded")
+	})
+
+	t.Run("Daemon fora do ar", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		fechado := l.Addr().String()
+		l.Close()
+		s, err := NewClamdScanner(ClamdConfig{Endereco: fechado, Timeout: time.Second})
+		require.NoError(t, err)
+		_, err = s.Verificar(strings.NewReader("qualquer"))
+		assert.ErrorIs(t, err, ErrScannerIndisponivel)
+		assert.ErrorIs(t, s.Ping(), ErrScannerIndisponivel)
+	})
+}
+
+func TestClamdScannerSocketUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	iniciarClamd(t, "unix", socket)
+	for _, endereco := range []string{"unix://" + socket, socket} {
+		s, err := NewClamdScanner(ClamdConfig{Endereco: endereco})
+		require.NoError(t, err)
+		resultado, err := s.Verificar(strings.NewReader(EICAR))
+		require.NoError(t, err, endereco)
+		assert.True(t, resultado.Infectado, endereco)
+	}
+}
+
+func TestNewClamdScannerEnderecoInvalido(t *testing.T) {
+	_, err := NewClamdScanner(ClamdConfig{})
+	assert.Error(t, err)
+	_, err = NewClamdScanner(ClamdConfig{Endereco: "tcp://localhost"})
+	assert.Error(t, err)
+}
+
+func TestInterpretarResposta(t *testing.T) {
+	resultado, err := interpretarResposta("stream: OK")
+	require.NoError(t, err)
+	assert.False(t, resultado.Infectado)
+
+	resultado, err = interpretarResposta("stream: Doc.Exploit.Pdf-123 FOUND")
+	require.NoError(t, err)
+	assert.Equal(t, &Resultado{Infectado: true, Assinatura: "Doc.Exploit.Pdf-123"}, resultado)
+
+	_, err = interpretarResposta("stream: Can't allocate memory ERROR")
+	assert.ErrorIs(t, err, ErrScannerIndisponivel)
+}
+
+func TestSimulado(t *testing.T) {
+	s := &Simulado{Assinaturas: map[string]string{"/JavaScript": "Pdf.Exploit.JS"}}
+
+	resultado, err := s.Verificar(strings.NewReader("%PDF-1.7 limpo"))
+	require.NoError(t, err)
+	assert.False(t, resultado.Infectado)
+
+	resultado, err = s.Verificar(strings.NewReader(EICAR))
+	require.NoError(t, err)
+	assert.Equal(t, AssinaturaEICAR, resultado.Assinatura)
+
+	resultado, err = s.Verificar(strings.NewReader("<< /S /JavaScript >>"))
+	require.NoError(t, err)
+	assert.Equal(t, "Pdf.Exploit.JS", resultado.Assinatura)
+
+	s.Erro = errors.New("falha simulada")
+	_, err = s.Verificar(strings.NewReader("%PDF"))
+	assert.Error(t, err)
+	assert.Equal(t, 4, s.Verificacoes())
+
+	resultado, err = Nulo{}.Verificar(strings.NewReader(EICAR))
+	require.NoError(t, err)
+	assert.False(t, resultado.Infectado)
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// EICAR arquivo de teste padrão de antivírus: inofensivo, mas reconhecido como vírus
+// por todos os antivírus (inclusive o ClamAV, como "Win.Test.EICAR_HDB-1")
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// AssinaturaEICAR assinatura informada pelo Simulado para o arquivo EICAR
+const AssinaturaEICAR = "Eicar-Test-Signature"
+
+// Simulado scanner para testes: considera infectado o conteúdo que contém o EICAR ou
+// algum dos trechos de Assinaturas, e falha com Erro quando ele está definido
+type Simulado struct {
+	Assinaturas map[string]string // trecho do conteúdo -> nome da ameaça
+	Erro        error
+
+	mu           sync.Mutex
+	verificacoes int
+}
+
+// Verificar lê o conteúdo inteiro e procura os trechos configurados
+func (s *Simulado) Verificar(conteudo io.Reader) (*Resultado, error) {
+	s.mu.Lock()
+	s.verificacoes++
+	erro := s.Erro
+	s.mu.Unlock()
+
+	dados, err := io.ReadAll(conteudo)
+	if err != nil {
+		return nil, err
+	}
+	if erro != nil {
+		return nil, erro
+	}
+	if bytes.Contains(dados, []byte(EICAR)) {
+		return &Resultado{Infectado: true, Assinatura: AssinaturaEICAR}, nil
+	}
+	for trecho, assinatura := range s.Assinaturas {
+		if bytes.Contains(dados, []byte(trecho)) {
+			return &Resultado{Infectado: true, Assinatura: assinatura}, nil
+		}
+	}
+	return &Resultado{}, nil
+}
+
+// Verificacoes quantidade de arquivos recebidos
+func (s *Simulado) Verificacoes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.verificacoes
+}
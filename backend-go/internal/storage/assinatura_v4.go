@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// payloadNaoAssinado dispensa o hash do corpo na assinatura (envio em stream)
+const payloadNaoAssinado = "UNSIGNED-PAYLOAD"
+
+// assinarV4 assina a requisição com AWS Signature Version 4 (serviço "s3").
+// Assina o Host e todos os cabeçalhos já definidos em req.Header; cabeçalhos
+// acrescentados depois (pelo transporte HTTP) ficam fora da assinatura.
+func assinarV4(req *http.Request, config S3Config, hashPayload string, agora time.Time) {
+	agora = agora.UTC()
+	dataHora := agora.Format("20060102T150405Z")
+	data := agora.Format("20060102")
+	req.Header.Set("X-Amz-Date", dataHora)
+	req.Header.Set("X-Amz-Content-Sha256", hashPayload)
+
+	nomes := []string{"host"}
+	valores := map[string]string{"host": req.URL.Host}
+	for nome, v := range req.Header {
+		nome = strings.ToLower(nome)
+		if nome == "authorization" {
+			continue
+		}
+		nomes = append(nomes, nome)
+		valores[nome] = strings.Join(v, ",")
+	}
+	sort.Strings(nomes)
+	var cabecalhos strings.Builder
+	for _, nome := range nomes {
+		cabecalhos.WriteString(nome + ":" + strings.TrimSpace(valores[nome]) + "\n")
+	}
+	assinados := strings.Join(nomes, ";")
+
+	requisicaoCanonica := strings.Join([]string{
+		req.Method,
+		codificarURI(req.URL.Path, false),
+		queryCanonica(req.URL.Query()),
+		cabecalhos.String(),
+		assinados,
+		hashPayload,
+	}, "\n")
+	escopo := data + "/" + config.Regiao + "/s3/aws4_request"
+	textoAssinado := "AWS4-HMAC-SHA256\n" + dataHora + "\n" + escopo + "\n" + hashHex([]byte(requisicaoCanonica))
+
+	chave := hmacSHA256([]byte("AWS4"+config.SecretKey), data)
+	chave = hmacSHA256(chave, config.Regiao)
+	chave = hmacSHA256(chave, "s3")
+	chave = hmacSHA256(chave, "aws4_request")
+	assinatura := hex.EncodeToString(hmacSHA256(chave, textoAssinado))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+config.AccessKey+"/"+escopo+
+		", SignedHeaders="+assinados+", Signature="+assinatura)
+}
+
+func hmacSHA256(chave []byte, texto string) []byte {
+	mac := hmac.New(sha256.New, chave)
+	mac.Write([]byte(texto))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	soma := sha256.Sum256(data)
+	return hex.EncodeToString(soma[:])
+}
+
+// codificarURI aplica a codificação exigida pela assinatura: tudo exceto letras,
+// dígitos e -_.~ vira %XX; a barra é preservada em caminhos (codificarBarra=false)
+func codificarURI(s string, codificarBarra bool) string {
+	const hexa = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !codificarBarra:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexa[c>>4])
+			b.WriteByte(hexa[c&15])
+		}
+	}
+	return b.String()
+}
+
+// queryCanonica ordena os parâmetros por nome e os codifica como na assinatura
+func queryCanonica(query url.Values) string {
+	pares := make([]string, 0, len(query))
+	for nome, valores := range query {
+		for _, v := range valores {
+			pares = append(pares, codificarURI(nome, true)+"="+codificarURI(v, true))
+		}
+	}
+	sort.Strings(pares)
+	return strings.Join(pares, "&")
+}
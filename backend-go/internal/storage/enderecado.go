@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrChecksumDivergente o conteúdo armazenado não corresponde ao checksum registrado
+var ErrChecksumDivergente = errors.New("conteúdo armazenado não corresponde ao checksum")
+
+// Checksum SHA-256 do conteúdo em hexadecimal
+func Checksum(data []byte) string {
+	soma := sha256.Sum256(data)
+	return hex.EncodeToString(soma[:])
+}
+
+// GravarPorConteudo grava o conteúdo endereçado pelo próprio hash, na chave
+// prefixo + SHA-256 + ext. Conteúdo idêntico resulta na mesma chave e não é gravado
+// de novo (a menos que a cópia existente esteja corrompida), e um arquivo gravado
+// nunca é sobrescrito com bytes diferentes. Retorna a chave e o checksum.
+func GravarPorConteudo(store FileStore, prefixo string, conteudo io.ReadSeeker, tamanho int64, ext, contentType string) (chave, checksum string, err error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, conteudo); err != nil {
+		return "", "", fmt.Errorf("erro ao calcular checksum: %w", err)
+	}
+	if _, err := conteudo.Seek(0, io.SeekStart); err != nil {
+		return "", "", fmt.Errorf("erro ao reposicionar conteúdo: %w", err)
+	}
+	checksum = hex.EncodeToString(h.Sum(nil))
+	chave = prefixo + checksum + strings.ToLower(ext)
+
+	if _, _, err := LerVerificado(store, chave, checksum); err == nil {
+		return chave, checksum, nil
+	} else if !errors.Is(err, ErrArquivoNaoEncontrado) && !errors.Is(err, ErrChecksumDivergente) {
+		return "", "", err
+	}
+	if err := store.Gravar(chave, conteudo, tamanho, contentType); err != nil {
+		return "", "", err
+	}
+	return chave, checksum, nil
+}
+
+// LerVerificado lê o arquivo inteiro e confere seu SHA-256 antes de devolvê-lo;
+// em caso de divergência retorna ErrChecksumDivergente e nenhum conteúdo
+func LerVerificado(store FileStore, chave, checksum string) ([]byte, *InfoArquivo, error) {
+	leitor, info, err := store.Abrir(chave)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer leitor.Close()
+	var buf bytes.Buffer
+	if info.Tamanho > 0 {
+		buf.Grow(int(info.Tamanho))
+	}
+	if _, err := io.Copy(&buf, leitor); err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if Checksum(buf.Bytes()) != strings.ToLower(checksum) {
+		return nil, nil, ErrChecksumDivergente
+	}
+	return buf.Bytes(), info, nil
+}
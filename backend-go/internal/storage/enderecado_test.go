@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGravarPorConteudo(t *testing.T) {
+	raiz := t.TempDir()
+	store := NewLocalFileStore(raiz)
+	conteudo := []byte("%PDF-1.4 CNH do motorista")
+	esperado := Checksum(conteudo)
+
+	chave, checksum, err := GravarPorConteudo(store, "m1/documentos/", bytes.NewReader(conteudo), int64(len(conteudo)), ".PDF", "application/pdf")
+	require.NoError(t, err)
+	assert.Equal(t, esperado, checksum)
+	assert.Equal(t, "m1/documentos/"+esperado+".pdf", chave)
+
+	lido, _, err := LerVerificado(store, chave, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, conteudo, lido)
+
+	t.Run("Conteúdo repetido reaproveita o arquivo", func(t *testing.T) {
+		antes, err := os.Stat(filepath.Join(raiz, filepath.FromSlash(chave)))
+		require.NoError(t, err)
+		mesmaChave, _, err := GravarPorConteudo(store, "m1/documentos/", bytes.NewReader(conteudo), int64(len(conteudo)), ".pdf", "")
+		require.NoError(t, err)
+		assert.Equal(t, chave, mesmaChave)
+		depois, err := os.Stat(filepath.Join(raiz, filepath.FromSlash(chave)))
+		require.NoError(t, err)
+		assert.Equal(t, antes.ModTime(), depois.ModTime())
+	})
+
+	t.Run("Conteúdo diferente não sobrescreve o anterior", func(t *testing.T) {
+		outraChave, outroChecksum, err := GravarPorConteudo(store, "m1/documentos/", strings.NewReader("outra CNH"), 9, ".pdf", "")
+		require.NoError(t, err)
+		assert.NotEqual(t, chave, outraChave)
+		assert.NotEqual(t, checksum, outroChecksum)
+		_, _, err = LerVerificado(store, chave, checksum)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Arquivo adulterado é recusado e regravado no próximo envio", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(raiz, filepath.FromSlash(chave)), []byte("adulterado"), 0600))
+		_, _, err := LerVerificado(store, chave, checksum)
+		assert.ErrorIs(t, err, ErrChecksumDivergente)
+
+		_, _, err = GravarPorConteudo(store, "m1/documentos/", bytes.NewReader(conteudo), int64(len(conteudo)), ".pdf", "")
+		require.NoError(t, err)
+		_, _, err = LerVerificado(store, chave, checksum)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Arquivo inexistente", func(t *testing.T) {
+		_, _, err := LerVerificado(store, "m1/documentos/nao-existe.pdf", checksum)
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+	})
+}
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalFileStore guarda os arquivos em um diretório do disco local; a chave é o
+// caminho relativo a esse diretório
+type LocalFileStore struct {
+	raiz string
+}
+
+// NewLocalFileStore cria o armazenamento sob o diretório raiz (criado sob demanda)
+func NewLocalFileStore(raiz string) *LocalFileStore {
+	return &LocalFileStore{raiz: raiz}
+}
+
+func (s *LocalFileStore) caminho(chave string) (string, error) {
+	if err := validarChave(chave); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.raiz, filepath.FromSlash(chave)), nil
+}
+
+// Gravar escreve em um temporário no mesmo diretório e o renomeia por cima do
+// destino, para que leitores nunca vejam um arquivo pela metade
+func (s *LocalFileStore) Gravar(chave string, conteudo io.Reader, tamanho int64, contentType string) error {
+	destino, err := s.caminho(chave)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(destino)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(destino)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, conteudo); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao sincronizar arquivo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao fechar arquivo: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), destino); err != nil {
+		return fmt.Errorf("erro ao substituir arquivo: %w", err)
+	}
+	return nil
+}
+
+// Ler retorna o conteúdo completo do arquivo
+func (s *LocalFileStore) Ler(chave string) ([]byte, error) {
+	leitor, _, err := s.Abrir(chave)
+	if err != nil {
+		return nil, err
+	}
+	defer leitor.Close()
+	return io.ReadAll(leitor)
+}
+
+// Abrir abre o arquivo para leitura
+func (s *LocalFileStore) Abrir(chave string) (io.ReadCloser, *InfoArquivo, error) {
+	caminho, err := s.caminho(chave)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrArquivoNaoEncontrado
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao abrir arquivo: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("erro ao consultar arquivo: %w", err)
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, nil, ErrArquivoNaoEncontrado
+	}
+	return f, infoLocal(chave, st), nil
+}
+
+// Info retorna os metadados do arquivo
+func (s *LocalFileStore) Info(chave string) (*InfoArquivo, error) {
+	caminho, err := s.caminho(chave)
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(caminho)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && st.IsDir()) {
+		return nil, ErrArquivoNaoEncontrado
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar arquivo: %w", err)
+	}
+	return infoLocal(chave, st), nil
+}
+
+// Deletar remove o arquivo
+func (s *LocalFileStore) Deletar(chave string) error {
+	caminho, err := s.caminho(chave)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(caminho); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("erro ao remover arquivo: %w", err)
+	}
+	return nil
+}
+
+// DeletarPrefixo remove o diretório correspondente ao prefixo
+func (s *LocalFileStore) DeletarPrefixo(prefixo string) error {
+	if err := validarPrefixo(prefixo); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(s.raiz, filepath.FromSlash(prefixo))); err != nil {
+		return fmt.Errorf("erro ao remover arquivos: %w", err)
+	}
+	return nil
+}
+
+// infoLocal o disco não guarda o content type; ele é deduzido da extensão
+func infoLocal(chave string, st os.FileInfo) *InfoArquivo {
+	return &InfoArquivo{
+		Chave:        chave,
+		Tamanho:      st.Size(),
+		ContentType:  contentTypePorChave(chave),
+		ModificadoEm: st.ModTime(),
+	}
+}
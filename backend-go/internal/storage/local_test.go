@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFileStore(t *testing.T) {
+	raiz := t.TempDir()
+	store := NewLocalFileStore(raiz)
+	testarFileStore(t, store)
+
+	t.Run("Chave corresponde ao caminho relativo à raiz", func(t *testing.T) {
+		require.NoError(t, store.Gravar("m2/CRLV.png", strings.NewReader("png"), 3, "image/png"))
+		data, err := os.ReadFile(filepath.Join(raiz, "m2", "CRLV.png"))
+		require.NoError(t, err)
+		assert.Equal(t, "png", string(data))
+
+		// Nenhum temporário fica para trás
+		entradas, err := os.ReadDir(filepath.Join(raiz, "m2"))
+		require.NoError(t, err)
+		assert.Len(t, entradas, 1)
+	})
+
+	t.Run("Diretório não é arquivo", func(t *testing.T) {
+		_, err := store.Info("m2")
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+	})
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeoutS3 limite de cada operação no object store; em Abrir vale apenas até o
+// início da resposta, para não interromper downloads longos
+const timeoutS3 = 30 * time.Second
+
+// S3Config configuração de acesso ao object store (AWS S3, MinIO...)
+type S3Config struct {
+	Endpoint  string // URL base, ex.: http://localhost:9000 ou https://s3.sa-east-1.amazonaws.com
+	Regiao    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3ConfigFromEnv lê a configuração das variáveis S3_* do .env
+func S3ConfigFromEnv() S3Config {
+	regiao := os.Getenv("S3_REGION")
+	if regiao == "" {
+		regiao = "us-east-1"
+	}
+	return S3Config{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Regiao:    regiao,
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+	}
+}
+
+// S3FileStore guarda os arquivos como objetos de um bucket compatível com S3.
+// Usa endereçamento por caminho (endpoint/bucket/chave), aceito pela AWS e pelo MinIO,
+// e assina as requisições com AWS Signature V4.
+type S3FileStore struct {
+	config   S3Config
+	endpoint *url.URL
+	cliente  *http.Client
+	agora    func() time.Time
+}
+
+// NewS3FileStore valida a configuração e cria o bucket se ele ainda não existir
+func NewS3FileStore(config S3Config) (*S3FileStore, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("configuração S3 incompleta: informe endpoint, bucket e credenciais")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint S3 inválido: %q", config.Endpoint)
+	}
+	transporte := http.DefaultTransport.(*http.Transport).Clone()
+	transporte.ResponseHeaderTimeout = timeoutS3
+	s := &S3FileStore{
+		config:   config,
+		endpoint: endpoint,
+		cliente:  &http.Client{Transport: transporte},
+		agora:    time.Now,
+	}
+	if err := s.garantirBucket(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// garantirBucket cria o bucket configurado quando ele não existe (útil com MinIO local)
+func (s *S3FileStore) garantirBucket() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutS3)
+	defer cancel()
+	resp, err := s.executar(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao acessar bucket %s: %w", s.config.Bucket, err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+	default:
+		return fmt.Errorf("erro ao acessar bucket %s: status %d", s.config.Bucket, resp.StatusCode)
+	}
+
+	// Fora de us-east-1 a AWS exige a região no corpo da criação
+	var corpo []byte
+	if s.config.Regiao != "us-east-1" {
+		corpo = []byte(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>` +
+			s.config.Regiao + `</LocationConstraint></CreateBucketConfiguration>`)
+	}
+	resp, err = s.executar(ctx, http.MethodPut, "", nil, corpo)
+	if err != nil {
+		return fmt.Errorf("erro ao criar bucket %s: %w", s.config.Bucket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return erroS3(resp)
+	}
+	return nil
+}
+
+// Gravar envia o objeto com PUT; com tamanho desconhecido o conteúdo é lido para a memória,
+// pois o S3 não aceita envio sem Content-Length
+func (s *S3FileStore) Gravar(chave string, conteudo io.Reader, tamanho int64, contentType string) error {
+	if err := validarChave(chave); err != nil {
+		return err
+	}
+	if tamanho < 0 {
+		data, err := io.ReadAll(conteudo)
+		if err != nil {
+			return fmt.Errorf("erro ao ler conteúdo: %w", err)
+		}
+		conteudo, tamanho = bytes.NewReader(data), int64(len(data))
+	}
+	if contentType == "" {
+		contentType = contentTypePorChave(chave)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutS3)
+	defer cancel()
+	req, err := s.novaRequisicao(ctx, http.MethodPut, chave, nil)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(conteudo)
+	req.ContentLength = tamanho
+	if tamanho == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", contentType)
+	// O conteúdo é transmitido sem ser lido antes; a integridade fica a cargo do TLS
+	assinarV4(req, s.config, payloadNaoAssinado, s.agora())
+
+	resp, err := s.cliente.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar objeto: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return erroS3(resp)
+	}
+	return nil
+}
+
+// Ler retorna o conteúdo completo do objeto
+func (s *S3FileStore) Ler(chave string) ([]byte, error) {
+	leitor, _, err := s.Abrir(chave)
+	if err != nil {
+		return nil, err
+	}
+	defer leitor.Close()
+	return io.ReadAll(leitor)
+}
+
+// Abrir inicia o download do objeto; o corpo da resposta é devolvido como stream
+func (s *S3FileStore) Abrir(chave string) (io.ReadCloser, *InfoArquivo, error) {
+	if err := validarChave(chave); err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.executar(context.Background(), http.MethodGet, chave, nil, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao baixar objeto: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil, ErrArquivoNaoEncontrado
+		}
+		return nil, nil, erroS3(resp)
+	}
+	return resp.Body, infoS3(chave, resp), nil
+}
+
+// Info consulta os metadados do objeto com HEAD
+func (s *S3FileStore) Info(chave string) (*InfoArquivo, error) {
+	if err := validarChave(chave); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutS3)
+	defer cancel()
+	resp, err := s.executar(ctx, http.MethodHead, chave, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar objeto: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return infoS3(chave, resp), nil
+	case http.StatusNotFound:
+		return nil, ErrArquivoNaoEncontrado
+	}
+	return nil, erroS3(resp)
+}
+
+// Deletar remove o objeto; o S3 responde sucesso também para objetos inexistentes
+func (s *S3FileStore) Deletar(chave string) error {
+	if err := validarChave(chave); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutS3)
+	defer cancel()
+	return s.deletar(ctx, chave)
+}
+
+func (s *S3FileStore) deletar(ctx context.Context, chave string) error {
+	resp, err := s.executar(ctx, http.MethodDelete, chave, nil, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao remover objeto: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return erroS3(resp)
+	}
+	return nil
+}
+
+// listaObjetos resposta do ListObjectsV2
+type listaObjetos struct {
+	Conteudo []struct {
+		Chave string `xml:"Key"`
+	} `xml:"Contents"`
+	Truncada     bool   `xml:"IsTruncated"`
+	ProximoToken string `xml:"NextContinuationToken"`
+}
+
+// DeletarPrefixo lista os objetos sob o prefixo (ListObjectsV2, em páginas) e remove um a um
+func (s *S3FileStore) DeletarPrefixo(prefixo string) error {
+	if err := validarPrefixo(prefixo); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*timeoutS3)
+	defer cancel()
+
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefixo}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.executar(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return fmt.Errorf("erro ao listar objetos: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := erroS3(resp)
+			resp.Body.Close()
+			return err
+		}
+		var lista listaObjetos
+		err = xml.NewDecoder(resp.Body).Decode(&lista)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("erro ao ler lista de objetos: %w", err)
+		}
+		for _, obj := range lista.Conteudo {
+			if err := s.deletar(ctx, obj.Chave); err != nil {
+				return err
+			}
+		}
+		if !lista.Truncada || lista.ProximoToken == "" {
+			return nil
+		}
+		token = lista.ProximoToken
+	}
+}
+
+// novaRequisicao monta a URL endpoint/bucket[/chave][?query]
+func (s *S3FileStore) novaRequisicao(ctx context.Context, metodo, chave string, query url.Values) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.config.Bucket
+	if chave != "" {
+		u.Path += "/" + chave
+	}
+	u.RawPath = codificarURI(u.Path, false)
+	u.RawQuery = queryCanonica(query)
+	return http.NewRequestWithContext(ctx, metodo, u.String(), nil)
+}
+
+// executar envia uma requisição com corpo em memória (ou vazio), assinando o hash do corpo
+func (s *S3FileStore) executar(ctx context.Context, metodo, chave string, query url.Values, corpo []byte) (*http.Response, error) {
+	req, err := s.novaRequisicao(ctx, metodo, chave, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(corpo) > 0 {
+		req.Body = io.NopCloser(bytes.NewReader(corpo))
+		req.ContentLength = int64(len(corpo))
+	}
+	assinarV4(req, s.config, hashHex(corpo), s.agora())
+	return s.cliente.Do(req)
+}
+
+// infoS3 extrai os metadados dos cabeçalhos da resposta
+func infoS3(chave string, resp *http.Response) *InfoArquivo {
+	info := &InfoArquivo{Chave: chave, Tamanho: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}
+	if tamanho, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Tamanho = tamanho
+	}
+	if modificado, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModificadoEm = modificado
+	}
+	return info
+}
+
+// erroS3 converte a resposta de erro (XML <Error>) em error
+func erroS3(resp *http.Response) error {
+	var corpo struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &corpo) == nil && corpo.Code != "" {
+		return fmt.Errorf("s3: %s %s: status %d: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, corpo.Code, corpo.Message)
+	}
+	return fmt.Errorf("s3: %s %s: status %d", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode)
+}
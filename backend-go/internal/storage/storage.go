@@ -0,0 +1,70 @@
+// Package storage armazena os arquivos enviados pelos motoristas (documentos e
+// fotos de perfil) no disco local ou em um object store compatível com S3.
+package storage
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrArquivoNaoEncontrado = errors.New("arquivo não encontrado no armazenamento")
+	ErrChaveInvalida        = errors.New("chave de armazenamento inválida")
+)
+
+// InfoArquivo metadados de um arquivo armazenado
+type InfoArquivo struct {
+	Chave        string
+	Tamanho      int64
+	ContentType  string
+	ModificadoEm time.Time
+}
+
+// FileStore armazena arquivos identificados por uma chave relativa com segmentos
+// separados por "/" (ex.: "<motorista>/CNH.pdf"). A chave é o que os registros
+// guardam; o local físico depende da implementação.
+type FileStore interface {
+	// Gravar salva o conteúdo na chave, substituindo um arquivo existente.
+	// tamanho negativo indica tamanho desconhecido.
+	Gravar(chave string, conteudo io.Reader, tamanho int64, contentType string) error
+	// Ler retorna o conteúdo completo do arquivo
+	Ler(chave string) ([]byte, error)
+	// Abrir retorna o conteúdo como stream, que deve ser fechado pelo chamador
+	Abrir(chave string) (io.ReadCloser, *InfoArquivo, error)
+	// Info retorna os metadados sem ler o conteúdo
+	Info(chave string) (*InfoArquivo, error)
+	// Deletar remove o arquivo; chave inexistente não é erro
+	Deletar(chave string) error
+	// DeletarPrefixo remove todos os arquivos sob o prefixo, que deve terminar em "/"
+	DeletarPrefixo(prefixo string) error
+}
+
+// validarChave recusa chaves absolutas, com "..", segmentos vazios ou barras invertidas,
+// que poderiam escapar do diretório raiz no armazenamento local
+func validarChave(chave string) error {
+	if chave == "" || strings.ContainsAny(chave, "\\\x00") || path.IsAbs(chave) ||
+		path.Clean(chave) != chave || chave == "." || chave == ".." || strings.HasPrefix(chave, "../") {
+		return ErrChaveInvalida
+	}
+	return nil
+}
+
+// validarPrefixo aceita apenas prefixos de "diretório" (terminados em "/")
+func validarPrefixo(prefixo string) error {
+	if !strings.HasSuffix(prefixo, "/") {
+		return ErrChaveInvalida
+	}
+	return validarChave(strings.TrimSuffix(prefixo, "/"))
+}
+
+// contentTypePorChave deduz o tipo do conteúdo pela extensão da chave
+func contentTypePorChave(chave string) string {
+	if tipo := mime.TypeByExtension(path.Ext(chave)); tipo != "" {
+		return tipo
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidarChave(t *testing.T) {
+	for _, chave := range []string{"m1/CNH.pdf", "m1/profile/foto.jpg", "arquivo"} {
+		assert.NoError(t, validarChave(chave), chave)
+	}
+	for _, chave := range []string{"", "/etc/passwd", "../fora", "m1/../../fora", "m1//CNH.pdf", "m1/./CNH.pdf", "m1\\CNH.pdf", ".", "m1/"} {
+		assert.ErrorIs(t, validarChave(chave), ErrChaveInvalida, chave)
+	}
+	assert.NoError(t, validarPrefixo("m1/"))
+	assert.ErrorIs(t, validarPrefixo("m1"), ErrChaveInvalida)
+	assert.ErrorIs(t, validarPrefixo("../"), ErrChaveInvalida)
+}
+
+// testarFileStore exercita o contrato de FileStore sobre um armazenamento vazio
+func testarFileStore(t *testing.T, store FileStore) {
+	t.Run("Gravar, ler e consultar", func(t *testing.T) {
+		conteudo := []byte("%PDF-1.4 conteúdo do documento")
+		require.NoError(t, store.Gravar("m1/CNH.pdf", bytes.NewReader(conteudo), int64(len(conteudo)), "application/pdf"))
+
+		lido, err := store.Ler("m1/CNH.pdf")
+		require.NoError(t, err)
+		assert.Equal(t, conteudo, lido)
+
+		info, err := store.Info("m1/CNH.pdf")
+		require.NoError(t, err)
+		assert.Equal(t, "m1/CNH.pdf", info.Chave)
+		assert.Equal(t, int64(len(conteudo)), info.Tamanho)
+		assert.Equal(t, "application/pdf", info.ContentType)
+		assert.False(t, info.ModificadoEm.IsZero())
+
+		leitor, info, err := store.Abrir("m1/CNH.pdf")
+		require.NoError(t, err)
+		stream, err := io.ReadAll(leitor)
+		require.NoError(t, leitor.Close())
+		require.NoError(t, err)
+		assert.Equal(t, conteudo, stream)
+		assert.Equal(t, int64(len(conteudo)), info.Tamanho)
+	})
+
+	t.Run("Gravar substitui o conteúdo e aceita tamanho desconhecido", func(t *testing.T) {
+		require.NoError(t, store.Gravar("m1/CNH.pdf", strings.NewReader("nova versão"), -1, ""))
+		lido, err := store.Ler("m1/CNH.pdf")
+		require.NoError(t, err)
+		assert.Equal(t, "nova versão", string(lido))
+	})
+
+	t.Run("Arquivo vazio e chave com espaços e acentos", func(t *testing.T) {
+		require.NoError(t, store.Gravar("m1/vazio.txt", bytes.NewReader(nil), 0, "text/plain"))
+		lido, err := store.Ler("m1/vazio.txt")
+		require.NoError(t, err)
+		assert.Empty(t, lido)
+
+		require.NoError(t, store.Gravar("m1/selfie cnh+ção.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"))
+		lido, err = store.Ler("m1/selfie cnh+ção.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", string(lido))
+	})
+
+	t.Run("Arquivo inexistente", func(t *testing.T) {
+		_, err := store.Ler("m1/CRLV.pdf")
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+		_, _, err = store.Abrir("m1/CRLV.pdf")
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+		_, err = store.Info("m1/CRLV.pdf")
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+		assert.NoError(t, store.Deletar("m1/CRLV.pdf"))
+	})
+
+	t.Run("Chaves inválidas são recusadas", func(t *testing.T) {
+		assert.ErrorIs(t, store.Gravar("../fora.txt", strings.NewReader("x"), 1, ""), ErrChaveInvalida)
+		_, err := store.Ler("/etc/passwd")
+		assert.ErrorIs(t, err, ErrChaveInvalida)
+		assert.ErrorIs(t, store.DeletarPrefixo("m1"), ErrChaveInvalida)
+	})
+
+	t.Run("Deletar e DeletarPrefixo", func(t *testing.T) {
+		require.NoError(t, store.Gravar("m1/profile/foto.jpg", strings.NewReader("foto"), 4, "image/jpeg"))
+		require.NoError(t, store.Gravar("m10/CNH.pdf", strings.NewReader("outro"), 5, "application/pdf"))
+
+		require.NoError(t, store.Deletar("m1/vazio.txt"))
+		_, err := store.Info("m1/vazio.txt")
+		assert.ErrorIs(t, err, ErrArquivoNaoEncontrado)
+
+		require.NoError(t, store.DeletarPrefixo("m1/"))
+		for _, chave := range []string{"m1/CNH.pdf", "m1/profile/foto.jpg", "m1/selfie cnh+ção.jpg"} {
+			_, err := store.Info(chave)
+			assert.ErrorIs(t, err, ErrArquivoNaoEncontrado, chave)
+		}
+		// Prefixo é por "diretório": m10 não pertence a m1
+		_, err = store.Info("m10/CNH.pdf")
+		assert.NoError(t, err)
+		assert.NoError(t, store.DeletarPrefixo("inexistente/"))
+	})
+}
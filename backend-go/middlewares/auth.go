@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"strings"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Chaves em ctx.Locals preenchidas pelo middleware Autenticar
+const (
+	LocalSubject = "auth_subject"
+	LocalPapel   = "auth_papel"
+	LocalSessao  = "auth_sessao"
+)
+
+// Autenticar middleware exige um token de acesso válido no header Authorization.
+func Autenticar(tokens *auth.TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			return apperrors.ErrTokenAusente
+		}
+		claims, err := tokens.ValidarToken(strings.TrimSpace(token), auth.TokenAcesso)
+		if err != nil {
+			return err
+		}
+		c.Locals(LocalSubject, claims.Subject)
+		c.Locals(LocalPapel, models.Papel(claims.Papel))
+		c.Locals(LocalSessao, claims.Sessao)
+		return c.Next()
+	}
+}
+
+// ProprioMotorista middleware garante que o token é de um motorista e corresponde ao parâmetro :id da rota.
+func ProprioMotorista() fiber.Handler {
+	return ProprioMotoristaOu()
+}
+
+// ProprioMotoristaOu libera o próprio motorista do :id ou operadores com um dos papéis informados.
+func ProprioMotoristaOu(papeis ...models.Papel) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ehProprioMotorista(c) || temPapel(c, papeis) {
+			return c.Next()
+		}
+		return apperrors.ErrAcessoNegado
+	}
+}
+
+// ExigirPapel middleware libera apenas principais com um dos papéis informados.
+func ExigirPapel(papeis ...models.Papel) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !temPapel(c, papeis) {
+			return apperrors.ErrAcessoNegado
+		}
+		return c.Next()
+	}
+}
+
+// Subject retorna o subject autenticado na requisição (vazio se não houver).
+func Subject(c *fiber.Ctx) string {
+	s, _ := c.Locals(LocalSubject).(string)
+	return s
+}
+
+// Papel retorna o papel autenticado na requisição (vazio se não houver).
+func Papel(c *fiber.Ctx) models.Papel {
+	p, _ := c.Locals(LocalPapel).(models.Papel)
+	return p
+}
+
+// Sessao retorna a sessão do token autenticado (vazio para tokens sem sessão).
+func Sessao(c *fiber.Ctx) string {
+	s, _ := c.Locals(LocalSessao).(string)
+	return s
+}
+
+func ehProprioMotorista(c *fiber.Ctx) bool {
+	return Papel(c) == models.PapelMotorista && Subject(c) != "" && Subject(c) == c.Params("id")
+}
+
+func temPapel(c *fiber.Ctx, papeis []models.Papel) bool {
+	atual := Papel(c)
+	for _, p := range papeis {
+		if atual == p {
+			return true
+		}
+	}
+	return false
+}
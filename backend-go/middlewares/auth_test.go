@@ -0,0 +1,118 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+)
+
+func TestAutenticar(t *testing.T) {
+	tokens := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste"})
+
+	setup := func() *fiber.App {
+		app := fiber.New()
+		app.Use(ErrorHandler())
+		profile := app.Group("/api/profile", Autenticar(tokens))
+		profile.Get("/:id", ProprioMotorista(), func(c *fiber.Ctx) error {
+			return c.SendString(Subject(c))
+		})
+		profile.Get("/:id/operador", ProprioMotoristaOu(models.PapelSuporte), func(c *fiber.Ctx) error {
+			return c.SendString(Subject(c))
+		})
+		app.Put("/api/documents/:id/approve", Autenticar(tokens), ExigirPapel(models.PapelRevisor), func(c *fiber.Ctx) error {
+			return c.SendString(Subject(c))
+		})
+		return app
+	}
+
+	t.Run("Sem token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/profile/123", nil)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Token do próprio motorista", func(t *testing.T) {
+		par, err := tokens.GerarTokens("123", "motorista")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/profile/123", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Token de outro motorista", func(t *testing.T) {
+		par, err := tokens.GerarTokens("456", "motorista")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/profile/123", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Refresh token não autentica", func(t *testing.T) {
+		par, err := tokens.GerarTokens("123", "motorista")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/profile/123", nil)
+		req.Header.Set("Authorization", "Bearer "+par.RefreshToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Operador com id do motorista não acessa rota exclusiva do motorista", func(t *testing.T) {
+		par, err := tokens.GerarTokens("123", "suporte")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/profile/123", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Operador com papel liberado acessa perfil de motorista", func(t *testing.T) {
+		par, err := tokens.GerarTokens("op-1", "suporte")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/profile/123/operador", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Motorista não aprova documentos", func(t *testing.T) {
+		par, err := tokens.GerarTokens("123", "motorista")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("PUT", "/api/documents/123/approve", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Revisor aprova documentos", func(t *testing.T) {
+		par, err := tokens.GerarTokens("op-1", "revisor")
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("PUT", "/api/documents/123/approve", nil)
+		req.Header.Set("Authorization", "Bearer "+par.AccessToken)
+		resp, err := setup().Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// ChaveArquivoLegada converte o caminho em disco gravado antes do FileStore
+// (data/<motorista>/...) na chave equivalente do armazenamento local, cuja raiz é data/.
+// Valores que já são chaves são devolvidos sem alteração.
+func ChaveArquivoLegada(caminho string) string {
+	caminho = filepath.ToSlash(caminho)
+	for _, prefixo := range []string{"./data/", "data/"} {
+		if chave, ok := strings.CutPrefix(caminho, prefixo); ok {
+			return chave
+		}
+	}
+	return caminho
+}
+
+// UnmarshalJSON aceita documentos gravados no formato antigo, com o caminho em
+// caminho_arquivo em vez da chave de armazenamento
+func (d *Documento) UnmarshalJSON(data []byte) error {
+	type documento Documento
+	aux := struct {
+		*documento
+		CaminhoArquivo string `json:"caminho_arquivo"`
+	}{documento: (*documento)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if d.ChaveArquivo == "" && aux.CaminhoArquivo != "" {
+		d.ChaveArquivo = ChaveArquivoLegada(aux.CaminhoArquivo)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// DoisFatores configuração de autenticação em dois fatores (TOTP) de motoristas e operadores.
+// Segredo e códigos de recuperação nunca são expostos em JSON; o repositório os persiste à parte.
+type DoisFatores struct {
+	Ativo              bool       `json:"ativo"`
+	AtivadoEm          *time.Time `json:"ativado_em,omitempty"`
+	Segredo            string     `json:"-"` // base32; preenchido na inscrição, ativo após a confirmação
+	CodigosRecuperacao []string   `json:"-"` // hashes dos códigos de uso único
+	UltimoPasso        int64      `json:"-"` // último passo TOTP aceito, impede reutilização do código
+}
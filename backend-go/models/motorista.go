@@ -38,42 +38,87 @@ const (
 
 // Motorista representa um motorista no sistema
 type Motorista struct {
-	ID             string          `json:"id" validate:"required"`
-	Nome           string          `json:"nome" validate:"required,min=2,max=100"`
-	DataNascimento time.Time       `json:"data_nascimento" validate:"required"`
-	CPF            string          `json:"cpf" validate:"required"`
-	CNH            string          `json:"cnh" validate:"required,len=11"`
-	CategoriaCNH   CategoriaCNH    `json:"categoria_cnh" validate:"required"`
-	ValidadeCNH    time.Time       `json:"validade_cnh" validate:"required"`
-	PlacaVeiculo   string          `json:"placa_veiculo" validate:"required"`
-	ModeloVeiculo  string          `json:"modelo_veiculo" validate:"required,min=3,max=100"`
-	Telefone       string          `json:"telefone" validate:"required"`
-	Email          string          `json:"email" validate:"required,email"`
-	Senha          string          `json:"senha" validate:"required,min=8"`
-	Status         StatusMotorista `json:"status"`
-	FotoPerfil     string          `json:"foto_perfil"`
-	CriadoEm       time.Time       `json:"criado_em"`
-	AtualizadoEm   time.Time       `json:"atualizado_em"`
-	Documentos     []Documento     `json:"documentos"`
+	ID              string          `json:"id" validate:"required"`
+	Nome            string          `json:"nome" validate:"required,min=2,max=100"`
+	DataNascimento  time.Time       `json:"data_nascimento" validate:"required"`
+	CPF             string          `json:"cpf" validate:"required"`
+	CNH             string          `json:"cnh" validate:"required,len=11"`
+	CategoriaCNH    CategoriaCNH    `json:"categoria_cnh" validate:"required"`
+	ValidadeCNH     time.Time       `json:"validade_cnh" validate:"required"`
+	PlacaVeiculo    string          `json:"placa_veiculo" validate:"required"`
+	ModeloVeiculo   string          `json:"modelo_veiculo" validate:"required,min=3,max=100"`
+	Telefone        string          `json:"telefone" validate:"required"`
+	Email           string          `json:"email" validate:"required,email"`
+	EmailVerificado bool            `json:"email_verificado"`
+	EmailPendente   string          `json:"email_pendente,omitempty"`    // novo email aguardando confirmação
+	Senha           string          `json:"-" validate:"required,min=8"` // hash; persistido apenas pelo repositório
+	Status          StatusMotorista `json:"status"`
+	StatusAnterior  StatusMotorista `json:"status_anterior,omitempty"` // restaurado se a exclusão for cancelada
+	ExclusaoEm      *time.Time      `json:"exclusao_em,omitempty"`     // quando os dados serão apagados definitivamente
+	FotoPerfil      string          `json:"foto_perfil"`               // chave no FileStore
+	CriadoEm        time.Time       `json:"criado_em"`
+	AtualizadoEm    time.Time       `json:"atualizado_em"`
+	Versao          int64           `json:"versao"` // incrementada pelo repositório a cada atualização
+	Documentos      []Documento     `json:"documentos"`
+	Historico       []Documento     `json:"historico_documentos,omitempty"` // versões substituídas; só recebe novas entradas
+	Revisoes        []Revisao       `json:"revisoes,omitempty"`
+	DoisFatores     DoisFatores     `json:"dois_fatores"`
 }
 
 // Documento representa um documento enviado pelo motorista
 type Documento struct {
-	ID             string    `json:"id"`
-	TipoDocumento  string    `json:"tipo_documento"` // CNH, CRLV, selfie_cnh
-	CaminhoArquivo string    `json:"caminho_arquivo"`
-	Formato        string    `json:"formato"`
-	Tamanho        int64     `json:"tamanho"`
-	Status         string    `json:"status"`
-	CriadoEm       time.Time `json:"criado_em"`
+	ID             string     `json:"id"`
+	TipoDocumento  string     `json:"tipo_documento"` // CNH, CRLV, selfie_cnh
+	ChaveArquivo   string     `json:"chave_arquivo"`  // chave no FileStore, ex.: <motorista>/CNH.pdf
+	Formato        string     `json:"formato"`
+	Tamanho        int64      `json:"tamanho"`
+	Checksum       string     `json:"checksum,omitempty"` // SHA-256 (hex) do conteúdo; vazio em documentos anteriores
+	Status         string     `json:"status"`
+	Varredura      string     `json:"varredura,omitempty"`       // verificação antivírus, ver Varredura*; vazio em documentos anteriores
+	Ameaca         string     `json:"ameaca,omitempty"`          // assinatura encontrada pelo antivírus
+	MotivoRejeicao string     `json:"motivo_rejeicao,omitempty"` // código, ver MotivoRejeicaoValido
+	Observacao     string     `json:"observacao,omitempty"`      // nota do revisor para o motorista
+	RevisadoPor    string     `json:"revisado_por,omitempty"`    // operador da última decisão
+	RevisadoEm     *time.Time `json:"revisado_em,omitempty"`
+	Versao         int        `json:"versao,omitempty"`      // 1, 2, ... por tipo; 0 em documentos anteriores ao histórico
+	EnviadoPor     string     `json:"enviado_por,omitempty"` // principal autenticado que fez o upload
+	CriadoEm       time.Time  `json:"criado_em"`
+	SubstituidoEm  *time.Time `json:"substituido_em,omitempty"` // quando a versão foi para o histórico
 }
 
 // Status de documentos
 const (
-	DocumentoStatusPendente = "pendente"
-	DocumentoStatusAprovado = "aprovado"
+	DocumentoStatusPendente  = "pendente"
+	DocumentoStatusAprovado  = "aprovado"
+	DocumentoStatusRejeitado = "rejeitado" // aguardando reenvio pelo motorista
 )
 
+// Estados da verificação antivírus do arquivo de um documento. O documento só pode ser
+// revisado e baixado depois de limpo; o arquivo infectado vai para a quarentena.
+const (
+	VarreduraVerificando = "verificando"
+	VarreduraLimpo       = "limpo"
+	VarreduraInfectado   = "infectado"
+)
+
+// AguardandoVarredura documento ainda sem resultado do antivírus: em verificação ou
+// enviado antes da verificação antivírus (sem estado), que é verificado como os novos
+func (d Documento) AguardandoVarredura() bool {
+	return d.Varredura == VarreduraVerificando || d.Varredura == ""
+}
+
+// ValidarDisponibilidade recusa documentos cujo arquivo ainda não pode ser revisado nem
+// servido: sem resultado do antivírus ou infectado
+func (d Documento) ValidarDisponibilidade() error {
+	if d.AguardandoVarredura() {
+		return apperrors.ErrDocumentoEmVerificacao
+	}
+	if d.Varredura == VarreduraInfectado {
+		return apperrors.ErrDocumentoInfectado
+	}
+	return nil
+}
+
 // ValidarCPF valida o formato e dígitos verificadores do CPF
 func ValidarCPF(cpf string) error {
 	// Exigir somente dígitos
@@ -255,3 +300,16 @@ func ValidarDocumento(formato string, tamanho int64) error {
 
 	return nil
 }
+
+// ValidarFotoPerfil valida formato e tamanho da foto de perfil
+func ValidarFotoPerfil(formato string, tamanho int64) error {
+	switch strings.ToUpper(formato) {
+	case "JPG", "JPEG", "PNG", "WEBP":
+	default:
+		return apperrors.ErrFotoFormatoInvalido
+	}
+	if tamanho > 5*1024*1024 {
+		return apperrors.ErrFotoMuitoGrande
+	}
+	return nil
+}
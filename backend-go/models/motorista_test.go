@@ -1,10 +1,14 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
 )
 
 func TestValidarCPF(t *testing.T) {
@@ -360,3 +364,30 @@ func TestValidarDocumento(t *testing.T) {
 		})
 	}
 }
+
+func TestValidarFotoPerfil(t *testing.T) {
+	tests := []struct {
+		name        string
+		formato     string
+		tamanho     int64
+		expectedErr error
+	}{
+		{"Foto válida - JPEG", "JPEG", 1 * 1024 * 1024, nil},
+		{"Foto válida - WEBP minúsculo", "webp", 1 * 1024 * 1024, nil},
+		{"PDF não é aceito como foto", "PDF", 1 * 1024 * 1024, apperrors.ErrFotoFormatoInvalido},
+		{"Foto muito grande - 6MB", "PNG", 6 * 1024 * 1024, apperrors.ErrFotoMuitoGrande},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedErr, ValidarFotoPerfil(tt.formato, tt.tamanho))
+		})
+	}
+}
+
+func TestMotoristaJSONNaoExpoeSenha(t *testing.T) {
+	data, err := json.Marshal(Motorista{ID: "1", Senha: "$2a$10$hash"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "senha")
+	assert.NotContains(t, string(data), "$2a$10$hash")
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Papel representa o papel do principal autenticado (motorista ou operador)
+type Papel string
+
+const (
+	PapelMotorista Papel = "motorista"
+	PapelRevisor   Papel = "revisor"
+	PapelSuporte   Papel = "suporte"
+	PapelAdmin     Papel = "admin"
+)
+
+// PapelOperadorValido indica se o papel pode ser atribuído a um operador
+func PapelOperadorValido(p Papel) bool {
+	return p == PapelRevisor || p == PapelSuporte || p == PapelAdmin
+}
+
+// Operador representa um operador interno (revisor de documentos, suporte ou admin)
+type Operador struct {
+	ID           string      `json:"id"`
+	Nome         string      `json:"nome"`
+	Email        string      `json:"email"`
+	Senha        string      `json:"-"` // hash; persistido apenas pelo repositório
+	Papel        Papel       `json:"papel"`
+	Ativo        bool        `json:"ativo"`
+	DoisFatores  DoisFatores `json:"dois_fatores"`
+	CriadoEm     time.Time   `json:"criado_em"`
+	AtualizadoEm time.Time   `json:"atualizado_em"`
+}
+
+// Revisao registra uma decisão tomada por um operador sobre o cadastro do motorista
+type Revisao struct {
+	Acao         string    `json:"acao"` // aprovado, rejeitado, documento_aprovado, documento_rejeitado
+	OperadorID   string    `json:"operador_id"`
+	Documento    string    `json:"documento,omitempty"`     // tipo do documento, nas decisões por documento
+	CodigoMotivo string    `json:"codigo_motivo,omitempty"` // motivo de rejeição do documento
+	Motivo       string    `json:"motivo,omitempty"`
+	Data         time.Time `json:"data"`
+}
+
+// Ações de revisão
+const (
+	RevisaoAprovado           = "aprovado"
+	RevisaoRejeitado          = "rejeitado"
+	RevisaoDocumentoAprovado  = "documento_aprovado"
+	RevisaoDocumentoRejeitado = "documento_rejeitado"
+)
@@ -0,0 +1,69 @@
+package models
+
+// Motivos de rejeição de documentos. O código é gravado no documento e na revisão; a
+// descrição é a exibida ao motorista no email de rejeição.
+const (
+	MotivoIlegivel           = "ilegivel"
+	MotivoVencido            = "vencido"
+	MotivoDadosDivergentes   = "dados_divergentes"
+	MotivoDocumentoIncorreto = "documento_incorreto"
+	MotivoSelfieInvalida     = "selfie_invalida"
+	MotivoOutro              = "outro"             // exige observação
+	MotivoArquivoInfectado   = "arquivo_infectado" // atribuído pela verificação antivírus
+)
+
+var descricoesMotivoRejeicao = map[string]string{
+	MotivoIlegivel:           "Documento ilegível ou com baixa qualidade de imagem",
+	MotivoVencido:            "Documento vencido",
+	MotivoDadosDivergentes:   "Dados diferentes dos informados no cadastro",
+	MotivoDocumentoIncorreto: "Arquivo não corresponde ao documento solicitado",
+	MotivoSelfieInvalida:     "Selfie não permite confirmar a identidade com a CNH",
+	MotivoOutro:              "Outro motivo",
+	MotivoArquivoInfectado:   "Arquivo bloqueado pela verificação antivírus; envie um novo arquivo",
+}
+
+// MotivoRejeicaoValido indica se o código de motivo de rejeição é conhecido
+func MotivoRejeicaoValido(codigo string) bool {
+	_, ok := descricoesMotivoRejeicao[codigo]
+	return ok
+}
+
+// DescricaoMotivoRejeicao texto do motivo para o motorista; códigos desconhecidos são devolvidos como estão
+func DescricaoMotivoRejeicao(codigo string) string {
+	if descricao, ok := descricoesMotivoRejeicao[codigo]; ok {
+		return descricao
+	}
+	return codigo
+}
+
+// StatusPorDocumentos deriva o status do cadastro a partir dos documentos: aguardando
+// documentos enquanto faltar algum obrigatório, rejeitado se algum precisar ser
+// reenviado, em análise enquanto houver pendentes e aprovado quando todos foram aprovados
+func StatusPorDocumentos(documentos []Documento, obrigatorios []string) StatusMotorista {
+	for _, tipo := range obrigatorios {
+		encontrado := false
+		for _, doc := range documentos {
+			if doc.TipoDocumento == tipo {
+				encontrado = true
+				break
+			}
+		}
+		if !encontrado {
+			return StatusAguardandoAprovacao
+		}
+	}
+	pendente := false
+	for _, doc := range documentos {
+		switch doc.Status {
+		case DocumentoStatusRejeitado:
+			return StatusRejeitado
+		case DocumentoStatusAprovado:
+		default:
+			pendente = true
+		}
+	}
+	if pendente {
+		return StatusDocumentosAnalise
+	}
+	return StatusAprovado
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"taxi_service/internal/apperrors"
+)
+
+func TestStatusPorDocumentos(t *testing.T) {
+	obrigatorios := []string{"CNH", "CRLV", "selfie_cnh"}
+	documentos := func(status ...string) []Documento {
+		docs := []Documento{}
+		for i, s := range status {
+			docs = append(docs, Documento{TipoDocumento: obrigatorios[i], Status: s})
+		}
+		return docs
+	}
+
+	tests := []struct {
+		name       string
+		documentos []Documento
+		esperado   StatusMotorista
+	}{
+		{"Sem documentos", nil, StatusAguardandoAprovacao},
+		{"Falta um obrigatório, mesmo com rejeitado", documentos(DocumentoStatusRejeitado, DocumentoStatusAprovado), StatusAguardandoAprovacao},
+		{"Todos pendentes", documentos(DocumentoStatusPendente, DocumentoStatusPendente, DocumentoStatusPendente), StatusDocumentosAnalise},
+		{"Parte aprovada", documentos(DocumentoStatusAprovado, DocumentoStatusPendente, DocumentoStatusAprovado), StatusDocumentosAnalise},
+		{"Rejeitado prevalece sobre pendente", documentos(DocumentoStatusAprovado, DocumentoStatusRejeitado, DocumentoStatusPendente), StatusRejeitado},
+		{"Todos aprovados", documentos(DocumentoStatusAprovado, DocumentoStatusAprovado, DocumentoStatusAprovado), StatusAprovado},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.esperado, StatusPorDocumentos(tt.documentos, obrigatorios))
+		})
+	}
+}
+
+func TestMotivoRejeicao(t *testing.T) {
+	assert.True(t, MotivoRejeicaoValido(MotivoIlegivel))
+	assert.False(t, MotivoRejeicaoValido("qualquer"))
+	assert.Equal(t, "Documento vencido", DescricaoMotivoRejeicao(MotivoVencido))
+	assert.Equal(t, "qualquer", DescricaoMotivoRejeicao("qualquer"))
+}
+
+func TestDocumentoValidarDisponibilidade(t *testing.T) {
+	assert.ErrorIs(t, Documento{}.ValidarDisponibilidade(), apperrors.ErrDocumentoEmVerificacao, "documento anterior à verificação antivírus")
+	assert.NoError(t, Documento{Varredura: VarreduraLimpo}.ValidarDisponibilidade())
+	assert.ErrorIs(t, Documento{Varredura: VarreduraVerificando}.ValidarDisponibilidade(), apperrors.ErrDocumentoEmVerificacao)
+	assert.ErrorIs(t, Documento{Varredura: VarreduraInfectado}.ValidarDisponibilidade(), apperrors.ErrDocumentoInfectado)
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Sessao representa um dispositivo logado. Cada sessão corresponde a uma família de
+// refresh tokens: a cada renovação o refresh anterior é substituído pelo novo (RefreshID).
+type Sessao struct {
+	ID           string     `json:"id"`
+	Subject      string     `json:"subject"`
+	Dispositivo  string     `json:"dispositivo"`
+	IP           string     `json:"ip"`
+	Geracao      int        `json:"geracao"`    // geração de tokens do subject na criação da sessão
+	RefreshID    string     `json:"refresh_id"` // jti do último refresh token emitido
+	CriadaEm     time.Time  `json:"criada_em"`
+	UltimoAcesso time.Time  `json:"ultimo_acesso"`
+	ExpiraEm     time.Time  `json:"expira_em"`
+	RevogadaEm   *time.Time `json:"revogada_em,omitempty"`
+}
+
+// Ativa indica se a sessão ainda pode renovar tokens
+func (s *Sessao) Ativa(agora time.Time) bool {
+	return s.RevogadaEm == nil && agora.Before(s.ExpiraEm)
+}
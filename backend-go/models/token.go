@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Finalidades de tokens de uso único
+const (
+	FinalidadeRedefinicaoSenha     = "redefinicao_senha"
+	FinalidadeVerificacaoEmail     = "verificacao_email"
+	FinalidadeExclusaoConta        = "exclusao_conta"
+	FinalidadeCancelamentoExclusao = "cancelamento_exclusao"
+)
+
+// TokenUsoUnico representa um token enviado por email (ex.: link de redefinição de senha).
+// Apenas o hash do token é persistido.
+type TokenUsoUnico struct {
+	ID          string     `json:"id"`
+	Hash        string     `json:"hash"`
+	Finalidade  string     `json:"finalidade"`
+	MotoristaID string     `json:"motorista_id"`
+	Email       string     `json:"email,omitempty"` // endereço confirmado pelo token de verificação
+	ExpiraEm    time.Time  `json:"expira_em"`
+	UsadoEm     *time.Time `json:"usado_em,omitempty"`
+	CriadoEm    time.Time  `json:"criado_em"`
+}
+
+// Valido indica se o token ainda pode ser usado
+func (t *TokenUsoUnico) Valido(agora time.Time) bool {
+	return t.UsadoEm == nil && agora.Before(t.ExpiraEm)
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// escreverArquivoAtomico grava data em caminho sem nunca deixar o arquivo truncado:
+// o conteúdo vai para um temporário no mesmo diretório, é sincronizado em disco e
+// só então renomeado por cima do original. Com snapshots > 0 a versão anterior é
+// preservada como caminho.1, a mais antiga antes dela como caminho.2 e assim por
+// diante, até caminho.<snapshots>.
+func escreverArquivoAtomico(caminho string, data []byte, perm os.FileMode, snapshots int) error {
+	dir := filepath.Dir(caminho)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(caminho)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo temporário: %w", err)
+	}
+	// Em caso de falha o temporário é descartado; após o rename a remoção é inócua
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao escrever arquivo temporário: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao ajustar permissões: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao sincronizar arquivo temporário: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao fechar arquivo temporário: %w", err)
+	}
+
+	if snapshots > 0 {
+		if err := rotacionarSnapshots(caminho, snapshots); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), caminho); err != nil {
+		return fmt.Errorf("erro ao substituir arquivo: %w", err)
+	}
+	return sincronizarDiretorio(dir)
+}
+
+// caminhoSnapshot retorna o caminho do n-ésimo snapshot (1 é o mais recente)
+func caminhoSnapshot(caminho string, n int) string {
+	return fmt.Sprintf("%s.%d", caminho, n)
+}
+
+// rotacionarSnapshots desloca caminho.1..N-1 para caminho.2..N e preserva o arquivo
+// atual como caminho.1. O arquivo atual é ligado (hard link) e não movido, para que
+// continue existindo até o rename do novo conteúdo.
+func rotacionarSnapshots(caminho string, snapshots int) error {
+	if _, err := os.Stat(caminho); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := os.Remove(caminhoSnapshot(caminho, snapshots)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("erro ao remover snapshot antigo: %w", err)
+	}
+	for n := snapshots - 1; n >= 1; n-- {
+		err := os.Rename(caminhoSnapshot(caminho, n), caminhoSnapshot(caminho, n+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("erro ao rotacionar snapshot: %w", err)
+		}
+	}
+	if err := os.Link(caminho, caminhoSnapshot(caminho, 1)); err != nil {
+		// Sistemas de arquivos sem hard link: copiar
+		if err := copiarArquivo(caminho, caminhoSnapshot(caminho, 1)); err != nil {
+			return fmt.Errorf("erro ao criar snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// descartarSnapshots remove caminho.1..N, por exemplo depois de uma regravação
+// que não deve deixar versões anteriores do conteúdo em disco
+func descartarSnapshots(caminho string, snapshots int) error {
+	for n := 1; n <= snapshots; n++ {
+		if err := os.Remove(caminhoSnapshot(caminho, n)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("erro ao remover snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+func copiarArquivo(origem, destino string) error {
+	entrada, err := os.Open(origem)
+	if err != nil {
+		return err
+	}
+	defer entrada.Close()
+	info, err := entrada.Stat()
+	if err != nil {
+		return err
+	}
+	saida, err := os.OpenFile(destino, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(saida, entrada); err != nil {
+		saida.Close()
+		return err
+	}
+	if err := saida.Sync(); err != nil {
+		saida.Close()
+		return err
+	}
+	return saida.Close()
+}
+
+// sincronizarDiretorio garante que o rename sobreviva a uma queda de energia
+func sincronizarDiretorio(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir diretório: %w", err)
+	}
+	defer d.Close()
+	// Alguns sistemas (Windows) não permitem fsync em diretórios; não é um erro de escrita
+	_ = d.Sync()
+	return nil
+}
+
+// recuperarArquivo verifica se o conteúdo de caminho é válido segundo validar.
+// Se estiver corrompido, restaura o snapshot válido mais recente, preservando o
+// arquivo danificado como caminho.corrompido-<timestamp> para análise, e registra
+// um aviso no log. Retorna erro apenas se nenhum snapshot válido existir.
+func recuperarArquivo(caminho string, snapshots int, perm os.FileMode, validar func([]byte) error) error {
+	data, err := os.ReadFile(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	erroOriginal := validar(data)
+	if erroOriginal == nil {
+		return nil
+	}
+
+	for n := 1; n <= snapshots; n++ {
+		snapshot := caminhoSnapshot(caminho, n)
+		conteudo, err := os.ReadFile(snapshot)
+		if err != nil || validar(conteudo) != nil {
+			continue
+		}
+		corrompido := fmt.Sprintf("%s.corrompido-%s", caminho, time.Now().UTC().Format("20060102T150405Z"))
+		if err := os.Rename(caminho, corrompido); err != nil {
+			return fmt.Errorf("erro ao preservar arquivo corrompido: %w", err)
+		}
+		if err := escreverArquivoAtomico(caminho, conteudo, perm, 0); err != nil {
+			return err
+		}
+		log.Printf("AVISO: %s está corrompido (%v); dados restaurados do snapshot %s. "+
+			"Alterações posteriores a esse snapshot foram perdidas; o arquivo danificado foi mantido em %s",
+			caminho, erroOriginal, snapshot, corrompido)
+		return nil
+	}
+	return fmt.Errorf("%s está corrompido e nenhum snapshot válido foi encontrado: %w", caminho, erroOriginal)
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// arquivosMigracoes contém o esquema dos repositórios SQL, um arquivo por versão
+// no formato NNNN_descricao.sql. Migrações já publicadas nunca devem ser alteradas;
+// mudanças de esquema entram como um novo arquivo com a versão seguinte. Quando o SQL
+// difere entre os bancos, a versão tem um arquivo por dialeto, NNNN_descricao.<dialeto>.sql.
+//
+//go:embed migracoes/*.sql
+var arquivosMigracoes embed.FS
+
+// Dialetos de SQL dos repositórios, usados nos nomes das migrações específicas de um banco
+const (
+	DialetoSQLite   = "sqlite"
+	DialetoPostgres = "postgres"
+)
+
+// Migracao representa uma versão do esquema
+type Migracao struct {
+	Versao    int
+	Descricao string
+	SQL       string
+}
+
+// Migracoes retorna as migrações embutidas para o dialeto em ordem de versão: as comuns
+// a todos os bancos e as específicas dele
+func Migracoes(dialeto string) ([]Migracao, error) {
+	arquivos, err := arquivosMigracoes.ReadDir("migracoes")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar migrações: %w", err)
+	}
+	migracoes := make([]Migracao, 0, len(arquivos))
+	for _, arquivo := range arquivos {
+		nome := strings.TrimSuffix(arquivo.Name(), ".sql")
+		if base, dialetoArquivo, ok := strings.Cut(nome, "."); ok {
+			if dialetoArquivo != DialetoSQLite && dialetoArquivo != DialetoPostgres {
+				return nil, fmt.Errorf("dialeto de migração desconhecido: %s", arquivo.Name())
+			}
+			if dialetoArquivo != dialeto {
+				continue
+			}
+			nome = base
+		}
+		numero, descricao, ok := strings.Cut(nome, "_")
+		versao, err := strconv.Atoi(numero)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("nome de migração inválido: %s", arquivo.Name())
+		}
+		conteudo, err := arquivosMigracoes.ReadFile(path.Join("migracoes", arquivo.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migração %s: %w", arquivo.Name(), err)
+		}
+		migracoes = append(migracoes, Migracao{Versao: versao, Descricao: descricao, SQL: string(conteudo)})
+	}
+	sort.Slice(migracoes, func(i, j int) bool { return migracoes[i].Versao < migracoes[j].Versao })
+	for i := 1; i < len(migracoes); i++ {
+		if migracoes[i].Versao == migracoes[i-1].Versao {
+			return nil, fmt.Errorf("versão de migração duplicada: %d", migracoes[i].Versao)
+		}
+	}
+	return migracoes, nil
+}
+
+// ExecutarMigracoes aplica as migrações ainda não registradas em schema_migracoes.
+// Cada versão roda em sua própria transação; uma falha interrompe a execução
+// mantendo as versões anteriores aplicadas. Retorna quantas migrações foram aplicadas.
+func ExecutarMigracoes(ctx context.Context, db *sql.DB, migracoes []Migracao) (int, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migracoes (
+    versao     INTEGER PRIMARY KEY,
+    descricao  TEXT NOT NULL,
+    aplicada_em TIMESTAMP NOT NULL
+)`); err != nil {
+		return 0, fmt.Errorf("erro ao criar tabela de migrações: %w", err)
+	}
+
+	aplicadas, err := versoesAplicadas(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, m := range migracoes {
+		if aplicadas[m.Versao] {
+			continue
+		}
+		if err := aplicarMigracao(ctx, db, m); err != nil {
+			return total, fmt.Errorf("erro na migração %04d_%s: %w", m.Versao, m.Descricao, err)
+		}
+		total++
+	}
+	return total, nil
+}
+
+// migracoesPendentes retorna as migrações ainda não registradas em schema_migracoes,
+// sem aplicá-las
+func migracoesPendentes(ctx context.Context, db *sql.DB, migracoes []Migracao) ([]Migracao, error) {
+	aplicadas, err := versoesAplicadas(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	var pendentes []Migracao
+	for _, m := range migracoes {
+		if !aplicadas[m.Versao] {
+			pendentes = append(pendentes, m)
+		}
+	}
+	return pendentes, nil
+}
+
+func versoesAplicadas(ctx context.Context, db *sql.DB) (map[int]bool, error) {
+	aplicadas := map[int]bool{}
+	rows, err := db.QueryContext(ctx, `SELECT versao FROM schema_migracoes`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar migrações aplicadas: %w", err)
+	}
+	for rows.Next() {
+		var versao int
+		if err := rows.Scan(&versao); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("erro ao consultar migrações aplicadas: %w", err)
+		}
+		aplicadas[versao] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao consultar migrações aplicadas: %w", err)
+	}
+	return aplicadas, nil
+}
+
+func aplicarMigracao(ctx context.Context, db *sql.DB, m Migracao) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migracoes (versao, descricao, aplicada_em) VALUES ($1, $2, $3)`,
+		m.Versao, m.Descricao, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,60 @@
+-- Motoristas e dados associados (documentos, revisões e códigos de recuperação do 2FA)
+CREATE TABLE motoristas (
+    id                        TEXT PRIMARY KEY,
+    nome                      TEXT NOT NULL,
+    data_nascimento           TIMESTAMP NOT NULL,
+    cpf                       TEXT NOT NULL,
+    cnh                       TEXT NOT NULL,
+    categoria_cnh             TEXT NOT NULL,
+    validade_cnh              TIMESTAMP NOT NULL,
+    placa_veiculo             TEXT NOT NULL,
+    modelo_veiculo            TEXT NOT NULL,
+    telefone                  TEXT NOT NULL,
+    email                     TEXT NOT NULL,
+    email_verificado          BOOLEAN NOT NULL DEFAULT FALSE,
+    email_pendente            TEXT NOT NULL DEFAULT '',
+    senha                     TEXT NOT NULL,
+    status                    TEXT NOT NULL,
+    status_anterior           TEXT NOT NULL DEFAULT '',
+    exclusao_em               TIMESTAMP,
+    foto_perfil               TEXT NOT NULL DEFAULT '',
+    criado_em                 TIMESTAMP NOT NULL,
+    atualizado_em             TIMESTAMP NOT NULL,
+    dois_fatores_ativo        BOOLEAN NOT NULL DEFAULT FALSE,
+    dois_fatores_ativado_em   TIMESTAMP,
+    dois_fatores_segredo      TEXT NOT NULL DEFAULT '',
+    dois_fatores_ultimo_passo BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX motoristas_cpf_idx ON motoristas (cpf);
+CREATE UNIQUE INDEX motoristas_cnh_idx ON motoristas (cnh);
+CREATE UNIQUE INDEX motoristas_email_idx ON motoristas (email);
+
+CREATE TABLE documentos (
+    motorista_id    TEXT NOT NULL REFERENCES motoristas (id) ON DELETE CASCADE,
+    posicao         INTEGER NOT NULL,
+    id              TEXT NOT NULL,
+    tipo_documento  TEXT NOT NULL,
+    caminho_arquivo TEXT NOT NULL,
+    formato         TEXT NOT NULL,
+    tamanho         BIGINT NOT NULL,
+    status          TEXT NOT NULL,
+    criado_em       TIMESTAMP NOT NULL,
+    PRIMARY KEY (motorista_id, posicao)
+);
+
+CREATE TABLE revisoes (
+    motorista_id TEXT NOT NULL REFERENCES motoristas (id) ON DELETE CASCADE,
+    posicao      INTEGER NOT NULL,
+    acao         TEXT NOT NULL,
+    operador_id  TEXT NOT NULL,
+    motivo       TEXT NOT NULL DEFAULT '',
+    data         TIMESTAMP NOT NULL,
+    PRIMARY KEY (motorista_id, posicao)
+);
+
+CREATE TABLE codigos_recuperacao_2fa (
+    motorista_id TEXT NOT NULL REFERENCES motoristas (id) ON DELETE CASCADE,
+    hash         TEXT NOT NULL,
+    PRIMARY KEY (motorista_id, hash)
+);
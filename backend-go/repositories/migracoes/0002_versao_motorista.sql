@@ -0,0 +1,2 @@
+-- Controle de concorrência otimista: incrementada a cada atualização do motorista
+ALTER TABLE motoristas ADD COLUMN versao BIGINT NOT NULL DEFAULT 0;
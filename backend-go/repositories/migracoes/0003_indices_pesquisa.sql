@@ -0,0 +1,6 @@
+-- Índices da pesquisa de motoristas (GET /api/admin/motoristas): cada ordenação
+-- termina no id, que desempata e compõe o cursor da paginação
+CREATE INDEX motoristas_criado_em_idx ON motoristas (criado_em, id);
+CREATE INDEX motoristas_status_criado_em_idx ON motoristas (status, criado_em, id);
+CREATE INDEX motoristas_nome_idx ON motoristas (nome, id);
+CREATE INDEX motoristas_placa_idx ON motoristas (placa_veiculo);
@@ -0,0 +1,5 @@
+-- Documentos e fotos passam a guardar a chave no FileStore em vez do caminho em disco;
+-- caminhos antigos (data/<motorista>/...) viram as chaves equivalentes do armazenamento local
+ALTER TABLE documentos RENAME COLUMN caminho_arquivo TO chave_arquivo;
+UPDATE documentos SET chave_arquivo = SUBSTR(chave_arquivo, 6) WHERE chave_arquivo LIKE 'data/%';
+UPDATE motoristas SET foto_perfil = SUBSTR(foto_perfil, 6) WHERE foto_perfil LIKE 'data/%';
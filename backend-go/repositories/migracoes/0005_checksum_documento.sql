@@ -0,0 +1,3 @@
+-- SHA-256 do conteúdo de cada documento, conferido ao servir o arquivo;
+-- documentos anteriores ficam sem checksum
+ALTER TABLE documentos ADD COLUMN checksum TEXT NOT NULL DEFAULT '';
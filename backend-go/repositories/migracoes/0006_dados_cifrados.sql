@@ -0,0 +1,11 @@
+-- Dados sensíveis cifrados pelo repositório quando há chave configurada: cpf, cnh,
+-- telefone, email, email_pendente e dois_fatores_segredo passam a guardar o valor
+-- cifrado, a data de nascimento cifrada fica em data_nascimento_cifrada e as buscas
+-- e restrições de unicidade usam os índices cegos (NULL em registros não cifrados)
+ALTER TABLE motoristas ADD COLUMN data_nascimento_cifrada TEXT NOT NULL DEFAULT '';
+ALTER TABLE motoristas ADD COLUMN cpf_indice TEXT;
+ALTER TABLE motoristas ADD COLUMN cnh_indice TEXT;
+ALTER TABLE motoristas ADD COLUMN email_indice TEXT;
+CREATE UNIQUE INDEX motoristas_cpf_indice_idx ON motoristas (cpf_indice);
+CREATE UNIQUE INDEX motoristas_cnh_indice_idx ON motoristas (cnh_indice);
+CREATE UNIQUE INDEX motoristas_email_indice_idx ON motoristas (email_indice);
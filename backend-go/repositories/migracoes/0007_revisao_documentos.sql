@@ -0,0 +1,8 @@
+-- Revisão individual de documentos: motivo (código), observação do revisor e autoria da
+-- última decisão em cada documento; tipo do documento e motivo nas revisões
+ALTER TABLE documentos ADD COLUMN motivo_rejeicao TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos ADD COLUMN observacao TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos ADD COLUMN revisado_por TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos ADD COLUMN revisado_em TIMESTAMP;
+ALTER TABLE revisoes ADD COLUMN documento TEXT NOT NULL DEFAULT '';
+ALTER TABLE revisoes ADD COLUMN codigo_motivo TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,27 @@
+-- Histórico de documentos: cada upload é uma versão numerada, com quem a enviou. As
+-- versões substituídas, com a decisão de revisão que receberam, vão para
+-- documentos_historico, que só recebe inserções
+ALTER TABLE documentos ADD COLUMN versao INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE documentos ADD COLUMN enviado_por TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos ADD COLUMN substituido_em TIMESTAMP;
+
+CREATE TABLE documentos_historico (
+    motorista_id    TEXT NOT NULL REFERENCES motoristas (id) ON DELETE CASCADE,
+    posicao         INTEGER NOT NULL,
+    id              TEXT NOT NULL,
+    tipo_documento  TEXT NOT NULL,
+    chave_arquivo   TEXT NOT NULL,
+    formato         TEXT NOT NULL,
+    tamanho         BIGINT NOT NULL,
+    checksum        TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL,
+    motivo_rejeicao TEXT NOT NULL DEFAULT '',
+    observacao      TEXT NOT NULL DEFAULT '',
+    revisado_por    TEXT NOT NULL DEFAULT '',
+    revisado_em     TIMESTAMP,
+    versao          INTEGER NOT NULL,
+    enviado_por     TEXT NOT NULL DEFAULT '',
+    criado_em       TIMESTAMP NOT NULL,
+    substituido_em  TIMESTAMP,
+    PRIMARY KEY (motorista_id, posicao)
+);
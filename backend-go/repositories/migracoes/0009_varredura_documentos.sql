@@ -0,0 +1,6 @@
+-- Verificação antivírus: estado da varredura do arquivo (verificando, limpo ou
+-- infectado; vazio nos documentos enviados antes dela) e a ameaça encontrada
+ALTER TABLE documentos ADD COLUMN varredura TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos ADD COLUMN ameaca TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos_historico ADD COLUMN varredura TEXT NOT NULL DEFAULT '';
+ALTER TABLE documentos_historico ADD COLUMN ameaca TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Pesquisa por prefixo de nome e placa (LOWER(nome) LIKE 'ana%'): os índices de 0003
+-- são sobre as colunas sem função e, fora da collation C, não atendem LIKE.
+-- text_pattern_ops compara caractere a caractere e permite a busca por prefixo no índice
+CREATE INDEX motoristas_nome_prefixo_idx ON motoristas (LOWER(nome) text_pattern_ops);
+CREATE INDEX motoristas_placa_prefixo_idx ON motoristas (UPPER(placa_veiculo) text_pattern_ops);
@@ -0,0 +1,4 @@
+-- Pesquisa por prefixo de nome e placa (nome LIKE 'ana%'): o LIKE do SQLite ignora a
+-- caixa das letras ASCII e só usa índices NOCASE sobre a própria coluna
+CREATE INDEX motoristas_nome_prefixo_idx ON motoristas (nome COLLATE NOCASE);
+CREATE INDEX motoristas_placa_prefixo_idx ON motoristas (placa_veiculo COLLATE NOCASE);
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"taxi_service/internal/cripto"
+)
+
+// Caminhos padrão dos armazenamentos em arquivo
+const (
+	CaminhoPadraoJSON   = "./data/motoristas.json"
+	CaminhoPadraoSQLite = "./data/taxi_service.db"
+)
+
+// AbrirMotoristaRepository abre o armazenamento de motoristas pelo tipo: "json" (padrão,
+// arquivo em caminho ou CaminhoPadraoJSON), "sqlite" (banco em caminho, SQLITE_PATH ou
+// CaminhoPadraoSQLite) ou "postgres" (conexão pelas variáveis DB_*; caminho é ignorado).
+// Repositórios SQL devem ser fechados com Close quando não forem mais usados.
+func AbrirMotoristaRepository(tipo, caminho string, cifrador *cripto.Cifrador) (MotoristaRepository, error) {
+	switch tipo {
+	case "", "json":
+		if caminho == "" {
+			caminho = CaminhoPadraoJSON
+		}
+		return NewJSONMotoristaRepository(caminho, cifrador), nil
+	case "sqlite":
+		if caminho == "" {
+			caminho = os.Getenv("SQLITE_PATH")
+		}
+		if caminho == "" {
+			caminho = CaminhoPadraoSQLite
+		}
+		repo, err := NewSQLiteMotoristaRepository(caminho, cifrador)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir banco SQLite: %w", err)
+		}
+		return repo, nil
+	case "postgres":
+		repo, err := NewPostgresMotoristaRepository(PostgresConfigFromEnv(), cifrador)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao conectar ao PostgreSQL: %w", err)
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("armazenamento de motoristas inválido: %q (use json, sqlite ou postgres)", tipo)
+	}
+}
+
+// AbrirMotoristaRepositoryLeitura abre um armazenamento existente apenas para leitura,
+// com os mesmos tipos e caminhos de AbrirMotoristaRepository. É a origem de uma
+// exportação: nada é gravado nela, então o arquivo JSON não é recuperado de snapshots,
+// os dados não são recifrados e os bancos SQL não recebem migrações.
+func AbrirMotoristaRepositoryLeitura(tipo, caminho string, cifrador *cripto.Cifrador) (MotoristaRepository, error) {
+	switch tipo {
+	case "", "json":
+		if caminho == "" {
+			caminho = CaminhoPadraoJSON
+		}
+		return NewJSONMotoristaRepositoryLeitura(caminho, cifrador)
+	case "sqlite":
+		if caminho == "" {
+			caminho = os.Getenv("SQLITE_PATH")
+		}
+		if caminho == "" {
+			caminho = CaminhoPadraoSQLite
+		}
+		repo, err := NewSQLiteMotoristaRepositoryLeitura(caminho, cifrador)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir banco SQLite: %w", err)
+		}
+		return repo, nil
+	case "postgres":
+		repo, err := NewPostgresMotoristaRepositoryLeitura(PostgresConfigFromEnv(), cifrador)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao conectar ao PostgreSQL: %w", err)
+		}
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("armazenamento de motoristas inválido: %q (use json, sqlite ou postgres)", tipo)
+	}
+}
+
+// loteCopia quantos motoristas CopiarMotoristas grava por transação
+const loteCopia = 100
+
+// ErrDestinoNaoVazio o armazenamento de destino de CopiarMotoristas já tem motoristas
+var ErrDestinoNaoVazio = errors.New("o armazenamento de destino já contém motoristas")
+
+// CopiarMotoristas copia todos os motoristas de origem para destino, que precisa estar
+// vazio, em transações de até loteCopia registros. Os dados são decifrados na leitura e
+// cifrados de novo na gravação, então origem e destino podem usar cifradores diferentes.
+// Versao recomeça em 1 no destino. Retorna quantos motoristas foram copiados.
+func CopiarMotoristas(origem, destino MotoristaRepository) (int, error) {
+	pagina, err := destino.Pesquisar(FiltroMotoristas{Limite: 1})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar destino: %w", err)
+	}
+	if len(pagina.Motoristas) > 0 {
+		return 0, ErrDestinoNaoVazio
+	}
+
+	motoristas, err := origem.ListarTodos()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler origem: %w", err)
+	}
+	copiados := 0
+	for inicio := 0; inicio < len(motoristas); inicio += loteCopia {
+		lote := motoristas[inicio:min(inicio+loteCopia, len(motoristas))]
+		err := destino.WithTx(func(tx MotoristaTx) error {
+			for _, m := range lote {
+				copia := *m
+				if err := tx.Criar(&copia); err != nil {
+					return fmt.Errorf("erro ao copiar motorista %s: %w", m.ID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return copiados, err
+		}
+		copiados += len(lote)
+	}
+	return copiados, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopiarMotoristas(t *testing.T) {
+	dir := t.TempDir()
+	origem, err := AbrirMotoristaRepository("json", filepath.Join(dir, "motoristas.json"), novoCifradorTeste(t, "k1", "k1"))
+	require.NoError(t, err)
+	total := loteCopia + 5
+	for i := 0; i < total; i++ {
+		cpf := fmt.Sprintf("%011d", i)
+		require.NoError(t, origem.Criar(motoristaSensivel(fmt.Sprintf("m%03d", i), cpf, "cnh"+cpf, cpf+"@email.com")))
+	}
+
+	repo, err := AbrirMotoristaRepository("sqlite", filepath.Join(dir, "taxi_service.db"), novoCifradorTeste(t, "k2", "k2"))
+	require.NoError(t, err)
+	destino := repo.(*SQLMotoristaRepository)
+	defer destino.Close()
+
+	n, err := CopiarMotoristas(origem, destino)
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+
+	todos, err := destino.ListarTodos()
+	require.NoError(t, err)
+	assert.Len(t, todos, total)
+	m, err := destino.BuscarPorEmail("00000000104@email.com")
+	require.NoError(t, err)
+	assert.Equal(t, "m104", m.ID)
+	assert.Equal(t, "00000000104", m.CPF)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", m.DoisFatores.Segredo)
+
+	t.Run("Destino com motoristas é recusado", func(t *testing.T) {
+		_, err := CopiarMotoristas(origem, destino)
+		assert.ErrorIs(t, err, ErrDestinoNaoVazio)
+	})
+
+	t.Run("Tipo desconhecido", func(t *testing.T) {
+		_, err := AbrirMotoristaRepository("mongo", "", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestAbrirMotoristaRepositoryLeitura(t *testing.T) {
+	t.Run("JSON não é recuperado nem recifrado", func(t *testing.T) {
+		caminho := filepath.Join(t.TempDir(), "motoristas.json")
+		escrita := NewJSONMotoristaRepository(caminho, nil)
+		require.NoError(t, escrita.Criar(motoristaSensivel("m1", "11111111111", "cnh1", "a@email.com")))
+		antes, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+
+		origem, err := AbrirMotoristaRepositoryLeitura("json", caminho, novoCifradorTeste(t, "k1", "k1"))
+		require.NoError(t, err)
+		todos, err := origem.ListarTodos()
+		require.NoError(t, err)
+		require.Len(t, todos, 1)
+		assert.Equal(t, "11111111111", todos[0].CPF)
+		depois, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+		assert.Equal(t, antes, depois)
+
+		// Arquivo corrompido é reportado, sem ser substituído pelo snapshot
+		require.NoError(t, os.WriteFile(caminho, []byte("{corrompido"), 0600))
+		origem, err = AbrirMotoristaRepositoryLeitura("json", caminho, nil)
+		require.NoError(t, err)
+		_, err = origem.ListarTodos()
+		assert.Error(t, err)
+		depois, err = os.ReadFile(caminho)
+		require.NoError(t, err)
+		assert.Equal(t, "{corrompido", string(depois))
+	})
+
+	t.Run("SQLite somente leitura", func(t *testing.T) {
+		caminho := filepath.Join(t.TempDir(), "taxi_service.db")
+		escrita, err := NewSQLiteMotoristaRepository(caminho, nil)
+		require.NoError(t, err)
+		require.NoError(t, escrita.Criar(motoristaSensivel("m1", "11111111111", "cnh1", "a@email.com")))
+		require.NoError(t, escrita.Close())
+
+		repo, err := AbrirMotoristaRepositoryLeitura("sqlite", caminho, nil)
+		require.NoError(t, err)
+		origem := repo.(*SQLMotoristaRepository)
+		defer origem.Close()
+		todos, err := origem.ListarTodos()
+		require.NoError(t, err)
+		assert.Len(t, todos, 1)
+		assert.Error(t, origem.Criar(motoristaSensivel("m2", "22222222222", "cnh2", "b@email.com")))
+	})
+
+	t.Run("SQLite com migrações pendentes é recusado", func(t *testing.T) {
+		caminho := filepath.Join(t.TempDir(), "legado.db")
+		db, err := sql.Open("sqlite", "file:"+caminho)
+		require.NoError(t, err)
+		migracoes, err := Migracoes(DialetoSQLite)
+		require.NoError(t, err)
+		_, err = ExecutarMigracoes(context.Background(), db, migracoes[:3])
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		_, err = AbrirMotoristaRepositoryLeitura("sqlite", caminho, nil)
+		assert.ErrorContains(t, err, "pendente")
+	})
+
+	t.Run("Origem inexistente", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := AbrirMotoristaRepositoryLeitura("json", filepath.Join(dir, "motoristas.json"), nil)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = AbrirMotoristaRepositoryLeitura("sqlite", filepath.Join(dir, "taxi_service.db"), nil)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoFileExists(t, filepath.Join(dir, "taxi_service.db"))
+	})
+}
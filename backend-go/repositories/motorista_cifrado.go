@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"taxi_service/internal/cripto"
+	"taxi_service/models"
+)
+
+// Domínios dos índices cegos: os mesmos nomes são usados como contexto da cifragem
+const (
+	dominioCPF   = "cpf"
+	dominioCNH   = "cnh"
+	dominioEmail = "email"
+)
+
+// dadosSensiveis campos de models.Motorista persistidos cifrados pelos repositórios,
+// junto com os índices cegos que permitem BuscarPorCPF, BuscarPorCNH e BuscarPorEmail
+// (e a verificação de unicidade) sem decifrar os registros. Sem cifrador os valores
+// ficam em texto puro e os índices vazios.
+type dadosSensiveis struct {
+	DataNascimento     string
+	CPF                string
+	CNH                string
+	Telefone           string
+	Email              string
+	EmailPendente      string
+	SegredoDoisFatores string
+	IndiceCPF          string
+	IndiceCNH          string
+	IndiceEmail        string
+}
+
+// campoSensivel liga o nome do campo (contexto da cifragem) ao valor e, se houver, ao índice
+type campoSensivel struct {
+	nome   string
+	valor  *string
+	indice *string
+}
+
+func (d *dadosSensiveis) campos() []campoSensivel {
+	return []campoSensivel{
+		{"data_nascimento", &d.DataNascimento, nil},
+		{dominioCPF, &d.CPF, &d.IndiceCPF},
+		{dominioCNH, &d.CNH, &d.IndiceCNH},
+		{"telefone", &d.Telefone, nil},
+		{dominioEmail, &d.Email, &d.IndiceEmail},
+		{"email_pendente", &d.EmailPendente, nil},
+		{"dois_fatores_segredo", &d.SegredoDoisFatores, nil},
+	}
+}
+
+// contextoCifragem vincula o valor cifrado ao campo e ao motorista
+func contextoCifragem(campo, motoristaID string) string {
+	return campo + ":" + motoristaID
+}
+
+// cifrarDados extrai e cifra os dados sensíveis do motorista, calculando os índices
+func cifrarDados(c *cripto.Cifrador, m *models.Motorista) (dadosSensiveis, error) {
+	d := dadosSensiveis{
+		DataNascimento:     m.DataNascimento.Format(time.RFC3339Nano),
+		CPF:                m.CPF,
+		CNH:                m.CNH,
+		Telefone:           m.Telefone,
+		Email:              m.Email,
+		EmailPendente:      m.EmailPendente,
+		SegredoDoisFatores: m.DoisFatores.Segredo,
+	}
+	for _, campo := range d.campos() {
+		if campo.indice != nil {
+			*campo.indice = c.Indice(campo.nome, *campo.valor)
+		}
+		cifrado, err := c.Cifrar(*campo.valor, contextoCifragem(campo.nome, m.ID))
+		if err != nil {
+			return dadosSensiveis{}, fmt.Errorf("erro ao cifrar %s: %w", campo.nome, err)
+		}
+		*campo.valor = cifrado
+	}
+	return d, nil
+}
+
+// decifrar devolve os dados ao modelo; valores ainda em texto puro são aceitos como estão
+func (d dadosSensiveis) decifrar(c *cripto.Cifrador, m *models.Motorista) error {
+	for _, campo := range d.campos() {
+		texto, err := c.Decifrar(*campo.valor, contextoCifragem(campo.nome, m.ID))
+		if err != nil {
+			return fmt.Errorf("erro ao decifrar %s do motorista %s: %w", campo.nome, m.ID, err)
+		}
+		*campo.valor = texto
+	}
+	m.DataNascimento = time.Time{}
+	if d.DataNascimento != "" {
+		data, err := time.Parse(time.RFC3339Nano, d.DataNascimento)
+		if err != nil {
+			return fmt.Errorf("data de nascimento inválida do motorista %s: %w", m.ID, err)
+		}
+		m.DataNascimento = data
+	}
+	m.CPF = d.CPF
+	m.CNH = d.CNH
+	m.Telefone = d.Telefone
+	m.Email = d.Email
+	m.EmailPendente = d.EmailPendente
+	m.DoisFatores.Segredo = d.SegredoDoisFatores
+	return nil
+}
+
+// recifrar cifra os valores ainda em texto puro (calculando os índices que faltam)
+// e traz os demais para a chave mestra ativa. Retorna se algo mudou.
+func (d *dadosSensiveis) recifrar(c *cripto.Cifrador, motoristaID string) (bool, error) {
+	if !c.Ativo() {
+		return false, nil
+	}
+	alterado := false
+	for _, campo := range d.campos() {
+		contexto := contextoCifragem(campo.nome, motoristaID)
+		if campo.indice != nil && *campo.indice == "" {
+			texto, err := c.Decifrar(*campo.valor, contexto)
+			if err != nil {
+				return false, fmt.Errorf("erro ao decifrar %s do motorista %s: %w", campo.nome, motoristaID, err)
+			}
+			*campo.indice = c.Indice(campo.nome, texto)
+			alterado = true
+		}
+		valor, mudou, err := c.Recifrar(*campo.valor, contexto)
+		if err != nil {
+			return false, fmt.Errorf("erro ao recifrar %s do motorista %s: %w", campo.nome, motoristaID, err)
+		}
+		*campo.valor = valor
+		alterado = alterado || mudou
+	}
+	return alterado, nil
+}
+
+// chaveBusca valor comparado com o armazenado em buscas e na verificação de
+// unicidade: o índice cego ou, sem criptografia, o próprio valor
+func chaveBusca(c *cripto.Cifrador, dominio, valor string) string {
+	if !c.Ativo() {
+		return valor
+	}
+	return c.Indice(dominio, valor)
+}
+
+// chave devolve o que chaveBusca compara para o campo do domínio informado
+func (d *dadosSensiveis) chave(dominio string) string {
+	for _, campo := range d.campos() {
+		if campo.nome == dominio && campo.indice != nil {
+			if *campo.indice != "" {
+				return *campo.indice
+			}
+			return *campo.valor
+		}
+	}
+	return ""
+}
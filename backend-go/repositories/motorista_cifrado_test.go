@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/cripto"
+	"taxi_service/models"
+)
+
+// novoCifradorTeste cifrador com as chaves mestras informadas (derivadas do próprio
+// identificador) e sempre a mesma chave de índices, como numa rotação real
+func novoCifradorTeste(t *testing.T, ativa string, ids ...string) *cripto.Cifrador {
+	t.Helper()
+	config := cripto.Config{Chaves: map[string][]byte{}, Ativa: ativa, ChaveIndice: bytes.Repeat([]byte{0xAA}, 32)}
+	for _, id := range ids {
+		config.Chaves[id] = bytes.Repeat([]byte(id[len(id)-1:]), 32)
+	}
+	c, err := cripto.NewCifrador(config)
+	require.NoError(t, err)
+	return c
+}
+
+func motoristaSensivel(id, cpf, cnh, email string) *models.Motorista {
+	return &models.Motorista{
+		ID:             id,
+		Nome:           "Maria Souza",
+		DataNascimento: time.Date(1985, 7, 2, 0, 0, 0, 0, time.UTC),
+		CPF:            cpf,
+		CNH:            cnh,
+		CategoriaCNH:   models.CategoriaB,
+		ValidadeCNH:    time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC),
+		PlacaVeiculo:   "XYZ9876",
+		ModeloVeiculo:  "Toyota Corolla",
+		Telefone:       "21988887777",
+		Email:          email,
+		EmailPendente:  "novo." + email,
+		Senha:          "hash-da-senha",
+		Status:         models.StatusAguardandoAprovacao,
+		DoisFatores:    models.DoisFatores{Segredo: "JBSWY3DPEHPK3PXP"},
+		CriadoEm:       time.Now().UTC(),
+		AtualizadoEm:   time.Now().UTC(),
+	}
+}
+
+// testarDadosCifrados verifica que nada sensível chega em claro ao armazenamento
+// (bruto devolve o conteúdo gravado) e que as buscas continuam funcionando
+func testarDadosCifrados(t *testing.T, repo MotoristaRepository, bruto func() string) {
+	require.NoError(t, repo.Criar(motoristaSensivel("c1", "52998224725", "04512345678", "maria@email.com")))
+
+	conteudo := bruto()
+	for _, valor := range []string{"52998224725", "04512345678", "maria@email.com", "21988887777", "JBSWY3DPEHPK3PXP", "1985-07-02"} {
+		assert.NotContains(t, conteudo, valor)
+	}
+	assert.Contains(t, conteudo, "Maria Souza", "campos não sensíveis continuam legíveis")
+
+	buscas := map[string]func() (*models.Motorista, error){
+		"cpf":   func() (*models.Motorista, error) { return repo.BuscarPorCPF("52998224725") },
+		"cnh":   func() (*models.Motorista, error) { return repo.BuscarPorCNH("04512345678") },
+		"email": func() (*models.Motorista, error) { return repo.BuscarPorEmail("maria@email.com") },
+	}
+	for nome, buscar := range buscas {
+		m, err := buscar()
+		require.NoError(t, err, nome)
+		assert.Equal(t, "c1", m.ID, nome)
+		assert.Equal(t, "52998224725", m.CPF)
+		assert.Equal(t, "21988887777", m.Telefone)
+		assert.Equal(t, "novo.maria@email.com", m.EmailPendente)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", m.DoisFatores.Segredo)
+		assert.True(t, m.DataNascimento.Equal(time.Date(1985, 7, 2, 0, 0, 0, 0, time.UTC)))
+	}
+
+	_, err := repo.BuscarPorCPF("52998224700")
+	assert.ErrorIs(t, err, ErrMotoristaNaoEncontrado)
+	assert.ErrorIs(t, repo.Criar(motoristaSensivel("c2", "52998224725", "1", "outra@email.com")), ErrCPFDuplicado)
+	assert.ErrorIs(t, repo.Criar(motoristaSensivel("c2", "2", "04512345678", "outra@email.com")), ErrCNHDuplicada)
+	assert.ErrorIs(t, repo.Criar(motoristaSensivel("c2", "2", "1", "maria@email.com")), ErrEmailDuplicado)
+}
+
+func TestJSONMotoristaRepositoryCifrado(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "motoristas.json")
+	bruto := func() string {
+		data, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("Dados sensíveis cifrados e buscas pelos índices cegos", func(t *testing.T) {
+		repo := &JSONMotoristaRepository{filePath: caminho, snapshots: 2, cifrador: novoCifradorTeste(t, "k1", "k1")}
+		testarDadosCifrados(t, repo, bruto)
+	})
+
+	t.Run("Registros em texto puro são cifrados e os snapshots descartados", func(t *testing.T) {
+		caminho := filepath.Join(t.TempDir(), "motoristas.json")
+		semChave := &JSONMotoristaRepository{filePath: caminho, snapshots: 2}
+		require.NoError(t, semChave.Criar(motoristaSensivel("p1", "52998224725", "04512345678", "maria@email.com")))
+		require.NoError(t, semChave.Criar(motoristaSensivel("p2", "11144477735", "04598765432", "joana@email.com")))
+		require.FileExists(t, caminhoSnapshot(caminho, 1))
+
+		repo := &JSONMotoristaRepository{filePath: caminho, snapshots: 2, cifrador: novoCifradorTeste(t, "k1", "k1")}
+		n, err := repo.RecifrarDados()
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		data, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "52998224725")
+		assert.NoFileExists(t, caminhoSnapshot(caminho, 1))
+
+		m, err := repo.BuscarPorCPF("11144477735")
+		require.NoError(t, err)
+		assert.Equal(t, "p2", m.ID)
+
+		n, err = repo.RecifrarDados()
+		require.NoError(t, err)
+		assert.Zero(t, n, "nada pendente")
+	})
+
+	t.Run("Rotação da chave mestra", func(t *testing.T) {
+		transicao := &JSONMotoristaRepository{filePath: caminho, cifrador: novoCifradorTeste(t, "k2", "k1", "k2")}
+		n, err := transicao.RecifrarDados()
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.NotContains(t, bruto(), "enc:v1:k1:")
+
+		// A chave antiga pode ser retirada
+		repo := &JSONMotoristaRepository{filePath: caminho, cifrador: novoCifradorTeste(t, "k2", "k2")}
+		m, err := repo.BuscarPorEmail("maria@email.com")
+		require.NoError(t, err)
+		assert.Equal(t, "52998224725", m.CPF)
+	})
+
+	t.Run("Sem a chave os dados cifrados não são lidos", func(t *testing.T) {
+		repo := &JSONMotoristaRepository{filePath: caminho}
+		_, err := repo.BuscarPorID("c1")
+		assert.ErrorIs(t, err, cripto.ErrSemChave)
+	})
+}
+
+func TestSQLiteMotoristaRepositoryCifrado(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "taxi_service.db")
+	repo, err := NewSQLiteMotoristaRepository(caminho, novoCifradorTeste(t, "k1", "k1"))
+	require.NoError(t, err)
+	defer repo.Close()
+
+	bruto := func() string {
+		rows, err := repo.db.Query(`SELECT nome, data_nascimento, data_nascimento_cifrada, cpf, cnh, telefone, email,
+			email_pendente, dois_fatores_segredo FROM motoristas`)
+		require.NoError(t, err)
+		defer rows.Close()
+		var linhas []string
+		for rows.Next() {
+			var colunas [9]string
+			var nascimento time.Time
+			require.NoError(t, rows.Scan(&colunas[0], &nascimento, &colunas[2], &colunas[3], &colunas[4], &colunas[5], &colunas[6], &colunas[7], &colunas[8]))
+			colunas[1] = nascimento.Format(time.DateOnly)
+			linhas = append(linhas, strings.Join(colunas[:], "|"))
+		}
+		require.NoError(t, rows.Err())
+		return strings.Join(linhas, "\n")
+	}
+	testarDadosCifrados(t, repo, bruto)
+
+	t.Run("Contrato completo do repositório com criptografia", func(t *testing.T) {
+		cifrado, err := NewSQLiteMotoristaRepository(filepath.Join(t.TempDir(), "cifrado.db"), novoCifradorTeste(t, "k1", "k1"))
+		require.NoError(t, err)
+		defer cifrado.Close()
+		testarSQLMotoristaRepository(t, cifrado)
+	})
+
+	t.Run("Registros em texto puro são cifrados ao abrir o banco com chave", func(t *testing.T) {
+		caminho := filepath.Join(t.TempDir(), "legado.db")
+		semChave, err := NewSQLiteMotoristaRepository(caminho, nil)
+		require.NoError(t, err)
+		require.NoError(t, semChave.Criar(motoristaSensivel("p1", "11144477735", "04598765432", "joana@email.com")))
+		m, err := semChave.BuscarPorCPF("11144477735")
+		require.NoError(t, err)
+		require.NoError(t, semChave.Close())
+
+		repo, err := NewSQLiteMotoristaRepository(caminho, novoCifradorTeste(t, "k1", "k1"))
+		require.NoError(t, err)
+		defer repo.Close()
+		var cpf string
+		require.NoError(t, repo.db.QueryRow(`SELECT cpf FROM motoristas WHERE id = 'p1'`).Scan(&cpf))
+		assert.True(t, cripto.Cifrado(cpf))
+
+		recifrado, err := repo.BuscarPorCPF("11144477735")
+		require.NoError(t, err)
+		assert.Equal(t, m.Versao, recifrado.Versao, "a recifragem não altera a versão")
+		assert.True(t, recifrado.DataNascimento.Equal(m.DataNascimento))
+	})
+
+	t.Run("Rotação da chave mestra ao reabrir", func(t *testing.T) {
+		require.NoError(t, repo.Close())
+		transicao, err := NewSQLiteMotoristaRepository(caminho, novoCifradorTeste(t, "k2", "k1", "k2"))
+		require.NoError(t, err)
+		require.NoError(t, transicao.Close())
+
+		reaberto, err := NewSQLiteMotoristaRepository(caminho, novoCifradorTeste(t, "k2", "k2"))
+		require.NoError(t, err)
+		defer reaberto.Close()
+		m, err := reaberto.BuscarPorCNH("04512345678")
+		require.NoError(t, err)
+		assert.Equal(t, "maria@email.com", m.Email)
+	})
+}
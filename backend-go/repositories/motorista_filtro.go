@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/models"
+)
+
+// OrdemMotoristas campo e direção da ordenação em Pesquisar; o ID desempata registros iguais
+type OrdemMotoristas string
+
+const (
+	OrdemCriadoEmAsc  OrdemMotoristas = "criado_em"
+	OrdemCriadoEmDesc OrdemMotoristas = "-criado_em"
+	OrdemNomeAsc      OrdemMotoristas = "nome"
+	OrdemNomeDesc     OrdemMotoristas = "-nome"
+)
+
+// Tamanho das páginas de Pesquisar
+const (
+	LimitePadraoPagina = 50
+	LimiteMaximoPagina = 200
+)
+
+// FiltroMotoristas critérios de Pesquisar. Campos vazios não restringem o resultado.
+type FiltroMotoristas struct {
+	Status       []models.StatusMotorista // qualquer um dos status informados
+	CriadoDe     *time.Time               // criado_em >= CriadoDe
+	CriadoAte    *time.Time               // criado_em < CriadoAte
+	CategoriaCNH models.CategoriaCNH
+	PrefixoPlaca string // comparado sem diferenciar maiúsculas
+	PrefixoNome  string // comparado sem diferenciar maiúsculas
+	Ordem        OrdemMotoristas
+	Cursor       string // ProximoCursor da página anterior
+	Limite       int    // padrão LimitePadraoPagina, no máximo LimiteMaximoPagina
+}
+
+// PaginaMotoristas resultado de Pesquisar; ProximoCursor vazio indica a última página
+type PaginaMotoristas struct {
+	Motoristas    []*models.Motorista
+	ProximoCursor string
+}
+
+// cursorMotoristas posição após o último registro de uma página (paginação por chave)
+type cursorMotoristas struct {
+	Ordem OrdemMotoristas `json:"o"`
+	Valor string          `json:"v"`
+	ID    string          `json:"id"`
+}
+
+// normalizar valida a ordenação e o limite e decodifica o cursor
+func (f *FiltroMotoristas) normalizar() (*cursorMotoristas, error) {
+	switch f.Ordem {
+	case "":
+		f.Ordem = OrdemCriadoEmAsc
+	case OrdemCriadoEmAsc, OrdemCriadoEmDesc, OrdemNomeAsc, OrdemNomeDesc:
+	default:
+		return nil, apperrors.ErrFiltroInvalido
+	}
+	switch {
+	case f.Limite <= 0:
+		f.Limite = LimitePadraoPagina
+	case f.Limite > LimiteMaximoPagina:
+		f.Limite = LimiteMaximoPagina
+	}
+	f.PrefixoPlaca = strings.ToUpper(strings.TrimSpace(f.PrefixoPlaca))
+	f.PrefixoNome = strings.ToLower(strings.TrimSpace(f.PrefixoNome))
+	if f.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return nil, apperrors.ErrCursorInvalido
+	}
+	var c cursorMotoristas
+	// O cursor só vale para a ordenação em que foi gerado
+	if err := json.Unmarshal(data, &c); err != nil || c.Ordem != f.Ordem || c.ID == "" {
+		return nil, apperrors.ErrCursorInvalido
+	}
+	if f.campoOrdem() == "criado_em" {
+		if _, err := time.Parse(time.RFC3339Nano, c.Valor); err != nil {
+			return nil, apperrors.ErrCursorInvalido
+		}
+	}
+	return &c, nil
+}
+
+// campoOrdem coluna usada na ordenação
+func (f *FiltroMotoristas) campoOrdem() string {
+	return strings.TrimPrefix(string(f.Ordem), "-")
+}
+
+func (f *FiltroMotoristas) decrescente() bool {
+	return strings.HasPrefix(string(f.Ordem), "-")
+}
+
+// valorOrdem valor do motorista no campo de ordenação, no formato gravado no cursor
+func (f *FiltroMotoristas) valorOrdem(m *models.Motorista) string {
+	if f.campoOrdem() == "nome" {
+		return m.Nome
+	}
+	return m.CriadoEm.UTC().Format(time.RFC3339Nano)
+}
+
+// pagina monta o resultado a partir de até Limite+1 registros já ordenados:
+// o registro excedente apenas indica que há uma próxima página
+func (f *FiltroMotoristas) pagina(motoristas []*models.Motorista) *PaginaMotoristas {
+	p := &PaginaMotoristas{Motoristas: motoristas}
+	if len(motoristas) > f.Limite {
+		p.Motoristas = motoristas[:f.Limite]
+		ultimo := p.Motoristas[f.Limite-1]
+		data, _ := json.Marshal(cursorMotoristas{Ordem: f.Ordem, Valor: f.valorOrdem(ultimo), ID: ultimo.ID})
+		p.ProximoCursor = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return p
+}
+
+// pesquisarEmMemoria aplica o filtro sobre a lista completa (repositório JSON)
+func pesquisarEmMemoria(motoristas []*models.Motorista, filtro FiltroMotoristas) (*PaginaMotoristas, error) {
+	cursor, err := filtro.normalizar()
+	if err != nil {
+		return nil, err
+	}
+
+	status := map[models.StatusMotorista]bool{}
+	for _, s := range filtro.Status {
+		status[s] = true
+	}
+	selecionados := make([]*models.Motorista, 0, len(motoristas))
+	for _, m := range motoristas {
+		switch {
+		case len(status) > 0 && !status[m.Status],
+			filtro.CriadoDe != nil && m.CriadoEm.Before(*filtro.CriadoDe),
+			filtro.CriadoAte != nil && !m.CriadoEm.Before(*filtro.CriadoAte),
+			filtro.CategoriaCNH != "" && m.CategoriaCNH != filtro.CategoriaCNH,
+			!strings.HasPrefix(strings.ToUpper(m.PlacaVeiculo), filtro.PrefixoPlaca),
+			!strings.HasPrefix(strings.ToLower(m.Nome), filtro.PrefixoNome):
+			continue
+		}
+		selecionados = append(selecionados, m)
+	}
+
+	// comparar retorna <0, 0 ou >0 conforme a posição de a em relação a b na ordenação
+	comparar := func(a, b *models.Motorista) int {
+		var c int
+		if filtro.campoOrdem() == "nome" {
+			c = strings.Compare(a.Nome, b.Nome)
+		} else {
+			c = a.CriadoEm.Compare(b.CriadoEm)
+		}
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if filtro.decrescente() {
+			c = -c
+		}
+		return c
+	}
+	sort.Slice(selecionados, func(i, j int) bool { return comparar(selecionados[i], selecionados[j]) < 0 })
+
+	inicio := 0
+	if cursor != nil {
+		// Registro de referência com os valores do cursor; a página começa logo depois dele
+		ref := &models.Motorista{ID: cursor.ID, Nome: cursor.Valor}
+		ref.CriadoEm, _ = time.Parse(time.RFC3339Nano, cursor.Valor)
+		inicio = sort.Search(len(selecionados), func(i int) bool { return comparar(selecionados[i], ref) > 0 })
+	}
+	fim := min(inicio+filtro.Limite+1, len(selecionados))
+	return filtro.pagina(selecionados[inicio:fim]), nil
+}
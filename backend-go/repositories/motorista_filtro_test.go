@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/models"
+)
+
+func TestJSONMotoristaRepositoryPesquisa(t *testing.T) {
+	repo := &JSONMotoristaRepository{filePath: filepath.Join(t.TempDir(), "motoristas.json")}
+	testarPesquisaMotoristas(t, repo)
+}
+
+func TestSQLiteMotoristaRepositoryPesquisa(t *testing.T) {
+	repo, err := NewSQLiteMotoristaRepository(filepath.Join(t.TempDir(), "taxi_service.db"), nil)
+	require.NoError(t, err)
+	defer repo.Close()
+	testarPesquisaMotoristas(t, repo)
+}
+
+// testarPesquisaMotoristas exercita Pesquisar sobre um repositório vazio
+func testarPesquisaMotoristas(t *testing.T, repo MotoristaRepository) {
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	dados := []struct {
+		id, nome, placa string
+		categoria       models.CategoriaCNH
+		status          models.StatusMotorista
+		dias            int
+	}{
+		{"m1", "Ana Souza", "ABC1234", models.CategoriaB, models.StatusAguardandoAprovacao, 0},
+		{"m2", "Bruno Lima", "abd5678", models.CategoriaD, models.StatusAprovado, 1},
+		{"m3", "Ana Souza", "XYZ9999", models.CategoriaB, models.StatusRejeitado, 2},
+		{"m4", "Carla Dias", "ABC0001", models.CategoriaE, models.StatusAprovado, 3},
+		{"m5", "anaí Castro", "QWE1111", models.CategoriaB, models.StatusAguardandoAprovacao, 4},
+		{"m6", "Diego_Melo", "A%C2222", models.CategoriaD, models.StatusAprovado, 5},
+	}
+	for i, d := range dados {
+		require.NoError(t, repo.Criar(&models.Motorista{
+			ID:           d.id,
+			Nome:         d.nome,
+			CPF:          "cpf-" + d.id,
+			CNH:          "cnh-" + d.id,
+			Email:        d.id + "@email.com",
+			CategoriaCNH: d.categoria,
+			PlacaVeiculo: d.placa,
+			Status:       d.status,
+			CriadoEm:     base.AddDate(0, 0, d.dias).Add(time.Duration(i) * time.Millisecond),
+			Documentos:   []models.Documento{{ID: "doc-" + d.id, TipoDocumento: "CNH", CriadoEm: base}},
+		}))
+	}
+
+	ids := func(p *PaginaMotoristas) []string {
+		lista := []string{}
+		for _, m := range p.Motoristas {
+			lista = append(lista, m.ID)
+		}
+		return lista
+	}
+	// todas percorre as páginas seguindo o cursor até o fim
+	todas := func(filtro FiltroMotoristas) []string {
+		lista := []string{}
+		for {
+			p, err := repo.Pesquisar(filtro)
+			require.NoError(t, err)
+			lista = append(lista, ids(p)...)
+			if p.ProximoCursor == "" {
+				return lista
+			}
+			require.Len(t, p.Motoristas, filtro.Limite)
+			filtro.Cursor = p.ProximoCursor
+		}
+	}
+
+	t.Run("Sem filtro ordena por criado_em e pagina pelo cursor", func(t *testing.T) {
+		p, err := repo.Pesquisar(FiltroMotoristas{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5", "m6"}, ids(p))
+		assert.Empty(t, p.ProximoCursor)
+		assert.Len(t, p.Motoristas[0].Documentos, 1)
+
+		assert.Equal(t, []string{"m1", "m2", "m3", "m4", "m5", "m6"}, todas(FiltroMotoristas{Limite: 4}))
+		assert.Equal(t, []string{"m6", "m5", "m4", "m3", "m2", "m1"}, todas(FiltroMotoristas{Ordem: OrdemCriadoEmDesc, Limite: 2}))
+	})
+
+	t.Run("Ordenação por nome desempata pelo ID entre páginas", func(t *testing.T) {
+		assert.Equal(t, []string{"m1", "m3", "m2", "m4", "m6", "m5"}, todas(FiltroMotoristas{Ordem: OrdemNomeAsc, Limite: 1}))
+		assert.Equal(t, []string{"m5", "m6", "m4", "m2", "m3", "m1"}, todas(FiltroMotoristas{Ordem: OrdemNomeDesc, Limite: 2}))
+	})
+
+	t.Run("Filtros combinados", func(t *testing.T) {
+		de, ate := base.AddDate(0, 0, 1), base.AddDate(0, 0, 4)
+		casos := []struct {
+			nome     string
+			filtro   FiltroMotoristas
+			esperado []string
+		}{
+			{"status", FiltroMotoristas{Status: []models.StatusMotorista{models.StatusAprovado, models.StatusRejeitado}}, []string{"m2", "m3", "m4", "m6"}},
+			{"intervalo de criação", FiltroMotoristas{CriadoDe: &de, CriadoAte: &ate}, []string{"m2", "m3", "m4"}},
+			{"categoria", FiltroMotoristas{CategoriaCNH: models.CategoriaD}, []string{"m2", "m6"}},
+			{"prefixo da placa sem diferenciar caixa", FiltroMotoristas{PrefixoPlaca: "ab"}, []string{"m1", "m2", "m4"}},
+			{"prefixo do nome sem diferenciar caixa", FiltroMotoristas{PrefixoNome: "ANA"}, []string{"m1", "m3", "m5"}},
+			{"curingas são literais", FiltroMotoristas{PrefixoPlaca: "A%"}, []string{"m6"}},
+			{"sublinhado é literal", FiltroMotoristas{PrefixoNome: "diego_"}, []string{"m6"}},
+			{"status e categoria", FiltroMotoristas{Status: []models.StatusMotorista{models.StatusAprovado}, CategoriaCNH: models.CategoriaD, Ordem: OrdemCriadoEmDesc}, []string{"m6", "m2"}},
+			{"nenhum resultado", FiltroMotoristas{PrefixoNome: "zz"}, []string{}},
+		}
+		for _, c := range casos {
+			t.Run(c.nome, func(t *testing.T) {
+				c.filtro.Limite = 2
+				assert.Equal(t, c.esperado, todas(c.filtro))
+			})
+		}
+	})
+
+	t.Run("Limite acima do máximo é reduzido", func(t *testing.T) {
+		p, err := repo.Pesquisar(FiltroMotoristas{Limite: LimiteMaximoPagina + 1})
+		require.NoError(t, err)
+		assert.Len(t, p.Motoristas, len(dados))
+	})
+
+	t.Run("Ordem e cursor inválidos", func(t *testing.T) {
+		_, err := repo.Pesquisar(FiltroMotoristas{Ordem: "cpf"})
+		assert.ErrorIs(t, err, apperrors.ErrFiltroInvalido)
+
+		_, err = repo.Pesquisar(FiltroMotoristas{Cursor: "não-é-um-cursor"})
+		assert.ErrorIs(t, err, apperrors.ErrCursorInvalido)
+
+		// Cursor gerado para outra ordenação
+		p, err := repo.Pesquisar(FiltroMotoristas{Limite: 1})
+		require.NoError(t, err)
+		_, err = repo.Pesquisar(FiltroMotoristas{Ordem: OrdemNomeAsc, Cursor: p.ProximoCursor})
+		assert.ErrorIs(t, err, apperrors.ErrCursorInvalido)
+	})
+}
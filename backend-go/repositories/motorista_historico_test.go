@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/models"
+)
+
+func TestJSONMotoristaRepositoryHistoricoDocumentos(t *testing.T) {
+	repo := &JSONMotoristaRepository{filePath: filepath.Join(t.TempDir(), "motoristas.json")}
+	testarHistoricoDocumentos(t, repo)
+}
+
+func TestSQLiteMotoristaRepositoryHistoricoDocumentos(t *testing.T) {
+	repo, err := NewSQLiteMotoristaRepository(filepath.Join(t.TempDir(), "taxi_service.db"), nil)
+	require.NoError(t, err)
+	defer repo.Close()
+	testarHistoricoDocumentos(t, repo)
+}
+
+// testarHistoricoDocumentos verifica que o histórico é gravado e relido e que versões
+// já gravadas não podem ser removidas nem substituídas
+func testarHistoricoDocumentos(t *testing.T, repo MotoristaRepository) {
+	agora := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Criar(&models.Motorista{
+		ID: "h1", Nome: "Ana", CPF: "52998224725", CNH: "04512345678", Email: "ana@email.com",
+		Status: models.StatusDocumentosAnalise, CriadoEm: agora, AtualizadoEm: agora,
+		Documentos: []models.Documento{{ID: "d1", TipoDocumento: "CNH", ChaveArquivo: "h1/a.pdf", Formato: "PDF",
+			Tamanho: 10, Checksum: "aaa", Status: models.DocumentoStatusPendente, Versao: 1, EnviadoPor: "h1", CriadoEm: agora}},
+	}))
+
+	// Reenvio: a versão 1, rejeitada, vai para o histórico
+	m, err := repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	revisadoEm, substituidoEm := agora.Add(time.Hour), agora.Add(2*time.Hour)
+	anterior := m.Documentos[0]
+	anterior.Status = models.DocumentoStatusRejeitado
+	anterior.MotivoRejeicao = models.MotivoArquivoInfectado
+	anterior.Varredura = models.VarreduraInfectado
+	anterior.Ameaca = "Win.Test.EICAR_HDB-1"
+	anterior.RevisadoPor = "op-1"
+	anterior.RevisadoEm = &revisadoEm
+	anterior.SubstituidoEm = &substituidoEm
+	m.Historico = append(m.Historico, anterior)
+	m.Documentos[0] = models.Documento{ID: "d2", TipoDocumento: "CNH", ChaveArquivo: "h1/b.pdf", Formato: "PDF",
+		Tamanho: 20, Checksum: "bbb", Status: models.DocumentoStatusPendente, Varredura: models.VarreduraVerificando, Versao: 2, EnviadoPor: "op-9", CriadoEm: substituidoEm}
+	require.NoError(t, repo.Atualizar(m))
+
+	m, err = repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	require.Len(t, m.Documentos, 1)
+	assert.Equal(t, 2, m.Documentos[0].Versao)
+	assert.Equal(t, "op-9", m.Documentos[0].EnviadoPor)
+	assert.Equal(t, models.VarreduraVerificando, m.Documentos[0].Varredura)
+	require.Len(t, m.Historico, 1)
+	h := m.Historico[0]
+	assert.Equal(t, "d1", h.ID)
+	assert.Equal(t, 1, h.Versao)
+	assert.Equal(t, "aaa", h.Checksum)
+	assert.Equal(t, "h1/a.pdf", h.ChaveArquivo)
+	assert.Equal(t, models.DocumentoStatusRejeitado, h.Status)
+	assert.Equal(t, models.MotivoArquivoInfectado, h.MotivoRejeicao)
+	assert.Equal(t, models.VarreduraInfectado, h.Varredura)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", h.Ameaca)
+	require.NotNil(t, h.SubstituidoEm)
+	assert.True(t, h.SubstituidoEm.Equal(substituidoEm))
+
+	// Atualizações que não mexem no histórico o preservam
+	m.Nome = "Ana Souza"
+	require.NoError(t, repo.Atualizar(m))
+	m, err = repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	assert.Len(t, m.Historico, 1)
+
+	removido, err := repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	removido.Historico = nil
+	assert.ErrorIs(t, repo.Atualizar(removido), ErrHistoricoAlterado)
+
+	substituido, err := repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	substituido.Historico[0].ID = "outro"
+	assert.ErrorIs(t, repo.Atualizar(substituido), ErrHistoricoAlterado)
+
+	m, err = repo.BuscarPorID("h1")
+	require.NoError(t, err)
+	require.Len(t, m.Historico, 1)
+	assert.Equal(t, "d1", m.Historico[0].ID)
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // driver "pgx" para database/sql
+
+	"taxi_service/internal/cripto"
+)
+
+// PostgresConfig configuração de conexão com o PostgreSQL
+type PostgresConfig struct {
+	Host        string
+	Port        string
+	Nome        string
+	Usuario     string
+	Senha       string
+	SSLMode     string // disable, require, verify-full...
+	MaxConexoes int    // conexões abertas simultâneas no pool
+}
+
+// PostgresConfigFromEnv lê a configuração das variáveis DB_* do .env
+func PostgresConfigFromEnv() PostgresConfig {
+	maxConexoes, _ := strconv.Atoi(os.Getenv("DB_MAX_CONNS"))
+	return PostgresConfig{
+		Host:        getEnvOrDefault("DB_HOST", "localhost"),
+		Port:        getEnvOrDefault("DB_PORT", "5432"),
+		Nome:        getEnvOrDefault("DB_NAME", "taxi_service"),
+		Usuario:     getEnvOrDefault("DB_USER", "postgres"),
+		Senha:       os.Getenv("DB_PASSWORD"),
+		SSLMode:     getEnvOrDefault("DB_SSLMODE", "disable"),
+		MaxConexoes: maxConexoes,
+	}
+}
+
+// DSN monta a URL de conexão no formato aceito pelo pgx
+func (c PostgresConfig) DSN() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(c.Usuario, c.Senha),
+		Host:   net.JoinHostPort(c.Host, c.Port),
+		Path:   "/" + c.Nome,
+	}
+	params := url.Values{}
+	params.Set("sslmode", c.SSLMode)
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+// NewPostgresMotoristaRepository conecta ao PostgreSQL, configura o pool de conexões
+// e aplica as migrações pendentes (as mesmas usadas pelo SQLite).
+func NewPostgresMotoristaRepository(config PostgresConfig, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	return abrirPostgres(config.DSN(), config.MaxConexoes, cifrador)
+}
+
+// NewPostgresMotoristaRepositoryLeitura conecta ao PostgreSQL apenas para leitura: as
+// transações da sessão são somente leitura e as migrações não são aplicadas
+// (ver NewSQLMotoristaRepositoryLeitura)
+func NewPostgresMotoristaRepositoryLeitura(config PostgresConfig, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	u, err := url.Parse(config.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir conexão com PostgreSQL: %w", err)
+	}
+	params := u.Query()
+	params.Set("default_transaction_read_only", "on")
+	u.RawQuery = params.Encode()
+	db, err := conectarPostgres(u.String(), config.MaxConexoes)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := NewSQLMotoristaRepositoryLeitura(db, DialetoPostgres, cifrador)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+func abrirPostgres(dsn string, maxConexoes int, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	db, err := conectarPostgres(dsn, maxConexoes)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := NewSQLMotoristaRepository(db, DialetoPostgres, cifrador)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	// READ COMMITTED (padrão) permitiria que duas unidades de trabalho lessem o mesmo
+	// motorista e uma sobrescrevesse a outra; serializável aborta a segunda, que é repetida
+	repo.isolamento = sql.LevelSerializable
+	repo.repetirTx = conflitoSerializacao
+	return repo, nil
+}
+
+// conectarPostgres abre o pool de conexões e verifica se o banco responde
+func conectarPostgres(dsn string, maxConexoes int) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir conexão com PostgreSQL: %w", err)
+	}
+	if maxConexoes <= 0 {
+		maxConexoes = 10
+	}
+	db.SetMaxOpenConns(maxConexoes)
+	db.SetMaxIdleConns(maxConexoes / 2)
+	db.SetConnMaxLifetime(30 * time.Minute)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao conectar ao PostgreSQL: %w", err)
+	}
+	return db, nil
+}
+
+// conflitoSerializacao identifica falhas de serialização e deadlocks, que podem ser repetidos
+func conflitoSerializacao(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// getEnvOrDefault obtém variável de ambiente ou retorna valor padrão
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
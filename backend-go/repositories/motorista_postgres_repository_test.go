@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresMotoristaRepository roda contra o banco em TEST_DATABASE_URL, cada
+// execução em um schema próprio removido ao final. Sem banco disponível o teste é ignorado.
+func TestPostgresMotoristaRepository(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL não definida; testes de integração do PostgreSQL ignorados")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	require.NoError(t, err)
+	defer admin.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	if err := admin.PingContext(ctx); err != nil {
+		t.Skipf("PostgreSQL indisponível: %v", err)
+	}
+
+	schema := fmt.Sprintf("teste_motoristas_%d", time.Now().UnixNano())
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	defer admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+	params := u.Query()
+	params.Set("search_path", schema)
+	u.RawQuery = params.Encode()
+
+	repo, err := abrirPostgres(u.String(), 4, nil)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	testarSQLMotoristaRepository(t, repo)
+
+	t.Run("Reconectar não reaplica migrações", func(t *testing.T) {
+		reaberto, err := abrirPostgres(u.String(), 2, nil)
+		require.NoError(t, err)
+		defer reaberto.Close()
+
+		migracoes, err := Migracoes(DialetoPostgres)
+		require.NoError(t, err)
+		aplicadas, err := ExecutarMigracoes(context.Background(), reaberto.db, migracoes)
+		require.NoError(t, err)
+		assert.Equal(t, 0, aplicadas)
+	})
+}
+
+func TestPostgresConfigDSN(t *testing.T) {
+	config := PostgresConfig{Host: "db", Port: "5433", Nome: "taxi", Usuario: "app", Senha: "s3nh@/forte", SSLMode: "require"}
+	assert.Equal(t, "postgres://app:s3nh%40%2Fforte@db:5433/taxi?sslmode=require", config.DSN())
+
+	t.Setenv("DB_HOST", "")
+	t.Setenv("DB_PORT", "")
+	t.Setenv("DB_MAX_CONNS", "20")
+	padrao := PostgresConfigFromEnv()
+	assert.Equal(t, "localhost", padrao.Host)
+	assert.Equal(t, "5432", padrao.Port)
+	assert.Equal(t, 20, padrao.MaxConexoes)
+}
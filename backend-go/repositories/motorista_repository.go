@@ -4,50 +4,243 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/cripto"
 	"taxi_service/models"
 )
 
-// MotoristaRepository define a interface para operações com motoristas
-type MotoristaRepository interface {
+// Erros do repositório de motoristas. CPF, CNH e email são únicos entre os motoristas
+// e a restrição é garantida pelo próprio repositório em Criar e Atualizar.
+var (
+	ErrMotoristaNaoEncontrado = errors.New("motorista não encontrado")
+	ErrMotoristaJaExiste      = errors.New("motorista com este ID já existe")
+	ErrCPFDuplicado           = errors.New("CPF já cadastrado para outro motorista")
+	ErrCNHDuplicada           = errors.New("CNH já cadastrada para outro motorista")
+	ErrEmailDuplicado         = errors.New("email já cadastrado para outro motorista")
+	ErrHistoricoAlterado      = errors.New("histórico de documentos não pode ser alterado, apenas acrescido")
+)
+
+// MotoristaTx operações sobre motoristas, disponíveis também dentro de uma unidade de trabalho
+type MotoristaTx interface {
 	Criar(motorista *models.Motorista) error
 	BuscarPorID(id string) (*models.Motorista, error)
 	BuscarPorEmail(email string) (*models.Motorista, error)
 	BuscarPorCPF(cpf string) (*models.Motorista, error)
 	BuscarPorCNH(cnh string) (*models.Motorista, error)
+	// Atualizar grava o motorista se Versao ainda for a armazenada, incrementando-a;
+	// caso outra escrita tenha ocorrido desde a leitura retorna apperrors.ErrConflitoVersao
 	Atualizar(motorista *models.Motorista) error
 	Deletar(id string) error
 	ListarTodos() ([]*models.Motorista, error)
 }
 
+// MotoristaRepository define a interface para operações com motoristas
+type MotoristaRepository interface {
+	MotoristaTx
+	// Pesquisar retorna uma página de motoristas que atendem ao filtro
+	Pesquisar(filtro FiltroMotoristas) (*PaginaMotoristas, error)
+	// WithTx executa fn como uma unidade de trabalho: leituras e escritas feitas por tx
+	// são isoladas de outras escritas concorrentes e só são persistidas se fn retornar nil.
+	// fn pode ser executada mais de uma vez em caso de conflito e não deve ter efeitos
+	// externos (emails, arquivos); dentro dela use apenas tx, nunca o próprio repositório.
+	WithTx(fn func(tx MotoristaTx) error) error
+}
+
 // JSONMotoristaRepository implementa MotoristaRepository usando arquivo JSON
 type JSONMotoristaRepository struct {
-	filePath string
-	mutex    sync.RWMutex
+	filePath  string
+	snapshots int              // versões anteriores mantidas como motoristas.json.1..N
+	cifrador  *cripto.Cifrador // nil: dados sensíveis gravados sem criptografia
+	mutex     sync.RWMutex
+}
+
+// snapshotsMotoristas quantas versões anteriores de motoristas.json são preservadas
+const snapshotsMotoristas = 5
+
+// NewJSONMotoristaRepository cria uma nova instância do repositório sobre o arquivo
+// informado (normalmente data/motoristas.json).
+// Se o arquivo estiver corrompido (ex.: queda durante uma escrita feita por
+// uma versão anterior), os dados são restaurados do snapshot válido mais recente.
+// Com cifrador, registros gravados sem criptografia ou com outra chave mestra são
+// recifrados na inicialização (RecifrarDados).
+func NewJSONMotoristaRepository(caminho string, cifrador *cripto.Cifrador) *JSONMotoristaRepository {
+	r := &JSONMotoristaRepository{
+		filePath:  caminho,
+		snapshots: snapshotsMotoristas,
+		cifrador:  cifrador,
+	}
+	if err := r.Recuperar(); err != nil {
+		log.Printf("ERRO: %v", err)
+	}
+	if n, err := r.RecifrarDados(); err != nil {
+		log.Printf("ERRO: %v", err)
+	} else if n > 0 {
+		log.Printf("AVISO: dados sensíveis de %d motorista(s) recifrados com a chave mestra ativa", n)
+	}
+	return r
+}
+
+// NewJSONMotoristaRepositoryLeitura abre um arquivo de motoristas existente apenas para
+// leitura (origem de uma exportação): ao contrário de NewJSONMotoristaRepository, não
+// restaura snapshots nem recifra os dados, que permanecem como estão no arquivo
+func NewJSONMotoristaRepositoryLeitura(caminho string, cifrador *cripto.Cifrador) (*JSONMotoristaRepository, error) {
+	if _, err := os.Stat(caminho); err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de motoristas: %w", err)
+	}
+	return &JSONMotoristaRepository{filePath: caminho, snapshots: snapshotsMotoristas, cifrador: cifrador}, nil
+}
+
+// Recuperar restaura o arquivo de motoristas a partir do snapshot válido mais
+// recente caso o arquivo principal não possa ser deserializado
+func (r *JSONMotoristaRepository) Recuperar() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return recuperarArquivo(r.filePath, r.snapshots, 0600, func(data []byte) error {
+		var registros []registroMotorista
+		return json.Unmarshal(data, &registros)
+	})
+}
+
+// registroMotorista é o formato persistido no arquivo JSON.
+// models.Motorista omite a senha e os segredos de 2FA na serialização para que
+// nunca apareçam em respostas; aqui os campos são reexpostos apenas para armazenamento.
+// Os dados pessoais sobrepõem os campos de mesmo nome do modelo e são gravados como
+// dadosSensiveis: cifrados e acompanhados dos índices cegos.
+type registroMotorista struct {
+	models.Motorista
+	Senha               string              `json:"senha"`
+	SegredosDoisFatores registroDoisFatores `json:"segredos_2fa"`
+	DataNascimento      string              `json:"data_nascimento"`
+	CPF                 string              `json:"cpf"`
+	CNH                 string              `json:"cnh"`
+	Telefone            string              `json:"telefone"`
+	Email               string              `json:"email"`
+	EmailPendente       string              `json:"email_pendente,omitempty"`
+	IndiceCPF           string              `json:"indice_cpf,omitempty"`
+	IndiceCNH           string              `json:"indice_cnh,omitempty"`
+	IndiceEmail         string              `json:"indice_email,omitempty"`
+}
+
+// novoRegistroMotorista prepara o motorista para gravação, cifrando os dados sensíveis
+func novoRegistroMotorista(c *cripto.Cifrador, m *models.Motorista) (registroMotorista, error) {
+	d, err := cifrarDados(c, m)
+	if err != nil {
+		return registroMotorista{}, err
+	}
+	r := registroMotorista{Motorista: *copiarMotorista(m), Senha: m.Senha}
+	r.SegredosDoisFatores = novoRegistroDoisFatores(r.Motorista.DoisFatores)
+	r.definirSensiveis(d)
+	return r, nil
+}
+
+// motorista decifra o registro; o resultado não compartilha listas com ele
+func (r *registroMotorista) motorista(c *cripto.Cifrador) (*models.Motorista, error) {
+	m := r.Motorista
+	m.Senha = r.Senha
+	r.SegredosDoisFatores.aplicar(&m.DoisFatores)
+	if err := r.sensiveis().decifrar(c, &m); err != nil {
+		return nil, err
+	}
+	return copiarMotorista(&m), nil
+}
+
+func (r *registroMotorista) sensiveis() dadosSensiveis {
+	return dadosSensiveis{
+		DataNascimento:     r.DataNascimento,
+		CPF:                r.CPF,
+		CNH:                r.CNH,
+		Telefone:           r.Telefone,
+		Email:              r.Email,
+		EmailPendente:      r.EmailPendente,
+		SegredoDoisFatores: r.SegredosDoisFatores.Segredo,
+		IndiceCPF:          r.IndiceCPF,
+		IndiceCNH:          r.IndiceCNH,
+		IndiceEmail:        r.IndiceEmail,
+	}
+}
+
+func (r *registroMotorista) definirSensiveis(d dadosSensiveis) {
+	r.DataNascimento = d.DataNascimento
+	r.CPF = d.CPF
+	r.CNH = d.CNH
+	r.Telefone = d.Telefone
+	r.Email = d.Email
+	r.EmailPendente = d.EmailPendente
+	r.SegredosDoisFatores.Segredo = d.SegredoDoisFatores
+	r.IndiceCPF = d.IndiceCPF
+	r.IndiceCNH = d.IndiceCNH
+	r.IndiceEmail = d.IndiceEmail
+	// Nada em texto puro permanece no modelo embutido
+	r.Motorista.DataNascimento = time.Time{}
+	r.Motorista.CPF, r.Motorista.CNH, r.Motorista.Telefone = "", "", ""
+	r.Motorista.Email, r.Motorista.EmailPendente, r.Motorista.DoisFatores.Segredo = "", "", ""
 }
 
-// NewJSONMotoristaRepository cria uma nova instância do repositório
-func NewJSONMotoristaRepository() *JSONMotoristaRepository {
-	return &JSONMotoristaRepository{
-		filePath: "./data/motoristas.json",
+// registroDoisFatores campos sensíveis de models.DoisFatores persistidos pelos repositórios
+type registroDoisFatores struct {
+	Segredo            string   `json:"segredo,omitempty"`
+	CodigosRecuperacao []string `json:"codigos_recuperacao,omitempty"`
+	UltimoPasso        int64    `json:"ultimo_passo,omitempty"`
+}
+
+func novoRegistroDoisFatores(d models.DoisFatores) registroDoisFatores {
+	return registroDoisFatores{
+		Segredo:            d.Segredo,
+		CodigosRecuperacao: d.CodigosRecuperacao,
+		UltimoPasso:        d.UltimoPasso,
 	}
 }
 
-// lerMotoristas lê todos os motoristas do arquivo JSON
+// aplicar devolve os segredos persistidos ao modelo
+func (r registroDoisFatores) aplicar(d *models.DoisFatores) {
+	d.Segredo = r.Segredo
+	d.CodigosRecuperacao = r.CodigosRecuperacao
+	d.UltimoPasso = r.UltimoPasso
+}
+
+// lerMotoristas lê e decifra todos os motoristas do arquivo JSON
 func (r *JSONMotoristaRepository) lerMotoristas() ([]*models.Motorista, error) {
+	registros, err := r.lerRegistros()
+	if err != nil {
+		return nil, err
+	}
+	motoristas := make([]*models.Motorista, 0, len(registros))
+	for i := range registros {
+		m, err := registros[i].motorista(r.cifrador)
+		if err != nil {
+			return nil, err
+		}
+		motoristas = append(motoristas, m)
+	}
+	return motoristas, nil
+}
+
+// lerRegistros lê os registros do arquivo, sem decifrá-los
+func (r *JSONMotoristaRepository) lerRegistros() ([]registroMotorista, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	return r.carregar()
+}
+
+// carregar lê o arquivo; quem chama deve manter o mutex
+func (r *JSONMotoristaRepository) carregar() ([]registroMotorista, error) {
 	// Criar diretório se não existir
-	if err := os.MkdirAll("./data", 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório: %w", err)
 	}
 
 	// Se o arquivo não existir, retornar lista vazia
 	if _, err := os.Stat(r.filePath); os.IsNotExist(err) {
-		return []*models.Motorista{}, nil
+		return []registroMotorista{}, nil
 	}
 
 	data, err := os.ReadFile(r.filePath)
@@ -55,152 +248,333 @@ func (r *JSONMotoristaRepository) lerMotoristas() ([]*models.Motorista, error) {
 		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
 	}
 
-	var motoristas []*models.Motorista
+	var registros []registroMotorista
 	if len(data) == 0 {
-		return []*models.Motorista{}, nil
+		return []registroMotorista{}, nil
 	}
 
-	if err := json.Unmarshal(data, &motoristas); err != nil {
+	if err := json.Unmarshal(data, &registros); err != nil {
 		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
 	}
 
-	return motoristas, nil
-}
+	for i := range registros {
+		registros[i].FotoPerfil = models.ChaveArquivoLegada(registros[i].FotoPerfil)
+	}
 
-// salvarMotoristas salva todos os motoristas no arquivo JSON
-func (r *JSONMotoristaRepository) salvarMotoristas(motoristas []*models.Motorista) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+	return registros, nil
+}
 
-	data, err := json.MarshalIndent(motoristas, "", "  ")
+// salvarRegistros grava todos os registros no arquivo JSON; quem chama deve manter o mutex de escrita
+func (r *JSONMotoristaRepository) salvarRegistros(registros []registroMotorista) error {
+	data, err := json.MarshalIndent(registros, "", "  ")
 	if err != nil {
 		return fmt.Errorf("erro ao serializar dados: %w", err)
 	}
 
-	if err := os.WriteFile(r.filePath, data, 0644); err != nil {
+	if err := escreverArquivoAtomico(r.filePath, data, 0600, r.snapshots); err != nil {
 		return fmt.Errorf("erro ao escrever arquivo: %w", err)
 	}
 
 	return nil
 }
 
-// Criar adiciona um novo motorista
-func (r *JSONMotoristaRepository) Criar(motorista *models.Motorista) error {
-	motoristas, err := r.lerMotoristas()
-	if err != nil {
-		return err
+// RecifrarDados cifra os dados sensíveis ainda gravados em texto puro e traz os
+// demais para a chave mestra ativa; retorna quantos motoristas foram regravados.
+// Os snapshots anteriores guardam os dados antigos (em claro ou com a chave
+// substituída) e são descartados em seguida.
+func (r *JSONMotoristaRepository) RecifrarDados() (int, error) {
+	if !r.cifrador.Ativo() {
+		return 0, nil
 	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	// Verificar se já existe motorista com mesmo ID
-	for _, m := range motoristas {
-		if m.ID == motorista.ID {
-			return errors.New("motorista com este ID já existe")
+	registros, err := r.carregar()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range registros {
+		d := registros[i].sensiveis()
+		mudou, err := d.recifrar(r.cifrador, registros[i].ID)
+		if err != nil {
+			return 0, err
+		}
+		if mudou {
+			registros[i].definirSensiveis(d)
+			n++
 		}
 	}
+	if n == 0 {
+		return 0, nil
+	}
+	if err := r.salvarRegistros(registros); err != nil {
+		return 0, err
+	}
+	return n, descartarSnapshots(r.filePath, r.snapshots)
+}
 
-	motoristas = append(motoristas, motorista)
-	return r.salvarMotoristas(motoristas)
+// Criar adiciona um novo motorista
+func (r *JSONMotoristaRepository) Criar(motorista *models.Motorista) error {
+	return r.WithTx(func(tx MotoristaTx) error { return tx.Criar(motorista) })
 }
 
 // BuscarPorID busca um motorista por ID
 func (r *JSONMotoristaRepository) BuscarPorID(id string) (*models.Motorista, error) {
-	motoristas, err := r.lerMotoristas()
+	return r.buscar(func(reg *registroMotorista) bool { return reg.ID == id })
+}
+
+// BuscarPorEmail busca um motorista por email
+func (r *JSONMotoristaRepository) BuscarPorEmail(email string) (*models.Motorista, error) {
+	return r.buscarPorChave(dominioEmail, email)
+}
+
+// BuscarPorCPF busca um motorista por CPF
+func (r *JSONMotoristaRepository) BuscarPorCPF(cpf string) (*models.Motorista, error) {
+	return r.buscarPorChave(dominioCPF, cpf)
+}
+
+// BuscarPorCNH busca um motorista por CNH
+func (r *JSONMotoristaRepository) BuscarPorCNH(cnh string) (*models.Motorista, error) {
+	return r.buscarPorChave(dominioCNH, cnh)
+}
+
+// buscarPorChave compara o índice cego do valor, decifrando apenas o registro encontrado
+func (r *JSONMotoristaRepository) buscarPorChave(dominio, valor string) (*models.Motorista, error) {
+	chave := chaveBusca(r.cifrador, dominio, valor)
+	return r.buscar(func(reg *registroMotorista) bool { return reg.chave(dominio) == chave })
+}
+
+func (r *JSONMotoristaRepository) buscar(filtro func(reg *registroMotorista) bool) (*models.Motorista, error) {
+	registros, err := r.lerRegistros()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, motorista := range motoristas {
-		if motorista.ID == id {
-			return motorista, nil
+	for i := range registros {
+		if filtro(&registros[i]) {
+			return registros[i].motorista(r.cifrador)
 		}
 	}
 
-	return nil, errors.New("motorista não encontrado")
+	return nil, ErrMotoristaNaoEncontrado
 }
 
-// BuscarPorEmail busca um motorista por email
-func (r *JSONMotoristaRepository) BuscarPorEmail(email string) (*models.Motorista, error) {
+// Atualizar atualiza um motorista existente
+func (r *JSONMotoristaRepository) Atualizar(motorista *models.Motorista) error {
+	return r.WithTx(func(tx MotoristaTx) error { return tx.Atualizar(motorista) })
+}
+
+// Deletar remove um motorista
+func (r *JSONMotoristaRepository) Deletar(id string) error {
+	return r.WithTx(func(tx MotoristaTx) error { return tx.Deletar(id) })
+}
+
+// ListarTodos retorna todos os motoristas
+func (r *JSONMotoristaRepository) ListarTodos() ([]*models.Motorista, error) {
+	return r.lerMotoristas()
+}
+
+// Pesquisar filtra e ordena em memória: o arquivo já é lido por inteiro a cada consulta
+func (r *JSONMotoristaRepository) Pesquisar(filtro FiltroMotoristas) (*PaginaMotoristas, error) {
 	motoristas, err := r.lerMotoristas()
 	if err != nil {
 		return nil, err
 	}
+	return pesquisarEmMemoria(motoristas, filtro)
+}
 
-	for _, motorista := range motoristas {
-		if motorista.Email == email {
-			return motorista, nil
-		}
+// WithTx mantém o lock de escrita do arquivo durante toda a execução de fn: as
+// leituras de tx veem o estado mais recente e nenhuma outra escrita se intercala.
+// O arquivo é regravado uma única vez ao final, apenas se fn alterar algo e retornar nil.
+func (r *JSONMotoristaRepository) WithTx(fn func(tx MotoristaTx) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	registros, err := r.carregar()
+	if err != nil {
+		return err
+	}
+	tx := &jsonMotoristaTx{cifrador: r.cifrador, registros: registros}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.alterado {
+		return nil
+	}
+	if err := r.salvarRegistros(tx.registros); err != nil {
+		return err
 	}
+	// Motoristas removidos (ex.: purga de contas encerradas) não podem sobreviver nos snapshots
+	if tx.removido {
+		return descartarSnapshots(r.filePath, r.snapshots)
+	}
+	return nil
+}
 
-	return nil, errors.New("motorista não encontrado")
+// jsonMotoristaTx opera sobre os registros carregados por WithTx, ainda cifrados.
+// Leituras decifram cópias, para que alterações só tenham efeito via Atualizar;
+// registros não alterados são regravados exatamente como foram lidos.
+type jsonMotoristaTx struct {
+	cifrador  *cripto.Cifrador
+	registros []registroMotorista
+	alterado  bool
+	removido  bool // algum motorista foi removido por Deletar
 }
 
-// BuscarPorCPF busca um motorista por CPF
-func (r *JSONMotoristaRepository) BuscarPorCPF(cpf string) (*models.Motorista, error) {
-	motoristas, err := r.lerMotoristas()
+// Criar adiciona um novo motorista
+func (t *jsonMotoristaTx) Criar(motorista *models.Motorista) error {
+	for i := range t.registros {
+		if t.registros[i].ID == motorista.ID {
+			return ErrMotoristaJaExiste
+		}
+	}
+	registro, err := novoRegistroMotorista(t.cifrador, motorista)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	for _, motorista := range motoristas {
-		if motorista.CPF == cpf {
-			return motorista, nil
-		}
+	if err := t.verificarUnicidade(&registro); err != nil {
+		return err
 	}
+	registro.Versao = 1
+	motorista.Versao = 1
+	t.registros = append(t.registros, registro)
+	t.alterado = true
+	return nil
+}
 
-	return nil, errors.New("motorista não encontrado")
+// BuscarPorID busca um motorista por ID
+func (t *jsonMotoristaTx) BuscarPorID(id string) (*models.Motorista, error) {
+	return t.buscar(func(reg *registroMotorista) bool { return reg.ID == id })
+}
+
+// BuscarPorEmail busca um motorista por email
+func (t *jsonMotoristaTx) BuscarPorEmail(email string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioEmail, email)
+}
+
+// BuscarPorCPF busca um motorista por CPF
+func (t *jsonMotoristaTx) BuscarPorCPF(cpf string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioCPF, cpf)
 }
 
 // BuscarPorCNH busca um motorista por CNH
-func (r *JSONMotoristaRepository) BuscarPorCNH(cnh string) (*models.Motorista, error) {
-	motoristas, err := r.lerMotoristas()
-	if err != nil {
-		return nil, err
-	}
+func (t *jsonMotoristaTx) BuscarPorCNH(cnh string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioCNH, cnh)
+}
 
-	for _, motorista := range motoristas {
-		if motorista.CNH == cnh {
-			return motorista, nil
+// Atualizar atualiza um motorista existente
+func (t *jsonMotoristaTx) Atualizar(motorista *models.Motorista) error {
+	for i := range t.registros {
+		if t.registros[i].ID == motorista.ID {
+			if t.registros[i].Versao != motorista.Versao {
+				return apperrors.ErrConflitoVersao
+			}
+			if !historicoPreservado(t.registros[i].Historico, motorista.Historico) {
+				return ErrHistoricoAlterado
+			}
+			registro, err := novoRegistroMotorista(t.cifrador, motorista)
+			if err != nil {
+				return err
+			}
+			if err := t.verificarUnicidade(&registro); err != nil {
+				return err
+			}
+			registro.Versao++
+			motorista.Versao++
+			t.registros[i] = registro
+			t.alterado = true
+			return nil
 		}
 	}
-
-	return nil, errors.New("motorista não encontrado")
+	return ErrMotoristaNaoEncontrado
 }
 
-// Atualizar atualiza um motorista existente
-func (r *JSONMotoristaRepository) Atualizar(motorista *models.Motorista) error {
-	motoristas, err := r.lerMotoristas()
-	if err != nil {
-		return err
+// Deletar remove um motorista
+func (t *jsonMotoristaTx) Deletar(id string) error {
+	for i := range t.registros {
+		if t.registros[i].ID == id {
+			t.registros = append(t.registros[:i], t.registros[i+1:]...)
+			t.alterado = true
+			t.removido = true
+			return nil
+		}
 	}
+	return ErrMotoristaNaoEncontrado
+}
 
-	for i, m := range motoristas {
-		if m.ID == motorista.ID {
-			motoristas[i] = motorista
-			return r.salvarMotoristas(motoristas)
+// ListarTodos retorna todos os motoristas
+func (t *jsonMotoristaTx) ListarTodos() ([]*models.Motorista, error) {
+	lista := make([]*models.Motorista, 0, len(t.registros))
+	for i := range t.registros {
+		m, err := t.registros[i].motorista(t.cifrador)
+		if err != nil {
+			return nil, err
 		}
+		lista = append(lista, m)
 	}
+	return lista, nil
+}
 
-	return errors.New("motorista não encontrado")
+func (t *jsonMotoristaTx) buscarPorChave(dominio, valor string) (*models.Motorista, error) {
+	chave := chaveBusca(t.cifrador, dominio, valor)
+	return t.buscar(func(reg *registroMotorista) bool { return reg.chave(dominio) == chave })
 }
 
-// Deletar remove um motorista
-func (r *JSONMotoristaRepository) Deletar(id string) error {
-	motoristas, err := r.lerMotoristas()
-	if err != nil {
-		return err
+func (t *jsonMotoristaTx) buscar(filtro func(reg *registroMotorista) bool) (*models.Motorista, error) {
+	for i := range t.registros {
+		if filtro(&t.registros[i]) {
+			return t.registros[i].motorista(t.cifrador)
+		}
 	}
+	return nil, ErrMotoristaNaoEncontrado
+}
 
-	for i, motorista := range motoristas {
-		if motorista.ID == id {
-			motoristas = append(motoristas[:i], motoristas[i+1:]...)
-			return r.salvarMotoristas(motoristas)
+// verificarUnicidade aplica as mesmas restrições dos índices únicos do esquema SQL,
+// comparando os índices cegos
+func (t *jsonMotoristaTx) verificarUnicidade(registro *registroMotorista) error {
+	for i := range t.registros {
+		r := &t.registros[i]
+		if r.ID == registro.ID {
+			continue
+		}
+		switch {
+		case r.chave(dominioCPF) == registro.chave(dominioCPF):
+			return ErrCPFDuplicado
+		case r.chave(dominioCNH) == registro.chave(dominioCNH):
+			return ErrCNHDuplicada
+		case r.chave(dominioEmail) == registro.chave(dominioEmail):
+			return ErrEmailDuplicado
 		}
 	}
+	return nil
+}
 
-	return errors.New("motorista não encontrado")
+// chave índice cego (ou valor em texto puro, sem criptografia) do domínio informado
+func (r *registroMotorista) chave(dominio string) string {
+	d := r.sensiveis()
+	return d.chave(dominio)
 }
 
-// ListarTodos retorna todos os motoristas
-func (r *JSONMotoristaRepository) ListarTodos() ([]*models.Motorista, error) {
-	return r.lerMotoristas()
+// historicoPreservado indica se novo mantém, na mesma ordem, as entradas de anterior
+func historicoPreservado(anterior, novo []models.Documento) bool {
+	if len(novo) < len(anterior) {
+		return false
+	}
+	for i := range anterior {
+		if anterior[i].ID != novo[i].ID || anterior[i].Checksum != novo[i].Checksum || anterior[i].ChaveArquivo != novo[i].ChaveArquivo {
+			return false
+		}
+	}
+	return true
+}
+
+// copiarMotorista copia o motorista e as listas que ele contém
+func copiarMotorista(m *models.Motorista) *models.Motorista {
+	c := *m
+	c.Documentos = slices.Clone(m.Documentos)
+	c.Historico = slices.Clone(m.Historico)
+	c.Revisoes = slices.Clone(m.Revisoes)
+	c.DoisFatores.CodigosRecuperacao = slices.Clone(m.DoisFatores.CodigosRecuperacao)
+	return &c
 }
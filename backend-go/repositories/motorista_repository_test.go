@@ -1,7 +1,11 @@
 package repositories
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -38,6 +42,7 @@ func TestJSONMotoristaRepository(t *testing.T) {
 			Email:          "joao.silva@email.com",
 			Senha:          "MinhaSenh@123",
 			Status:         models.StatusAguardandoAprovacao,
+			DoisFatores:    models.DoisFatores{Ativo: true, Segredo: "JBSWY3DPEHPK3PXP", CodigosRecuperacao: []string{"hash-1"}, UltimoPasso: 42},
 			CriadoEm:       time.Now(),
 			AtualizadoEm:   time.Now(),
 		}
@@ -52,6 +57,11 @@ func TestJSONMotoristaRepository(t *testing.T) {
 		assert.Equal(t, "1", motorista.ID)
 		assert.Equal(t, "João Silva", motorista.Nome)
 		assert.Equal(t, "joao.silva@email.com", motorista.Email)
+		assert.Equal(t, "MinhaSenh@123", motorista.Senha)
+		assert.True(t, motorista.DoisFatores.Ativo)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", motorista.DoisFatores.Segredo)
+		assert.Equal(t, []string{"hash-1"}, motorista.DoisFatores.CodigosRecuperacao)
+		assert.Equal(t, int64(42), motorista.DoisFatores.UltimoPasso)
 	})
 
 	t.Run("Buscar motorista por email", func(t *testing.T) {
@@ -134,3 +144,162 @@ func TestJSONMotoristaRepository(t *testing.T) {
 		assert.Contains(t, err.Error(), "motorista não encontrado")
 	})
 }
+
+func TestJSONMotoristaRepositoryEscritaAtomica(t *testing.T) {
+	dir := t.TempDir()
+	caminho := filepath.Join(dir, "motoristas.json")
+	repo := &JSONMotoristaRepository{filePath: caminho, snapshots: 3}
+
+	for i := 1; i <= 5; i++ {
+		id := strconv.Itoa(i)
+		require.NoError(t, repo.Criar(&models.Motorista{ID: id, Nome: "Motorista " + id, CPF: "cpf-" + id, CNH: "cnh-" + id, Email: id + "@email.com"}))
+	}
+
+	t.Run("Mantém apenas os N snapshots mais recentes e nenhum temporário", func(t *testing.T) {
+		arquivos, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		nomes := []string{}
+		for _, a := range arquivos {
+			nomes = append(nomes, a.Name())
+		}
+		assert.ElementsMatch(t, []string{"motoristas.json", "motoristas.json.1", "motoristas.json.2", "motoristas.json.3"}, nomes)
+
+		// motoristas.json.1 é a versão imediatamente anterior (4 motoristas)
+		data, err := os.ReadFile(caminhoSnapshot(caminho, 1))
+		require.NoError(t, err)
+		var registros []registroMotorista
+		require.NoError(t, json.Unmarshal(data, &registros))
+		assert.Len(t, registros, 4)
+	})
+
+	t.Run("Arquivo truncado é restaurado do snapshot válido mais recente", func(t *testing.T) {
+		// Simula uma queda no meio da escrita e um snapshot também danificado
+		require.NoError(t, os.WriteFile(caminho, []byte(`[{"id": "1", "nom`), 0644))
+		require.NoError(t, os.WriteFile(caminhoSnapshot(caminho, 1), []byte{}, 0644))
+
+		_, err := repo.ListarTodos()
+		require.Error(t, err)
+
+		require.NoError(t, repo.Recuperar())
+		motoristas, err := repo.ListarTodos()
+		require.NoError(t, err)
+		assert.Len(t, motoristas, 3)
+
+		corrompidos, err := filepath.Glob(caminho + ".corrompido-*")
+		require.NoError(t, err)
+		assert.Len(t, corrompidos, 1)
+	})
+
+	t.Run("Arquivo íntegro não é alterado", func(t *testing.T) {
+		antes, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+		require.NoError(t, repo.Recuperar())
+		depois, err := os.ReadFile(caminho)
+		require.NoError(t, err)
+		assert.Equal(t, antes, depois)
+	})
+
+	t.Run("Arquivo legível apenas pelo dono", func(t *testing.T) {
+		require.NoError(t, repo.Criar(&models.Motorista{ID: "6", Nome: "Motorista 6", CPF: "cpf-6", CNH: "cnh-6", Email: "6@email.com"}))
+		info, err := os.Stat(caminho)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("Remover motorista descarta os snapshots", func(t *testing.T) {
+		require.FileExists(t, caminhoSnapshot(caminho, 1))
+		require.NoError(t, repo.Deletar("6"))
+
+		for n := 1; n <= 3; n++ {
+			assert.NoFileExists(t, caminhoSnapshot(caminho, n))
+		}
+		_, err := repo.BuscarPorID("6")
+		assert.ErrorIs(t, err, ErrMotoristaNaoEncontrado)
+	})
+
+	t.Run("Sem snapshot válido a recuperação falha", func(t *testing.T) {
+		outro := &JSONMotoristaRepository{filePath: filepath.Join(t.TempDir(), "motoristas.json"), snapshots: 3}
+		require.NoError(t, os.WriteFile(outro.filePath, []byte("{corrompido"), 0644))
+		assert.ErrorContains(t, outro.Recuperar(), "nenhum snapshot válido")
+	})
+}
+
+func TestJSONMotoristaRepositoryTransacoes(t *testing.T) {
+	repo := &JSONMotoristaRepository{filePath: filepath.Join(t.TempDir(), "motoristas.json")}
+	novo := func(id, cpf, cnh, email string) *models.Motorista {
+		return &models.Motorista{ID: id, Nome: "Motorista " + id, CPF: cpf, CNH: cnh, Email: email}
+	}
+	require.NoError(t, repo.Criar(novo("1", "111", "aaa", "um@email.com")))
+	require.NoError(t, repo.Criar(novo("2", "222", "bbb", "dois@email.com")))
+
+	t.Run("CPF, CNH e email são únicos", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Criar(novo("3", "111", "ccc", "tres@email.com")), ErrCPFDuplicado)
+		assert.ErrorIs(t, repo.Criar(novo("3", "333", "aaa", "tres@email.com")), ErrCNHDuplicada)
+		assert.ErrorIs(t, repo.Criar(novo("3", "333", "ccc", "um@email.com")), ErrEmailDuplicado)
+
+		m, err := repo.BuscarPorID("2")
+		require.NoError(t, err)
+		m.Email = "um@email.com"
+		assert.ErrorIs(t, repo.Atualizar(m), ErrEmailDuplicado)
+
+		// Regravar o próprio motorista não conflita com ele mesmo
+		m.Email = "dois@email.com"
+		assert.NoError(t, repo.Atualizar(m))
+	})
+
+	t.Run("Criar inicia a versão em 1 e Atualizar recusa cópias antigas", func(t *testing.T) {
+		m, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), m.Versao)
+		testarVersaoMotorista(t, repo, "1")
+	})
+
+	t.Run("WithTx não grava nada quando fn falha", func(t *testing.T) {
+		falha := errors.New("falha")
+		err := repo.WithTx(func(tx MotoristaTx) error {
+			require.NoError(t, tx.Deletar("1"))
+			return falha
+		})
+		assert.ErrorIs(t, err, falha)
+		_, err = repo.BuscarPorID("1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Alterar o motorista lido sem Atualizar não tem efeito", func(t *testing.T) {
+		require.NoError(t, repo.WithTx(func(tx MotoristaTx) error {
+			m, err := tx.BuscarPorID("1")
+			require.NoError(t, err)
+			m.Nome = "Alterado"
+			return nil
+		}))
+		m, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, "Motorista 1", m.Nome)
+	})
+
+	t.Run("Atualizações concorrentes via WithTx não se perdem", func(t *testing.T) {
+		testarAtualizacoesConcorrentes(t, repo, "1")
+	})
+}
+
+func TestJSONMotoristaRepositoryArquivosLegados(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "motoristas.json")
+	// Formato anterior ao FileStore: caminhos em disco em caminho_arquivo e foto_perfil
+	legado := `[{"id": "m1", "nome": "Ana", "foto_perfil": "data/m1/profile/foto.png",
+		"documentos": [{"id": "d1", "tipo_documento": "CNH", "caminho_arquivo": "data/m1/CNH.pdf"}]}]`
+	require.NoError(t, os.WriteFile(caminho, []byte(legado), 0600))
+	repo := &JSONMotoristaRepository{filePath: caminho}
+
+	m, err := repo.BuscarPorID("m1")
+	require.NoError(t, err)
+	assert.Equal(t, "m1/profile/foto.png", m.FotoPerfil)
+	require.Len(t, m.Documentos, 1)
+	assert.Equal(t, "m1/CNH.pdf", m.Documentos[0].ChaveArquivo)
+
+	// Regravado, o arquivo passa ao formato novo
+	require.NoError(t, repo.Atualizar(m))
+	data, err := os.ReadFile(caminho)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"chave_arquivo": "m1/CNH.pdf"`)
+	assert.NotContains(t, string(data), "caminho_arquivo")
+}
@@ -0,0 +1,764 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/cripto"
+	"taxi_service/models"
+)
+
+// timeoutConsulta limite de cada operação no banco; as assinaturas de MotoristaRepository
+// não recebem contexto, então cada chamada cria o seu.
+const timeoutConsulta = 5 * time.Second
+
+// SQLMotoristaRepository implementa MotoristaRepository sobre database/sql.
+// As consultas usam SQL comum aos bancos suportados (placeholders $N), exceto onde o
+// dialeto muda o uso de índices, e o esquema vem das migrações em migracoes/.
+type SQLMotoristaRepository struct {
+	db         *sql.DB
+	dialeto    string           // DialetoSQLite ou DialetoPostgres
+	cifrador   *cripto.Cifrador // nil: dados sensíveis gravados sem criptografia
+	isolamento sql.IsolationLevel
+	repetirTx  func(err error) bool // conflitos em que WithTx pode repetir a unidade de trabalho
+}
+
+// NewSQLMotoristaRepository cria o repositório sobre uma conexão já aberta, aplicando as
+// migrações pendentes e, com cifrador, recifrando os dados pendentes (RecifrarDados)
+func NewSQLMotoristaRepository(db *sql.DB, dialeto string, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	migracoes, err := Migracoes(dialeto)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := ExecutarMigracoes(ctx, db, migracoes); err != nil {
+		return nil, err
+	}
+	r := &SQLMotoristaRepository{db: db, dialeto: dialeto, cifrador: cifrador}
+	if n, err := r.RecifrarDados(); err != nil {
+		return nil, err
+	} else if n > 0 {
+		log.Printf("AVISO: dados sensíveis de %d motorista(s) recifrados com a chave mestra ativa", n)
+	}
+	return r, nil
+}
+
+// NewSQLMotoristaRepositoryLeitura cria o repositório para apenas ler os dados de uma
+// conexão já aberta (origem de uma exportação): não aplica migrações nem recifra, e
+// recusa bancos com migrações pendentes, cujo esquema as consultas não reconheceriam
+func NewSQLMotoristaRepositoryLeitura(db *sql.DB, dialeto string, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	migracoes, err := Migracoes(dialeto)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	pendentes, err := migracoesPendentes(ctx, db, migracoes)
+	if err != nil {
+		return nil, err
+	}
+	if len(pendentes) > 0 {
+		return nil, fmt.Errorf("banco com %d migração(ões) pendente(s) a partir de %04d_%s; inicie o servidor sobre ele para atualizar o esquema",
+			len(pendentes), pendentes[0].Versao, pendentes[0].Descricao)
+	}
+	return &SQLMotoristaRepository{db: db, dialeto: dialeto, cifrador: cifrador}, nil
+}
+
+// Close encerra as conexões com o banco
+func (r *SQLMotoristaRepository) Close() error {
+	return r.db.Close()
+}
+
+const colunasMotorista = `id, nome, data_nascimento, cpf, cnh, categoria_cnh, validade_cnh, placa_veiculo,
+	modelo_veiculo, telefone, email, email_verificado, email_pendente, senha, status, status_anterior,
+	exclusao_em, foto_perfil, criado_em, atualizado_em, dois_fatores_ativo, dois_fatores_ativado_em,
+	dois_fatores_segredo, dois_fatores_ultimo_passo, data_nascimento_cifrada, cpf_indice, cnh_indice,
+	email_indice, versao`
+
+// executor é satisfeito por *sql.DB e *sql.Tx
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Criar adiciona um novo motorista
+func (r *SQLMotoristaRepository) Criar(motorista *models.Motorista) error {
+	return r.WithTx(func(tx MotoristaTx) error { return tx.Criar(motorista) })
+}
+
+// BuscarPorID busca um motorista por ID
+func (r *SQLMotoristaRepository) BuscarPorID(id string) (*models.Motorista, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).BuscarPorID(id)
+}
+
+// BuscarPorEmail busca um motorista por email
+func (r *SQLMotoristaRepository) BuscarPorEmail(email string) (*models.Motorista, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).BuscarPorEmail(email)
+}
+
+// BuscarPorCPF busca um motorista por CPF
+func (r *SQLMotoristaRepository) BuscarPorCPF(cpf string) (*models.Motorista, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).BuscarPorCPF(cpf)
+}
+
+// BuscarPorCNH busca um motorista por CNH
+func (r *SQLMotoristaRepository) BuscarPorCNH(cnh string) (*models.Motorista, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).BuscarPorCNH(cnh)
+}
+
+// Atualizar atualiza um motorista existente, substituindo documentos, revisões e códigos
+// de recuperação; o histórico de documentos apenas recebe as novas entradas
+func (r *SQLMotoristaRepository) Atualizar(motorista *models.Motorista) error {
+	return r.WithTx(func(tx MotoristaTx) error { return tx.Atualizar(motorista) })
+}
+
+// Deletar remove um motorista e, em cascata, seus documentos, revisões e códigos de recuperação
+func (r *SQLMotoristaRepository) Deletar(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).Deletar(id)
+}
+
+// ListarTodos retorna todos os motoristas
+func (r *SQLMotoristaRepository) ListarTodos() ([]*models.Motorista, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	return (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).ListarTodos()
+}
+
+// Pesquisar retorna uma página de motoristas que atendem ao filtro. Filtro, ordenação
+// e paginação (por chave: registros após o cursor) são feitos pelo banco, usando os
+// índices das migrações 0003 e 0010.
+func (r *SQLMotoristaRepository) Pesquisar(filtro FiltroMotoristas) (*PaginaMotoristas, error) {
+	cursor, err := filtro.normalizar()
+	if err != nil {
+		return nil, err
+	}
+
+	var condicoes []string
+	var args []any
+	param := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if len(filtro.Status) > 0 {
+		marcadores := make([]string, len(filtro.Status))
+		for i, s := range filtro.Status {
+			marcadores[i] = param(string(s))
+		}
+		condicoes = append(condicoes, "status IN ("+strings.Join(marcadores, ", ")+")")
+	}
+	if filtro.CriadoDe != nil {
+		condicoes = append(condicoes, "criado_em >= "+param(filtro.CriadoDe.UTC()))
+	}
+	if filtro.CriadoAte != nil {
+		condicoes = append(condicoes, "criado_em < "+param(filtro.CriadoAte.UTC()))
+	}
+	if filtro.CategoriaCNH != "" {
+		condicoes = append(condicoes, "categoria_cnh = "+param(string(filtro.CategoriaCNH)))
+	}
+	// O SQLite só ignora a caixa de letras ASCII; nomes iniciados por letra acentuada
+	// precisam ser informados com a mesma caixa do cadastro
+	if filtro.PrefixoPlaca != "" {
+		condicoes = append(condicoes, r.colunaPrefixo("UPPER", "placa_veiculo")+" LIKE "+param(escaparLike(filtro.PrefixoPlaca)+"%")+` ESCAPE '\'`)
+	}
+	if filtro.PrefixoNome != "" {
+		condicoes = append(condicoes, r.colunaPrefixo("LOWER", "nome")+" LIKE "+param(escaparLike(filtro.PrefixoNome)+"%")+` ESCAPE '\'`)
+	}
+
+	campo, operador, direcao := filtro.campoOrdem(), ">", "ASC"
+	if filtro.decrescente() {
+		operador, direcao = "<", "DESC"
+	}
+	if cursor != nil {
+		var valor any = cursor.Valor
+		if campo == "criado_em" {
+			valor, _ = time.Parse(time.RFC3339Nano, cursor.Valor)
+		}
+		condicoes = append(condicoes, fmt.Sprintf("(%s, id) %s (%s, %s)", campo, operador, param(valor), param(cursor.ID)))
+	}
+
+	consulta := `SELECT ` + colunasMotorista + ` FROM motoristas`
+	if len(condicoes) > 0 {
+		consulta += ` WHERE ` + strings.Join(condicoes, " AND ")
+	}
+	consulta += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", campo, direcao, direcao, param(filtro.Limite+1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+	motoristas, err := (&sqlMotoristaTx{ctx: ctx, q: r.db, cifrador: r.cifrador}).listar(consulta, args...)
+	if err != nil {
+		return nil, err
+	}
+	return filtro.pagina(motoristas), nil
+}
+
+// colunaPrefixo expressão comparada com o prefixo normalizado da pesquisa, a mesma dos
+// índices da migração 0010: no PostgreSQL a coluna convertida por UPPER/LOWER; no SQLite
+// a própria coluna, já que o LIKE ignora a caixa e só usa o índice NOCASE sem função
+func (r *SQLMotoristaRepository) colunaPrefixo(funcao, coluna string) string {
+	if r.dialeto == DialetoSQLite {
+		return coluna
+	}
+	return funcao + "(" + coluna + ")"
+}
+
+// escaparLike escapa os curingas do LIKE para comparar o prefixo literalmente
+func escaparLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// maxFiltroFilhos acima deste número de motoristas os filhos são lidos sem filtro
+const maxFiltroFilhos = 500
+
+// WithTx executa fn em uma transação do banco, desfeita se fn retornar erro.
+// No PostgreSQL a transação é serializável e, em conflito com outra transação
+// concorrente, fn é executada novamente (até tentativasTx vezes); por isso fn não
+// deve ter efeitos fora do banco. Dentro de fn use apenas tx: o SQLite tem uma única
+// conexão, e chamar o repositório diretamente bloquearia até o timeout.
+func (r *SQLMotoristaRepository) WithTx(fn func(tx MotoristaTx) error) error {
+	var err error
+	for tentativa := 1; tentativa <= tentativasTx; tentativa++ {
+		err = r.executarTx(fn)
+		if err == nil || r.repetirTx == nil || !r.repetirTx(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// RecifrarDados cifra os dados sensíveis ainda gravados em texto puro e traz os
+// demais para a chave mestra ativa, sem alterar a versão dos motoristas; retorna
+// quantos foram regravados. Uma linha alterada por outra escrita nesse meio tempo
+// é mantida como está, já que a escrita concorrente usou a chave ativa.
+func (r *SQLMotoristaRepository) RecifrarDados() (int, error) {
+	if !r.cifrador.Ativo() {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, versao, data_nascimento, data_nascimento_cifrada, cpf, cnh, telefone,
+		email, email_pendente, dois_fatores_segredo, cpf_indice, cnh_indice, email_indice FROM motoristas`)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar motoristas: %w", err)
+	}
+	defer rows.Close()
+	type pendente struct {
+		id     string
+		versao int64
+		d      dadosSensiveis
+	}
+	var pendentes []pendente
+	for rows.Next() {
+		var (
+			p                               pendente
+			nascimento                      time.Time
+			indiceCPF, indiceCNH, indiceEml sql.NullString
+		)
+		if err := rows.Scan(&p.id, &p.versao, &nascimento, &p.d.DataNascimento, &p.d.CPF, &p.d.CNH, &p.d.Telefone,
+			&p.d.Email, &p.d.EmailPendente, &p.d.SegredoDoisFatores, &indiceCPF, &indiceCNH, &indiceEml); err != nil {
+			return 0, fmt.Errorf("erro ao ler motorista: %w", err)
+		}
+		if p.d.DataNascimento == "" {
+			p.d.DataNascimento = nascimento.Format(time.RFC3339Nano)
+		}
+		p.d.IndiceCPF, p.d.IndiceCNH, p.d.IndiceEmail = indiceCPF.String, indiceCNH.String, indiceEml.String
+		mudou, err := p.d.recifrar(r.cifrador, p.id)
+		if err != nil {
+			return 0, err
+		}
+		if mudou {
+			pendentes = append(pendentes, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("erro ao consultar motoristas: %w", err)
+	}
+	rows.Close()
+
+	n := 0
+	for _, p := range pendentes {
+		res, err := r.db.ExecContext(ctx, `UPDATE motoristas SET data_nascimento = $2, data_nascimento_cifrada = $3,
+			cpf = $4, cnh = $5, telefone = $6, email = $7, email_pendente = $8, dois_fatores_segredo = $9,
+			cpf_indice = $10, cnh_indice = $11, email_indice = $12
+			WHERE id = $1 AND versao = $13`,
+			p.id, time.Time{}, p.d.DataNascimento, p.d.CPF, p.d.CNH, p.d.Telefone, p.d.Email, p.d.EmailPendente,
+			p.d.SegredoDoisFatores, textoOpcional(p.d.IndiceCPF), textoOpcional(p.d.IndiceCNH), textoOpcional(p.d.IndiceEmail), p.versao)
+		if err != nil {
+			return n, erroUnicidade(fmt.Errorf("erro ao recifrar motorista %s: %w", p.id, err))
+		}
+		if alteradas, err := res.RowsAffected(); err == nil && alteradas > 0 {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// tentativasTx número máximo de execuções de uma unidade de trabalho em conflito
+const tentativasTx = 3
+
+func (r *SQLMotoristaRepository) executarTx(fn func(tx MotoristaTx) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConsulta)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: r.isolamento})
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&sqlMotoristaTx{ctx: ctx, q: tx, cifrador: r.cifrador}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	return nil
+}
+
+// sqlMotoristaTx implementa MotoristaTx sobre uma conexão ou transação
+type sqlMotoristaTx struct {
+	ctx      context.Context
+	q        executor
+	cifrador *cripto.Cifrador
+}
+
+// Criar adiciona um novo motorista
+func (t *sqlMotoristaTx) Criar(motorista *models.Motorista) error {
+	valores, err := valoresMotorista(t.cifrador, motorista)
+	if err != nil {
+		return err
+	}
+	valores = append(valores, int64(1))
+	if _, err := t.q.ExecContext(t.ctx, `INSERT INTO motoristas (`+colunasMotorista+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+		$25, $26, $27, $28, $29)`,
+		valores...); err != nil {
+		return erroUnicidade(fmt.Errorf("erro ao inserir motorista: %w", err))
+	}
+	if err := inserirFilhos(t.ctx, t.q, motorista); err != nil {
+		return err
+	}
+	motorista.Versao = 1
+	return nil
+}
+
+// BuscarPorID busca um motorista por ID
+func (t *sqlMotoristaTx) BuscarPorID(id string) (*models.Motorista, error) {
+	return t.buscarUm("id", id)
+}
+
+// BuscarPorEmail busca um motorista por email
+func (t *sqlMotoristaTx) BuscarPorEmail(email string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioEmail, email)
+}
+
+// BuscarPorCPF busca um motorista por CPF
+func (t *sqlMotoristaTx) BuscarPorCPF(cpf string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioCPF, cpf)
+}
+
+// BuscarPorCNH busca um motorista por CNH
+func (t *sqlMotoristaTx) BuscarPorCNH(cnh string) (*models.Motorista, error) {
+	return t.buscarPorChave(dominioCNH, cnh)
+}
+
+// buscarPorChave busca pelo índice cego do valor (coluna <dominio>_indice) ou, sem
+// criptografia, pela própria coluna
+func (t *sqlMotoristaTx) buscarPorChave(dominio, valor string) (*models.Motorista, error) {
+	if !t.cifrador.Ativo() {
+		return t.buscarUm(dominio, valor)
+	}
+	return t.buscarUm(dominio+"_indice", chaveBusca(t.cifrador, dominio, valor))
+}
+
+// Atualizar atualiza um motorista existente, substituindo documentos, revisões e códigos de recuperação
+func (t *sqlMotoristaTx) Atualizar(motorista *models.Motorista) error {
+	valores, err := valoresMotorista(t.cifrador, motorista)
+	if err != nil {
+		return err
+	}
+	valores = append(valores, motorista.Versao)
+	res, err := t.q.ExecContext(t.ctx, `UPDATE motoristas SET
+		nome = $2, data_nascimento = $3, cpf = $4, cnh = $5, categoria_cnh = $6, validade_cnh = $7,
+		placa_veiculo = $8, modelo_veiculo = $9, telefone = $10, email = $11, email_verificado = $12,
+		email_pendente = $13, senha = $14, status = $15, status_anterior = $16, exclusao_em = $17,
+		foto_perfil = $18, criado_em = $19, atualizado_em = $20, dois_fatores_ativo = $21,
+		dois_fatores_ativado_em = $22, dois_fatores_segredo = $23, dois_fatores_ultimo_passo = $24,
+		data_nascimento_cifrada = $25, cpf_indice = $26, cnh_indice = $27, email_indice = $28,
+		versao = versao + 1
+		WHERE id = $1 AND versao = $29`, valores...)
+	if err != nil {
+		return erroUnicidade(fmt.Errorf("erro ao atualizar motorista: %w", err))
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// Nenhuma linha: o motorista não existe ou a versão lida já foi superada
+		if _, err := t.BuscarPorID(motorista.ID); err != nil {
+			return err
+		}
+		return apperrors.ErrConflitoVersao
+	}
+	for _, tabela := range []string{"documentos", "revisoes", "codigos_recuperacao_2fa"} {
+		if _, err := t.q.ExecContext(t.ctx, `DELETE FROM `+tabela+` WHERE motorista_id = $1`, motorista.ID); err != nil {
+			return fmt.Errorf("erro ao atualizar %s: %w", tabela, err)
+		}
+	}
+	if err := inserirFilhos(t.ctx, t.q, motorista); err != nil {
+		return err
+	}
+	motorista.Versao++
+	return nil
+}
+
+// Deletar remove um motorista e, em cascata, seus documentos, revisões e códigos de recuperação
+func (t *sqlMotoristaTx) Deletar(id string) error {
+	res, err := t.q.ExecContext(t.ctx, `DELETE FROM motoristas WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao remover motorista: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrMotoristaNaoEncontrado
+	}
+	return nil
+}
+
+// ListarTodos retorna todos os motoristas
+func (t *sqlMotoristaTx) ListarTodos() ([]*models.Motorista, error) {
+	return t.listar(`SELECT ` + colunasMotorista + ` FROM motoristas ORDER BY criado_em, id`)
+}
+
+// buscarUm busca pelo valor de uma coluna com índice único
+func (t *sqlMotoristaTx) buscarUm(coluna, valor string) (*models.Motorista, error) {
+	motoristas, err := t.listar(`SELECT `+colunasMotorista+` FROM motoristas WHERE `+coluna+` = $1`, valor)
+	if err != nil {
+		return nil, err
+	}
+	if len(motoristas) == 0 {
+		return nil, ErrMotoristaNaoEncontrado
+	}
+	return motoristas[0], nil
+}
+
+// listar executa a consulta de motoristas e carrega os dados das tabelas associadas
+func (t *sqlMotoristaTx) listar(consulta string, args ...any) ([]*models.Motorista, error) {
+	rows, err := t.q.QueryContext(t.ctx, consulta, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar motoristas: %w", err)
+	}
+	defer rows.Close()
+
+	motoristas := []*models.Motorista{}
+	porID := map[string]*models.Motorista{}
+	for rows.Next() {
+		m, err := lerMotorista(rows, t.cifrador)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler motorista: %w", err)
+		}
+		motoristas = append(motoristas, m)
+		porID[m.ID] = m
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao consultar motoristas: %w", err)
+	}
+	rows.Close()
+
+	if len(motoristas) == 0 {
+		return motoristas, nil
+	}
+	// Consulta única: os filhos de todos os motoristas lidos
+	filtro, argsFiltro := "", []any{}
+	switch {
+	case len(motoristas) == 1:
+		filtro, argsFiltro = ` WHERE motorista_id = $1`, []any{motoristas[0].ID}
+	case len(motoristas) <= maxFiltroFilhos:
+		// Páginas de Pesquisar: apenas os filhos dos motoristas da página
+		marcadores := make([]string, len(motoristas))
+		for i, m := range motoristas {
+			argsFiltro = append(argsFiltro, m.ID)
+			marcadores[i] = fmt.Sprintf("$%d", i+1)
+		}
+		filtro = ` WHERE motorista_id IN (` + strings.Join(marcadores, ", ") + `)`
+	}
+	if err := carregarFilhos(t.ctx, t.q, porID, filtro, argsFiltro); err != nil {
+		return nil, err
+	}
+	return motoristas, nil
+}
+
+// erroUnicidade traduz violações dos índices únicos de motoristas para os erros do
+// repositório. As mensagens trazem a coluna (SQLite: "motoristas.cpf") ou o nome do
+// índice (PostgreSQL: "motoristas_cpf_idx").
+func erroUnicidade(err error) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "motoristas.cpf_indice"), strings.Contains(msg, "motoristas_cpf_indice_idx"):
+		return ErrCPFDuplicado
+	case strings.Contains(msg, "motoristas.cnh_indice"), strings.Contains(msg, "motoristas_cnh_indice_idx"):
+		return ErrCNHDuplicada
+	case strings.Contains(msg, "motoristas.email_indice"), strings.Contains(msg, "motoristas_email_indice_idx"):
+		return ErrEmailDuplicado
+	case strings.Contains(msg, "motoristas.cpf"), strings.Contains(msg, "motoristas_cpf_idx"):
+		return ErrCPFDuplicado
+	case strings.Contains(msg, "motoristas.cnh"), strings.Contains(msg, "motoristas_cnh_idx"):
+		return ErrCNHDuplicada
+	case strings.Contains(msg, "motoristas.email"), strings.Contains(msg, "motoristas_email_idx"):
+		return ErrEmailDuplicado
+	case strings.Contains(msg, "motoristas.id"), strings.Contains(msg, "motoristas_pkey"):
+		return ErrMotoristaJaExiste
+	}
+	return err
+}
+
+// scanner é satisfeito por *sql.Row e *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// lerMotorista lê uma linha com as colunas de colunasMotorista, decifrando os dados sensíveis
+func lerMotorista(s scanner, c *cripto.Cifrador) (*models.Motorista, error) {
+	var (
+		m                     models.Motorista
+		d                     dadosSensiveis
+		nascimento            time.Time
+		exclusaoEm, ativadoEm sql.NullTime
+		indiceCPF, indiceCNH  sql.NullString
+		indiceEmail           sql.NullString
+	)
+	err := s.Scan(&m.ID, &m.Nome, &nascimento, &d.CPF, &d.CNH, &m.CategoriaCNH, &m.ValidadeCNH, &m.PlacaVeiculo,
+		&m.ModeloVeiculo, &d.Telefone, &d.Email, &m.EmailVerificado, &d.EmailPendente, &m.Senha, &m.Status, &m.StatusAnterior,
+		&exclusaoEm, &m.FotoPerfil, &m.CriadoEm, &m.AtualizadoEm, &m.DoisFatores.Ativo, &ativadoEm,
+		&d.SegredoDoisFatores, &m.DoisFatores.UltimoPasso, &d.DataNascimento, &indiceCPF, &indiceCNH,
+		&indiceEmail, &m.Versao)
+	if err != nil {
+		return nil, err
+	}
+	if exclusaoEm.Valid {
+		m.ExclusaoEm = &exclusaoEm.Time
+	}
+	if ativadoEm.Valid {
+		m.DoisFatores.AtivadoEm = &ativadoEm.Time
+	}
+	// Registros não cifrados mantêm a data na coluna data_nascimento
+	if d.DataNascimento == "" {
+		d.DataNascimento = nascimento.Format(time.RFC3339Nano)
+	}
+	d.IndiceCPF, d.IndiceCNH, d.IndiceEmail = indiceCPF.String, indiceCNH.String, indiceEmail.String
+	if err := d.decifrar(c, &m); err != nil {
+		return nil, err
+	}
+	m.Documentos = []models.Documento{}
+	return &m, nil
+}
+
+// valoresMotorista valores das colunas de colunasMotorista, exceto versao, com os dados
+// sensíveis cifrados. Com criptografia a coluna data_nascimento recebe a data zero e
+// o valor cifrado vai para data_nascimento_cifrada.
+func valoresMotorista(c *cripto.Cifrador, m *models.Motorista) ([]any, error) {
+	d, err := cifrarDados(c, m)
+	if err != nil {
+		return nil, err
+	}
+	nascimento, nascimentoCifrado := m.DataNascimento.UTC(), ""
+	if cripto.Cifrado(d.DataNascimento) {
+		nascimento, nascimentoCifrado = time.Time{}, d.DataNascimento
+	}
+	return []any{
+		m.ID, m.Nome, nascimento, d.CPF, d.CNH, string(m.CategoriaCNH), m.ValidadeCNH.UTC(), m.PlacaVeiculo,
+		m.ModeloVeiculo, d.Telefone, d.Email, m.EmailVerificado, d.EmailPendente, m.Senha, string(m.Status), string(m.StatusAnterior),
+		tempoOpcional(m.ExclusaoEm), m.FotoPerfil, m.CriadoEm.UTC(), m.AtualizadoEm.UTC(), m.DoisFatores.Ativo, tempoOpcional(m.DoisFatores.AtivadoEm),
+		d.SegredoDoisFatores, m.DoisFatores.UltimoPasso, nascimentoCifrado, textoOpcional(d.IndiceCPF), textoOpcional(d.IndiceCNH),
+		textoOpcional(d.IndiceEmail),
+	}, nil
+}
+
+// textoOpcional converte texto vazio para NULL, que não conflita nos índices únicos
+func textoOpcional(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// tempoOpcional converte ponteiros de data para NULL. As datas são gravadas sempre em UTC,
+// já que a coluna TIMESTAMP (comum a SQLite e PostgreSQL) não guarda fuso horário.
+func tempoOpcional(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// colunasDocumento colunas comuns a documentos e documentos_historico, na ordem usada
+// por inserirDocumento e lerDocumento
+const colunasDocumento = `id, tipo_documento, chave_arquivo, formato, tamanho, checksum, status,
+	motivo_rejeicao, observacao, revisado_por, revisado_em, versao, enviado_por, criado_em, substituido_em,
+	varredura, ameaca`
+
+func inserirDocumento(ctx context.Context, tx executor, tabela, motoristaID string, posicao int, d models.Documento) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO `+tabela+` (motorista_id, posicao, `+colunasDocumento+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		motoristaID, posicao, d.ID, d.TipoDocumento, d.ChaveArquivo, d.Formato, d.Tamanho, d.Checksum, d.Status,
+		d.MotivoRejeicao, d.Observacao, d.RevisadoPor, tempoOpcional(d.RevisadoEm), d.Versao, d.EnviadoPor,
+		d.CriadoEm.UTC(), tempoOpcional(d.SubstituidoEm), d.Varredura, d.Ameaca)
+	return err
+}
+
+// lerDocumento lê uma linha com motorista_id seguido de colunasDocumento
+func lerDocumento(rows *sql.Rows) (string, models.Documento, error) {
+	var motoristaID string
+	var d models.Documento
+	var revisadoEm, substituidoEm sql.NullTime
+	if err := rows.Scan(&motoristaID, &d.ID, &d.TipoDocumento, &d.ChaveArquivo, &d.Formato, &d.Tamanho, &d.Checksum, &d.Status,
+		&d.MotivoRejeicao, &d.Observacao, &d.RevisadoPor, &revisadoEm, &d.Versao, &d.EnviadoPor, &d.CriadoEm, &substituidoEm,
+		&d.Varredura, &d.Ameaca); err != nil {
+		return "", d, err
+	}
+	if revisadoEm.Valid {
+		d.RevisadoEm = &revisadoEm.Time
+	}
+	if substituidoEm.Valid {
+		d.SubstituidoEm = &substituidoEm.Time
+	}
+	return motoristaID, d, nil
+}
+
+// inserirFilhos grava documentos, revisões e códigos de recuperação do motorista e as
+// novas entradas do histórico de documentos
+func inserirFilhos(ctx context.Context, tx executor, m *models.Motorista) error {
+	for i, d := range m.Documentos {
+		if err := inserirDocumento(ctx, tx, "documentos", m.ID, i, d); err != nil {
+			return fmt.Errorf("erro ao inserir documento: %w", err)
+		}
+	}
+	if err := gravarHistorico(ctx, tx, m); err != nil {
+		return err
+	}
+	for i, rev := range m.Revisoes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO revisoes
+			(motorista_id, posicao, acao, operador_id, documento, codigo_motivo, motivo, data)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			m.ID, i, rev.Acao, rev.OperadorID, rev.Documento, rev.CodigoMotivo, rev.Motivo, rev.Data.UTC()); err != nil {
+			return fmt.Errorf("erro ao inserir revisão: %w", err)
+		}
+	}
+	for _, hash := range m.DoisFatores.CodigosRecuperacao {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO codigos_recuperacao_2fa (motorista_id, hash) VALUES ($1, $2)`,
+			m.ID, hash); err != nil {
+			return fmt.Errorf("erro ao inserir código de recuperação: %w", err)
+		}
+	}
+	return nil
+}
+
+// gravarHistorico insere as entradas de m.Historico ainda não gravadas. As já gravadas
+// não podem ter sido removidas nem substituídas (ErrHistoricoAlterado).
+func gravarHistorico(ctx context.Context, tx executor, m *models.Motorista) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM documentos_historico WHERE motorista_id = $1 ORDER BY posicao`, m.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar histórico de documentos: %w", err)
+	}
+	defer rows.Close()
+	gravados := 0
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("erro ao ler histórico de documentos: %w", err)
+		}
+		if gravados >= len(m.Historico) || m.Historico[gravados].ID != id {
+			return ErrHistoricoAlterado
+		}
+		gravados++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao consultar histórico de documentos: %w", err)
+	}
+	rows.Close()
+	for i := gravados; i < len(m.Historico); i++ {
+		if err := inserirDocumento(ctx, tx, "documentos_historico", m.ID, i, m.Historico[i]); err != nil {
+			return fmt.Errorf("erro ao inserir histórico de documentos: %w", err)
+		}
+	}
+	return nil
+}
+
+// carregarFilhos preenche documentos, histórico, revisões e códigos de recuperação dos motoristas informados
+func carregarFilhos(ctx context.Context, q executor, porID map[string]*models.Motorista, filtro string, args []any) error {
+	for _, tabela := range []string{"documentos", "documentos_historico"} {
+		rows, err := q.QueryContext(ctx, `SELECT motorista_id, `+colunasDocumento+`
+			FROM `+tabela+filtro+` ORDER BY motorista_id, posicao`, args...)
+		if err != nil {
+			return fmt.Errorf("erro ao consultar %s: %w", tabela, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			motoristaID, d, err := lerDocumento(rows)
+			if err != nil {
+				return fmt.Errorf("erro ao ler documento: %w", err)
+			}
+			if m, ok := porID[motoristaID]; ok {
+				if tabela == "documentos" {
+					m.Documentos = append(m.Documentos, d)
+				} else {
+					m.Historico = append(m.Historico, d)
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("erro ao consultar %s: %w", tabela, err)
+		}
+		rows.Close()
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT motorista_id, acao, operador_id, documento, codigo_motivo, motivo, data
+		FROM revisoes`+filtro+` ORDER BY motorista_id, posicao`, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar revisões: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var motoristaID string
+		var rev models.Revisao
+		if err := rows.Scan(&motoristaID, &rev.Acao, &rev.OperadorID, &rev.Documento, &rev.CodigoMotivo, &rev.Motivo, &rev.Data); err != nil {
+			return fmt.Errorf("erro ao ler revisão: %w", err)
+		}
+		if m, ok := porID[motoristaID]; ok {
+			m.Revisoes = append(m.Revisoes, rev)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao consultar revisões: %w", err)
+	}
+	rows.Close()
+
+	rows, err = q.QueryContext(ctx, `SELECT motorista_id, hash FROM codigos_recuperacao_2fa`+filtro, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar códigos de recuperação: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var motoristaID, hash string
+		if err := rows.Scan(&motoristaID, &hash); err != nil {
+			return fmt.Errorf("erro ao ler código de recuperação: %w", err)
+		}
+		if m, ok := porID[motoristaID]; ok {
+			m.DoisFatores.CodigosRecuperacao = append(m.DoisFatores.CodigosRecuperacao, hash)
+		}
+	}
+	return rows.Err()
+}
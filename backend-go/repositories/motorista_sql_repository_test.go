@@ -0,0 +1,359 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/models"
+)
+
+func TestSQLiteMotoristaRepository(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "taxi_service.db")
+	repo, err := NewSQLiteMotoristaRepository(caminho, nil)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	testarSQLMotoristaRepository(t, repo)
+
+	t.Run("Pesquisa por prefixo usa os índices NOCASE", func(t *testing.T) {
+		casos := map[string]string{
+			"nome":          "motoristas_nome_prefixo_idx",
+			"placa_veiculo": "motoristas_placa_prefixo_idx",
+		}
+		for coluna, indice := range casos {
+			rows, err := repo.db.Query(`EXPLAIN QUERY PLAN SELECT id FROM motoristas WHERE `+
+				repo.colunaPrefixo("LOWER", coluna)+` LIKE $1 ESCAPE '\'`, "ana%")
+			require.NoError(t, err)
+			var plano []string
+			for rows.Next() {
+				var id, pai, livre int
+				var detalhe string
+				require.NoError(t, rows.Scan(&id, &pai, &livre, &detalhe))
+				plano = append(plano, detalhe)
+			}
+			require.NoError(t, rows.Close())
+			assert.Contains(t, strings.Join(plano, "\n"), indice, coluna)
+		}
+	})
+
+	t.Run("Migrações específicas do dialeto", func(t *testing.T) {
+		sqlite, err := Migracoes(DialetoSQLite)
+		require.NoError(t, err)
+		postgres, err := Migracoes(DialetoPostgres)
+		require.NoError(t, err)
+		require.Equal(t, len(sqlite), len(postgres))
+		ultimaSQLite, ultimaPostgres := sqlite[len(sqlite)-1], postgres[len(postgres)-1]
+		assert.Equal(t, "indices_prefixo", ultimaSQLite.Descricao)
+		assert.Equal(t, ultimaSQLite.Versao, ultimaPostgres.Versao)
+		assert.Contains(t, ultimaSQLite.SQL, "COLLATE NOCASE")
+		assert.Contains(t, ultimaPostgres.SQL, "text_pattern_ops")
+	})
+
+	t.Run("Reabrir o banco não reaplica migrações", func(t *testing.T) {
+		repo.Close()
+		reaberto, err := NewSQLiteMotoristaRepository(caminho, nil)
+		require.NoError(t, err)
+		defer reaberto.Close()
+
+		migracoes, err := Migracoes(DialetoSQLite)
+		require.NoError(t, err)
+		aplicadas, err := ExecutarMigracoes(context.Background(), reaberto.db, migracoes)
+		require.NoError(t, err)
+		assert.Equal(t, 0, aplicadas)
+	})
+}
+
+func TestSQLiteMigracaoChaveArquivo(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "legado.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// Banco criado antes do FileStore, com caminhos em disco
+	migracoes, err := Migracoes(DialetoSQLite)
+	require.NoError(t, err)
+	_, err = ExecutarMigracoes(context.Background(), db, migracoes[:3])
+	require.NoError(t, err)
+	agora := time.Now().UTC()
+	_, err = db.Exec(`INSERT INTO motoristas (id, nome, data_nascimento, cpf, cnh, categoria_cnh, validade_cnh,
+		placa_veiculo, modelo_veiculo, telefone, email, senha, status, foto_perfil, criado_em, atualizado_em)
+		VALUES ('m1', 'Ana', $1, '1', '1', 'B', $1, 'ABC1234', 'Civic', '11', 'a@email.com', 'hash', 'aprovado', 'data/m1/profile/foto.png', $1, $1)`, agora)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO documentos (motorista_id, posicao, id, tipo_documento, caminho_arquivo, formato, tamanho, status, criado_em)
+		VALUES ('m1', 0, 'd1', 'CNH', 'data/m1/CNH.pdf', 'PDF', 10, 'pendente', $1)`, agora)
+	require.NoError(t, err)
+
+	_, err = ExecutarMigracoes(context.Background(), db, migracoes)
+	require.NoError(t, err)
+	m, err := (&SQLMotoristaRepository{db: db}).BuscarPorID("m1")
+	require.NoError(t, err)
+	assert.Equal(t, "m1/profile/foto.png", m.FotoPerfil)
+	require.Len(t, m.Documentos, 1)
+	assert.Equal(t, "m1/CNH.pdf", m.Documentos[0].ChaveArquivo)
+}
+
+// testarSQLMotoristaRepository exercita o repositório SQL independente do banco
+func testarSQLMotoristaRepository(t *testing.T, repo *SQLMotoristaRepository) {
+	agora := time.Now().UTC().Truncate(time.Microsecond)
+	ativadoEm := agora.Add(-time.Hour)
+	novoMotorista := func(id, cpf, cnh, email string) *models.Motorista {
+		return &models.Motorista{
+			ID:             id,
+			Nome:           "João Silva",
+			DataNascimento: time.Date(1990, 3, 15, 0, 0, 0, 0, time.UTC),
+			CPF:            cpf,
+			CNH:            cnh,
+			CategoriaCNH:   models.CategoriaB,
+			ValidadeCNH:    time.Date(2030, 3, 15, 0, 0, 0, 0, time.UTC),
+			PlacaVeiculo:   "ABC1234",
+			ModeloVeiculo:  "Honda Civic 2020",
+			Telefone:       "11999999999",
+			Email:          email,
+			Senha:          "hash-da-senha",
+			Status:         models.StatusAguardandoAprovacao,
+			CriadoEm:       agora,
+			AtualizadoEm:   agora,
+			Documentos: []models.Documento{
+				{ID: "doc-1", TipoDocumento: "CNH", ChaveArquivo: id + "/cnh.pdf", Formato: "PDF", Tamanho: 1024, Checksum: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", Status: models.DocumentoStatusPendente, CriadoEm: agora},
+				{ID: "doc-2", TipoDocumento: "CRLV", ChaveArquivo: id + "/crlv.pdf", Formato: "PDF", Tamanho: 2048, Status: models.DocumentoStatusPendente, CriadoEm: agora},
+			},
+			DoisFatores: models.DoisFatores{Ativo: true, AtivadoEm: &ativadoEm, Segredo: "JBSWY3DPEHPK3PXP", CodigosRecuperacao: []string{"hash-1", "hash-2"}, UltimoPasso: 42},
+		}
+	}
+
+	t.Run("Criar e buscar por ID, email, CPF e CNH", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoMotorista("1", "12345678909", "12345678901", "joao.silva@email.com")))
+
+		buscas := map[string]func() (*models.Motorista, error){
+			"id":    func() (*models.Motorista, error) { return repo.BuscarPorID("1") },
+			"email": func() (*models.Motorista, error) { return repo.BuscarPorEmail("joao.silva@email.com") },
+			"cpf":   func() (*models.Motorista, error) { return repo.BuscarPorCPF("12345678909") },
+			"cnh":   func() (*models.Motorista, error) { return repo.BuscarPorCNH("12345678901") },
+		}
+		for nome, buscar := range buscas {
+			m, err := buscar()
+			require.NoError(t, err, nome)
+			assert.Equal(t, "1", m.ID, nome)
+		}
+
+		m, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, "hash-da-senha", m.Senha)
+		assert.True(t, m.DataNascimento.Equal(time.Date(1990, 3, 15, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, m.CriadoEm.Equal(agora))
+		require.Len(t, m.Documentos, 2)
+		assert.Equal(t, "CNH", m.Documentos[0].TipoDocumento)
+		assert.Equal(t, "CRLV", m.Documentos[1].TipoDocumento)
+		assert.Equal(t, int64(2048), m.Documentos[1].Tamanho)
+		assert.True(t, m.DoisFatores.Ativo)
+		require.NotNil(t, m.DoisFatores.AtivadoEm)
+		assert.True(t, m.DoisFatores.AtivadoEm.Equal(ativadoEm))
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", m.DoisFatores.Segredo)
+		assert.ElementsMatch(t, []string{"hash-1", "hash-2"}, m.DoisFatores.CodigosRecuperacao)
+		assert.Equal(t, int64(42), m.DoisFatores.UltimoPasso)
+		assert.Nil(t, m.ExclusaoEm)
+	})
+
+	t.Run("Índices únicos de CPF, CNH e email", func(t *testing.T) {
+		assert.ErrorIs(t, repo.Criar(novoMotorista("2", "12345678909", "99999999999", "outro@email.com")), ErrCPFDuplicado)
+		assert.ErrorIs(t, repo.Criar(novoMotorista("2", "98765432100", "12345678901", "outro@email.com")), ErrCNHDuplicada)
+		assert.ErrorIs(t, repo.Criar(novoMotorista("2", "98765432100", "99999999999", "joao.silva@email.com")), ErrEmailDuplicado)
+		assert.ErrorIs(t, repo.Criar(novoMotorista("1", "98765432100", "99999999999", "outro@email.com")), ErrMotoristaJaExiste)
+
+		// Falhas não deixam registros parciais
+		motoristas, err := repo.ListarTodos()
+		require.NoError(t, err)
+		assert.Len(t, motoristas, 1)
+	})
+
+	t.Run("Atualizar substitui documentos e revisões", func(t *testing.T) {
+		m, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		exclusaoEm := agora.Add(72 * time.Hour)
+		m.Nome = "João Silva Santos"
+		m.Status = models.StatusEncerrado
+		m.StatusAnterior = models.StatusAprovado
+		m.ExclusaoEm = &exclusaoEm
+		m.Documentos = m.Documentos[1:]
+		m.Documentos[0].Status = models.DocumentoStatusAprovado
+		m.Revisoes = []models.Revisao{{Acao: models.RevisaoAprovado, OperadorID: "op-1", Data: agora}}
+		m.DoisFatores.CodigosRecuperacao = []string{"hash-2"}
+		require.NoError(t, repo.Atualizar(m))
+
+		atualizado, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva Santos", atualizado.Nome)
+		assert.Equal(t, models.StatusEncerrado, atualizado.Status)
+		assert.Equal(t, models.StatusAprovado, atualizado.StatusAnterior)
+		require.NotNil(t, atualizado.ExclusaoEm)
+		assert.True(t, atualizado.ExclusaoEm.Equal(exclusaoEm))
+		require.Len(t, atualizado.Documentos, 1)
+		assert.Equal(t, models.DocumentoStatusAprovado, atualizado.Documentos[0].Status)
+		require.Len(t, atualizado.Revisoes, 1)
+		assert.Equal(t, "op-1", atualizado.Revisoes[0].OperadorID)
+		assert.Equal(t, []string{"hash-2"}, atualizado.DoisFatores.CodigosRecuperacao)
+
+		assert.ErrorIs(t, repo.Atualizar(&models.Motorista{ID: "999"}), ErrMotoristaNaoEncontrado)
+	})
+
+	t.Run("Revisão por documento é persistida", func(t *testing.T) {
+		m, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		revisadoEm := agora.Add(time.Hour)
+		m.Documentos[0].Status = models.DocumentoStatusRejeitado
+		m.Documentos[0].MotivoRejeicao = models.MotivoIlegivel
+		m.Documentos[0].Observacao = "Foto desfocada"
+		m.Documentos[0].RevisadoPor = "op-2"
+		m.Documentos[0].RevisadoEm = &revisadoEm
+		m.Revisoes = append(m.Revisoes, models.Revisao{Acao: models.RevisaoDocumentoRejeitado, OperadorID: "op-2",
+			Documento: "CRLV", CodigoMotivo: models.MotivoIlegivel, Motivo: "Foto desfocada", Data: revisadoEm})
+		require.NoError(t, repo.Atualizar(m))
+
+		atualizado, err := repo.BuscarPorID("1")
+		require.NoError(t, err)
+		doc := atualizado.Documentos[0]
+		assert.Equal(t, models.DocumentoStatusRejeitado, doc.Status)
+		assert.Equal(t, models.MotivoIlegivel, doc.MotivoRejeicao)
+		assert.Equal(t, "Foto desfocada", doc.Observacao)
+		assert.Equal(t, "op-2", doc.RevisadoPor)
+		require.NotNil(t, doc.RevisadoEm)
+		assert.True(t, doc.RevisadoEm.Equal(revisadoEm))
+		require.Len(t, atualizado.Revisoes, 2)
+		assert.Equal(t, "CRLV", atualizado.Revisoes[1].Documento)
+		assert.Equal(t, models.MotivoIlegivel, atualizado.Revisoes[1].CodigoMotivo)
+	})
+
+	t.Run("Listar todos carrega os dados de cada motorista", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoMotorista("2", "98765432100", "99999999999", "maria@email.com")))
+
+		// Atualizar para um CPF de outro motorista viola a unicidade
+		m, err := repo.BuscarPorID("2")
+		require.NoError(t, err)
+		m.CPF = "12345678909"
+		assert.ErrorIs(t, repo.Atualizar(m), ErrCPFDuplicado)
+
+		motoristas, err := repo.ListarTodos()
+		require.NoError(t, err)
+		require.Len(t, motoristas, 2)
+		for _, m := range motoristas {
+			switch m.ID {
+			case "1":
+				assert.Len(t, m.Documentos, 1)
+			case "2":
+				assert.Len(t, m.Documentos, 2)
+				assert.Empty(t, m.Revisoes)
+			}
+		}
+	})
+
+	t.Run("Versão é incrementada a cada atualização e cópias antigas são recusadas", func(t *testing.T) {
+		testarVersaoMotorista(t, repo, "2")
+	})
+
+	t.Run("WithTx desfaz todas as escritas quando fn falha", func(t *testing.T) {
+		falha := errors.New("falha")
+		err := repo.WithTx(func(tx MotoristaTx) error {
+			m, err := tx.BuscarPorID("2")
+			require.NoError(t, err)
+			m.Nome = "Maria Alterada"
+			require.NoError(t, tx.Atualizar(m))
+			require.NoError(t, tx.Deletar("1"))
+			return falha
+		})
+		assert.ErrorIs(t, err, falha)
+
+		m, err := repo.BuscarPorID("2")
+		require.NoError(t, err)
+		assert.Equal(t, "João Silva", m.Nome)
+		_, err = repo.BuscarPorID("1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Atualizações concorrentes via WithTx não se perdem", func(t *testing.T) {
+		testarAtualizacoesConcorrentes(t, repo, "2")
+	})
+
+	t.Run("Deletar remove o motorista e seus documentos", func(t *testing.T) {
+		require.NoError(t, repo.Deletar("1"))
+		_, err := repo.BuscarPorID("1")
+		assert.ErrorIs(t, err, ErrMotoristaNaoEncontrado)
+		assert.ErrorIs(t, repo.Deletar("1"), ErrMotoristaNaoEncontrado)
+
+		var documentos int
+		require.NoError(t, repo.db.QueryRow(`SELECT COUNT(*) FROM documentos WHERE motorista_id = $1`, "1").Scan(&documentos))
+		assert.Zero(t, documentos)
+
+		// Os dados do motorista deletado podem ser reutilizados
+		require.NoError(t, repo.Criar(novoMotorista("3", "12345678909", "12345678901", "joao.silva@email.com")))
+	})
+}
+
+// testarAtualizacoesConcorrentes dispara várias unidades de trabalho que acrescentam uma
+// revisão ao mesmo motorista; nenhuma delas pode sobrescrever as demais
+func testarAtualizacoesConcorrentes(t *testing.T, repo MotoristaRepository, id string) {
+	antes, err := repo.BuscarPorID(id)
+	require.NoError(t, err)
+
+	const total = 10
+	var wg sync.WaitGroup
+	erros := make(chan error, total)
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			erros <- repo.WithTx(func(tx MotoristaTx) error {
+				m, err := tx.BuscarPorID(id)
+				if err != nil {
+					return err
+				}
+				m.Revisoes = append(m.Revisoes, models.Revisao{Acao: models.RevisaoRejeitado, OperadorID: fmt.Sprintf("op-%d", i), Data: time.Now().UTC()})
+				return tx.Atualizar(m)
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(erros)
+	for err := range erros {
+		require.NoError(t, err)
+	}
+
+	depois, err := repo.BuscarPorID(id)
+	require.NoError(t, err)
+	assert.Len(t, depois.Revisoes, len(antes.Revisoes)+total)
+}
+
+// testarVersaoMotorista verifica o controle de concorrência otimista de Atualizar
+func testarVersaoMotorista(t *testing.T, repo MotoristaRepository, id string) {
+	lido, err := repo.BuscarPorID(id)
+	require.NoError(t, err)
+	obsoleto, err := repo.BuscarPorID(id)
+	require.NoError(t, err)
+	versao := lido.Versao
+
+	lido.Telefone = "11911112222"
+	require.NoError(t, repo.Atualizar(lido))
+	assert.Equal(t, versao+1, lido.Versao)
+
+	obsoleto.Telefone = "11933334444"
+	assert.ErrorIs(t, repo.Atualizar(obsoleto), apperrors.ErrConflitoVersao)
+
+	atual, err := repo.BuscarPorID(id)
+	require.NoError(t, err)
+	assert.Equal(t, versao+1, atual.Versao)
+	assert.Equal(t, "11911112222", atual.Telefone)
+
+	// A cópia atualizada pode ser gravada novamente
+	require.NoError(t, repo.Atualizar(lido))
+	assert.Equal(t, versao+2, lido.Versao)
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"taxi_service/internal/cripto"
+
+	_ "modernc.org/sqlite" // driver "sqlite" em Go puro (sem cgo)
+)
+
+// NewSQLiteMotoristaRepository abre (ou cria) o banco SQLite no caminho informado
+// e aplica as migrações pendentes.
+func NewSQLiteMotoristaRepository(caminho string, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(caminho), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	// Chaves estrangeiras ficam desligadas por padrão no SQLite; sem elas Deletar não remove os filhos
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	db, err := sql.Open("sqlite", "file:"+caminho+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir banco SQLite: %w", err)
+	}
+	// O SQLite aceita um único escritor; uma conexão evita erros de banco bloqueado
+	db.SetMaxOpenConns(1)
+
+	repo, err := NewSQLMotoristaRepository(db, DialetoSQLite, cifrador)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// NewSQLiteMotoristaRepositoryLeitura abre um banco SQLite existente apenas para leitura,
+// sem criar o arquivo nem aplicar migrações (ver NewSQLMotoristaRepositoryLeitura)
+func NewSQLiteMotoristaRepositoryLeitura(caminho string, cifrador *cripto.Cifrador) (*SQLMotoristaRepository, error) {
+	if _, err := os.Stat(caminho); err != nil {
+		return nil, fmt.Errorf("erro ao abrir banco SQLite: %w", err)
+	}
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Add("_pragma", "busy_timeout(5000)")
+	db, err := sql.Open("sqlite", "file:"+caminho+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir banco SQLite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	repo, err := NewSQLMotoristaRepositoryLeitura(db, DialetoSQLite, cifrador)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
@@ -0,0 +1,223 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"taxi_service/models"
+)
+
+// OperadorTx operações sobre operadores disponíveis dentro de uma unidade de trabalho
+type OperadorTx interface {
+	BuscarPorID(id string) (*models.Operador, error)
+	Atualizar(operador *models.Operador) error
+}
+
+// OperadorRepository define a interface para operações com operadores
+type OperadorRepository interface {
+	Criar(operador *models.Operador) error
+	BuscarPorID(id string) (*models.Operador, error)
+	BuscarPorEmail(email string) (*models.Operador, error)
+	Atualizar(operador *models.Operador) error
+	ListarTodos() ([]*models.Operador, error)
+	// WithTx executa fn como uma unidade de trabalho: nenhuma outra escrita ocorre entre
+	// as leituras e escritas feitas por tx, que só são persistidas se fn retornar nil.
+	// fn não deve ter efeitos externos; dentro dela use apenas tx.
+	WithTx(fn func(tx OperadorTx) error) error
+}
+
+// JSONOperadorRepository implementa OperadorRepository usando arquivo JSON
+type JSONOperadorRepository struct {
+	filePath string
+	mutex    sync.RWMutex
+}
+
+// NewJSONOperadorRepository cria uma nova instância do repositório
+func NewJSONOperadorRepository() *JSONOperadorRepository {
+	return &JSONOperadorRepository{
+		filePath: "./data/operadores.json",
+	}
+}
+
+// registroOperador é o formato persistido (inclui o hash da senha e os segredos de 2FA)
+type registroOperador struct {
+	models.Operador
+	Senha               string              `json:"senha"`
+	SegredosDoisFatores registroDoisFatores `json:"segredos_2fa"`
+}
+
+// lerOperadores lê todos os operadores do arquivo JSON
+func (r *JSONOperadorRepository) lerOperadores() ([]*models.Operador, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.carregar()
+}
+
+// carregar lê o arquivo; o chamador deve manter o mutex
+func (r *JSONOperadorRepository) carregar() ([]*models.Operador, error) {
+	data, err := os.ReadFile(r.filePath)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return []*models.Operador{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+
+	var registros []registroOperador
+	if err := json.Unmarshal(data, &registros); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
+	}
+
+	operadores := make([]*models.Operador, 0, len(registros))
+	for i := range registros {
+		o := registros[i].Operador
+		o.Senha = registros[i].Senha
+		registros[i].SegredosDoisFatores.aplicar(&o.DoisFatores)
+		operadores = append(operadores, &o)
+	}
+	return operadores, nil
+}
+
+// salvarOperadores salva todos os operadores no arquivo JSON
+func (r *JSONOperadorRepository) salvarOperadores(operadores []*models.Operador) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.gravar(operadores)
+}
+
+// gravar substitui o arquivo; o chamador deve manter o mutex
+func (r *JSONOperadorRepository) gravar(operadores []*models.Operador) error {
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	registros := make([]registroOperador, 0, len(operadores))
+	for _, o := range operadores {
+		registros = append(registros, registroOperador{Operador: *o, Senha: o.Senha, SegredosDoisFatores: novoRegistroDoisFatores(o.DoisFatores)})
+	}
+
+	data, err := json.MarshalIndent(registros, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+
+	if err := escreverArquivoAtomico(r.filePath, data, 0600, 0); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
+
+// Criar adiciona um novo operador
+func (r *JSONOperadorRepository) Criar(operador *models.Operador) error {
+	operadores, err := r.lerOperadores()
+	if err != nil {
+		return err
+	}
+
+	for _, o := range operadores {
+		if o.ID == operador.ID {
+			return errors.New("operador com este ID já existe")
+		}
+		if o.Email == operador.Email {
+			return errors.New("operador com este email já existe")
+		}
+	}
+
+	operadores = append(operadores, operador)
+	return r.salvarOperadores(operadores)
+}
+
+// BuscarPorID busca um operador por ID
+func (r *JSONOperadorRepository) BuscarPorID(id string) (*models.Operador, error) {
+	operadores, err := r.lerOperadores()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range operadores {
+		if o.ID == id {
+			return o, nil
+		}
+	}
+	return nil, errors.New("operador não encontrado")
+}
+
+// BuscarPorEmail busca um operador por email
+func (r *JSONOperadorRepository) BuscarPorEmail(email string) (*models.Operador, error) {
+	operadores, err := r.lerOperadores()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range operadores {
+		if o.Email == email {
+			return o, nil
+		}
+	}
+	return nil, errors.New("operador não encontrado")
+}
+
+// Atualizar atualiza um operador existente
+func (r *JSONOperadorRepository) Atualizar(operador *models.Operador) error {
+	return r.WithTx(func(tx OperadorTx) error { return tx.Atualizar(operador) })
+}
+
+// WithTx mantém o mutex de escrita da leitura até a gravação do arquivo
+func (r *JSONOperadorRepository) WithTx(fn func(tx OperadorTx) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	operadores, err := r.carregar()
+	if err != nil {
+		return err
+	}
+	tx := &jsonOperadorTx{operadores: operadores}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.alterado {
+		return nil
+	}
+	return r.gravar(tx.operadores)
+}
+
+// jsonOperadorTx opera sobre os operadores carregados por WithTx. Leituras devolvem
+// cópias, para que alterações só tenham efeito via Atualizar.
+type jsonOperadorTx struct {
+	operadores []*models.Operador
+	alterado   bool
+}
+
+// BuscarPorID busca um operador por ID
+func (t *jsonOperadorTx) BuscarPorID(id string) (*models.Operador, error) {
+	for _, o := range t.operadores {
+		if o.ID == id {
+			copia := *o
+			copia.DoisFatores.CodigosRecuperacao = slices.Clone(o.DoisFatores.CodigosRecuperacao)
+			return &copia, nil
+		}
+	}
+	return nil, errors.New("operador não encontrado")
+}
+
+// Atualizar substitui o operador com o mesmo ID
+func (t *jsonOperadorTx) Atualizar(operador *models.Operador) error {
+	for i, o := range t.operadores {
+		if o.ID == operador.ID {
+			t.operadores[i] = operador
+			t.alterado = true
+			return nil
+		}
+	}
+	return errors.New("operador não encontrado")
+}
+
+// ListarTodos retorna todos os operadores
+func (r *JSONOperadorRepository) ListarTodos() ([]*models.Operador, error) {
+	return r.lerOperadores()
+}
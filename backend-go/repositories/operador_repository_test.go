@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/models"
+)
+
+func TestJSONOperadorRepository(t *testing.T) {
+	tempFile := "./data/test_operadores.json"
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONOperadorRepository{filePath: tempFile}
+
+	t.Run("Criar operador", func(t *testing.T) {
+		err := repo.Criar(&models.Operador{
+			ID:       "op-1",
+			Nome:     "Ana Revisora",
+			Email:    "ana@taxiservice.com",
+			Senha:    "$2a$10$hash",
+			Papel:    models.PapelRevisor,
+			Ativo:    true,
+			CriadoEm: time.Now(),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Buscar operador por email preserva hash da senha", func(t *testing.T) {
+		o, err := repo.BuscarPorEmail("ana@taxiservice.com")
+		require.NoError(t, err)
+		assert.Equal(t, "op-1", o.ID)
+		assert.Equal(t, models.PapelRevisor, o.Papel)
+		assert.Equal(t, "$2a$10$hash", o.Senha)
+	})
+
+	t.Run("Erro ao criar operador com email duplicado", func(t *testing.T) {
+		err := repo.Criar(&models.Operador{ID: "op-2", Email: "ana@taxiservice.com"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Atualizar operador", func(t *testing.T) {
+		o, err := repo.BuscarPorID("op-1")
+		require.NoError(t, err)
+		o.Ativo = false
+		require.NoError(t, repo.Atualizar(o))
+
+		o, err = repo.BuscarPorID("op-1")
+		require.NoError(t, err)
+		assert.False(t, o.Ativo)
+	})
+
+	t.Run("Erro ao buscar operador inexistente", func(t *testing.T) {
+		_, err := repo.BuscarPorID("999")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "operador não encontrado")
+	})
+}
+
+func TestJSONOperadorRepositoryWithTx(t *testing.T) {
+	repo := &JSONOperadorRepository{filePath: filepath.Join(t.TempDir(), "operadores.json")}
+	require.NoError(t, repo.Criar(&models.Operador{
+		ID: "op-1", Email: "ana@taxiservice.com", Ativo: true,
+		DoisFatores: models.DoisFatores{Ativo: true, CodigosRecuperacao: []string{"hash-1", "hash-2"}},
+	}))
+
+	// Requisições paralelas com o mesmo código de recuperação: só uma o consome
+	var wg sync.WaitGroup
+	var aceitos atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.WithTx(func(tx OperadorTx) error {
+				o, err := tx.BuscarPorID("op-1")
+				if err != nil {
+					return err
+				}
+				i := slices.Index(o.DoisFatores.CodigosRecuperacao, "hash-1")
+				if i < 0 {
+					return errors.New("código já usado")
+				}
+				o.DoisFatores.CodigosRecuperacao = slices.Delete(o.DoisFatores.CodigosRecuperacao, i, i+1)
+				return tx.Atualizar(o)
+			})
+			if err == nil {
+				aceitos.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), aceitos.Load())
+
+	o, err := repo.BuscarPorID("op-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hash-2"}, o.DoisFatores.CodigosRecuperacao)
+
+	t.Run("Erro em fn descarta as alterações", func(t *testing.T) {
+		err := repo.WithTx(func(tx OperadorTx) error {
+			o, err := tx.BuscarPorID("op-1")
+			require.NoError(t, err)
+			o.Ativo = false
+			require.NoError(t, tx.Atualizar(o))
+			return errors.New("falha")
+		})
+		assert.Error(t, err)
+		o, err := repo.BuscarPorID("op-1")
+		require.NoError(t, err)
+		assert.True(t, o.Ativo)
+	})
+}
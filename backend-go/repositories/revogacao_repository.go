@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONRevogacaoRepository implementa auth.RevogacaoStore usando arquivo JSON.
+// As gerações ficam em memória após a primeira leitura, já que são consultadas a cada requisição.
+type JSONRevogacaoRepository struct {
+	filePath string
+	mutex    sync.Mutex
+	geracoes map[string]int
+}
+
+// NewJSONRevogacaoRepository cria uma nova instância do repositório
+func NewJSONRevogacaoRepository() *JSONRevogacaoRepository {
+	return &JSONRevogacaoRepository{
+		filePath: "./data/revogacoes.json",
+	}
+}
+
+// carregar lê o arquivo na primeira chamada (chamador deve segurar o mutex)
+func (r *JSONRevogacaoRepository) carregar() error {
+	if r.geracoes != nil {
+		return nil
+	}
+	geracoes := map[string]int{}
+	data, err := os.ReadFile(r.filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &geracoes); err != nil {
+			return fmt.Errorf("erro ao deserializar dados: %w", err)
+		}
+	}
+	r.geracoes = geracoes
+	return nil
+}
+
+// Geracao retorna a geração atual de tokens do subject
+func (r *JSONRevogacaoRepository) Geracao(subject string) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return 0, err
+	}
+	return r.geracoes[subject], nil
+}
+
+// Revogar incrementa a geração do subject, invalidando os tokens emitidos até agora
+func (r *JSONRevogacaoRepository) Revogar(subject string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+	r.geracoes[subject]++
+
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	data, err := json.MarshalIndent(r.geracoes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+	if err := escreverArquivoAtomico(r.filePath, data, 0600, 0); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"taxi_service/models"
+)
+
+// SessaoRepository define a interface para as sessões (dispositivos logados)
+type SessaoRepository interface {
+	Criar(sessao *models.Sessao) error
+	BuscarPorID(id string) (*models.Sessao, error)
+	Atualizar(sessao *models.Sessao) error
+	// RotacionarRefresh grava a sessão apenas se ela continuar ativa com o refresh
+	// refreshAnterior (compare-and-swap); false indica que outra renovação já o trocou
+	RotacionarRefresh(sessao *models.Sessao, refreshAnterior string) (bool, error)
+	ListarPorSubject(subject string) ([]*models.Sessao, error)
+	RevogarPorSubject(subject, exceto string, agora time.Time) error
+	SessaoAtiva(id string) (bool, error)
+}
+
+// ErrSessaoNaoEncontrada é retornado quando a sessão não existe
+var ErrSessaoNaoEncontrada = errors.New("sessão não encontrada")
+
+// JSONSessaoRepository implementa SessaoRepository (e auth.SessaoStore) usando arquivo JSON.
+// As sessões ficam em memória após a primeira leitura, já que SessaoAtiva é consultada a cada requisição.
+type JSONSessaoRepository struct {
+	filePath string
+	mutex    sync.Mutex
+	sessoes  map[string]*models.Sessao
+}
+
+// NewJSONSessaoRepository cria uma nova instância do repositório
+func NewJSONSessaoRepository() *JSONSessaoRepository {
+	return &JSONSessaoRepository{
+		filePath: "./data/sessoes.json",
+	}
+}
+
+// carregar lê o arquivo na primeira chamada (chamador deve segurar o mutex)
+func (r *JSONSessaoRepository) carregar() error {
+	if r.sessoes != nil {
+		return nil
+	}
+	var lista []*models.Sessao
+	data, err := os.ReadFile(r.filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &lista); err != nil {
+			return fmt.Errorf("erro ao deserializar dados: %w", err)
+		}
+	}
+	r.sessoes = make(map[string]*models.Sessao, len(lista))
+	for _, s := range lista {
+		r.sessoes[s.ID] = s
+	}
+	return nil
+}
+
+// salvar grava todas as sessões no arquivo (chamador deve segurar o mutex)
+func (r *JSONSessaoRepository) salvar() error {
+	lista := make([]*models.Sessao, 0, len(r.sessoes))
+	for _, s := range r.sessoes {
+		lista = append(lista, s)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].CriadaEm.Before(lista[j].CriadaEm) })
+
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	data, err := json.MarshalIndent(lista, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+	if err := escreverArquivoAtomico(r.filePath, data, 0600, 0); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
+
+// Criar adiciona uma nova sessão, descartando as que já expiraram
+func (r *JSONSessaoRepository) Criar(sessao *models.Sessao) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+	agora := time.Now()
+	for id, s := range r.sessoes {
+		if !agora.Before(s.ExpiraEm) {
+			delete(r.sessoes, id)
+		}
+	}
+	copia := *sessao
+	r.sessoes[sessao.ID] = &copia
+	return r.salvar()
+}
+
+// BuscarPorID busca uma sessão pelo ID
+func (r *JSONSessaoRepository) BuscarPorID(id string) (*models.Sessao, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+	s, ok := r.sessoes[id]
+	if !ok {
+		return nil, ErrSessaoNaoEncontrada
+	}
+	copia := *s
+	return &copia, nil
+}
+
+// Atualizar substitui os dados de uma sessão existente
+func (r *JSONSessaoRepository) Atualizar(sessao *models.Sessao) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+	if _, ok := r.sessoes[sessao.ID]; !ok {
+		return ErrSessaoNaoEncontrada
+	}
+	copia := *sessao
+	r.sessoes[sessao.ID] = &copia
+	return r.salvar()
+}
+
+// RotacionarRefresh troca o refresh da sessão se o armazenado ainda for refreshAnterior
+func (r *JSONSessaoRepository) RotacionarRefresh(sessao *models.Sessao, refreshAnterior string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return false, err
+	}
+	atual, ok := r.sessoes[sessao.ID]
+	if !ok {
+		return false, ErrSessaoNaoEncontrada
+	}
+	if atual.RefreshID != refreshAnterior || atual.RevogadaEm != nil {
+		return false, nil
+	}
+	copia := *sessao
+	r.sessoes[sessao.ID] = &copia
+	if err := r.salvar(); err != nil {
+		r.sessoes[sessao.ID] = atual
+		return false, err
+	}
+	return true, nil
+}
+
+// ListarPorSubject retorna as sessões do subject, da mais recente para a mais antiga
+func (r *JSONSessaoRepository) ListarPorSubject(subject string) ([]*models.Sessao, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+	sessoes := []*models.Sessao{}
+	for _, s := range r.sessoes {
+		if s.Subject == subject {
+			copia := *s
+			sessoes = append(sessoes, &copia)
+		}
+	}
+	sort.Slice(sessoes, func(i, j int) bool { return sessoes[i].UltimoAcesso.After(sessoes[j].UltimoAcesso) })
+	return sessoes, nil
+}
+
+// RevogarPorSubject revoga todas as sessões ativas do subject, exceto a informada (vazio revoga todas)
+func (r *JSONSessaoRepository) RevogarPorSubject(subject, exceto string, agora time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return err
+	}
+	for _, s := range r.sessoes {
+		if s.Subject == subject && s.ID != exceto && s.Ativa(agora) {
+			s.RevogadaEm = &agora
+		}
+	}
+	return r.salvar()
+}
+
+// SessaoAtiva indica se a sessão existe, não foi revogada e não expirou
+func (r *JSONSessaoRepository) SessaoAtiva(id string) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.carregar(); err != nil {
+		return false, err
+	}
+	s, ok := r.sessoes[id]
+	return ok && s.Ativa(time.Now()), nil
+}
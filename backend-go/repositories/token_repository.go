@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"taxi_service/models"
+)
+
+// TokenRepository define a interface para tokens de uso único
+type TokenRepository interface {
+	Criar(token *models.TokenUsoUnico) error
+	BuscarPorHash(hash string) (*models.TokenUsoUnico, error)
+	Consumir(hash, finalidade string, agora time.Time) (*models.TokenUsoUnico, error)
+	InvalidarPendentes(motoristaID, finalidade string, agora time.Time) error
+}
+
+// ErrTokenNaoEncontrado é retornado quando o token não existe, expirou ou já foi usado
+var ErrTokenNaoEncontrado = errors.New("token não encontrado")
+
+// JSONTokenRepository implementa TokenRepository usando arquivo JSON
+type JSONTokenRepository struct {
+	filePath string
+	mutex    sync.Mutex
+}
+
+// NewJSONTokenRepository cria uma nova instância do repositório
+func NewJSONTokenRepository() *JSONTokenRepository {
+	return &JSONTokenRepository{
+		filePath: "./data/tokens.json",
+	}
+}
+
+// ler lê todos os tokens do arquivo (chamador deve segurar o mutex)
+func (r *JSONTokenRepository) ler() ([]*models.TokenUsoUnico, error) {
+	data, err := os.ReadFile(r.filePath)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return []*models.TokenUsoUnico{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+
+	var tokens []*models.TokenUsoUnico
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar dados: %w", err)
+	}
+	return tokens, nil
+}
+
+// salvar grava todos os tokens no arquivo (chamador deve segurar o mutex)
+func (r *JSONTokenRepository) salvar(tokens []*models.TokenUsoUnico) error {
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar dados: %w", err)
+	}
+	if err := escreverArquivoAtomico(r.filePath, data, 0600, 0); err != nil {
+		return fmt.Errorf("erro ao escrever arquivo: %w", err)
+	}
+	return nil
+}
+
+// Criar adiciona um novo token, descartando tokens expirados ou usados
+func (r *JSONTokenRepository) Criar(token *models.TokenUsoUnico) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokens, err := r.ler()
+	if err != nil {
+		return err
+	}
+
+	ativos := tokens[:0]
+	for _, t := range tokens {
+		if t.Valido(time.Now()) {
+			ativos = append(ativos, t)
+		}
+	}
+	return r.salvar(append(ativos, token))
+}
+
+// BuscarPorHash busca um token pelo hash
+func (r *JSONTokenRepository) BuscarPorHash(hash string) (*models.TokenUsoUnico, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokens, err := r.ler()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return nil, ErrTokenNaoEncontrado
+}
+
+// Consumir marca o token como usado de forma atômica, garantindo uso único
+func (r *JSONTokenRepository) Consumir(hash, finalidade string, agora time.Time) (*models.TokenUsoUnico, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokens, err := r.ler()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if t.Hash == hash && t.Finalidade == finalidade && t.Valido(agora) {
+			t.UsadoEm = &agora
+			if err := r.salvar(tokens); err != nil {
+				return nil, err
+			}
+			return t, nil
+		}
+	}
+	return nil, ErrTokenNaoEncontrado
+}
+
+// InvalidarPendentes marca como usados todos os tokens válidos do motorista para a finalidade
+func (r *JSONTokenRepository) InvalidarPendentes(motoristaID, finalidade string, agora time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokens, err := r.ler()
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if t.MotoristaID == motoristaID && t.Finalidade == finalidade && t.Valido(agora) {
+			t.UsadoEm = &agora
+		}
+	}
+	return r.salvar(tokens)
+}
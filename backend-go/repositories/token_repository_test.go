@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/models"
+)
+
+func TestJSONTokenRepository(t *testing.T) {
+	tempFile := "./data/test_tokens.json"
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONTokenRepository{filePath: tempFile}
+	agora := time.Now()
+
+	novoToken := func(id, hash string, expira time.Time) *models.TokenUsoUnico {
+		return &models.TokenUsoUnico{
+			ID:          id,
+			Hash:        hash,
+			Finalidade:  models.FinalidadeRedefinicaoSenha,
+			MotoristaID: "motorista-1",
+			ExpiraEm:    expira,
+			CriadoEm:    agora,
+		}
+	}
+
+	t.Run("Consumir token válido apenas uma vez", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoToken("1", "hash-1", agora.Add(time.Hour))))
+
+		token, err := repo.Consumir("hash-1", models.FinalidadeRedefinicaoSenha, agora)
+		require.NoError(t, err)
+		assert.Equal(t, "motorista-1", token.MotoristaID)
+		assert.NotNil(t, token.UsadoEm)
+
+		_, err = repo.Consumir("hash-1", models.FinalidadeRedefinicaoSenha, agora)
+		assert.ErrorIs(t, err, ErrTokenNaoEncontrado)
+	})
+
+	t.Run("Erro ao consumir token expirado", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoToken("2", "hash-2", agora.Add(time.Hour))))
+
+		_, err := repo.Consumir("hash-2", models.FinalidadeRedefinicaoSenha, agora.Add(2*time.Hour))
+		assert.ErrorIs(t, err, ErrTokenNaoEncontrado)
+	})
+
+	t.Run("Erro ao consumir token com outra finalidade", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoToken("3", "hash-3", agora.Add(time.Hour))))
+
+		_, err := repo.Consumir("hash-3", "outra", agora)
+		assert.ErrorIs(t, err, ErrTokenNaoEncontrado)
+	})
+
+	t.Run("Invalidar tokens pendentes do motorista", func(t *testing.T) {
+		require.NoError(t, repo.Criar(novoToken("4", "hash-4", agora.Add(time.Hour))))
+		require.NoError(t, repo.InvalidarPendentes("motorista-1", models.FinalidadeRedefinicaoSenha, agora))
+
+		token, err := repo.BuscarPorHash("hash-4")
+		require.NoError(t, err)
+		assert.False(t, token.Valido(agora))
+	})
+}
+
+func TestJSONRevogacaoRepository(t *testing.T) {
+	tempFile := "./data/test_revogacoes.json"
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONRevogacaoRepository{filePath: tempFile}
+
+	geracao, err := repo.Geracao("motorista-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, geracao)
+
+	require.NoError(t, repo.Revogar("motorista-1"))
+
+	// Nova instância relê o arquivo persistido
+	relido := &JSONRevogacaoRepository{filePath: tempFile}
+	geracao, err = relido.Geracao("motorista-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, geracao)
+}
+
+func TestJSONSessaoRepository(t *testing.T) {
+	tempFile := "./data/test_sessoes.json"
+	os.Remove(tempFile)
+	defer os.Remove(tempFile)
+
+	repo := &JSONSessaoRepository{filePath: tempFile}
+	agora := time.Now()
+
+	novaSessao := func(id, subject string, acesso time.Time) *models.Sessao {
+		return &models.Sessao{
+			ID:           id,
+			Subject:      subject,
+			Dispositivo:  "Android",
+			IP:           "10.0.0.1",
+			RefreshID:    "jti-" + id,
+			CriadaEm:     acesso,
+			UltimoAcesso: acesso,
+			ExpiraEm:     agora.Add(time.Hour),
+		}
+	}
+
+	require.NoError(t, repo.Criar(novaSessao("s1", "motorista-1", agora.Add(-2*time.Minute))))
+	require.NoError(t, repo.Criar(novaSessao("s2", "motorista-1", agora.Add(-time.Minute))))
+	require.NoError(t, repo.Criar(novaSessao("s3", "motorista-2", agora)))
+
+	t.Run("Listar sessões do subject pela mais recente", func(t *testing.T) {
+		sessoes, err := repo.ListarPorSubject("motorista-1")
+		require.NoError(t, err)
+		require.Len(t, sessoes, 2)
+		assert.Equal(t, "s2", sessoes[0].ID)
+		assert.Equal(t, "s1", sessoes[1].ID)
+	})
+
+	t.Run("Revogar todas exceto a atual", func(t *testing.T) {
+		require.NoError(t, repo.RevogarPorSubject("motorista-1", "s2", agora))
+
+		ativa, err := repo.SessaoAtiva("s1")
+		require.NoError(t, err)
+		assert.False(t, ativa)
+		ativa, err = repo.SessaoAtiva("s2")
+		require.NoError(t, err)
+		assert.True(t, ativa)
+		ativa, err = repo.SessaoAtiva("s3")
+		require.NoError(t, err)
+		assert.True(t, ativa)
+	})
+
+	t.Run("Sessões persistem no arquivo", func(t *testing.T) {
+		relido := &JSONSessaoRepository{filePath: tempFile}
+		sessao, err := relido.BuscarPorID("s2")
+		require.NoError(t, err)
+		assert.Equal(t, "jti-s2", sessao.RefreshID)
+
+		sessao.RefreshID = "jti-novo"
+		require.NoError(t, relido.Atualizar(sessao))
+		sessao, err = relido.BuscarPorID("s2")
+		require.NoError(t, err)
+		assert.Equal(t, "jti-novo", sessao.RefreshID)
+
+		_, err = relido.BuscarPorID("inexistente")
+		assert.ErrorIs(t, err, ErrSessaoNaoEncontrada)
+	})
+
+	t.Run("Rotacionar refresh só troca o refresh esperado", func(t *testing.T) {
+		sessao, err := repo.BuscarPorID("s3")
+		require.NoError(t, err)
+		sessao.RefreshID = "jti-s3-b"
+		trocado, err := repo.RotacionarRefresh(sessao, "jti-s3")
+		require.NoError(t, err)
+		assert.True(t, trocado)
+
+		// Segunda renovação com o mesmo refresh perde a troca
+		sessao.RefreshID = "jti-s3-c"
+		trocado, err = repo.RotacionarRefresh(sessao, "jti-s3")
+		require.NoError(t, err)
+		assert.False(t, trocado)
+		sessao, err = repo.BuscarPorID("s3")
+		require.NoError(t, err)
+		assert.Equal(t, "jti-s3-b", sessao.RefreshID)
+
+		_, err = repo.RotacionarRefresh(&models.Sessao{ID: "inexistente"}, "")
+		assert.ErrorIs(t, err, ErrSessaoNaoEncontrada)
+	})
+}
@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"taxi_service/internal/auth"
 	"taxi_service/middlewares"
+	"taxi_service/repositories"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -11,7 +13,8 @@ import (
 // SetupRoutes inicializa todas as rotas da aplicação.
 func SetupRoutes(app *fiber.App) {
 	// Middlewares
-	app.Use(cors.New())
+	// ETag precisa ser exposto para que o frontend o reenvie em If-Match
+	app.Use(cors.New(cors.Config{ExposeHeaders: fiber.HeaderETag}))
 	app.Use(logger.New())
 	app.Use(middlewares.ErrorHandler())
 
@@ -23,5 +26,10 @@ func SetupRoutes(app *fiber.App) {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "OK"})
 	})
 
-	SetupMotoristaRoutes(api)
+	// Serviço de tokens compartilhado entre motoristas e operadores
+	sessaoRepo := repositories.NewJSONSessaoRepository()
+	tokenService := auth.NewTokenServiceFromEnv(repositories.NewJSONRevogacaoRepository(), sessaoRepo)
+
+	SetupMotoristaRoutes(api, tokenService, sessaoRepo)
+	SetupOperadorRoutes(api, tokenService, sessaoRepo)
 }
@@ -1,46 +1,187 @@
 package routes
 
 import (
+	"log"
+	"os"
+	"time"
+
 	"taxi_service/controllers"
+	"taxi_service/internal/auth"
+	"taxi_service/internal/cripto"
+	"taxi_service/internal/scanner"
+	"taxi_service/internal/storage"
+	"taxi_service/middlewares"
+	"taxi_service/models"
 	"taxi_service/repositories"
 	"taxi_service/services"
 
 	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func SetupMotoristaRoutes(api fiber.Router) {
+func SetupMotoristaRoutes(api fiber.Router, tokenService *auth.TokenService, sessaoRepo repositories.SessaoRepository) {
 	// Inicializar dependências
-	motoristaRepo := repositories.NewJSONMotoristaRepository()
+	motoristaRepo := novoMotoristaRepository()
+	arquivos := novoFileStore()
 	emailService := services.NewSMTPEmailServiceFromEnv()
-	motoristaService := services.NewMotoristaService(motoristaRepo, emailService)
-	motoristaController := controllers.NewMotoristaController(motoristaService)
+	hasher := auth.NewBcryptHasher(bcrypt.DefaultCost)
+	limitador := auth.NewLimitadorLogin(auth.LimitadorConfig{}, auth.NewTentativasMemoria())
+	motoristaService := services.NewMotoristaService(motoristaRepo, emailService, hasher, limitador)
+
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	tokenRepo := repositories.NewJSONTokenRepository()
+	verificacaoService := services.NewVerificacaoEmailService(motoristaRepo, tokenRepo, emailService, appURL)
+	verificacaoController := controllers.NewVerificacaoEmailController(verificacaoService)
+	sessaoService := services.NewSessaoService(sessaoRepo, motoristaRepo, tokenService)
+	sessaoController := controllers.NewSessaoController(sessaoService)
+	// Documentos enviados só podem ser revisados e baixados depois da verificação antivírus
+	varreduraService := services.NewVarreduraService(motoristaRepo, motoristaService, arquivos, novoFileScanner())
+	services.AgendarVarreduraPendente(varreduraService, 5*time.Minute)
+	motoristaController := controllers.NewMotoristaController(motoristaService, verificacaoService, sessaoService, tokenService, arquivos, varreduraService)
+
+	recuperacaoService := services.NewRecuperacaoService(motoristaRepo, tokenRepo, emailService, hasher, tokenService, limitador, appURL)
+	recuperacaoController := controllers.NewRecuperacaoController(recuperacaoService)
+
+	// Contas encerradas são removidas definitivamente após o período de carência
+	exclusaoService := services.NewExclusaoService(motoristaRepo, tokenRepo, emailService, tokenService, appURL, arquivos)
+	exclusaoController := controllers.NewExclusaoController(exclusaoService)
+	services.AgendarPurgaExclusoes(exclusaoService, time.Hour)
+
+	// Políticas de acesso
+	autenticado := middlewares.Autenticar(tokenService)
+	proprio := middlewares.ProprioMotorista()
+	proprioOuOperador := middlewares.ProprioMotoristaOu(models.PapelRevisor, models.PapelSuporte, models.PapelAdmin)
+	proprioOuSuporte := middlewares.ProprioMotoristaOu(models.PapelSuporte, models.PapelAdmin)
+	proprioOuRevisor := middlewares.ProprioMotoristaOu(models.PapelRevisor, models.PapelAdmin)
+	revisor := middlewares.ExigirPapel(models.PapelRevisor, models.PapelAdmin)
+	operador := middlewares.ExigirPapel(models.PapelRevisor, models.PapelSuporte, models.PapelAdmin)
 
 	// Grupo de rotas da API
 	apiGroup := api.Group("/api")
 
 	// Rotas de autenticação
-	auth := apiGroup.Group("/auth")
-	auth.Post("/register", motoristaController.CadastrarMotorista) // Cadastro de motorista
-	auth.Post("/login", motoristaController.LoginMotorista)        // Login de motorista
-
-	// Rotas de perfil
-	profile := apiGroup.Group("/profile")
-	profile.Get("/:id", motoristaController.BuscarMotorista)                     // Buscar motorista
-	profile.Put("/:id", motoristaController.AtualizarPerfil)                     // Atualizar telefone/email
-	profile.Put("/:id/password", motoristaController.AlterarSenha)               // Alterar senha
-	profile.Post("/:id/photo", motoristaController.UploadFotoPerfil)             // Upload foto
-	profile.Get("/:id/photo", motoristaController.FotoPerfil)                    // Obter foto
-	profile.Post("/:id/request-deletion", motoristaController.SolicitarExclusao) // Solicitar exclusão
-	profile.Post("/:id/confirm-deletion", motoristaController.ConfirmarExclusao) // Confirmar exclusão
-
-	// Rotas de documentos
-	documents := apiGroup.Group("/documents")
-	documents.Post("/:id/upload/files", motoristaController.UploadDocumentosArquivos) // Upload múltiplo multipart (arquivos reais)
-	documents.Get("/:id/file/:tipo", motoristaController.DownloadDocumento)           // Download/visualização de arquivo
-	documents.Put("/:id/approve", motoristaController.AprovarMotorista)               // Aprovar motorista
-	documents.Put("/:id/reject", motoristaController.RejeitarMotorista)               // Rejeitar motorista
+	authGroup := apiGroup.Group("/auth")
+	authGroup.Post("/register", motoristaController.CadastrarMotorista)            // Cadastro de motorista
+	authGroup.Post("/login", motoristaController.LoginMotorista)                   // Login de motorista
+	authGroup.Post("/login/2fa", motoristaController.LoginSegundoFator)            // Segunda etapa do login com 2FA
+	authGroup.Post("/refresh", motoristaController.RenovarToken)                   // Renovar tokens
+	authGroup.Post("/verify-email", verificacaoController.ConfirmarEmail)          // Confirmar email pelo link recebido
+	authGroup.Post("/confirm-deletion", exclusaoController.ConfirmarExclusao)      // Confirmar exclusão da conta pelo link recebido
+	authGroup.Post("/cancel-deletion", exclusaoController.CancelarExclusaoPorLink) // Cancelar exclusão pelo link do email de confirmação
+
+	// Rotas de recuperação de conta
+	authGroup.Post("/recover", recuperacaoController.SolicitarRecuperacao) // Solicitar link de redefinição
+	authGroup.Post("/reset/validate", recuperacaoController.ValidarLink)   // Validar link de redefinição
+	authGroup.Post("/reset", recuperacaoController.RedefinirSenha)         // Redefinir senha
+
+	// Rotas de perfil (exigem token do próprio motorista; consulta liberada a operadores)
+	profile := apiGroup.Group("/profile", autenticado)
+	profile.Get("/:id", proprioOuOperador, motoristaController.BuscarMotorista)                 // Buscar motorista
+	profile.Put("/:id", proprio, motoristaController.AtualizarPerfil)                           // Atualizar telefone/email
+	profile.Put("/:id/password", proprio, motoristaController.AlterarSenha)                     // Alterar senha
+	profile.Post("/:id/email/verification", proprio, verificacaoController.ReenviarVerificacao) // Reenviar link de confirmação
+	profile.Get("/:id/sessions", proprio, sessaoController.ListarSessoes)                       // Dispositivos logados
+	profile.Delete("/:id/sessions", proprio, sessaoController.RevogarTodasSessoes)              // Sair de todos os dispositivos
+	profile.Delete("/:id/sessions/:sid", proprio, sessaoController.RevogarSessao)               // Encerrar sessão de um dispositivo
+	profile.Post("/:id/2fa/enroll", proprio, motoristaController.IniciarDoisFatores)            // Iniciar configuração do 2FA
+	profile.Post("/:id/2fa/verify", proprio, motoristaController.ConfirmarDoisFatores)          // Confirmar e ativar o 2FA
+	profile.Delete("/:id/2fa", proprio, motoristaController.DesativarDoisFatores)               // Desativar o 2FA
+	profile.Post("/:id/photo", proprio, motoristaController.UploadFotoPerfil)                   // Upload foto
+	profile.Get("/:id/photo", proprio, motoristaController.FotoPerfil)                          // Obter foto
+	profile.Post("/:id/request-deletion", proprio, exclusaoController.SolicitarExclusao)        // Solicitar exclusão (envia link por email)
+	profile.Post("/:id/cancel-deletion", proprioOuSuporte, exclusaoController.CancelarExclusao) // Cancelar exclusão antes da remoção definitiva
+
+	// Rotas de documentos (upload pelo próprio motorista; revisão somente por revisores/admins)
+	documents := apiGroup.Group("/documents", autenticado)
+	documents.Post("/:id/upload/files", proprio, motoristaController.UploadDocumentosArquivos)                 // Upload múltiplo multipart (arquivos reais)
+	documents.Get("/:id/file/:tipo", proprioOuRevisor, motoristaController.DownloadDocumento)                  // Download/visualização de arquivo
+	documents.Get("/:id/history/:tipo", proprioOuRevisor, motoristaController.HistoricoDocumento)              // Versões enviadas do documento
+	documents.Get("/:id/history/:tipo/:versao", proprioOuRevisor, motoristaController.DownloadVersaoDocumento) // Download de uma versão
+	documents.Put("/:id/approve", revisor, motoristaController.AprovarMotorista)                               // Aprovar motorista
+	documents.Put("/:id/reject", revisor, motoristaController.RejeitarMotorista)                               // Rejeitar motorista
+	documents.Put("/:id/review/:documento/approve", revisor, motoristaController.AprovarDocumento)             // Aprovar um documento (ID ou tipo)
+	documents.Put("/:id/review/:documento/reject", revisor, motoristaController.RejeitarDocumento)             // Rejeitar um documento com motivo
+
+	// Rotas administrativas (somente operadores)
+	admin := apiGroup.Group("/admin", autenticado, operador)
+	admin.Get("/motoristas", motoristaController.ListarMotoristas) // Pesquisar motoristas (filtros, ordenação e cursor)
 
 	// Rotas utilitárias
 	utils := apiGroup.Group("/utils")
 	utils.Post("/check-password", motoristaController.VerificarForcaSenha) // Verificar força da senha
 }
+
+// novoMotoristaRepository escolhe o armazenamento de motoristas pela variável MOTORISTA_STORAGE:
+// "json" (padrão, arquivo data/motoristas.json), "sqlite" (banco em SQLITE_PATH)
+// ou "postgres" (conexão pelas variáveis DB_*). Os dados sensíveis são cifrados com as
+// chaves de DATA_ENCRYPTION_KEYS (ver novoCifrador).
+func novoMotoristaRepository() repositories.MotoristaRepository {
+	repo, err := repositories.AbrirMotoristaRepository(os.Getenv("MOTORISTA_STORAGE"), "", novoCifrador())
+	if err != nil {
+		log.Fatal(err)
+	}
+	return repo
+}
+
+// novoCifrador lê as chaves de criptografia dos dados sensíveis. Sem DATA_ENCRYPTION_KEYS
+// (modo desenvolvimento) os dados são gravados em texto puro.
+func novoCifrador() *cripto.Cifrador {
+	cifrador, err := cripto.NewCifradorFromEnv()
+	if err != nil {
+		log.Fatalf("falha ao configurar criptografia: %v", err)
+	}
+	if cifrador == nil {
+		log.Print("AVISO: DATA_ENCRYPTION_KEYS não configurada; dados sensíveis dos motoristas serão gravados sem criptografia")
+	}
+	return cifrador
+}
+
+// novoFileStore escolhe onde ficam documentos e fotos pela variável FILE_STORAGE:
+// "local" (padrão, diretório FILE_STORAGE_PATH ou data/) ou "s3" (bucket pelas variáveis S3_*).
+func novoFileStore() storage.FileStore {
+	switch armazenamento := os.Getenv("FILE_STORAGE"); armazenamento {
+	case "", "local":
+		raiz := os.Getenv("FILE_STORAGE_PATH")
+		if raiz == "" {
+			raiz = "./data"
+		}
+		return storage.NewLocalFileStore(raiz)
+	case "s3":
+		store, err := storage.NewS3FileStore(storage.S3ConfigFromEnv())
+		if err != nil {
+			log.Fatalf("falha ao configurar armazenamento S3: %v", err)
+		}
+		return store
+	default:
+		log.Fatalf("FILE_STORAGE inválido: %q (use local ou s3)", armazenamento)
+		return nil
+	}
+}
+
+// novoFileScanner escolhe o antivírus dos documentos pela variável FILE_SCANNER:
+// "none" (padrão, todos os arquivos são considerados limpos) ou "clamd" (daemon do
+// ClamAV em CLAMD_ADDRESS, ex.: tcp://127.0.0.1:3310 ou unix:///run/clamav/clamd.ctl).
+func novoFileScanner() scanner.FileScanner {
+	switch antivirus := os.Getenv("FILE_SCANNER"); antivirus {
+	case "", "none":
+		log.Print("AVISO: FILE_SCANNER não configurado; documentos enviados não serão verificados por antivírus")
+		return scanner.Nulo{}
+	case "clamd":
+		clamd, err := scanner.NewClamdScanner(scanner.ClamdConfigFromEnv())
+		if err != nil {
+			log.Fatalf("falha ao configurar antivírus: %v", err)
+		}
+		// Daemon fora do ar não impede a inicialização: os documentos ficam em
+		// verificação e são verificados quando ele voltar
+		if err := clamd.Ping(); err != nil {
+			log.Printf("AVISO: clamd não respondeu: %v", err)
+		}
+		return clamd
+	default:
+		log.Fatalf("FILE_SCANNER inválido: %q (use none ou clamd)", antivirus)
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"log"
+	"os"
+
+	"taxi_service/controllers"
+	"taxi_service/internal/auth"
+	"taxi_service/middlewares"
+	"taxi_service/models"
+	"taxi_service/repositories"
+	"taxi_service/services"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func SetupOperadorRoutes(api fiber.Router, tokenService *auth.TokenService, sessaoRepo repositories.SessaoRepository) {
+	// Inicializar dependências
+	operadorRepo := repositories.NewJSONOperadorRepository()
+	limitador := auth.NewLimitadorLogin(auth.LimitadorConfig{}, auth.NewTentativasMemoria())
+	operadorService := services.NewOperadorService(operadorRepo, auth.NewBcryptHasher(bcrypt.DefaultCost), limitador)
+	sessaoService := services.NewSessaoOperadorService(sessaoRepo, operadorRepo, tokenService)
+	operadorController := controllers.NewOperadorController(operadorService, sessaoService, tokenService)
+
+	// Primeiro admin criado a partir do .env quando não há operadores cadastrados
+	if err := operadorService.GarantirAdminInicial(os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		log.Printf("falha ao criar admin inicial: %v", err)
+	}
+
+	// Políticas de acesso
+	autenticado := middlewares.Autenticar(tokenService)
+	operador := middlewares.ExigirPapel(models.PapelRevisor, models.PapelSuporte, models.PapelAdmin)
+	admin := middlewares.ExigirPapel(models.PapelAdmin)
+
+	// Rotas de operadores
+	operators := api.Group("/api/operators")
+	operators.Post("/login", operadorController.LoginOperador)                                       // Login de operador
+	operators.Post("/login/2fa", operadorController.LoginSegundoFator)                               // Segunda etapa do login com 2FA
+	operators.Post("/refresh", operadorController.RenovarToken)                                      // Renovar tokens
+	operators.Get("/me", autenticado, operador, operadorController.OperadorAtual)                    // Operador autenticado
+	operators.Post("/me/2fa/enroll", autenticado, operador, operadorController.IniciarDoisFatores)   // Iniciar configuração do 2FA
+	operators.Post("/me/2fa/verify", autenticado, operador, operadorController.ConfirmarDoisFatores) // Confirmar e ativar o 2FA
+	operators.Post("/", autenticado, admin, operadorController.CriarOperador)                        // Criar operador (admin)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+)
+
+// Parâmetros da autenticação em dois fatores
+const (
+	emissorTOTP                  = "Taxi Service"
+	quantidadeCodigosRecuperacao = 10
+)
+
+// InscricaoDoisFatores dados exibidos uma única vez ao iniciar a configuração do 2FA
+type InscricaoDoisFatores struct {
+	OTPAuthURI         string   `json:"otpauth_uri"`
+	Segredo            string   `json:"segredo"`
+	CodigosRecuperacao []string `json:"codigos_recuperacao"`
+}
+
+// iniciarInscricao gera um novo segredo e códigos de recuperação.
+// O 2FA só passa a ser exigido após confirmarInscricao com um código válido.
+func iniciarInscricao(d *models.DoisFatores, conta string) (*InscricaoDoisFatores, error) {
+	if d.Ativo {
+		return nil, apperrors.ErrDoisFatoresJaAtivo
+	}
+	segredo, err := auth.GerarSegredoTOTP()
+	if err != nil {
+		return nil, err
+	}
+	codigos, hashes, err := auth.GerarCodigosRecuperacao(quantidadeCodigosRecuperacao)
+	if err != nil {
+		return nil, err
+	}
+	*d = models.DoisFatores{Segredo: segredo, CodigosRecuperacao: hashes}
+	return &InscricaoDoisFatores{
+		OTPAuthURI:         auth.URIOTPAuth(emissorTOTP, conta, segredo),
+		Segredo:            segredo,
+		CodigosRecuperacao: codigos,
+	}, nil
+}
+
+// confirmarInscricao ativa o 2FA quando o código do aplicativo autenticador confere
+func confirmarInscricao(d *models.DoisFatores, codigo string, agora time.Time) error {
+	if d.Ativo {
+		return apperrors.ErrDoisFatoresJaAtivo
+	}
+	if d.Segredo == "" {
+		return apperrors.ErrDoisFatoresNaoIniciado
+	}
+	passo, ok := auth.ValidarTOTP(d.Segredo, codigo, agora, d.UltimoPasso)
+	if !ok {
+		return apperrors.ErrCodigo2FAInvalido
+	}
+	d.Ativo = true
+	d.AtivadoEm = &agora
+	d.UltimoPasso = passo
+	return nil
+}
+
+// verificarSegundoFator aceita um código TOTP ou um código de recuperação, que é consumido no uso
+func verificarSegundoFator(d *models.DoisFatores, codigo string, agora time.Time) error {
+	if !d.Ativo {
+		return apperrors.ErrDoisFatoresInativo
+	}
+	if passo, ok := auth.ValidarTOTP(d.Segredo, codigo, agora, d.UltimoPasso); ok {
+		d.UltimoPasso = passo
+		return nil
+	}
+	hash := auth.HashCodigoRecuperacao(codigo)
+	for i, h := range d.CodigosRecuperacao {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			d.CodigosRecuperacao = append(d.CodigosRecuperacao[:i:i], d.CodigosRecuperacao[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.ErrCodigo2FAInvalido
+}
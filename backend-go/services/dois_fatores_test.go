@@ -0,0 +1,160 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+)
+
+func TestDoisFatores(t *testing.T) {
+	agora := time.Now()
+
+	t.Run("Inscrição só ativa após código válido", func(t *testing.T) {
+		var d models.DoisFatores
+		inscricao, err := iniciarInscricao(&d, "joao@email.com")
+		require.NoError(t, err)
+		assert.Contains(t, inscricao.OTPAuthURI, "otpauth://totp/")
+		assert.Len(t, inscricao.CodigosRecuperacao, quantidadeCodigosRecuperacao)
+		assert.False(t, d.Ativo)
+
+		// Apenas os hashes dos códigos de recuperação ficam no modelo
+		for _, codigo := range inscricao.CodigosRecuperacao {
+			assert.NotContains(t, d.CodigosRecuperacao, codigo)
+		}
+		assert.Contains(t, d.CodigosRecuperacao, auth.HashCodigoRecuperacao(inscricao.CodigosRecuperacao[0]))
+
+		assert.Equal(t, apperrors.ErrCodigo2FAInvalido, confirmarInscricao(&d, "000000", agora))
+		codigo, err := auth.CodigoTOTP(d.Segredo, agora)
+		require.NoError(t, err)
+		require.NoError(t, confirmarInscricao(&d, codigo, agora))
+		assert.True(t, d.Ativo)
+
+		_, err = iniciarInscricao(&d, "joao@email.com")
+		assert.Equal(t, apperrors.ErrDoisFatoresJaAtivo, err)
+	})
+
+	t.Run("Confirmar sem iniciar", func(t *testing.T) {
+		var d models.DoisFatores
+		assert.Equal(t, apperrors.ErrDoisFatoresNaoIniciado, confirmarInscricao(&d, "123456", agora))
+	})
+
+	t.Run("Código de recuperação é consumido no uso", func(t *testing.T) {
+		var d models.DoisFatores
+		inscricao, err := iniciarInscricao(&d, "joao@email.com")
+		require.NoError(t, err)
+		codigo, err := auth.CodigoTOTP(d.Segredo, agora)
+		require.NoError(t, err)
+		require.NoError(t, confirmarInscricao(&d, codigo, agora))
+
+		recuperacao := inscricao.CodigosRecuperacao[3]
+		require.NoError(t, verificarSegundoFator(&d, recuperacao, agora))
+		assert.Len(t, d.CodigosRecuperacao, quantidadeCodigosRecuperacao-1)
+		assert.Equal(t, apperrors.ErrCodigo2FAInvalido, verificarSegundoFator(&d, recuperacao, agora))
+	})
+
+	t.Run("Código TOTP não pode ser reutilizado", func(t *testing.T) {
+		var d models.DoisFatores
+		_, err := iniciarInscricao(&d, "joao@email.com")
+		require.NoError(t, err)
+		codigo, err := auth.CodigoTOTP(d.Segredo, agora)
+		require.NoError(t, err)
+		require.NoError(t, confirmarInscricao(&d, codigo, agora))
+
+		assert.Equal(t, apperrors.ErrCodigo2FAInvalido, verificarSegundoFator(&d, codigo, agora))
+	})
+}
+
+func TestVerificarSegundoFatorMotorista(t *testing.T) {
+	segredo, err := auth.GerarSegredoTOTP()
+	require.NoError(t, err)
+
+	setup := func() (MotoristaService, *MockMotoristaRepository, *MockEmailService, *models.Motorista) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+		motorista := &models.Motorista{ID: "1", Nome: "João", Email: "joao@email.com", DoisFatores: models.DoisFatores{Ativo: true, Segredo: segredo}}
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		return service, mockRepo, mockEmail, motorista
+	}
+
+	t.Run("Código válido conclui o login e registra o passo", func(t *testing.T) {
+		service, mockRepo, _, motorista := setup()
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		codigo, err := auth.CodigoTOTP(segredo, time.Now())
+		require.NoError(t, err)
+
+		m, err := service.VerificarSegundoFator("1", codigo, "10.0.0.1")
+		require.NoError(t, err)
+		assert.NotZero(t, m.DoisFatores.UltimoPasso)
+	})
+
+	t.Run("Códigos errados contam para o bloqueio", func(t *testing.T) {
+		service, _, mockEmail, _ := setup()
+		mockEmail.On("EnviarEmailBloqueioConta", "joao@email.com", "João", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		for i := 0; i < 5; i++ {
+			_, err := service.VerificarSegundoFator("1", "000000", "10.0.0.1")
+			assert.Equal(t, apperrors.ErrCodigo2FAInvalido, err)
+		}
+		_, err := service.VerificarSegundoFator("1", "000000", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrLoginBloqueado, err)
+		mockEmail.AssertExpectations(t)
+	})
+}
+
+func TestVerificarSegundoFatorOperador(t *testing.T) {
+	mockRepo := new(MockOperadorRepository)
+	service := NewOperadorService(mockRepo, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+	operador := &models.Operador{ID: "op-1", Email: "ana@taxiservice.com", Ativo: true}
+	mockRepo.On("BuscarPorID", "op-1").Return(operador, nil)
+	mockRepo.On("Atualizar", operador).Return(nil)
+
+	inscricao, err := service.IniciarDoisFatores("op-1")
+	require.NoError(t, err)
+	codigo, err := auth.CodigoTOTP(operador.DoisFatores.Segredo, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, service.ConfirmarDoisFatores("op-1", codigo))
+
+	recuperacao := inscricao.CodigosRecuperacao[0]
+	o, err := service.VerificarSegundoFator("op-1", recuperacao, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Len(t, o.DoisFatores.CodigosRecuperacao, quantidadeCodigosRecuperacao-1)
+	_, err = service.VerificarSegundoFator("op-1", recuperacao, "10.0.0.1")
+	assert.Equal(t, apperrors.ErrCodigo2FAInvalido, err)
+}
+
+func TestLoginOperadorDoisFatoresCompartilhaLimite(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+	mockRepo := new(MockOperadorRepository)
+	service := NewOperadorService(mockRepo, hasher, novoLimitador())
+	hash, err := hasher.Hash("MinhaSenh@123")
+	require.NoError(t, err)
+	operador := &models.Operador{ID: "op-1", Email: "ana@taxiservice.com", Senha: hash, Ativo: true,
+		DoisFatores: models.DoisFatores{Ativo: true, Segredo: "JBSWY3DPEHPK3PXP"}}
+	mockRepo.On("BuscarPorEmail", "ana@taxiservice.com").Return(operador, nil)
+	mockRepo.On("BuscarPorID", "op-1").Return(operador, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := service.LoginOperador("ana@taxiservice.com", "errada", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+	}
+	// A senha correta emite o desafio sem zerar as falhas anteriores
+	_, err = service.LoginOperador("ana@taxiservice.com", "MinhaSenh@123", "10.0.0.1")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := service.VerificarSegundoFator("op-1", "000000", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCodigo2FAInvalido, err)
+	}
+	_, err = service.LoginOperador("ana@taxiservice.com", "MinhaSenh@123", "10.0.0.1")
+	assert.Equal(t, apperrors.ErrLoginBloqueado, err)
+	_, err = service.VerificarSegundoFator("op-1", "000000", "10.0.0.1")
+	assert.Equal(t, apperrors.ErrLoginBloqueado, err)
+}
@@ -3,9 +3,14 @@ package services
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"taxi_service/models"
 )
 
 // EmailService define a interface para envio de emails
@@ -13,7 +18,13 @@ type EmailService interface {
 	EnviarEmailConfirmacao(email, nome string) error
 	EnviarEmailRecebimentoDocumentos(email, nome string) error
 	EnviarEmailAprovacao(email, nome string) error
-	EnviarEmailRejeicao(email, nome, motivo string) error
+	EnviarEmailRejeicao(email, nome, motivo string, documentos []models.Documento) error
+	EnviarEmailRecuperacao(email, nome, link string) error
+	EnviarEmailBloqueioConta(email, nome string, ate time.Time) error
+	EnviarEmailVerificacao(email, nome, link string) error
+	EnviarEmailAvisoTrocaEmail(email, nome, novoEmail string) error
+	EnviarEmailExclusaoConta(email, nome, link string) error
+	EnviarEmailExclusaoConfirmada(email, nome, link string, exclusaoEm time.Time) error
 }
 
 // SMTPEmailService implementação real usando SMTP
@@ -191,23 +202,170 @@ func (s *SMTPEmailService) EnviarEmailAprovacao(email, nome string) error {
 	return s.enviarEmail(email, subject, body)
 }
 
-// EnviarEmailRejeicao envia email de rejeição do cadastro
-func (s *SMTPEmailService) EnviarEmailRejeicao(email, nome, motivo string) error {
+// nomesDocumentos nomes dos tipos de documento exibidos nos emails
+var nomesDocumentos = map[string]string{
+	"CNH":        "CNH",
+	"CRLV":       "CRLV (documento do veículo)",
+	"selfie_cnh": "Selfie segurando a CNH",
+}
+
+// EnviarEmailRejeicao envia email de rejeição com os documentos que devem ser reenviados.
+// motivo (opcional) é o motivo geral informado na rejeição do cadastro inteiro.
+func (s *SMTPEmailService) EnviarEmailRejeicao(email, nome, motivo string, documentos []models.Documento) error {
 	subject := "Documentos rejeitados - Taxi Service"
+	var detalhes strings.Builder
+	if motivo != "" {
+		fmt.Fprintf(&detalhes, "<p><strong>Motivo:</strong> %s</p>\n", motivo)
+	}
+	if len(documentos) > 0 {
+		detalhes.WriteString("<p>Reenvie apenas os documentos abaixo:</p>\n<ul>\n")
+		for _, doc := range documentos {
+			tipo, ok := nomesDocumentos[doc.TipoDocumento]
+			if !ok {
+				tipo = doc.TipoDocumento
+			}
+			fmt.Fprintf(&detalhes, "<li><strong>%s</strong>: %s", tipo, models.DescricaoMotivoRejeicao(doc.MotivoRejeicao))
+			if doc.Observacao != "" && doc.Observacao != motivo {
+				fmt.Fprintf(&detalhes, " (%s)", html.EscapeString(doc.Observacao))
+			}
+			detalhes.WriteString("</li>\n")
+		}
+		detalhes.WriteString("</ul>\n")
+	}
 	body := fmt.Sprintf(`
 		<html>
 		<body>
 			<h2>Documentos Rejeitados</h2>
 			<p>Olá <strong>%s</strong>,</p>
 			<p>Infelizmente seus documentos foram rejeitados por nossa equipe.</p>
-			<p><strong>Motivo:</strong> %s</p>
+			%s
 			<p>Você pode corrigir os problemas identificados e reenviar seus documentos.</p>
 			<p>Se tiver dúvidas, entre em contato conosco.</p>
 			<br>
 			<p>Atenciosamente,<br>Equipe Taxi Service</p>
 		</body>
 		</html>
-	`, nome, motivo)
+	`, nome, detalhes.String())
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailRecuperacao envia o link de redefinição de senha
+func (s *SMTPEmailService) EnviarEmailRecuperacao(email, nome, link string) error {
+	subject := "Recuperação de Conta - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Recuperação de Conta</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>Recebemos uma solicitação para redefinir a senha da sua conta.</p>
+			<p><a href="%s">Clique aqui para redefinir sua senha</a></p>
+			<p>O link expira em 1 hora e só pode ser usado uma vez. Se você não fez esta solicitação, ignore este email.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, link)
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailBloqueioConta avisa que o login foi bloqueado por excesso de tentativas
+func (s *SMTPEmailService) EnviarEmailBloqueioConta(email, nome string, ate time.Time) error {
+	subject := "Acesso bloqueado temporariamente - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Acesso Bloqueado Temporariamente</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>Detectamos várias tentativas de login sem sucesso na sua conta e bloqueamos novos acessos até %s.</p>
+			<p>Se não foi você, recomendamos redefinir sua senha assim que o bloqueio terminar.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, ate.Format("02/01/2006 15:04"))
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailVerificacao envia o link de confirmação do endereço de email
+func (s *SMTPEmailService) EnviarEmailVerificacao(email, nome, link string) error {
+	subject := "Confirme seu email - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Confirmação de Email</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>Confirme que este endereço pertence a você para continuar usando o Taxi Service.</p>
+			<p><a href="%s">Clique aqui para confirmar seu email</a></p>
+			<p>O link expira em 24 horas. Se você não reconhece esta solicitação, ignore este email.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, link)
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailAvisoTrocaEmail avisa o endereço atual sobre um pedido de troca de email
+func (s *SMTPEmailService) EnviarEmailAvisoTrocaEmail(email, nome, novoEmail string) error {
+	subject := "Solicitação de troca de email - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Troca de Email Solicitada</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>Recebemos uma solicitação para alterar o email da sua conta para <strong>%s</strong>.</p>
+			<p>A alteração só será concluída após a confirmação pelo novo endereço. Se você não fez esta solicitação, altere sua senha e contate o suporte.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, novoEmail)
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailExclusaoConta envia o link de confirmação da exclusão da conta
+func (s *SMTPEmailService) EnviarEmailExclusaoConta(email, nome, link string) error {
+	subject := "Solicitação de Exclusão de Conta - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Solicitação de Exclusão de Conta</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>Recebemos uma solicitação para excluir permanentemente a sua conta.</p>
+			<p><a href="%s">Clique aqui para confirmar a exclusão</a></p>
+			<p>Após a confirmação, sua conta será encerrada e seus dados serão excluídos definitivamente em 72 horas. Até lá, é possível cancelar a exclusão pelo link que enviaremos na confirmação ou pelo suporte.</p>
+			<p>O link expira em 24 horas. Se você não fez esta solicitação, altere sua senha e contate o suporte.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, link)
+
+	return s.enviarEmail(email, subject, body)
+}
+
+// EnviarEmailExclusaoConfirmada confirma o encerramento da conta e envia o link para cancelar
+// a exclusão até a remoção definitiva dos dados
+func (s *SMTPEmailService) EnviarEmailExclusaoConfirmada(email, nome, link string, exclusaoEm time.Time) error {
+	subject := "Conta encerrada - Taxi Service"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Conta Encerrada</h2>
+			<p>Olá <strong>%s</strong>,</p>
+			<p>A exclusão da sua conta foi confirmada. Seus dados serão excluídos definitivamente em %s.</p>
+			<p>Mudou de ideia? <a href="%s">Clique aqui para cancelar a exclusão</a> e reativar a sua conta. O link pode ser usado uma única vez, até a data acima.</p>
+			<p>Se você não confirmou esta exclusão, cancele-a pelo link e altere sua senha.</p>
+			<br>
+			<p>Atenciosamente,<br>Equipe Taxi Service</p>
+		</body>
+		</html>
+	`, nome, exclusaoEm.Format("02/01/2006 15:04"), link)
 
 	return s.enviarEmail(email, subject, body)
 }
@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/models"
 )
 
 // MockSMTPServer is a minimal SMTP mock for tests
@@ -268,7 +271,7 @@ func TestSMTPEmailService(t *testing.T) {
 		err = service.EnviarEmailAprovacao("test@example.com", "João Silva")
 		assert.Error(t, err)
 
-		err = service.EnviarEmailRejeicao("test@example.com", "João Silva", "Documentos com problemas de qualidade")
+		err = service.EnviarEmailRejeicao("test@example.com", "João Silva", "Documentos com problemas de qualidade", nil)
 		assert.Error(t, err)
 	})
 
@@ -393,7 +396,7 @@ func TestEmailFunctionalityWithMockSMTPServer(t *testing.T) {
 		mockServer.ClearMessages()
 
 		motivo := "Documentos com baixa qualidade de imagem"
-		err := service.EnviarEmailRejeicao("ana@example.com", "Ana Costa", motivo)
+		err := service.EnviarEmailRejeicao("ana@example.com", "Ana Costa", motivo, nil)
 		assert.NoError(t, err)
 
 		time.Sleep(50 * time.Millisecond)
@@ -409,6 +412,91 @@ func TestEmailFunctionalityWithMockSMTPServer(t *testing.T) {
 		assert.Contains(t, msg.Body, motivo)
 	})
 
+	t.Run("Email de rejeição lista os documentos a reenviar", func(t *testing.T) {
+		service := NewSMTPEmailService(config)
+		mockServer.ClearMessages()
+
+		documentos := []models.Documento{
+			{TipoDocumento: "CNH", MotivoRejeicao: models.MotivoVencido},
+			{TipoDocumento: "selfie_cnh", MotivoRejeicao: models.MotivoIlegivel, Observacao: "Rosto <cortado>"},
+		}
+		err := service.EnviarEmailRejeicao("ana@example.com", "Ana Costa", "", documentos)
+		assert.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		messages := mockServer.GetMessages()
+		require.Len(t, messages, 1)
+		body := messages[0].Body
+		assert.Contains(t, body, "Reenvie apenas os documentos abaixo")
+		assert.Contains(t, body, "<strong>CNH</strong>: Documento vencido")
+		assert.Contains(t, body, "Selfie segurando a CNH")
+		assert.Contains(t, body, "Rosto &lt;cortado&gt;")
+		assert.NotContains(t, body, "CRLV")
+		assert.NotContains(t, body, "Motivo:")
+	})
+
+	t.Run("Envio de email de recuperação", func(t *testing.T) {
+		service := NewSMTPEmailService(config)
+		mockServer.ClearMessages()
+
+		link := "http://localhost:5173/reset-password?token=abc123"
+		err := service.EnviarEmailRecuperacao("jose@example.com", "José Santos", link)
+		assert.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		messages := mockServer.GetMessages()
+		assert.Len(t, messages, 1)
+
+		msg := messages[0]
+		assert.Equal(t, []string{"jose@example.com"}, msg.To)
+		assert.Contains(t, msg.Subject, "Recuperação de Conta")
+		assert.Contains(t, msg.Body, "José Santos")
+		assert.Contains(t, msg.Body, link)
+	})
+
+	t.Run("Envio de email de bloqueio de conta", func(t *testing.T) {
+		service := NewSMTPEmailService(config)
+		mockServer.ClearMessages()
+
+		ate := time.Date(2025, 1, 10, 12, 15, 0, 0, time.UTC)
+		err := service.EnviarEmailBloqueioConta("jose@example.com", "José Santos", ate)
+		assert.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		messages := mockServer.GetMessages()
+		assert.Len(t, messages, 1)
+
+		msg := messages[0]
+		assert.Equal(t, []string{"jose@example.com"}, msg.To)
+		assert.Contains(t, msg.Subject, "Acesso bloqueado")
+		assert.Contains(t, msg.Body, "José Santos")
+		assert.Contains(t, msg.Body, "10/01/2025 12:15")
+	})
+
+	t.Run("Envio de email de exclusão confirmada", func(t *testing.T) {
+		service := NewSMTPEmailService(config)
+		mockServer.ClearMessages()
+
+		link := "http://localhost:5173/cancel-deletion?token=abc123"
+		exclusaoEm := time.Date(2025, 1, 13, 9, 30, 0, 0, time.UTC)
+		err := service.EnviarEmailExclusaoConfirmada("jose@example.com", "José Santos", link, exclusaoEm)
+		assert.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		messages := mockServer.GetMessages()
+		assert.Len(t, messages, 1)
+
+		msg := messages[0]
+		assert.Equal(t, []string{"jose@example.com"}, msg.To)
+		assert.Contains(t, msg.Subject, "Conta encerrada")
+		assert.Contains(t, msg.Body, link)
+		assert.Contains(t, msg.Body, "13/01/2025 09:30")
+	})
+
 	t.Run("Múltiplos emails em sequência", func(t *testing.T) {
 		service := NewSMTPEmailService(config)
 		mockServer.ClearMessages()
@@ -558,7 +646,7 @@ func TestEmailContentValidation(t *testing.T) {
 		{
 			name: "Email de rejeição",
 			emailFunc: func() error {
-				return service.EnviarEmailRejeicao("user@example.com", "Usuario Teste", "Documento ilegível")
+				return service.EnviarEmailRejeicao("user@example.com", "Usuario Teste", "Documento ilegível", nil)
 			},
 			expectedSubject: "Documentos rejeitados",
 			expectedContent: []string{"Documentos Rejeitados", "Usuario Teste", "Documento ilegível"},
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/internal/storage"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+const (
+	// validadeLinkExclusao tempo de validade do link de confirmação de exclusão
+	validadeLinkExclusao = 24 * time.Hour
+	// carenciaExclusao período entre a confirmação e a remoção definitiva dos dados
+	carenciaExclusao = 72 * time.Hour
+)
+
+// ExclusaoService define a interface para a exclusão de contas de motoristas
+type ExclusaoService interface {
+	SolicitarExclusao(motoristaID string) error
+	ConfirmarExclusao(token string) (*models.Motorista, error)
+	CancelarExclusao(motoristaID string) error
+	CancelarExclusaoPorLink(token string) error
+	PurgarContasEncerradas(agora time.Time) (int, error)
+}
+
+// ExclusaoServiceImpl implementa ExclusaoService
+type ExclusaoServiceImpl struct {
+	motoristaRepo repositories.MotoristaRepository
+	tokenRepo     repositories.TokenRepository
+	emailService  EmailService
+	tokenService  *auth.TokenService
+	appURL        string
+	arquivos      storage.FileStore
+}
+
+// NewExclusaoService cria uma nova instância do serviço.
+// appURL é a URL base do frontend usada no link enviado por email e arquivos
+// o armazenamento dos documentos e fotos (chaves sob o prefixo <id>/).
+func NewExclusaoService(motoristaRepo repositories.MotoristaRepository, tokenRepo repositories.TokenRepository, emailService EmailService, tokenService *auth.TokenService, appURL string, arquivos storage.FileStore) ExclusaoService {
+	return &ExclusaoServiceImpl{
+		motoristaRepo: motoristaRepo,
+		tokenRepo:     tokenRepo,
+		emailService:  emailService,
+		tokenService:  tokenService,
+		appURL:        strings.TrimRight(appURL, "/"),
+		arquivos:      arquivos,
+	}
+}
+
+// SolicitarExclusao marca a conta como aguardando_exclusao e envia o link de confirmação.
+// Solicitar novamente apenas reenvia o link.
+func (s *ExclusaoServiceImpl) SolicitarExclusao(motoristaID string) error {
+	agora := time.Now()
+	motorista, err := atualizarMotorista(s.motoristaRepo, motoristaID, func(m *models.Motorista) error {
+		if m.Status == models.StatusEncerrado {
+			return apperrors.ErrLoginContaEncerrada
+		}
+		if m.Status != models.StatusAguardandoExclusao {
+			m.StatusAnterior = m.Status
+			m.Status = models.StatusAguardandoExclusao
+			m.AtualizadoEm = agora
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apenas o link mais recente permanece válido
+	if err := s.tokenRepo.InvalidarPendentes(motorista.ID, models.FinalidadeExclusaoConta, agora); err != nil {
+		return fmt.Errorf("erro ao invalidar links anteriores: %w", err)
+	}
+	token, hash, err := auth.GerarTokenOpaco()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	if err := s.tokenRepo.Criar(&models.TokenUsoUnico{
+		ID:          uuid.New().String(),
+		Hash:        hash,
+		Finalidade:  models.FinalidadeExclusaoConta,
+		MotoristaID: motorista.ID,
+		ExpiraEm:    agora.Add(validadeLinkExclusao),
+		CriadoEm:    agora,
+	}); err != nil {
+		return fmt.Errorf("erro ao salvar token: %w", err)
+	}
+
+	link := s.appURL + "/confirm-deletion?token=" + token
+	return s.emailService.EnviarEmailExclusaoConta(motorista.Email, motorista.Nome, link)
+}
+
+// ConfirmarExclusao consome o link recebido por email, encerra a conta, revoga os tokens
+// e agenda a remoção definitiva dos dados para depois do período de carência.
+// O link é a própria prova de identidade, já que o login fica bloqueado durante a exclusão;
+// pelo mesmo motivo, o email de confirmação leva o link para cancelar a exclusão.
+func (s *ExclusaoServiceImpl) ConfirmarExclusao(token string) (*models.Motorista, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	hash := auth.HashToken(token)
+	t, err := s.tokenRepo.BuscarPorHash(hash)
+	if err != nil || t.Finalidade != models.FinalidadeExclusaoConta {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	motorista, err := s.motoristaRepo.BuscarPorID(t.MotoristaID)
+	if err != nil {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	if motorista.Status != models.StatusAguardandoExclusao {
+		return nil, apperrors.ErrExclusaoNaoSolicitada
+	}
+	agora := time.Now()
+	if _, err := s.tokenRepo.Consumir(hash, models.FinalidadeExclusaoConta, agora); err != nil {
+		return nil, apperrors.ErrLinkInvalido
+	}
+
+	exclusaoEm := agora.Add(carenciaExclusao)
+	motorista, err = atualizarMotorista(s.motoristaRepo, motorista.ID, func(m *models.Motorista) error {
+		// Um cancelamento concorrente prevalece sobre o link já consumido
+		if m.Status != models.StatusAguardandoExclusao {
+			return apperrors.ErrExclusaoNaoSolicitada
+		}
+		m.Status = models.StatusEncerrado
+		m.ExclusaoEm = &exclusaoEm
+		m.AtualizadoEm = agora
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenService.RevogarTokens(motorista.ID); err != nil {
+		return nil, err
+	}
+	// A conta já está encerrada: uma falha no envio não desfaz a confirmação, e o suporte
+	// continua podendo cancelar a exclusão
+	if err := s.enviarLinkCancelamento(motorista, agora); err != nil {
+		fmt.Printf("Erro ao enviar link de cancelamento da exclusão: %v\n", err)
+	}
+	return motorista, nil
+}
+
+// enviarLinkCancelamento gera o link de uso único para cancelar a exclusão, válido até a
+// remoção definitiva dos dados, e o envia no email de confirmação
+func (s *ExclusaoServiceImpl) enviarLinkCancelamento(motorista *models.Motorista, agora time.Time) error {
+	if err := s.tokenRepo.InvalidarPendentes(motorista.ID, models.FinalidadeCancelamentoExclusao, agora); err != nil {
+		return fmt.Errorf("erro ao invalidar links anteriores: %w", err)
+	}
+	token, hash, err := auth.GerarTokenOpaco()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	if err := s.tokenRepo.Criar(&models.TokenUsoUnico{
+		ID:          uuid.New().String(),
+		Hash:        hash,
+		Finalidade:  models.FinalidadeCancelamentoExclusao,
+		MotoristaID: motorista.ID,
+		ExpiraEm:    *motorista.ExclusaoEm,
+		CriadoEm:    agora,
+	}); err != nil {
+		return fmt.Errorf("erro ao salvar token: %w", err)
+	}
+
+	link := s.appURL + "/cancel-deletion?token=" + token
+	return s.emailService.EnviarEmailExclusaoConfirmada(motorista.Email, motorista.Nome, link, *motorista.ExclusaoEm)
+}
+
+// CancelarExclusao desfaz a solicitação ou a confirmação enquanto os dados não foram removidos,
+// devolvendo a conta ao status anterior à solicitação.
+func (s *ExclusaoServiceImpl) CancelarExclusao(motoristaID string) error {
+	agora := time.Now()
+	_, err := atualizarMotorista(s.motoristaRepo, motoristaID, func(m *models.Motorista) error {
+		if m.Status != models.StatusAguardandoExclusao && m.ExclusaoEm == nil {
+			return apperrors.ErrExclusaoNaoSolicitada
+		}
+		// Vencida a carência a purga pode já estar removendo os arquivos
+		if m.ExclusaoEm != nil && !agora.Before(*m.ExclusaoEm) {
+			return apperrors.ErrExclusaoConcluida
+		}
+		m.Status = m.StatusAnterior
+		if m.Status == "" {
+			m.Status = models.StatusAguardandoAprovacao
+		}
+		m.StatusAnterior = ""
+		m.ExclusaoEm = nil
+		m.AtualizadoEm = agora
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Com o status restaurado, links ainda pendentes já não confirmariam nem cancelariam
+	// a exclusão; invalidar o de cancelamento é também o que o torna de uso único
+	for _, finalidade := range []string{models.FinalidadeExclusaoConta, models.FinalidadeCancelamentoExclusao} {
+		if err := s.tokenRepo.InvalidarPendentes(motoristaID, finalidade, agora); err != nil {
+			return fmt.Errorf("erro ao invalidar links de exclusão: %w", err)
+		}
+	}
+	return nil
+}
+
+// CancelarExclusaoPorLink cancela a exclusão pelo link recebido no email de confirmação,
+// já que o motorista não consegue entrar na conta encerrada
+func (s *ExclusaoServiceImpl) CancelarExclusaoPorLink(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return apperrors.ErrLinkInvalido
+	}
+	t, err := s.tokenRepo.BuscarPorHash(auth.HashToken(token))
+	if err != nil || t.Finalidade != models.FinalidadeCancelamentoExclusao || !t.Valido(time.Now()) {
+		return apperrors.ErrLinkInvalido
+	}
+	return s.CancelarExclusao(t.MotoristaID)
+}
+
+// PurgarContasEncerradas remove definitivamente as contas cujo período de carência terminou,
+// incluindo o diretório de arquivos do motorista. Retorna quantas contas foram removidas.
+func (s *ExclusaoServiceImpl) PurgarContasEncerradas(agora time.Time) (int, error) {
+	motoristas, err := s.motoristaRepo.ListarTodos()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar motoristas: %w", err)
+	}
+	vencida := func(m *models.Motorista) bool {
+		return m.Status == models.StatusEncerrado && m.ExclusaoEm != nil && !agora.Before(*m.ExclusaoEm)
+	}
+	removidas := 0
+	for _, m := range motoristas {
+		if !vencida(m) {
+			continue
+		}
+		// Arquivos primeiro e fora da transação: vencida a carência a exclusão já não pode
+		// ser cancelada, e se a remoção falhar o registro permanece e a purga é refeita na
+		// próxima execução
+		if err := s.arquivos.DeletarPrefixo(m.ID + "/"); err != nil {
+			return removidas, fmt.Errorf("erro ao remover arquivos do motorista %s: %w", m.ID, err)
+		}
+		if err := s.arquivos.DeletarPrefixo(ChaveQuarentena(m.ID + "/")); err != nil {
+			return removidas, fmt.Errorf("erro ao remover arquivos em quarentena do motorista %s: %w", m.ID, err)
+		}
+		removida := false
+		err := s.motoristaRepo.WithTx(func(tx repositories.MotoristaTx) error {
+			removida = false
+			atual, err := tx.BuscarPorID(m.ID)
+			if err != nil || !vencida(atual) {
+				return nil
+			}
+			if err := tx.Deletar(m.ID); err != nil {
+				return fmt.Errorf("erro ao remover motorista %s: %w", m.ID, err)
+			}
+			removida = true
+			return nil
+		})
+		if err != nil {
+			return removidas, err
+		}
+		if removida {
+			removidas++
+		}
+	}
+	return removidas, nil
+}
+
+// AgendarPurgaExclusoes executa a purga imediatamente e depois a cada intervalo.
+// Retorna uma função que interrompe o agendamento.
+func AgendarPurgaExclusoes(service ExclusaoService, intervalo time.Duration) func() {
+	parar := make(chan struct{})
+	executar := func() {
+		removidas, err := service.PurgarContasEncerradas(time.Now())
+		if err != nil {
+			fmt.Printf("Erro ao purgar contas encerradas: %v\n", err)
+		}
+		if removidas > 0 {
+			fmt.Printf("%d conta(s) encerrada(s) removida(s) definitivamente\n", removidas)
+		}
+	}
+	go func() {
+		executar()
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				executar()
+			case <-parar:
+				return
+			}
+		}
+	}()
+	return func() { close(parar) }
+}
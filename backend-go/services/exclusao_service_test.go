@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/internal/storage"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+func TestExclusaoService(t *testing.T) {
+	setup := func(dataDir string) (ExclusaoService, *MockMotoristaRepository, *MockTokenRepository, *MockEmailService, *auth.TokenService) {
+		mockRepo := new(MockMotoristaRepository)
+		mockTokens := new(MockTokenRepository)
+		mockEmail := new(MockEmailService)
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}})
+		service := NewExclusaoService(mockRepo, mockTokens, mockEmail, tokenService, "http://localhost:5173/", storage.NewLocalFileStore(dataDir))
+		return service, mockRepo, mockTokens, mockEmail, tokenService
+	}
+
+	t.Run("Solicitar exclusão envia link e guarda o status anterior", func(t *testing.T) {
+		service, mockRepo, mockTokens, mockEmail, _ := setup(t.TempDir())
+		motorista := &models.Motorista{ID: "1", Nome: "João Silva", Email: "joao.silva@email.com", Status: models.StatusAprovado}
+
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeExclusaoConta).Return(nil)
+		mockTokens.On("Criar", mock.AnythingOfType("*models.TokenUsoUnico")).Return(nil)
+		mockEmail.On("EnviarEmailExclusaoConta", "joao.silva@email.com", "João Silva", mock.AnythingOfType("string")).Return(nil)
+
+		require.NoError(t, service.SolicitarExclusao("1"))
+		assert.Equal(t, models.StatusAguardandoExclusao, motorista.Status)
+		assert.Equal(t, models.StatusAprovado, motorista.StatusAnterior)
+
+		salvo := mockTokens.Calls[1].Arguments.Get(0).(*models.TokenUsoUnico)
+		token := strings.TrimPrefix(mockEmail.Calls[0].Arguments.String(2), "http://localhost:5173/confirm-deletion?token=")
+		assert.Equal(t, auth.HashToken(token), salvo.Hash)
+		assert.Equal(t, models.FinalidadeExclusaoConta, salvo.Finalidade)
+		assert.Equal(t, "Solicitação de Exclusão de Conta - Taxi Service", mockEmail.ObterEmailsEnviados()[0].Assunto)
+	})
+
+	t.Run("Confirmar exclusão encerra a conta e revoga os tokens", func(t *testing.T) {
+		service, mockRepo, mockTokens, mockEmail, tokenService := setup(t.TempDir())
+		motorista := &models.Motorista{ID: "1", Nome: "João Silva", Email: "joao.silva@email.com", Status: models.StatusAguardandoExclusao, StatusAnterior: models.StatusAprovado}
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeExclusaoConta, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour)}
+		sessao, err := tokenService.GerarTokens("1", "motorista")
+		require.NoError(t, err)
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("Consumir", hash, models.FinalidadeExclusaoConta).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeCancelamentoExclusao).Return(nil)
+		mockTokens.On("Criar", mock.AnythingOfType("*models.TokenUsoUnico")).Return(nil)
+		mockEmail.On("EnviarEmailExclusaoConfirmada", "joao.silva@email.com", "João Silva", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+
+		_, err = service.ConfirmarExclusao("token-valido")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusEncerrado, motorista.Status)
+		require.NotNil(t, motorista.ExclusaoEm)
+		assert.WithinDuration(t, time.Now().Add(72*time.Hour), *motorista.ExclusaoEm, time.Minute)
+
+		_, err = tokenService.ValidarToken(sessao.AccessToken, auth.TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+
+		// O email de confirmação leva o link de cancelamento, válido até a remoção dos dados
+		cancelamento := mockTokens.Calls[len(mockTokens.Calls)-1].Arguments.Get(0).(*models.TokenUsoUnico)
+		token := strings.TrimPrefix(mockEmail.Calls[0].Arguments.String(2), "http://localhost:5173/cancel-deletion?token=")
+		assert.Equal(t, auth.HashToken(token), cancelamento.Hash)
+		assert.Equal(t, models.FinalidadeCancelamentoExclusao, cancelamento.Finalidade)
+		assert.Equal(t, *motorista.ExclusaoEm, cancelamento.ExpiraEm)
+		assert.Equal(t, *motorista.ExclusaoEm, mockEmail.Calls[0].Arguments.Get(3))
+	})
+
+	t.Run("Cancelar pelo link do email de confirmação", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _ := setup(t.TempDir())
+		exclusaoEm := time.Now().Add(time.Hour)
+		motorista := &models.Motorista{ID: "1", Status: models.StatusEncerrado, StatusAnterior: models.StatusAprovado, ExclusaoEm: &exclusaoEm}
+		hash := auth.HashToken("cancelar")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeCancelamentoExclusao, MotoristaID: "1", ExpiraEm: exclusaoEm}
+		confirmacao := &models.TokenUsoUnico{Hash: auth.HashToken("confirmar"), Finalidade: models.FinalidadeExclusaoConta, MotoristaID: "1", ExpiraEm: exclusaoEm}
+		usadoEm := time.Now()
+		usado := &models.TokenUsoUnico{Hash: auth.HashToken("usado"), Finalidade: models.FinalidadeCancelamentoExclusao, MotoristaID: "1", ExpiraEm: exclusaoEm, UsadoEm: &usadoEm}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("BuscarPorHash", confirmacao.Hash).Return(confirmacao, nil)
+		mockTokens.On("BuscarPorHash", usado.Hash).Return(usado, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeExclusaoConta).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeCancelamentoExclusao).Return(nil)
+
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.CancelarExclusaoPorLink(""))
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.CancelarExclusaoPorLink("confirmar"))
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.CancelarExclusaoPorLink("usado"))
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+
+		require.NoError(t, service.CancelarExclusaoPorLink("cancelar"))
+		assert.Equal(t, models.StatusAprovado, motorista.Status)
+		assert.Nil(t, motorista.ExclusaoEm)
+		// O cancelamento invalida o próprio link
+		mockTokens.AssertCalled(t, "InvalidarPendentes", "1", models.FinalidadeCancelamentoExclusao)
+	})
+
+	t.Run("Confirmar exige token válido e exclusão solicitada", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _ := setup(t.TempDir())
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeExclusaoConta, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour)}
+		outraFinalidade := &models.TokenUsoUnico{Hash: auth.HashToken("redefinicao"), Finalidade: models.FinalidadeRedefinicaoSenha, MotoristaID: "1"}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("BuscarPorHash", outraFinalidade.Hash).Return(outraFinalidade, nil)
+		mockTokens.On("BuscarPorHash", auth.HashToken("inexistente")).Return(nil, repositories.ErrTokenNaoEncontrado)
+		mockRepo.On("BuscarPorID", "1").Return(&models.Motorista{ID: "1", Status: models.StatusAprovado}, nil)
+
+		_, err := service.ConfirmarExclusao("")
+		assert.Equal(t, apperrors.ErrLinkInvalido, err)
+		_, err = service.ConfirmarExclusao("inexistente")
+		assert.Equal(t, apperrors.ErrLinkInvalido, err)
+		_, err = service.ConfirmarExclusao("redefinicao")
+		assert.Equal(t, apperrors.ErrLinkInvalido, err)
+		_, err = service.ConfirmarExclusao("token-valido")
+		assert.Equal(t, apperrors.ErrExclusaoNaoSolicitada, err)
+		mockTokens.AssertNotCalled(t, "Consumir", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Cancelar exclusão restaura o status anterior", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, _ := setup(t.TempDir())
+		exclusaoEm := time.Now().Add(time.Hour)
+		motorista := &models.Motorista{ID: "1", Status: models.StatusEncerrado, StatusAnterior: models.StatusAprovado, ExclusaoEm: &exclusaoEm}
+		ativo := &models.Motorista{ID: "2", Status: models.StatusAprovado}
+
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("BuscarPorID", "2").Return(ativo, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeExclusaoConta).Return(nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeCancelamentoExclusao).Return(nil)
+
+		require.NoError(t, service.CancelarExclusao("1"))
+		assert.Equal(t, models.StatusAprovado, motorista.Status)
+		assert.Nil(t, motorista.ExclusaoEm)
+		assert.Equal(t, apperrors.ErrExclusaoNaoSolicitada, service.CancelarExclusao("2"))
+	})
+
+	t.Run("Cancelar depois da carência é recusado", func(t *testing.T) {
+		service, mockRepo, _, _, _ := setup(t.TempDir())
+		vencida := time.Now().Add(-time.Minute)
+		motorista := &models.Motorista{ID: "1", Status: models.StatusEncerrado, StatusAnterior: models.StatusAprovado, ExclusaoEm: &vencida}
+
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+
+		assert.Equal(t, apperrors.ErrExclusaoConcluida, service.CancelarExclusao("1"))
+		assert.Equal(t, models.StatusEncerrado, motorista.Status)
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+	})
+
+	t.Run("Purga remove contas vencidas e seus arquivos", func(t *testing.T) {
+		dataDir := t.TempDir()
+		service, mockRepo, _, _, _ := setup(dataDir)
+		agora := time.Now()
+		vencida := agora.Add(-time.Minute)
+		futura := agora.Add(time.Hour)
+		motoristas := []*models.Motorista{
+			{ID: "vencida", Status: models.StatusEncerrado, ExclusaoEm: &vencida},
+			{ID: "em-carencia", Status: models.StatusEncerrado, ExclusaoEm: &futura},
+			{ID: "ativo", Status: models.StatusAprovado},
+		}
+		for _, m := range motoristas {
+			require.NoError(t, os.MkdirAll(filepath.Join(dataDir, m.ID, "profile"), 0755))
+		}
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "quarentena", "vencida", "documentos"), 0755))
+		mockRepo.On("ListarTodos").Return(motoristas, nil)
+		mockRepo.On("BuscarPorID", "vencida").Return(motoristas[0], nil)
+		mockRepo.On("Deletar", "vencida").Return(nil)
+
+		removidas, err := service.PurgarContasEncerradas(agora)
+		require.NoError(t, err)
+		assert.Equal(t, 1, removidas)
+		assert.NoDirExists(t, filepath.Join(dataDir, "vencida"))
+		assert.NoDirExists(t, filepath.Join(dataDir, "quarentena", "vencida"))
+		assert.DirExists(t, filepath.Join(dataDir, "em-carencia"))
+		mockRepo.AssertNumberOfCalls(t, "Deletar", 1)
+	})
+
+	t.Run("Purga remove os arquivos antes da transação", func(t *testing.T) {
+		dataDir := t.TempDir()
+		service, mockRepo, _, _, _ := setup(dataDir)
+		agora := time.Now()
+		vencida := agora.Add(-time.Minute)
+		motorista := &models.Motorista{ID: "vencida", Status: models.StatusEncerrado, ExclusaoEm: &vencida}
+		require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "vencida", "profile"), 0755))
+		mockRepo.On("ListarTodos").Return([]*models.Motorista{motorista}, nil)
+		mockRepo.On("BuscarPorID", "vencida").Return(motorista, nil)
+		mockRepo.On("Deletar", "vencida").Return(errors.New("banco indisponível"))
+
+		// A transação falha depois da remoção dos arquivos; o registro fica para a próxima execução
+		removidas, err := service.PurgarContasEncerradas(agora)
+		assert.Error(t, err)
+		assert.Equal(t, 0, removidas)
+		assert.NoDirExists(t, filepath.Join(dataDir, "vencida"))
+	})
+}
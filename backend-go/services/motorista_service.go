@@ -1,14 +1,17 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
 	"taxi_service/models"
 	"taxi_service/repositories"
 )
@@ -31,36 +34,64 @@ type CadastroMotoristaRequest struct {
 
 // UploadDocumentoRequest representa os dados para upload de documento
 type UploadDocumentoRequest struct {
-	TipoDocumento  string `json:"tipo_documento" validate:"required"`
-	CaminhoArquivo string `json:"caminho_arquivo" validate:"required"`
-	Formato        string `json:"formato" validate:"required"`
-	Tamanho        int64  `json:"tamanho" validate:"required"`
+	TipoDocumento string `json:"tipo_documento" validate:"required"`
+	ChaveArquivo  string `json:"chave_arquivo" validate:"required"`
+	Formato       string `json:"formato" validate:"required"`
+	Tamanho       int64  `json:"tamanho" validate:"required"`
+	Checksum      string `json:"checksum"` // SHA-256 do conteúdo gravado
+	EnviadoPor    string `json:"-"`        // principal autenticado que fez o upload
 }
 
 var documentosObrigatorios = []string{"CNH", "CRLV", "selfie_cnh"}
 
+// NormalizarTipoDocumento converte as variações de nome da selfie ("Selfie",
+// "SELFIE_CNH"...) em selfie_cnh; os demais tipos são mantidos como vieram
+func NormalizarTipoDocumento(tipo string) string {
+	if strings.Contains(strings.ToLower(tipo), "selfie") {
+		return "selfie_cnh"
+	}
+	return tipo
+}
+
+// Próximos passos devolvidos no login para o cliente decidir a navegação
+const (
+	ProximoPassoUploadDocumentos = "upload_documentos"
+	ProximoPassoPainel           = "painel"
+	ProximoPassoDoisFatores      = "dois_fatores" // informar o código em /api/auth/login/2fa
+)
+
 // MotoristaService define a interface para serviços de motorista
 type MotoristaService interface {
 	CadastrarMotorista(request CadastroMotoristaRequest) (*models.Motorista, error)
 	ValidarDadosCadastro(request CadastroMotoristaRequest) error
 	UploadDocumento(motoristaID string, request UploadDocumentoRequest) error
 	UploadDocumentosLote(motoristaID string, requests []UploadDocumentoRequest) error
-	AprovarMotorista(motoristaID string) error
-	RejeitarMotorista(motoristaID string, motivo string) error
-	AtualizarPerfil(id string, telefone string, email string) (*models.Motorista, error)
+	HistoricoDocumento(motoristaID, tipo string) ([]models.Documento, error)
+	VersaoDocumento(motoristaID, tipo string, versao int) (*models.Documento, error)
+	AprovarMotorista(motoristaID, operadorID string) error
+	RejeitarMotorista(motoristaID, operadorID, motivo string) error
+	AprovarDocumento(motoristaID, documento, operadorID string) (*models.Motorista, error)
+	RejeitarDocumento(motoristaID, documento, operadorID, codigoMotivo, observacao string) (*models.Motorista, error)
+	RegistrarVarredura(motoristaID, chaveArquivo string, infectado bool, ameaca string) error
+	AtualizarPerfil(id string, versaoEsperada int64, telefone string, email string) (*models.Motorista, error)
 	AlterarSenha(id, senhaAtual, novaSenha, confirmacao string) error
-	UploadFotoPerfil(id string, caminho string, formato string, tamanho int64) error
-	SolicitarExclusao(id string) error
-	ConfirmarExclusao(id string) error
+	UploadFotoPerfil(id string, chave string, formato string, tamanho int64) error
 	BuscarMotorista(id string) (*models.Motorista, error)
+	ListarMotoristas(filtro repositories.FiltroMotoristas) (*repositories.PaginaMotoristas, error)
 	VerificarForcaSenha(senha string) (string, error)
-	LoginMotorista(email, senha string) (*models.Motorista, error)
+	LoginMotorista(email, senha, ip string) (*models.Motorista, error)
+	VerificarSegundoFator(id, codigo, ip string) (*models.Motorista, error)
+	IniciarDoisFatores(id string) (*InscricaoDoisFatores, error)
+	ConfirmarDoisFatores(id, codigo string) error
+	DesativarDoisFatores(id, codigo string) error
 }
 
 // MotoristaServiceImpl implementa MotoristaService
 type MotoristaServiceImpl struct {
 	motoristaRepo repositories.MotoristaRepository
 	emailService  EmailService
+	hasher        auth.PasswordHasher
+	limitador     *auth.LimitadorLogin
 }
 
 // getMotorista encapsula busca e mapeia erro de not found
@@ -72,11 +103,59 @@ func (s *MotoristaServiceImpl) getMotorista(id string) (*models.Motorista, error
 	return m, nil
 }
 
+// atualizarMotorista carrega o motorista, aplica alterar e o salva em uma única unidade
+// de trabalho do repositório, para que atualizações concorrentes não se sobrescrevam.
+// Erros de alterar são devolvidos sem alteração; alterar pode rodar mais de uma vez
+// e não deve ter efeitos externos (emails devem ser enviados depois).
+func (s *MotoristaServiceImpl) atualizarMotorista(id string, alterar func(m *models.Motorista) error) (*models.Motorista, error) {
+	return atualizarMotorista(s.motoristaRepo, id, alterar)
+}
+
+func atualizarMotorista(repo repositories.MotoristaRepository, id string, alterar func(m *models.Motorista) error) (*models.Motorista, error) {
+	var motorista *models.Motorista
+	err := repo.WithTx(func(tx repositories.MotoristaTx) error {
+		m, err := tx.BuscarPorID(id)
+		if err != nil {
+			return apperrors.ErrMotoristaNaoEncontrado
+		}
+		if err := alterar(m); err != nil {
+			return err
+		}
+		if err := tx.Atualizar(m); err != nil {
+			if appErr := erroUnicidadeMotorista(err); appErr != nil {
+				return appErr
+			}
+			return fmt.Errorf("erro ao atualizar motorista: %w", err)
+		}
+		motorista = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return motorista, nil
+}
+
+// erroUnicidadeMotorista converte violações de unicidade do repositório nos erros da API
+func erroUnicidadeMotorista(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrCPFDuplicado):
+		return apperrors.ErrCPFJaCadastrado
+	case errors.Is(err, repositories.ErrCNHDuplicada):
+		return apperrors.ErrCNHJaCadastrada
+	case errors.Is(err, repositories.ErrEmailDuplicado):
+		return apperrors.ErrEmailJaCadastrado
+	}
+	return nil
+}
+
 // NewMotoristaService cria uma nova instância do serviço
-func NewMotoristaService(motoristaRepo repositories.MotoristaRepository, emailService EmailService) MotoristaService {
+func NewMotoristaService(motoristaRepo repositories.MotoristaRepository, emailService EmailService, hasher auth.PasswordHasher, limitador *auth.LimitadorLogin) MotoristaService {
 	return &MotoristaServiceImpl{
 		motoristaRepo: motoristaRepo,
 		emailService:  emailService,
+		hasher:        hasher,
+		limitador:     limitador,
 	}
 }
 
@@ -103,7 +182,8 @@ func (s *MotoristaServiceImpl) CadastrarMotorista(request CadastroMotoristaReque
 		return nil, apperrors.ErrSenhasNaoConferem
 	}
 
-	// Verificar se já existe motorista com mesmo CPF, CNH ou email
+	// Verificação antecipada de CPF, CNH ou email já cadastrados; a garantia contra
+	// cadastros simultâneos é a restrição de unicidade do repositório em Criar
 	if _, err := s.motoristaRepo.BuscarPorCPF(request.CPF); err == nil {
 		return nil, apperrors.ErrCPFJaCadastrado
 	}
@@ -135,6 +215,11 @@ func (s *MotoristaServiceImpl) CadastrarMotorista(request CadastroMotoristaReque
 		return nil, err
 	}
 
+	senhaHash, err := s.hasher.Hash(request.Senha)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+
 	// Criar motorista
 	motorista := &models.Motorista{
 		ID:             uuid.New().String(),
@@ -148,7 +233,7 @@ func (s *MotoristaServiceImpl) CadastrarMotorista(request CadastroMotoristaReque
 		ModeloVeiculo:  request.ModeloVeiculo,
 		Telefone:       request.Telefone,
 		Email:          request.Email,
-		Senha:          request.Senha, // Em produção, seria hasheada
+		Senha:          senhaHash,
 		Status:         models.StatusAguardandoAprovacao,
 		CriadoEm:       time.Now(),
 		AtualizadoEm:   time.Now(),
@@ -157,15 +242,14 @@ func (s *MotoristaServiceImpl) CadastrarMotorista(request CadastroMotoristaReque
 
 	// Salvar no repositório
 	if err := s.motoristaRepo.Criar(motorista); err != nil {
+		if appErr := erroUnicidadeMotorista(err); appErr != nil {
+			return nil, appErr
+		}
 		return nil, fmt.Errorf("erro ao salvar motorista: %w", err)
 	}
 
-	// Enviar email de confirmação
-	if err := s.emailService.EnviarEmailConfirmacao(motorista.Email, motorista.Nome); err != nil {
-		// Log do erro, mas não falha o cadastro
-		fmt.Printf("Erro ao enviar email de confirmação: %v\n", err)
-	}
-
+	// O único email do cadastro é o link de verificação, enviado pelo controller: o
+	// motorista só é orientado a enviar documentos depois de confirmar o endereço
 	return motorista, nil
 }
 
@@ -222,68 +306,53 @@ func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request Uploa
 		return apperrors.ErrDocumentoTipoInvalido
 	}
 
-	// Buscar motorista
-	motorista, err := s.getMotorista(motoristaID)
-	if err != nil {
-		return err
-	}
-
-	// Verificar se já existe documento do mesmo tipo
-	for i, doc := range motorista.Documentos {
-		if doc.TipoDocumento == request.TipoDocumento {
-			// Substituir documento existente
-			motorista.Documentos[i] = models.Documento{
-				ID:             uuid.New().String(),
-				TipoDocumento:  request.TipoDocumento,
-				CaminhoArquivo: request.CaminhoArquivo,
-				Formato:        strings.ToUpper(request.Formato),
-				Tamanho:        request.Tamanho,
-				Status:         models.DocumentoStatusPendente,
-				CriadoEm:       time.Now(),
-			}
-
-			motorista.AtualizadoEm = time.Now()
-			return s.motoristaRepo.Atualizar(motorista)
+	var todosEnviados bool
+	motorista, err := s.atualizarMotorista(motoristaID, func(motorista *models.Motorista) error {
+		anterior := motorista.Status
+		agora := time.Now()
+		documento := models.Documento{
+			ID:            uuid.New().String(),
+			TipoDocumento: request.TipoDocumento,
+			ChaveArquivo:  request.ChaveArquivo,
+			Formato:       strings.ToUpper(request.Formato),
+			Tamanho:       request.Tamanho,
+			Checksum:      request.Checksum,
+			Status:        models.DocumentoStatusPendente,
+			Varredura:     models.VarreduraVerificando,
+			Versao:        1,
+			EnviadoPor:    request.EnviadoPor,
+			CriadoEm:      agora,
 		}
-	}
-
-	// Adicionar novo documento
-	documento := models.Documento{
-		ID:             uuid.New().String(),
-		TipoDocumento:  request.TipoDocumento,
-		CaminhoArquivo: request.CaminhoArquivo,
-		Formato:        strings.ToUpper(request.Formato),
-		Tamanho:        request.Tamanho,
-		Status:         models.DocumentoStatusPendente,
-		CriadoEm:       time.Now(),
-	}
-
-	motorista.Documentos = append(motorista.Documentos, documento)
-	motorista.AtualizadoEm = time.Now()
-
-	// Verificar se todos os documentos obrigatórios foram enviados
-	todosEnviados := true
-	for _, tipoObrigatorio := range documentosObrigatorios {
-		encontrado := false
-		for _, doc := range motorista.Documentos {
-			if doc.TipoDocumento == tipoObrigatorio {
-				encontrado = true
+		motorista.AtualizadoEm = agora
+
+		// O documento atual do mesmo tipo, com a decisão de revisão que recebeu, vai para
+		// o histórico; o arquivo dele continua no FileStore (endereçado pelo conteúdo)
+		substituido := false
+		for i, doc := range motorista.Documentos {
+			if doc.TipoDocumento == request.TipoDocumento {
+				doc.Versao = versaoDocumento(doc)
+				doc.SubstituidoEm = &agora
+				motorista.Historico = append(motorista.Historico, doc)
+				documento.Versao = doc.Versao + 1
+				motorista.Documentos[i] = documento
+				substituido = true
 				break
 			}
 		}
-		if !encontrado {
-			todosEnviados = false
-			break
+		if !substituido {
+			motorista.Documentos = append(motorista.Documentos, documento)
 		}
-	}
 
-	// Se todos os documentos foram enviados, mudar status
-	if todosEnviados && motorista.Status == models.StatusAguardandoAprovacao {
-		motorista.Status = models.StatusDocumentosAnalise // manter compat; domínio duplicado
-	}
-
-	if err := s.motoristaRepo.Atualizar(motorista); err != nil {
-		return fmt.Errorf("erro ao atualizar motorista: %w", err)
+		// Durante a revisão o status acompanha os documentos: com todos os obrigatórios
+		// enviados e nenhum rejeitado o cadastro volta (ou passa) para análise
+		if statusEmRevisao(motorista.Status) {
+			motorista.Status = models.StatusPorDocumentos(motorista.Documentos, documentosObrigatorios)
+		}
+		todosEnviados = motorista.Status == models.StatusDocumentosAnalise && anterior != models.StatusDocumentosAnalise
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Enviar email de confirmação de recebimento
@@ -296,25 +365,42 @@ func (s *MotoristaServiceImpl) UploadDocumento(motoristaID string, request Uploa
 	return nil
 }
 
-// UploadDocumentosLote faz upload de vários documentos em uma única chamada
-func (s *MotoristaServiceImpl) UploadDocumentosLote(motoristaID string, requests []UploadDocumentoRequest) error {
-	if len(requests) == 0 {
+// ValidarTiposDocumentos confere os tipos de um envio em lote (após
+// NormalizarTipoDocumento): obrigatórios, permitidos e sem repetição. O controller a
+// chama antes de gravar os arquivos, para que um lote recusado não deixe arquivos órfãos.
+func ValidarTiposDocumentos(tipos []string) error {
+	if len(tipos) == 0 {
 		return apperrors.ErrNenhumDocumentoEnviado
 	}
 	// Map para evitar tipos duplicados na mesma requisição
 	vistos := map[string]bool{}
-	for _, r := range requests {
-		if r.TipoDocumento == "" {
+	for _, tipo := range tipos {
+		if tipo == "" {
 			return apperrors.ErrCampoObrigatorio
 		}
-		lower := strings.ToLower(r.TipoDocumento)
-		if strings.Contains(lower, "selfie") {
-			r.TipoDocumento = "selfie_cnh"
+		tipo = NormalizarTipoDocumento(tipo)
+		if !slices.Contains(documentosObrigatorios, tipo) {
+			return apperrors.ErrDocumentoTipoInvalido
 		}
-		if vistos[r.TipoDocumento] {
+		if vistos[tipo] {
 			return apperrors.ErrDocumentoDuplicadoBatch
 		}
-		vistos[r.TipoDocumento] = true
+		vistos[tipo] = true
+	}
+	return nil
+}
+
+// UploadDocumentosLote faz upload de vários documentos em uma única chamada
+func (s *MotoristaServiceImpl) UploadDocumentosLote(motoristaID string, requests []UploadDocumentoRequest) error {
+	tipos := make([]string, len(requests))
+	for i, r := range requests {
+		tipos[i] = r.TipoDocumento
+	}
+	if err := ValidarTiposDocumentos(tipos); err != nil {
+		return err
+	}
+	for _, r := range requests {
+		r.TipoDocumento = NormalizarTipoDocumento(r.TipoDocumento)
 		if err := s.UploadDocumento(motoristaID, r); err != nil {
 			return err
 		}
@@ -322,169 +408,416 @@ func (s *MotoristaServiceImpl) UploadDocumentosLote(motoristaID string, requests
 	return nil
 }
 
-// (Removida função de validação automática; aprovação agora é somente manual)
-
-// AprovarMotorista aprova manualmente um motorista
-func (s *MotoristaServiceImpl) AprovarMotorista(motoristaID string) error {
+// HistoricoDocumento retorna todas as versões enviadas do documento do tipo informado,
+// da mais recente (a atual, se houver) para a mais antiga
+func (s *MotoristaServiceImpl) HistoricoDocumento(motoristaID, tipo string) ([]models.Documento, error) {
 	motorista, err := s.getMotorista(motoristaID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Garantir que todos os documentos obrigatórios existem antes de aprovar manualmente
-	for _, tipoObrigatorio := range documentosObrigatorios {
-		encontrado := false
-		for _, doc := range motorista.Documentos {
-			if doc.TipoDocumento == tipoObrigatorio {
-				encontrado = true
-				break
-			}
+	versoes := []models.Documento{}
+	for _, doc := range motorista.Documentos {
+		if doc.TipoDocumento == tipo {
+			doc.Versao = versaoDocumento(doc)
+			versoes = append(versoes, doc)
 		}
-		if !encontrado {
-			return apperrors.ErrDocumentosObrigPendentes
+	}
+	for i := len(motorista.Historico) - 1; i >= 0; i-- {
+		if motorista.Historico[i].TipoDocumento == tipo {
+			versoes = append(versoes, motorista.Historico[i])
 		}
 	}
-
-	// Marcar todos documentos como aprovados
-	for i := range motorista.Documentos {
-		motorista.Documentos[i].Status = models.DocumentoStatusAprovado
+	if len(versoes) == 0 {
+		return nil, apperrors.ErrDocumentoNaoEncontrado
 	}
-	motorista.Status = models.StatusAprovado
-	motorista.AtualizadoEm = time.Now()
+	return versoes, nil
+}
 
-	if err := s.motoristaRepo.Atualizar(motorista); err != nil {
-		return fmt.Errorf("erro ao atualizar status do motorista: %w", err)
+// VersaoDocumento retorna uma versão específica (atual ou do histórico) do documento
+func (s *MotoristaServiceImpl) VersaoDocumento(motoristaID, tipo string, versao int) (*models.Documento, error) {
+	versoes, err := s.HistoricoDocumento(motoristaID, tipo)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versoes {
+		if versoes[i].Versao == versao {
+			return &versoes[i], nil
+		}
 	}
+	return nil, apperrors.ErrDocumentoNaoEncontrado
+}
 
-	return s.emailService.EnviarEmailAprovacao(motorista.Email, motorista.Nome)
+// versaoDocumento número da versão; documentos enviados antes do histórico são a versão 1
+func versaoDocumento(doc models.Documento) int {
+	return max(doc.Versao, 1)
 }
 
-// AtualizarPerfil atualiza telefone e email
-func (s *MotoristaServiceImpl) AtualizarPerfil(id string, telefone string, email string) (*models.Motorista, error) {
-	motorista, err := s.getMotorista(id)
+// (Removida função de validação automática; aprovação agora é somente manual)
+
+// AprovarMotorista aprova manualmente um motorista, registrando o operador responsável
+func (s *MotoristaServiceImpl) AprovarMotorista(motoristaID, operadorID string) error {
+	motorista, err := s.atualizarMotorista(motoristaID, func(motorista *models.Motorista) error {
+		if !motorista.EmailVerificado {
+			return apperrors.ErrEmailNaoVerificado
+		}
+
+		// Garantir que todos os documentos obrigatórios existem antes de aprovar manualmente
+		for _, tipoObrigatorio := range documentosObrigatorios {
+			encontrado := false
+			for _, doc := range motorista.Documentos {
+				if doc.TipoDocumento == tipoObrigatorio {
+					encontrado = true
+					break
+				}
+			}
+			if !encontrado {
+				return apperrors.ErrDocumentosObrigPendentes
+			}
+		}
+		// Documentos rejeitados só deixam de ser após o reenvio
+		if len(documentosRejeitados(motorista)) > 0 {
+			return apperrors.ErrDocumentosRejeitados
+		}
+		for _, doc := range motorista.Documentos {
+			if err := doc.ValidarDisponibilidade(); err != nil {
+				return err
+			}
+		}
+
+		// Marcar os documentos ainda pendentes como aprovados
+		motorista.AtualizadoEm = time.Now()
+		for i := range motorista.Documentos {
+			if motorista.Documentos[i].Status != models.DocumentoStatusAprovado {
+				registrarDecisao(&motorista.Documentos[i], models.DocumentoStatusAprovado, operadorID, "", "", motorista.AtualizadoEm)
+			}
+		}
+		motorista.Status = models.StatusAprovado
+		motorista.Revisoes = append(motorista.Revisoes, models.Revisao{
+			Acao:       models.RevisaoAprovado,
+			OperadorID: operadorID,
+			Data:       motorista.AtualizadoEm,
+		})
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
+	return s.emailService.EnviarEmailAprovacao(motorista.Email, motorista.Nome)
+}
+
+// AtualizarPerfil atualiza telefone e email. Com versaoEsperada diferente de zero (If-Match)
+// a alteração só é aplicada se o cadastro ainda estiver nessa versão.
+func (s *MotoristaServiceImpl) AtualizarPerfil(id string, versaoEsperada int64, telefone string, email string) (*models.Motorista, error) {
 	telefone = DigitsOnly(telefone)
 	email = strings.ToLower(strings.TrimSpace(email))
 
-	if telefone != "" {
-		if err := models.ValidarTelefone(telefone); err != nil {
-			return nil, err
+	var motorista *models.Motorista
+	err := s.motoristaRepo.WithTx(func(tx repositories.MotoristaTx) error {
+		m, err := tx.BuscarPorID(id)
+		if err != nil {
+			return apperrors.ErrMotoristaNaoEncontrado
+		}
+		if versaoEsperada != 0 && m.Versao != versaoEsperada {
+			return apperrors.ErrVersaoDesatualizada
 		}
-		motorista.Telefone = telefone
-	}
 
-	if email != "" {
-		if err := models.ValidarEmail(email); err != nil {
-			return nil, err
+		if telefone != "" {
+			if err := models.ValidarTelefone(telefone); err != nil {
+				return err
+			}
+			m.Telefone = telefone
 		}
-		motorista.Email = email
-	}
 
-	// Atualizar timestamp e salvar alterações
-	motorista.AtualizadoEm = time.Now()
-	if err := s.motoristaRepo.Atualizar(motorista); err != nil {
-		return nil, fmt.Errorf("erro ao atualizar perfil do motorista: %w", err)
-	}
+		if email == m.Email {
+			// Voltar ao email atual cancela uma troca pendente
+			m.EmailPendente = ""
+		} else if email != "" {
+			if err := models.ValidarEmail(email); err != nil {
+				return err
+			}
+			if _, err := tx.BuscarPorEmail(email); err == nil {
+				return apperrors.ErrEmailJaCadastrado
+			}
+			// A troca só é aplicada quando o novo endereço confirmar o link de verificação
+			m.EmailPendente = email
+		}
 
+		// Atualizar timestamp e salvar alterações
+		m.AtualizadoEm = time.Now()
+		if err := tx.Atualizar(m); err != nil {
+			return fmt.Errorf("erro ao atualizar perfil do motorista: %w", err)
+		}
+		motorista = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return motorista, nil
 }
 
 // AlterarSenha altera senha com validações
 func (s *MotoristaServiceImpl) AlterarSenha(id, senhaAtual, novaSenha, confirmacao string) error {
-	motorista, err := s.getMotorista(id)
-	if err != nil {
-		return err
-	}
-
 	senhaAtual = strings.TrimSpace(senhaAtual)
 	novaSenha = strings.TrimSpace(novaSenha)
 	confirmacao = strings.TrimSpace(confirmacao)
 
-	if senhaAtual == "" {
-		return apperrors.ErrCampoObrigatorio
-	}
-	if motorista.Senha != senhaAtual {
-		return apperrors.ErrSenhaAtualIncorreta
-	}
-	if novaSenha == "" {
-		return apperrors.ErrCampoObrigatorio
-	}
-	if novaSenha != confirmacao {
-		return apperrors.ErrSenhasNaoConferem
-	}
-	if _, err := models.ValidarForcaSenha(novaSenha); err != nil {
-		return err
-	}
+	_, err := s.atualizarMotorista(id, func(motorista *models.Motorista) error {
+		if senhaAtual == "" {
+			return apperrors.ErrCampoObrigatorio
+		}
+		if !s.hasher.Comparar(motorista.Senha, senhaAtual) {
+			return apperrors.ErrSenhaAtualIncorreta
+		}
+		if novaSenha == "" {
+			return apperrors.ErrCampoObrigatorio
+		}
+		if novaSenha != confirmacao {
+			return apperrors.ErrSenhasNaoConferem
+		}
+		if _, err := models.ValidarForcaSenha(novaSenha); err != nil {
+			return err
+		}
 
-	motorista.Senha = novaSenha
-	motorista.AtualizadoEm = time.Now()
+		senhaHash, err := s.hasher.Hash(novaSenha)
+		if err != nil {
+			return fmt.Errorf("erro ao gerar hash da senha: %w", err)
+		}
 
-	if err := s.motoristaRepo.Atualizar(motorista); err != nil {
-		return fmt.Errorf("erro ao alterar senha do motorista: %w", err)
-	}
+		motorista.Senha = senhaHash
+		motorista.AtualizadoEm = time.Now()
+		return nil
+	})
+	return err
+}
 
-	return nil
+// UploadFotoPerfil registra a chave da foto (arquivo já gravado no FileStore pelo controller)
+func (s *MotoristaServiceImpl) UploadFotoPerfil(id string, chave string, formato string, tamanho int64) error {
+	_, err := s.atualizarMotorista(id, func(m *models.Motorista) error {
+		if err := models.ValidarFotoPerfil(formato, tamanho); err != nil {
+			return err
+		}
+		m.FotoPerfil = chave
+		m.AtualizadoEm = time.Now()
+		return nil
+	})
+	return err
 }
 
-// UploadFotoPerfil salva caminho da foto (arquivo já salvo pelo controller)
-func (s *MotoristaServiceImpl) UploadFotoPerfil(id string, caminho string, formato string, tamanho int64) error {
-	m, err := s.getMotorista(id)
+// RejeitarMotorista rejeita o cadastro com motivo, registrando o operador responsável.
+// Todos os documentos ainda não aprovados são rejeitados com esse motivo; para apontar
+// apenas os documentos com problema use RejeitarDocumento.
+func (s *MotoristaServiceImpl) RejeitarMotorista(motoristaID, operadorID, motivo string) error {
+	motorista, err := s.atualizarMotorista(motoristaID, func(motorista *models.Motorista) error {
+		motorista.Status = models.StatusRejeitado
+		motorista.AtualizadoEm = time.Now()
+		for i := range motorista.Documentos {
+			if motorista.Documentos[i].Status != models.DocumentoStatusAprovado {
+				registrarDecisao(&motorista.Documentos[i], models.DocumentoStatusRejeitado, operadorID, models.MotivoOutro, motivo, motorista.AtualizadoEm)
+			}
+		}
+		motorista.Revisoes = append(motorista.Revisoes, models.Revisao{
+			Acao:       models.RevisaoRejeitado,
+			OperadorID: operadorID,
+			Motivo:     motivo,
+			Data:       motorista.AtualizadoEm,
+		})
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	formatoU := strings.ToUpper(formato)
-	permitidos := map[string]bool{"JPG": true, "JPEG": true, "PNG": true, "WEBP": true}
-	if !permitidos[formatoU] {
-		return apperrors.ErrFotoFormatoInvalido
+
+	return s.emailService.EnviarEmailRejeicao(motorista.Email, motorista.Nome, motivo, documentosRejeitados(motorista))
+}
+
+// AprovarDocumento aprova um documento, identificado pelo ID ou pelo tipo, e recalcula o
+// status do cadastro. A aprovação do último documento pendente aprova o motorista, o que
+// exige email verificado.
+func (s *MotoristaServiceImpl) AprovarDocumento(motoristaID, documento, operadorID string) (*models.Motorista, error) {
+	return s.revisarDocumento(motoristaID, documento, operadorID, func(motorista *models.Motorista, doc *models.Documento, agora time.Time) {
+		registrarDecisao(doc, models.DocumentoStatusAprovado, operadorID, "", "", agora)
+		motorista.Revisoes = append(motorista.Revisoes, models.Revisao{
+			Acao:       models.RevisaoDocumentoAprovado,
+			OperadorID: operadorID,
+			Documento:  doc.TipoDocumento,
+			Data:       agora,
+		})
+	})
+}
+
+// RejeitarDocumento rejeita um documento com um código de motivo (models.Motivo*) e uma
+// observação para o motorista, obrigatória com o motivo "outro". Quando não resta nenhum
+// documento pendente, o motorista recebe um email com todos os documentos a reenviar.
+func (s *MotoristaServiceImpl) RejeitarDocumento(motoristaID, documento, operadorID, codigoMotivo, observacao string) (*models.Motorista, error) {
+	observacao = strings.TrimSpace(observacao)
+	if !models.MotivoRejeicaoValido(codigoMotivo) {
+		return nil, apperrors.ErrMotivoRejeicaoInvalido
+	}
+	if codigoMotivo == models.MotivoOutro && observacao == "" {
+		return nil, apperrors.ErrCampoObrigatorio
+	}
+	return s.revisarDocumento(motoristaID, documento, operadorID, func(motorista *models.Motorista, doc *models.Documento, agora time.Time) {
+		registrarDecisao(doc, models.DocumentoStatusRejeitado, operadorID, codigoMotivo, observacao, agora)
+		motorista.Revisoes = append(motorista.Revisoes, models.Revisao{
+			Acao:         models.RevisaoDocumentoRejeitado,
+			OperadorID:   operadorID,
+			Documento:    doc.TipoDocumento,
+			CodigoMotivo: codigoMotivo,
+			Motivo:       observacao,
+			Data:         agora,
+		})
+	})
+}
+
+// revisarDocumento aplica a decisão sobre um documento, deriva o novo status do cadastro
+// e avisa o motorista quando a revisão é concluída
+func (s *MotoristaServiceImpl) revisarDocumento(motoristaID, documento, operadorID string, decidir func(m *models.Motorista, doc *models.Documento, agora time.Time)) (*models.Motorista, error) {
+	var anterior models.StatusMotorista
+	motorista, err := s.atualizarMotorista(motoristaID, func(motorista *models.Motorista) error {
+		anterior = motorista.Status
+		if !statusEmRevisao(motorista.Status) && motorista.Status != models.StatusAprovado {
+			return apperrors.ErrRevisaoNaoPermitida
+		}
+		i := indiceDocumento(motorista, documento)
+		if i < 0 {
+			return apperrors.ErrDocumentoNaoEncontrado
+		}
+		// O arquivo só pode ser revisado depois da verificação antivírus
+		if err := motorista.Documentos[i].ValidarDisponibilidade(); err != nil {
+			return err
+		}
+		agora := time.Now()
+		decidir(motorista, &motorista.Documentos[i], agora)
+		motorista.Status = models.StatusPorDocumentos(motorista.Documentos, documentosObrigatorios)
+		if motorista.Status == models.StatusAprovado && !motorista.EmailVerificado {
+			return apperrors.ErrEmailNaoVerificado
+		}
+		motorista.AtualizadoEm = agora
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if tamanho > 5*1024*1024 {
-		return apperrors.ErrFotoMuitoGrande
+
+	switch {
+	case motorista.Status == models.StatusAprovado && anterior != models.StatusAprovado:
+		if err := s.emailService.EnviarEmailAprovacao(motorista.Email, motorista.Nome); err != nil {
+			fmt.Printf("Erro ao enviar email de aprovação: %v\n", err)
+		}
+	case motorista.Status == models.StatusRejeitado && !documentosPendentes(motorista):
+		if err := s.emailService.EnviarEmailRejeicao(motorista.Email, motorista.Nome, "", documentosRejeitados(motorista)); err != nil {
+			fmt.Printf("Erro ao enviar email de rejeição: %v\n", err)
+		}
 	}
-	m.FotoPerfil = caminho
-	m.AtualizadoEm = time.Now()
-	return s.motoristaRepo.Atualizar(m)
+	return motorista, nil
 }
 
-// SolicitarExclusao marca status aguardando_exclusao
-func (s *MotoristaServiceImpl) SolicitarExclusao(id string) error {
-	m, err := s.getMotorista(id)
+// operadorAntivirus registrado como autor das rejeições feitas pela verificação antivírus
+const operadorAntivirus = "antivirus"
+
+// RegistrarVarredura grava o resultado da verificação antivírus nos documentos atuais
+// que usam o arquivo e ainda aguardam a verificação. O limpo passa a poder ser revisado;
+// o infectado é rejeitado com o motivo models.MotivoArquivoInfectado, para que o
+// motorista envie outro arquivo.
+func (s *MotoristaServiceImpl) RegistrarVarredura(motoristaID, chaveArquivo string, infectado bool, ameaca string) error {
+	motorista, err := s.atualizarMotorista(motoristaID, func(motorista *models.Motorista) error {
+		agora := time.Now()
+		encontrado := false
+		for i := range motorista.Documentos {
+			doc := &motorista.Documentos[i]
+			if doc.ChaveArquivo != chaveArquivo || !doc.AguardandoVarredura() {
+				continue
+			}
+			encontrado = true
+			if !infectado {
+				doc.Varredura = models.VarreduraLimpo
+				continue
+			}
+			doc.Varredura = models.VarreduraInfectado
+			doc.Ameaca = ameaca
+			registrarDecisao(doc, models.DocumentoStatusRejeitado, operadorAntivirus, models.MotivoArquivoInfectado, "", agora)
+			motorista.Revisoes = append(motorista.Revisoes, models.Revisao{
+				Acao:         models.RevisaoDocumentoRejeitado,
+				OperadorID:   operadorAntivirus,
+				Documento:    doc.TipoDocumento,
+				CodigoMotivo: models.MotivoArquivoInfectado,
+				Motivo:       ameaca,
+				Data:         agora,
+			})
+		}
+		// O documento pode ter sido substituído por um novo envio durante a verificação
+		if !encontrado {
+			return apperrors.ErrDocumentoNaoEncontrado
+		}
+		if statusEmRevisao(motorista.Status) {
+			motorista.Status = models.StatusPorDocumentos(motorista.Documentos, documentosObrigatorios)
+		}
+		motorista.AtualizadoEm = agora
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	m.Status = models.StatusAguardandoExclusao
-	m.AtualizadoEm = time.Now()
-	return s.motoristaRepo.Atualizar(m)
+
+	// Como na revisão, o motorista é avisado quando não resta nenhum documento pendente
+	if infectado && motorista.Status == models.StatusRejeitado && !documentosPendentes(motorista) {
+		if err := s.emailService.EnviarEmailRejeicao(motorista.Email, motorista.Nome, "", documentosRejeitados(motorista)); err != nil {
+			fmt.Printf("Erro ao enviar email de rejeição: %v\n", err)
+		}
+	}
+	return nil
 }
 
-// ConfirmarExclusao marca status encerrado
-func (s *MotoristaServiceImpl) ConfirmarExclusao(id string) error {
-	m, err := s.getMotorista(id)
-	if err != nil {
-		return err
+// statusEmRevisao status em que o cadastro acompanha os documentos (models.StatusPorDocumentos)
+func statusEmRevisao(status models.StatusMotorista) bool {
+	switch status {
+	case models.StatusAguardandoAprovacao, models.StatusDocumentosAnalise, models.StatusRejeitado:
+		return true
 	}
-	m.Status = models.StatusEncerrado
-	m.AtualizadoEm = time.Now()
-	return s.motoristaRepo.Atualizar(m)
+	return false
 }
 
-// RejeitarMotorista rejeita um motorista com motivo
-func (s *MotoristaServiceImpl) RejeitarMotorista(motoristaID string, motivo string) error {
-	motorista, err := s.getMotorista(motoristaID)
-	if err != nil {
-		return err
+// indiceDocumento posição do documento com o ID ou o tipo informado, ou -1
+func indiceDocumento(m *models.Motorista, documento string) int {
+	for i, doc := range m.Documentos {
+		if doc.ID == documento || doc.TipoDocumento == documento {
+			return i
+		}
 	}
+	return -1
+}
 
-	motorista.Status = models.StatusRejeitado
-	motorista.AtualizadoEm = time.Now()
+// registrarDecisao grava no documento a decisão do revisor; o motivo e a observação
+// da rejeição anterior são descartados ao aprovar
+func registrarDecisao(doc *models.Documento, status, operadorID, codigoMotivo, observacao string, agora time.Time) {
+	doc.Status = status
+	doc.MotivoRejeicao = codigoMotivo
+	doc.Observacao = observacao
+	doc.RevisadoPor = operadorID
+	doc.RevisadoEm = &agora
+}
 
-	if err := s.motoristaRepo.Atualizar(motorista); err != nil {
-		return fmt.Errorf("erro ao atualizar status do motorista: %w", err)
+// documentosRejeitados documentos que o motorista precisa reenviar
+func documentosRejeitados(m *models.Motorista) []models.Documento {
+	var rejeitados []models.Documento
+	for _, doc := range m.Documentos {
+		if doc.Status == models.DocumentoStatusRejeitado {
+			rejeitados = append(rejeitados, doc)
+		}
 	}
+	return rejeitados
+}
 
-	return s.emailService.EnviarEmailRejeicao(motorista.Email, motorista.Nome, motivo)
+// documentosPendentes indica se ainda há documentos aguardando revisão
+func documentosPendentes(m *models.Motorista) bool {
+	for _, doc := range m.Documentos {
+		if doc.Status == models.DocumentoStatusPendente {
+			return true
+		}
+	}
+	return false
 }
 
 // BuscarMotorista busca um motorista por ID
@@ -492,24 +825,194 @@ func (s *MotoristaServiceImpl) BuscarMotorista(id string) (*models.Motorista, er
 	return s.motoristaRepo.BuscarPorID(id)
 }
 
+// ListarMotoristas pesquisa motoristas para a área administrativa.
+// Erros de filtro e cursor já são apperrors; os demais resultam em erro interno.
+func (s *MotoristaServiceImpl) ListarMotoristas(filtro repositories.FiltroMotoristas) (*repositories.PaginaMotoristas, error) {
+	pagina, err := s.motoristaRepo.Pesquisar(filtro)
+	if err != nil {
+		if _, ok := err.(*apperrors.Error); !ok {
+			fmt.Printf("Erro ao pesquisar motoristas: %v\n", err)
+		}
+		return nil, err
+	}
+	return pagina, nil
+}
+
 // VerificarForcaSenha verifica a força de uma senha
 func (s *MotoristaServiceImpl) VerificarForcaSenha(senha string) (string, error) {
 	return models.ValidarForcaSenha(senha)
 }
 
-// LoginMotorista realiza o login de um motorista
-func (s *MotoristaServiceImpl) LoginMotorista(email, senha string) (*models.Motorista, error) {
+// LoginMotorista realiza o login de um motorista.
+// Email inexistente e senha incorreta retornam o mesmo erro e contam para o limite de tentativas.
+func (s *MotoristaServiceImpl) LoginMotorista(email, senha, ip string) (*models.Motorista, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || senha == "" {
+		return nil, apperrors.ErrCampoObrigatorio
+	}
+	if err := models.ValidarEmail(email); err != nil {
+		return nil, err
+	}
+
+	if err := s.limitador.Verificar(email, ip); err != nil {
+		return nil, err
+	}
+
 	motorista, err := s.motoristaRepo.BuscarPorEmail(email)
 	if err != nil {
-		return nil, apperrors.ErrMotoristaNaoEncontrado
+		// Mesmo custo de uma senha incorreta, para o tempo de resposta não revelar o cadastro
+		s.hasher.Comparar(auth.HashFicticio, senha)
+		s.registrarFalhaLogin(email, ip, nil)
+		return nil, apperrors.ErrCredenciaisInvalidas
+	}
+	if !s.hasher.Comparar(motorista.Senha, senha) {
+		s.registrarFalhaLogin(email, ip, motorista)
+		return nil, apperrors.ErrCredenciaisInvalidas
 	}
-	if motorista.Senha != senha {
-		return nil, apperrors.ErrSenhaAtualIncorreta
+	if err := s.limitador.RegistrarSucesso(email); err != nil {
+		fmt.Printf("Erro ao limpar tentativas de login: %v\n", err)
+	}
+
+	// Status só é avaliado após a senha conferir, para não expor o estado da conta
+	if err := validarStatusLogin(motorista.Status); err != nil {
+		return nil, err
+	}
+
+	// Migração transparente: registros em texto puro (ou com custo antigo) são rehasheados
+	if s.hasher.PrecisaRehash(motorista.Senha) {
+		if senhaHash, err := s.hasher.Hash(senha); err == nil {
+			senhaAntiga := motorista.Senha
+			_, err := s.atualizarMotorista(motorista.ID, func(m *models.Motorista) error {
+				// Só substitui o hash verificado; uma troca de senha concorrente prevalece
+				if m.Senha == senhaAntiga {
+					m.Senha = senhaHash
+				}
+				return nil
+			})
+			if err != nil {
+				// Log do erro, mas não falha o login
+				fmt.Printf("Erro ao migrar hash da senha: %v\n", err)
+			} else {
+				motorista.Senha = senhaHash
+			}
+		}
 	}
 
 	return motorista, nil
 }
 
+// VerificarSegundoFator conclui o login de motoristas com 2FA ativo.
+// Códigos errados contam para o mesmo limite de tentativas da senha.
+func (s *MotoristaServiceImpl) VerificarSegundoFator(id, codigo, ip string) (*models.Motorista, error) {
+	motorista, err := s.getMotorista(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limitador.Verificar(motorista.Email, ip); err != nil {
+		return nil, err
+	}
+	// A verificação e o registro do passo usado (ou do código de recuperação consumido)
+	// ocorrem na mesma unidade de trabalho, impedindo o reuso de um código em paralelo
+	atualizado, err := s.atualizarMotorista(id, func(m *models.Motorista) error {
+		if err := verificarSegundoFator(&m.DoisFatores, codigo, time.Now()); err != nil {
+			return err
+		}
+		m.AtualizadoEm = time.Now()
+		return nil
+	})
+	if err != nil {
+		if err == apperrors.ErrCodigo2FAInvalido {
+			s.registrarFalhaLogin(motorista.Email, ip, motorista)
+		}
+		return nil, err
+	}
+	if err := s.limitador.RegistrarSucesso(atualizado.Email); err != nil {
+		fmt.Printf("Erro ao limpar tentativas de login: %v\n", err)
+	}
+	return atualizado, nil
+}
+
+// IniciarDoisFatores gera o segredo TOTP e os códigos de recuperação do motorista
+func (s *MotoristaServiceImpl) IniciarDoisFatores(id string) (*InscricaoDoisFatores, error) {
+	var inscricao *InscricaoDoisFatores
+	_, err := s.atualizarMotorista(id, func(motorista *models.Motorista) error {
+		var err error
+		inscricao, err = iniciarInscricao(&motorista.DoisFatores, motorista.Email)
+		if err != nil {
+			return err
+		}
+		motorista.AtualizadoEm = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inscricao, nil
+}
+
+// ConfirmarDoisFatores ativa o 2FA após o primeiro código válido do aplicativo
+func (s *MotoristaServiceImpl) ConfirmarDoisFatores(id, codigo string) error {
+	_, err := s.atualizarMotorista(id, func(motorista *models.Motorista) error {
+		if err := confirmarInscricao(&motorista.DoisFatores, codigo, time.Now()); err != nil {
+			return err
+		}
+		motorista.AtualizadoEm = time.Now()
+		return nil
+	})
+	return err
+}
+
+// DesativarDoisFatores remove o 2FA mediante um código válido (TOTP ou de recuperação)
+func (s *MotoristaServiceImpl) DesativarDoisFatores(id, codigo string) error {
+	_, err := s.atualizarMotorista(id, func(motorista *models.Motorista) error {
+		if err := verificarSegundoFator(&motorista.DoisFatores, codigo, time.Now()); err != nil {
+			return err
+		}
+		motorista.DoisFatores = models.DoisFatores{}
+		motorista.AtualizadoEm = time.Now()
+		return nil
+	})
+	return err
+}
+
+// registrarFalhaLogin contabiliza a falha e avisa o motorista quando a conta é bloqueada
+func (s *MotoristaServiceImpl) registrarFalhaLogin(email, ip string, motorista *models.Motorista) {
+	ate, err := s.limitador.RegistrarFalha(email, ip)
+	if err != nil {
+		fmt.Printf("Erro ao registrar tentativa de login: %v\n", err)
+		return
+	}
+	if ate.IsZero() || motorista == nil {
+		return
+	}
+	if err := s.emailService.EnviarEmailBloqueioConta(motorista.Email, motorista.Nome, ate); err != nil {
+		// Log do erro, mas não falha o login
+		fmt.Printf("Erro ao enviar email de bloqueio: %v\n", err)
+	}
+}
+
+// validarStatusLogin recusa o login de contas em análise, em exclusão ou encerradas
+func validarStatusLogin(status models.StatusMotorista) error {
+	switch status {
+	case models.StatusDocumentosAnalise:
+		return apperrors.ErrLoginDocumentosEmAnalise
+	case models.StatusAguardandoExclusao:
+		return apperrors.ErrLoginAguardandoExclusao
+	case models.StatusEncerrado:
+		return apperrors.ErrLoginContaEncerrada
+	}
+	return nil
+}
+
+// ProximoPassoLogin indica para onde o cliente deve levar o motorista após o login
+func ProximoPassoLogin(status models.StatusMotorista) string {
+	switch status {
+	case models.StatusAguardandoAprovacao, models.StatusRejeitado:
+		return ProximoPassoUploadDocumentos
+	}
+	return ProximoPassoPainel
+}
+
 // DigitsOnly remove caracteres especiais de strings como CPF, CNH e telefone
 func DigitsOnly(s string) string {
 	return regexp.MustCompile(`\D`).ReplaceAllString(s, "")
@@ -3,14 +3,21 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/crypto/bcrypt"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
 	"taxi_service/models"
+	"taxi_service/repositories"
 )
 
 // MockMotoristaRepository is a mock implementation of repositories.MotoristaRepository
@@ -70,6 +77,19 @@ func (m *MockMotoristaRepository) ListarTodos() ([]*models.Motorista, error) {
 	return args.Get(0).([]*models.Motorista), args.Error(1)
 }
 
+func (m *MockMotoristaRepository) Pesquisar(filtro repositories.FiltroMotoristas) (*repositories.PaginaMotoristas, error) {
+	args := m.Called(filtro)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repositories.PaginaMotoristas), args.Error(1)
+}
+
+// WithTx executa fn sobre o próprio mock; as expectativas continuam nos métodos chamados por fn
+func (m *MockMotoristaRepository) WithTx(fn func(tx repositories.MotoristaTx) error) error {
+	return fn(m)
+}
+
 // MockEmailService is a mock email service for testing
 type MockEmailService struct {
 	mock.Mock
@@ -112,12 +132,76 @@ func (m *MockEmailService) EnviarEmailAprovacao(email, nome string) error {
 	return args.Error(0)
 }
 
-func (m *MockEmailService) EnviarEmailRejeicao(email, nome, motivo string) error {
-	args := m.Called(email, nome, motivo)
+func (m *MockEmailService) EnviarEmailRejeicao(email, nome, motivo string, documentos []models.Documento) error {
+	args := m.Called(email, nome, motivo, documentos)
+	tipos := []string{}
+	for _, doc := range documentos {
+		tipos = append(tipos, doc.TipoDocumento)
+	}
 	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
 		Para:    email,
 		Assunto: "Documentos rejeitados - Taxi Service",
-		Corpo:   fmt.Sprintf("Olá %s, seus documentos foram rejeitados. Motivo: %s", nome, motivo),
+		Corpo:   fmt.Sprintf("Olá %s, seus documentos foram rejeitados. Motivo: %s. Reenviar: %s", nome, motivo, strings.Join(tipos, ", ")),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailBloqueioConta(email, nome string, ate time.Time) error {
+	args := m.Called(email, nome, ate)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Acesso bloqueado temporariamente - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, seu acesso está bloqueado até %s", nome, ate.Format("02/01/2006 15:04")),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailVerificacao(email, nome, link string) error {
+	args := m.Called(email, nome, link)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Confirme seu email - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, confirme seu email em %s", nome, link),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailAvisoTrocaEmail(email, nome, novoEmail string) error {
+	args := m.Called(email, nome, novoEmail)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Solicitação de troca de email - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, foi solicitada a troca do seu email para %s", nome, novoEmail),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailExclusaoConta(email, nome, link string) error {
+	args := m.Called(email, nome, link)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Solicitação de Exclusão de Conta - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, confirme a exclusão da sua conta em %s", nome, link),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailExclusaoConfirmada(email, nome, link string, exclusaoEm time.Time) error {
+	args := m.Called(email, nome, link, exclusaoEm)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Conta encerrada - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, cancele a exclusão da sua conta em %s", nome, link),
+	})
+	return args.Error(0)
+}
+
+func (m *MockEmailService) EnviarEmailRecuperacao(email, nome, link string) error {
+	args := m.Called(email, nome, link)
+	m.emailsEnviados = append(m.emailsEnviados, EmailEnviado{
+		Para:    email,
+		Assunto: "Recuperação de Conta - Taxi Service",
+		Corpo:   fmt.Sprintf("Olá %s, redefina sua senha em %s", nome, link),
 	})
 	return args.Error(0)
 }
@@ -152,14 +236,13 @@ func TestCadastrarMotorista(t *testing.T) {
 	t.Run("Successful Registration", func(t *testing.T) {
 		mockRepo := new(MockMotoristaRepository)
 		mockEmail := new(MockEmailService)
-		service := NewMotoristaService(mockRepo, mockEmail)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 		request := createValidRequest()
 
 		mockRepo.On("BuscarPorCPF", request.CPF).Return(nil, errors.New("not found"))
 		mockRepo.On("BuscarPorCNH", request.CNH).Return(nil, errors.New("not found"))
 		mockRepo.On("BuscarPorEmail", request.Email).Return(nil, errors.New("not found"))
 		mockRepo.On("Criar", mock.AnythingOfType("*models.Motorista")).Return(nil)
-		mockEmail.On("EnviarEmailConfirmacao", request.Email, request.Nome).Return(nil)
 
 		// Execute the method under test
 		motorista, err := service.CadastrarMotorista(request)
@@ -171,11 +254,9 @@ func TestCadastrarMotorista(t *testing.T) {
 		assert.Equal(t, request.Email, motorista.Email)
 		assert.Equal(t, models.StatusAguardandoAprovacao, motorista.Status)
 
-		// Verify email was sent
-		emails := mockEmail.ObterEmailsEnviados()
-		assert.Len(t, emails, 1)
-		assert.Equal(t, request.Email, emails[0].Para)
-		assert.Contains(t, emails[0].Assunto, "Cadastro realizado com sucesso")
+		// The verification link is the only sign-up email and is sent by the controller
+		assert.Empty(t, mockEmail.ObterEmailsEnviados())
+		mockEmail.AssertNotCalled(t, "EnviarEmailConfirmacao", mock.Anything, mock.Anything)
 
 		// Verify all mock expectations were met
 		mockRepo.AssertExpectations(t)
@@ -185,7 +266,7 @@ func TestCadastrarMotorista(t *testing.T) {
 	t.Run("Password Mismatch Error", func(t *testing.T) {
 		mockRepo := new(MockMotoristaRepository)
 		mockEmail := new(MockEmailService)
-		service := NewMotoristaService(mockRepo, mockEmail)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 		request := createValidRequest()
 		request.CPF = "52998224725"
 		request.ConfirmacaoSenha = "MinhaSenh@456"
@@ -198,7 +279,7 @@ func TestCadastrarMotorista(t *testing.T) {
 	t.Run("CPF Already Exists Error", func(t *testing.T) {
 		mockRepo := new(MockMotoristaRepository)
 		mockEmail := new(MockEmailService)
-		service := NewMotoristaService(mockRepo, mockEmail)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 		request := createValidRequest()
 		request.Email = "maria@email.com"
 		request.CNH = "98765432109"
@@ -221,7 +302,7 @@ func TestUploadDocumento(t *testing.T) {
 	// Setup
 	mockRepo := new(MockMotoristaRepository)
 	mockEmail := new(MockEmailService)
-	service := NewMotoristaService(mockRepo, mockEmail)
+	service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 
 	// Create a test driver
 	testDriverID := uuid.New().String()
@@ -235,10 +316,11 @@ func TestUploadDocumento(t *testing.T) {
 
 	// Valid document upload request
 	validUploadRequest := UploadDocumentoRequest{
-		TipoDocumento:  "CNH",
-		CaminhoArquivo: "/uploads/cnh_test.jpg",
-		Formato:        "JPG",
-		Tamanho:        2 * 1024 * 1024, // 2MB
+		TipoDocumento: "CNH",
+		ChaveArquivo:  "/uploads/cnh_test.jpg",
+		Formato:       "JPG",
+		Tamanho:       2 * 1024 * 1024, // 2MB
+		Checksum:      "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
 	}
 
 	t.Run("Successful Document Upload", func(t *testing.T) {
@@ -254,6 +336,7 @@ func TestUploadDocumento(t *testing.T) {
 		assert.Len(t, testDriver.Documentos, 1)
 		assert.Equal(t, "CNH", testDriver.Documentos[0].TipoDocumento)
 		assert.Equal(t, "JPG", testDriver.Documentos[0].Formato)
+		assert.Equal(t, validUploadRequest.Checksum, testDriver.Documentos[0].Checksum)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -262,24 +345,24 @@ func TestUploadDocumento(t *testing.T) {
 		// Reset mocks
 		mockRepo = new(MockMotoristaRepository)
 		mockEmail = new(MockEmailService)
-		service = NewMotoristaService(mockRepo, mockEmail)
+		service = NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 
 		// Update driver with the first document already added
 		testDriver.Documentos = []models.Documento{
 			{
-				TipoDocumento:  "CNH",
-				CaminhoArquivo: "/uploads/cnh_test.jpg",
-				Formato:        "JPG",
-				Status:         "pendente",
+				TipoDocumento: "CNH",
+				ChaveArquivo:  "/uploads/cnh_test.jpg",
+				Formato:       "JPG",
+				Status:        "pendente",
 			},
 		}
 
 		// Setup mock expectations for CRLV upload
 		crlvRequest := UploadDocumentoRequest{
-			TipoDocumento:  "CRLV",
-			CaminhoArquivo: "/uploads/crlv_test.png",
-			Formato:        "PNG",
-			Tamanho:        int64(1.5 * 1024 * 1024), // 1.5MB
+			TipoDocumento: "CRLV",
+			ChaveArquivo:  "/uploads/crlv_test.png",
+			Formato:       "PNG",
+			Tamanho:       int64(1.5 * 1024 * 1024), // 1.5MB
 		}
 
 		mockRepo.On("BuscarPorID", testDriverID).Return(testDriver, nil).Once()
@@ -291,10 +374,10 @@ func TestUploadDocumento(t *testing.T) {
 
 		// Setup mock expectations for selfie upload
 		selfieRequest := UploadDocumentoRequest{
-			TipoDocumento:  "selfie_cnh",
-			CaminhoArquivo: "/uploads/selfie_test.jpg",
-			Formato:        "JPG",
-			Tamanho:        1 * 1024 * 1024, // 1MB
+			TipoDocumento: "selfie_cnh",
+			ChaveArquivo:  "/uploads/selfie_test.jpg",
+			Formato:       "JPG",
+			Tamanho:       1 * 1024 * 1024, // 1MB
 		}
 
 		// The driver now should have 2 documents
@@ -305,16 +388,18 @@ func TestUploadDocumento(t *testing.T) {
 			Status: models.StatusAguardandoAprovacao,
 			Documentos: []models.Documento{
 				{
-					TipoDocumento:  "CNH",
-					CaminhoArquivo: "/uploads/cnh_test.jpg",
-					Formato:        "JPG",
-					Status:         "pendente",
+					TipoDocumento: "CNH",
+					ChaveArquivo:  "/uploads/cnh_test.jpg",
+					Formato:       "JPG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "CRLV",
-					CaminhoArquivo: "/uploads/crlv_test.png",
-					Formato:        "PNG",
-					Status:         "pendente",
+					TipoDocumento: "CRLV",
+					ChaveArquivo:  "/uploads/crlv_test.png",
+					Formato:       "PNG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 			},
 		}
@@ -329,28 +414,32 @@ func TestUploadDocumento(t *testing.T) {
 
 		// Final driver state with status change
 		driverWithAllDocs := &models.Motorista{
-			ID:     testDriverID,
-			Nome:   "Test Driver",
-			Email:  "test@driver.com",
-			Status: models.StatusDocumentosAnalise,
+			ID:              testDriverID,
+			Nome:            "Test Driver",
+			Email:           "test@driver.com",
+			EmailVerificado: true,
+			Status:          models.StatusDocumentosAnalise,
 			Documentos: []models.Documento{
 				{
-					TipoDocumento:  "CNH",
-					CaminhoArquivo: "/uploads/cnh_test.jpg",
-					Formato:        "JPG",
-					Status:         "pendente",
+					TipoDocumento: "CNH",
+					ChaveArquivo:  "/uploads/cnh_test.jpg",
+					Formato:       "JPG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "CRLV",
-					CaminhoArquivo: "/uploads/crlv_test.png",
-					Formato:        "PNG",
-					Status:         "pendente",
+					TipoDocumento: "CRLV",
+					ChaveArquivo:  "/uploads/crlv_test.png",
+					Formato:       "PNG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "selfie_cnh",
-					CaminhoArquivo: "/uploads/selfie_test.jpg",
-					Formato:        "JPG",
-					Status:         "pendente",
+					TipoDocumento: "selfie_cnh",
+					ChaveArquivo:  "/uploads/selfie_test.jpg",
+					Formato:       "JPG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 			},
 		}
@@ -375,13 +464,13 @@ func TestUploadDocumento(t *testing.T) {
 	t.Run("File Too Large Error", func(t *testing.T) {
 		mockRepo := new(MockMotoristaRepository)
 		mockEmail := new(MockEmailService)
-		service := NewMotoristaService(mockRepo, mockEmail)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 
 		largeFileRequest := UploadDocumentoRequest{
-			TipoDocumento:  "CNH",
-			CaminhoArquivo: "/uploads/cnh_large.jpg",
-			Formato:        "JPG",
-			Tamanho:        6 * 1024 * 1024, // 6MB - too large
+			TipoDocumento: "CNH",
+			ChaveArquivo:  "/uploads/cnh_large.jpg",
+			Formato:       "JPG",
+			Tamanho:       6 * 1024 * 1024, // 6MB - too large
 		}
 
 		err := service.UploadDocumento(testDriverID, largeFileRequest)
@@ -393,13 +482,13 @@ func TestUploadDocumento(t *testing.T) {
 		// Reset mocks
 		mockRepo = new(MockMotoristaRepository)
 		mockEmail = new(MockEmailService)
-		service = NewMotoristaService(mockRepo, mockEmail)
+		service = NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 
 		invalidFormatRequest := UploadDocumentoRequest{
-			TipoDocumento:  "CNH",
-			CaminhoArquivo: "/uploads/cnh.txt",
-			Formato:        "TXT",
-			Tamanho:        1 * 1024 * 1024,
+			TipoDocumento: "CNH",
+			ChaveArquivo:  "/uploads/cnh.txt",
+			Formato:       "TXT",
+			Tamanho:       1 * 1024 * 1024,
 		}
 
 		// No need to mock repository calls since validation should fail first
@@ -413,32 +502,36 @@ func TestUploadDocumento(t *testing.T) {
 		// Reset mocks
 		mockRepo = new(MockMotoristaRepository)
 		mockEmail = new(MockEmailService)
-		service = NewMotoristaService(mockRepo, mockEmail)
+		service = NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
 		mockEmail.LimparEmails()
 
 		driverWithAllDocs := &models.Motorista{
-			ID:     testDriverID,
-			Nome:   "Test Driver",
-			Email:  "test@driver.com",
-			Status: models.StatusDocumentosAnalise,
+			ID:              testDriverID,
+			Nome:            "Test Driver",
+			Email:           "test@driver.com",
+			EmailVerificado: true,
+			Status:          models.StatusDocumentosAnalise,
 			Documentos: []models.Documento{
 				{
-					TipoDocumento:  "CNH",
-					CaminhoArquivo: "/uploads/cnh_test.jpg",
-					Formato:        "JPG",
-					Status:         "pendente",
+					TipoDocumento: "CNH",
+					ChaveArquivo:  "/uploads/cnh_test.jpg",
+					Formato:       "JPG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "CRLV",
-					CaminhoArquivo: "/uploads/crlv_test.png",
-					Formato:        "PNG",
-					Status:         "pendente",
+					TipoDocumento: "CRLV",
+					ChaveArquivo:  "/uploads/crlv_test.png",
+					Formato:       "PNG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "selfie_cnh",
-					CaminhoArquivo: "/uploads/selfie_test.jpg",
-					Formato:        "JPG",
-					Status:         "pendente",
+					TipoDocumento: "selfie_cnh",
+					ChaveArquivo:  "/uploads/selfie_test.jpg",
+					Formato:       "JPG",
+					Status:        "pendente",
+					Varredura:     models.VarreduraLimpo,
 				},
 			},
 		}
@@ -453,22 +546,25 @@ func TestUploadDocumento(t *testing.T) {
 			Status: models.StatusAprovado,
 			Documentos: []models.Documento{
 				{
-					TipoDocumento:  "CNH",
-					CaminhoArquivo: "/uploads/cnh_test.jpg",
-					Formato:        "JPG",
-					Status:         "aprovado",
+					TipoDocumento: "CNH",
+					ChaveArquivo:  "/uploads/cnh_test.jpg",
+					Formato:       "JPG",
+					Status:        "aprovado",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "CRLV",
-					CaminhoArquivo: "/uploads/crlv_test.png",
-					Formato:        "PNG",
-					Status:         "aprovado",
+					TipoDocumento: "CRLV",
+					ChaveArquivo:  "/uploads/crlv_test.png",
+					Formato:       "PNG",
+					Status:        "aprovado",
+					Varredura:     models.VarreduraLimpo,
 				},
 				{
-					TipoDocumento:  "selfie_cnh",
-					CaminhoArquivo: "/uploads/selfie_test.jpg",
-					Formato:        "JPG",
-					Status:         "aprovado",
+					TipoDocumento: "selfie_cnh",
+					ChaveArquivo:  "/uploads/selfie_test.jpg",
+					Formato:       "JPG",
+					Status:        "aprovado",
+					Varredura:     models.VarreduraLimpo,
 				},
 			},
 		}
@@ -502,3 +598,431 @@ func TestUploadDocumento(t *testing.T) {
 		mockEmail.AssertExpectations(t)
 	})
 }
+
+func TestLoginMotorista(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+
+	t.Run("Senha em texto puro é migrada para hash no login", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, hasher, novoLimitador())
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com", Senha: "MinhaSenh@123"}
+
+		mockRepo.On("BuscarPorEmail", "joao@email.com").Return(motorista, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil).Once()
+
+		m, err := service.LoginMotorista("joao@email.com", "MinhaSenh@123", "10.0.0.1")
+		require.NoError(t, err)
+		assert.NotEqual(t, "MinhaSenh@123", m.Senha)
+		assert.True(t, hasher.Comparar(m.Senha, "MinhaSenh@123"))
+		assert.False(t, hasher.PrecisaRehash(m.Senha))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Senha já hasheada não é regravada", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, hasher, novoLimitador())
+		hash, err := hasher.Hash("MinhaSenh@123")
+		require.NoError(t, err)
+
+		mockRepo.On("BuscarPorEmail", "joao@email.com").Return(&models.Motorista{ID: "1", Senha: hash}, nil)
+
+		_, err = service.LoginMotorista("joao@email.com", "MinhaSenh@123", "10.0.0.1")
+		require.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+	})
+
+	t.Run("Senha incorreta", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, hasher, novoLimitador())
+
+		mockRepo.On("BuscarPorEmail", "joao@email.com").Return(&models.Motorista{ID: "1", Senha: "MinhaSenh@123"}, nil)
+
+		_, err := service.LoginMotorista("joao@email.com", "errada", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+	})
+
+	t.Run("Email inexistente retorna o mesmo erro de senha incorreta", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		service := NewMotoristaService(mockRepo, new(MockEmailService), hasher, novoLimitador())
+
+		mockRepo.On("BuscarPorEmail", "ninguem@email.com").Return(nil, errors.New("não encontrado"))
+
+		_, err := service.LoginMotorista("ninguem@email.com", "MinhaSenh@123", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+	})
+
+	t.Run("Email inexistente também compara a senha com bcrypt", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		espiao := &hasherEspiao{PasswordHasher: auth.NewBcryptHasher(bcrypt.MinCost)}
+		service := NewMotoristaService(mockRepo, new(MockEmailService), espiao, novoLimitador())
+
+		mockRepo.On("BuscarPorEmail", "ninguem@email.com").Return(nil, errors.New("não encontrado"))
+
+		_, err := service.LoginMotorista("ninguem@email.com", "MinhaSenh@123", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+		assert.Equal(t, []string{auth.HashFicticio}, espiao.comparados)
+	})
+
+	t.Run("Conta bloqueada após falhas consecutivas com aviso por email", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, hasher, novoLimitador())
+		hash, err := hasher.Hash("MinhaSenh@123")
+		require.NoError(t, err)
+
+		mockRepo.On("BuscarPorEmail", "joao@email.com").Return(&models.Motorista{ID: "1", Nome: "João", Email: "joao@email.com", Senha: hash}, nil)
+		mockEmail.On("EnviarEmailBloqueioConta", "joao@email.com", "João", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		for i := 0; i < 5; i++ {
+			_, err := service.LoginMotorista("joao@email.com", "errada", "10.0.0.1")
+			assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+		}
+
+		// Nem a senha correta passa durante o bloqueio
+		_, err = service.LoginMotorista("joao@email.com", "MinhaSenh@123", "10.0.0.2")
+		assert.Equal(t, apperrors.ErrLoginBloqueado, err)
+		mockEmail.AssertExpectations(t)
+	})
+}
+
+// hasherEspiao registra os hashes comparados
+type hasherEspiao struct {
+	auth.PasswordHasher
+	comparados []string
+}
+
+func (h *hasherEspiao) Comparar(hash, senha string) bool {
+	h.comparados = append(h.comparados, hash)
+	return h.PasswordHasher.Comparar(hash, senha)
+}
+
+func novoLimitador() *auth.LimitadorLogin {
+	return auth.NewLimitadorLogin(auth.LimitadorConfig{}, auth.NewTentativasMemoria())
+}
+
+func TestAtualizarPerfilEmail(t *testing.T) {
+	setup := func() (MotoristaService, *MockMotoristaRepository, *models.Motorista) {
+		mockRepo := new(MockMotoristaRepository)
+		service := NewMotoristaService(mockRepo, new(MockEmailService), auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com", EmailVerificado: true}
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		return service, mockRepo, motorista
+	}
+
+	t.Run("Novo email fica pendente até a confirmação", func(t *testing.T) {
+		service, mockRepo, _ := setup()
+		mockRepo.On("BuscarPorEmail", "novo@email.com").Return(nil, errors.New("não encontrado"))
+
+		m, err := service.AtualizarPerfil("1", 0, "", " Novo@email.com ")
+		require.NoError(t, err)
+		assert.Equal(t, "joao@email.com", m.Email)
+		assert.Equal(t, "novo@email.com", m.EmailPendente)
+		assert.True(t, m.EmailVerificado)
+	})
+
+	t.Run("Email de outra conta é recusado", func(t *testing.T) {
+		service, mockRepo, _ := setup()
+		mockRepo.On("BuscarPorEmail", "maria@email.com").Return(&models.Motorista{ID: "2"}, nil)
+
+		_, err := service.AtualizarPerfil("1", 0, "", "maria@email.com")
+		assert.Equal(t, apperrors.ErrEmailJaCadastrado, err)
+	})
+
+	t.Run("Versão diferente da informada no If-Match é recusada", func(t *testing.T) {
+		service, mockRepo, motorista := setup()
+		motorista.Versao = 4
+
+		_, err := service.AtualizarPerfil("1", 3, "11988887777", "")
+		assert.Equal(t, apperrors.ErrVersaoDesatualizada, err)
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+
+		m, err := service.AtualizarPerfil("1", 4, "11988887777", "")
+		require.NoError(t, err)
+		assert.Equal(t, "11988887777", m.Telefone)
+	})
+
+	t.Run("Informar o email atual cancela a troca pendente", func(t *testing.T) {
+		service, _, motorista := setup()
+		motorista.EmailPendente = "novo@email.com"
+
+		m, err := service.AtualizarPerfil("1", 0, "", "joao@email.com")
+		require.NoError(t, err)
+		assert.Empty(t, m.EmailPendente)
+	})
+}
+
+func TestAprovarMotoristaExigeEmailVerificado(t *testing.T) {
+	mockRepo := new(MockMotoristaRepository)
+	service := NewMotoristaService(mockRepo, new(MockEmailService), auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+	mockRepo.On("BuscarPorID", "1").Return(&models.Motorista{ID: "1", Email: "joao@email.com"}, nil)
+
+	err := service.AprovarMotorista("1", "op-1")
+	assert.Equal(t, apperrors.ErrEmailNaoVerificado, err)
+	mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+}
+
+func TestRevisaoPorDocumento(t *testing.T) {
+	setup := func() (MotoristaService, *MockEmailService, *models.Motorista) {
+		mockRepo := new(MockMotoristaRepository)
+		mockEmail := new(MockEmailService)
+		service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+		motorista := &models.Motorista{
+			ID: "1", Nome: "João", Email: "joao@email.com", EmailVerificado: true,
+			Status: models.StatusDocumentosAnalise,
+			Documentos: []models.Documento{
+				{ID: "d-cnh", TipoDocumento: "CNH", Status: models.DocumentoStatusPendente, Varredura: models.VarreduraLimpo},
+				{ID: "d-crlv", TipoDocumento: "CRLV", Status: models.DocumentoStatusPendente, Varredura: models.VarreduraLimpo},
+				{ID: "d-selfie", TipoDocumento: "selfie_cnh", Status: models.DocumentoStatusPendente, Varredura: models.VarreduraLimpo},
+			},
+		}
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+		return service, mockEmail, motorista
+	}
+
+	t.Run("Rejeição avisa o motorista só ao fim da revisão, com os documentos a reenviar", func(t *testing.T) {
+		service, mockEmail, motorista := setup()
+		m, err := service.RejeitarDocumento("1", "CNH", "op-1", models.MotivoIlegivel, "Foto desfocada")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusRejeitado, m.Status)
+		assert.Equal(t, models.DocumentoStatusRejeitado, m.Documentos[0].Status)
+		assert.Equal(t, models.MotivoIlegivel, m.Documentos[0].MotivoRejeicao)
+		assert.Equal(t, "Foto desfocada", m.Documentos[0].Observacao)
+		assert.Equal(t, "op-1", m.Documentos[0].RevisadoPor)
+		require.NotNil(t, m.Documentos[0].RevisadoEm)
+		mockEmail.AssertNotCalled(t, "EnviarEmailRejeicao", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		_, err = service.AprovarDocumento("1", "d-crlv", "op-1")
+		require.NoError(t, err)
+		mockEmail.On("EnviarEmailRejeicao", "joao@email.com", "João", "", mock.MatchedBy(func(docs []models.Documento) bool {
+			return len(docs) == 1 && docs[0].TipoDocumento == "CNH"
+		})).Return(nil).Once()
+		m, err = service.AprovarDocumento("1", "selfie_cnh", "op-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusRejeitado, m.Status)
+		mockEmail.AssertExpectations(t)
+
+		require.Len(t, motorista.Revisoes, 3)
+		assert.Equal(t, models.RevisaoDocumentoRejeitado, motorista.Revisoes[0].Acao)
+		assert.Equal(t, "CNH", motorista.Revisoes[0].Documento)
+		assert.Equal(t, models.MotivoIlegivel, motorista.Revisoes[0].CodigoMotivo)
+		assert.Equal(t, models.RevisaoDocumentoAprovado, motorista.Revisoes[2].Acao)
+	})
+
+	t.Run("Reenvio do documento rejeitado volta o cadastro para análise", func(t *testing.T) {
+		service, mockEmail, motorista := setup()
+		mockEmail.On("EnviarEmailRejeicao", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockEmail.On("EnviarEmailRecebimentoDocumentos", "joao@email.com", "João").Return(nil)
+		_, err := service.RejeitarDocumento("1", "CNH", "op-1", models.MotivoVencido, "")
+		require.NoError(t, err)
+
+		require.NoError(t, service.UploadDocumento("1", UploadDocumentoRequest{TipoDocumento: "CNH", ChaveArquivo: "1/cnh.pdf", Formato: "PDF", Tamanho: 1024}))
+		assert.Equal(t, models.StatusDocumentosAnalise, motorista.Status)
+		assert.Equal(t, models.DocumentoStatusPendente, motorista.Documentos[0].Status)
+		assert.Empty(t, motorista.Documentos[0].MotivoRejeicao)
+		mockEmail.AssertCalled(t, "EnviarEmailRecebimentoDocumentos", "joao@email.com", "João")
+	})
+
+	t.Run("Aprovar o último documento aprova o motorista", func(t *testing.T) {
+		service, mockEmail, motorista := setup()
+		mockEmail.On("EnviarEmailAprovacao", "joao@email.com", "João").Return(nil).Once()
+		for _, doc := range []string{"CNH", "CRLV"} {
+			_, err := service.AprovarDocumento("1", doc, "op-1")
+			require.NoError(t, err)
+			assert.Equal(t, models.StatusDocumentosAnalise, motorista.Status)
+		}
+		mockEmail.AssertNotCalled(t, "EnviarEmailAprovacao", mock.Anything, mock.Anything)
+
+		m, err := service.AprovarDocumento("1", "selfie_cnh", "op-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusAprovado, m.Status)
+		mockEmail.AssertExpectations(t)
+	})
+
+	t.Run("Aprovação final exige email verificado", func(t *testing.T) {
+		service, _, motorista := setup()
+		motorista.EmailVerificado = false
+		motorista.Documentos[0].Status = models.DocumentoStatusAprovado
+		motorista.Documentos[1].Status = models.DocumentoStatusAprovado
+		_, err := service.AprovarDocumento("1", "selfie_cnh", "op-1")
+		assert.Equal(t, apperrors.ErrEmailNaoVerificado, err)
+	})
+
+	t.Run("Aprovação do cadastro inteiro recusada com documento rejeitado", func(t *testing.T) {
+		service, _, motorista := setup()
+		motorista.Documentos[1].Status = models.DocumentoStatusRejeitado
+		assert.Equal(t, apperrors.ErrDocumentosRejeitados, service.AprovarMotorista("1", "op-1"))
+	})
+
+	t.Run("Rejeição do cadastro inteiro rejeita os documentos não aprovados", func(t *testing.T) {
+		service, mockEmail, motorista := setup()
+		motorista.Documentos[2].Status = models.DocumentoStatusAprovado
+		mockEmail.On("EnviarEmailRejeicao", "joao@email.com", "João", "Cadastro incompleto", mock.MatchedBy(func(docs []models.Documento) bool {
+			return len(docs) == 2 && docs[0].MotivoRejeicao == models.MotivoOutro && docs[0].Observacao == "Cadastro incompleto"
+		})).Return(nil)
+		require.NoError(t, service.RejeitarMotorista("1", "op-1", "Cadastro incompleto"))
+		assert.Equal(t, models.StatusRejeitado, motorista.Status)
+		assert.Equal(t, models.DocumentoStatusAprovado, motorista.Documentos[2].Status)
+		mockEmail.AssertExpectations(t)
+	})
+
+	t.Run("Erros", func(t *testing.T) {
+		service, _, motorista := setup()
+		_, err := service.RejeitarDocumento("1", "CNH", "op-1", "desconhecido", "")
+		assert.Equal(t, apperrors.ErrMotivoRejeicaoInvalido, err)
+		_, err = service.RejeitarDocumento("1", "CNH", "op-1", models.MotivoOutro, "  ")
+		assert.Equal(t, apperrors.ErrCampoObrigatorio, err)
+		_, err = service.AprovarDocumento("1", "RG", "op-1")
+		assert.Equal(t, apperrors.ErrDocumentoNaoEncontrado, err)
+
+		motorista.Status = models.StatusEncerrado
+		_, err = service.AprovarDocumento("1", "CNH", "op-1")
+		assert.Equal(t, apperrors.ErrRevisaoNaoPermitida, err)
+	})
+}
+
+func TestHistoricoDocumentos(t *testing.T) {
+	mockRepo := new(MockMotoristaRepository)
+	mockEmail := new(MockEmailService)
+	service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+	// Documento enviado antes do histórico existir: sem número de versão
+	motorista := &models.Motorista{
+		ID: "1", Nome: "João", Email: "joao@email.com", Status: models.StatusDocumentosAnalise,
+		Documentos: []models.Documento{{ID: "d1", TipoDocumento: "CNH", ChaveArquivo: "1/a.pdf", Checksum: "aaa", Status: models.DocumentoStatusPendente, Varredura: models.VarreduraLimpo}},
+	}
+	mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+	mockRepo.On("Atualizar", motorista).Return(nil)
+	mockEmail.On("EnviarEmailRejeicao", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := service.RejeitarDocumento("1", "CNH", "op-1", models.MotivoIlegivel, "")
+	require.NoError(t, err)
+	require.NoError(t, service.UploadDocumento("1", UploadDocumentoRequest{TipoDocumento: "CNH", ChaveArquivo: "1/b.pdf", Formato: "PDF", Tamanho: 10, Checksum: "bbb", EnviadoPor: "1"}))
+	require.NoError(t, service.UploadDocumento("1", UploadDocumentoRequest{TipoDocumento: "CNH", ChaveArquivo: "1/c.pdf", Formato: "PDF", Tamanho: 10, Checksum: "ccc", EnviadoPor: "op-2"}))
+
+	t.Run("Cada upload é uma versão e as substituídas guardam a revisão recebida", func(t *testing.T) {
+		require.Len(t, motorista.Documentos, 1)
+		assert.Equal(t, 3, motorista.Documentos[0].Versao)
+		assert.Equal(t, "op-2", motorista.Documentos[0].EnviadoPor)
+		require.Len(t, motorista.Historico, 2)
+		assert.Equal(t, 1, motorista.Historico[0].Versao)
+		assert.Equal(t, models.DocumentoStatusRejeitado, motorista.Historico[0].Status)
+		assert.Equal(t, models.MotivoIlegivel, motorista.Historico[0].MotivoRejeicao)
+		assert.NotNil(t, motorista.Historico[0].SubstituidoEm)
+		assert.Equal(t, 2, motorista.Historico[1].Versao)
+		assert.Equal(t, "1", motorista.Historico[1].EnviadoPor)
+	})
+
+	t.Run("Histórico lista da versão atual para a mais antiga", func(t *testing.T) {
+		versoes, err := service.HistoricoDocumento("1", "CNH")
+		require.NoError(t, err)
+		require.Len(t, versoes, 3)
+		for i, checksum := range []string{"ccc", "bbb", "aaa"} {
+			assert.Equal(t, 3-i, versoes[i].Versao)
+			assert.Equal(t, checksum, versoes[i].Checksum)
+		}
+
+		_, err = service.HistoricoDocumento("1", "CRLV")
+		assert.Equal(t, apperrors.ErrDocumentoNaoEncontrado, err)
+	})
+
+	t.Run("Versões anteriores continuam acessíveis", func(t *testing.T) {
+		doc, err := service.VersaoDocumento("1", "CNH", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "1/a.pdf", doc.ChaveArquivo)
+		doc, err = service.VersaoDocumento("1", "CNH", 3)
+		require.NoError(t, err)
+		assert.Equal(t, "1/c.pdf", doc.ChaveArquivo)
+
+		_, err = service.VersaoDocumento("1", "CNH", 4)
+		assert.Equal(t, apperrors.ErrDocumentoNaoEncontrado, err)
+	})
+}
+
+func TestAlterarSenha(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+	mockRepo := new(MockMotoristaRepository)
+	mockEmail := new(MockEmailService)
+	service := NewMotoristaService(mockRepo, mockEmail, hasher, novoLimitador())
+	hash, err := hasher.Hash("MinhaSenh@123")
+	require.NoError(t, err)
+	motorista := &models.Motorista{ID: "1", Senha: hash}
+
+	mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+	mockRepo.On("Atualizar", motorista).Return(nil)
+
+	err = service.AlterarSenha("1", "MinhaSenh@123", "NovaSenh@456", "NovaSenh@456")
+	require.NoError(t, err)
+	assert.True(t, hasher.Comparar(motorista.Senha, "NovaSenh@456"))
+	assert.NotEqual(t, "NovaSenh@456", motorista.Senha)
+}
+
+func TestLoginMotoristaPorStatus(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+	hash, err := hasher.Hash("MinhaSenh@123")
+	require.NoError(t, err)
+
+	tests := []struct {
+		status       models.StatusMotorista
+		erro         error
+		proximoPasso string
+	}{
+		{models.StatusAtivo, nil, ProximoPassoPainel},
+		{models.StatusAprovado, nil, ProximoPassoPainel},
+		{models.StatusAguardandoAprovacao, nil, ProximoPassoUploadDocumentos},
+		{models.StatusRejeitado, nil, ProximoPassoUploadDocumentos},
+		{models.StatusDocumentosAnalise, apperrors.ErrLoginDocumentosEmAnalise, ""},
+		{models.StatusAguardandoExclusao, apperrors.ErrLoginAguardandoExclusao, ""},
+		{models.StatusEncerrado, apperrors.ErrLoginContaEncerrada, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			mockRepo := new(MockMotoristaRepository)
+			service := NewMotoristaService(mockRepo, new(MockEmailService), hasher, novoLimitador())
+			mockRepo.On("BuscarPorEmail", "joao.silva@email.com").Return(&models.Motorista{ID: "1", Senha: hash, Status: tt.status}, nil)
+
+			m, err := service.LoginMotorista("joao.silva@email.com", "MinhaSenh@123", "10.0.0.1")
+			if tt.erro != nil {
+				assert.Equal(t, tt.erro, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.proximoPasso, ProximoPassoLogin(m.Status))
+		})
+	}
+
+	t.Run("Status não é revelado com senha incorreta", func(t *testing.T) {
+		mockRepo := new(MockMotoristaRepository)
+		service := NewMotoristaService(mockRepo, new(MockEmailService), hasher, novoLimitador())
+		mockRepo.On("BuscarPorEmail", "joao.silva@email.com").Return(&models.Motorista{ID: "1", Senha: hash, Status: models.StatusEncerrado}, nil)
+
+		_, err := service.LoginMotorista("joao.silva@email.com", "senhaerrada", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+	})
+
+	t.Run("Validação dos campos de login", func(t *testing.T) {
+		service := NewMotoristaService(new(MockMotoristaRepository), new(MockEmailService), hasher, novoLimitador())
+
+		_, err := service.LoginMotorista("", "MinhaSenh@123", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCampoObrigatorio, err)
+		_, err = service.LoginMotorista("joao.silva@email.com", "", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCampoObrigatorio, err)
+		_, err = service.LoginMotorista("email_invalido", "MinhaSenh@123", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrEmailInvalido, err)
+	})
+}
+
+func TestNormalizarTipoDocumento(t *testing.T) {
+	for _, tipo := range []string{"selfie_cnh", "Selfie", "SELFIE_CNH", "minha-selfie"} {
+		assert.Equal(t, "selfie_cnh", NormalizarTipoDocumento(tipo), tipo)
+	}
+	assert.Equal(t, "CNH", NormalizarTipoDocumento("CNH"))
+	assert.Equal(t, "cnh", NormalizarTipoDocumento("cnh"))
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// CriarOperadorRequest representa os dados de entrada para criação de operador
+type CriarOperadorRequest struct {
+	Nome  string `json:"nome"`
+	Email string `json:"email"`
+	Senha string `json:"senha"`
+	Papel string `json:"papel"`
+}
+
+// OperadorService define a interface para serviços de operadores internos
+type OperadorService interface {
+	CriarOperador(request CriarOperadorRequest) (*models.Operador, error)
+	LoginOperador(email, senha, ip string) (*models.Operador, error)
+	BuscarOperador(id string) (*models.Operador, error)
+	GarantirAdminInicial(email, senha string) error
+	VerificarSegundoFator(id, codigo, ip string) (*models.Operador, error)
+	IniciarDoisFatores(id string) (*InscricaoDoisFatores, error)
+	ConfirmarDoisFatores(id, codigo string) error
+}
+
+// OperadorServiceImpl implementa OperadorService
+type OperadorServiceImpl struct {
+	operadorRepo repositories.OperadorRepository
+	hasher       auth.PasswordHasher
+	limitador    *auth.LimitadorLogin
+}
+
+// NewOperadorService cria uma nova instância do serviço
+func NewOperadorService(operadorRepo repositories.OperadorRepository, hasher auth.PasswordHasher, limitador *auth.LimitadorLogin) OperadorService {
+	return &OperadorServiceImpl{
+		operadorRepo: operadorRepo,
+		hasher:       hasher,
+		limitador:    limitador,
+	}
+}
+
+// CriarOperador cadastra um novo operador com o papel informado
+func (s *OperadorServiceImpl) CriarOperador(request CriarOperadorRequest) (*models.Operador, error) {
+	request.Nome = strings.TrimSpace(request.Nome)
+	request.Email = strings.ToLower(strings.TrimSpace(request.Email))
+	request.Senha = strings.TrimSpace(request.Senha)
+
+	if request.Nome == "" || request.Email == "" || request.Senha == "" {
+		return nil, apperrors.ErrCampoObrigatorio
+	}
+	if err := models.ValidarEmail(request.Email); err != nil {
+		return nil, err
+	}
+	papel := models.Papel(request.Papel)
+	if !models.PapelOperadorValido(papel) {
+		return nil, apperrors.ErrPapelInvalido
+	}
+	if _, err := models.ValidarForcaSenha(request.Senha); err != nil {
+		return nil, err
+	}
+	if _, err := s.operadorRepo.BuscarPorEmail(request.Email); err == nil {
+		return nil, apperrors.ErrEmailJaCadastrado
+	}
+
+	senhaHash, err := s.hasher.Hash(request.Senha)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+
+	operador := &models.Operador{
+		ID:           uuid.New().String(),
+		Nome:         request.Nome,
+		Email:        request.Email,
+		Senha:        senhaHash,
+		Papel:        papel,
+		Ativo:        true,
+		CriadoEm:     time.Now(),
+		AtualizadoEm: time.Now(),
+	}
+	if err := s.operadorRepo.Criar(operador); err != nil {
+		return nil, fmt.Errorf("erro ao salvar operador: %w", err)
+	}
+	return operador, nil
+}
+
+// LoginOperador autentica um operador ativo. Falhas contam para o limite de tentativas
+// por email e por IP, como no login de motoristas.
+func (s *OperadorServiceImpl) LoginOperador(email, senha, ip string) (*models.Operador, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if err := s.limitador.Verificar(email, ip); err != nil {
+		return nil, err
+	}
+	operador, err := s.operadorRepo.BuscarPorEmail(email)
+	if err != nil {
+		s.hasher.Comparar(auth.HashFicticio, senha)
+		s.registrarFalhaLogin(email, ip)
+		return nil, apperrors.ErrCredenciaisInvalidas
+	}
+	if !s.hasher.Comparar(operador.Senha, senha) {
+		s.registrarFalhaLogin(email, ip)
+		return nil, apperrors.ErrCredenciaisInvalidas
+	}
+	// Com 2FA ativo a senha correta só emite o desafio: as falhas continuam contando até o
+	// segundo fator ser aceito, senão cada acerto da senha zeraria as tentativas de código
+	if !operador.DoisFatores.Ativo {
+		if err := s.limitador.RegistrarSucesso(email); err != nil {
+			fmt.Printf("Erro ao limpar tentativas de login: %v\n", err)
+		}
+	}
+	if !operador.Ativo {
+		return nil, apperrors.ErrOperadorInativo
+	}
+	return operador, nil
+}
+
+// registrarFalhaLogin conta a tentativa malsucedida para a conta e o IP
+func (s *OperadorServiceImpl) registrarFalhaLogin(email, ip string) {
+	if _, err := s.limitador.RegistrarFalha(email, ip); err != nil {
+		fmt.Printf("Erro ao registrar tentativa de login: %v\n", err)
+	}
+}
+
+// BuscarOperador busca um operador por ID
+func (s *OperadorServiceImpl) BuscarOperador(id string) (*models.Operador, error) {
+	operador, err := s.operadorRepo.BuscarPorID(id)
+	if err != nil {
+		return nil, apperrors.ErrOperadorNaoEncontrado
+	}
+	return operador, nil
+}
+
+// atualizarOperador carrega o operador, aplica alterar e o salva em uma única unidade
+// de trabalho do repositório, como atualizarMotorista
+func (s *OperadorServiceImpl) atualizarOperador(id string, alterar func(o *models.Operador) error) (*models.Operador, error) {
+	var operador *models.Operador
+	err := s.operadorRepo.WithTx(func(tx repositories.OperadorTx) error {
+		o, err := tx.BuscarPorID(id)
+		if err != nil {
+			return apperrors.ErrOperadorNaoEncontrado
+		}
+		if err := alterar(o); err != nil {
+			return err
+		}
+		o.AtualizadoEm = time.Now()
+		if err := tx.Atualizar(o); err != nil {
+			return fmt.Errorf("erro ao atualizar operador: %w", err)
+		}
+		operador = o
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return operador, nil
+}
+
+// VerificarSegundoFator conclui o login de operadores com 2FA ativo.
+// Códigos errados contam para o mesmo limite de tentativas da senha.
+func (s *OperadorServiceImpl) VerificarSegundoFator(id, codigo, ip string) (*models.Operador, error) {
+	operador, err := s.BuscarOperador(id)
+	if err != nil {
+		return nil, err
+	}
+	if !operador.Ativo {
+		return nil, apperrors.ErrOperadorInativo
+	}
+	if err := s.limitador.Verificar(operador.Email, ip); err != nil {
+		return nil, err
+	}
+	// A verificação e o registro do passo usado (ou do código de recuperação consumido)
+	// ocorrem na mesma unidade de trabalho, impedindo o reuso de um código em paralelo
+	atualizado, err := s.atualizarOperador(id, func(o *models.Operador) error {
+		if !o.Ativo {
+			return apperrors.ErrOperadorInativo
+		}
+		return verificarSegundoFator(&o.DoisFatores, codigo, time.Now())
+	})
+	if err != nil {
+		if err == apperrors.ErrCodigo2FAInvalido {
+			s.registrarFalhaLogin(operador.Email, ip)
+		}
+		return nil, err
+	}
+	if err := s.limitador.RegistrarSucesso(atualizado.Email); err != nil {
+		fmt.Printf("Erro ao limpar tentativas de login: %v\n", err)
+	}
+	return atualizado, nil
+}
+
+// IniciarDoisFatores gera o segredo TOTP e os códigos de recuperação do operador
+func (s *OperadorServiceImpl) IniciarDoisFatores(id string) (*InscricaoDoisFatores, error) {
+	var inscricao *InscricaoDoisFatores
+	_, err := s.atualizarOperador(id, func(o *models.Operador) error {
+		var err error
+		inscricao, err = iniciarInscricao(&o.DoisFatores, o.Email)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inscricao, nil
+}
+
+// ConfirmarDoisFatores ativa o 2FA do operador após o primeiro código válido
+func (s *OperadorServiceImpl) ConfirmarDoisFatores(id, codigo string) error {
+	_, err := s.atualizarOperador(id, func(o *models.Operador) error {
+		return confirmarInscricao(&o.DoisFatores, codigo, time.Now())
+	})
+	return err
+}
+
+// GarantirAdminInicial cria o primeiro admin quando ainda não existe nenhum operador
+func (s *OperadorServiceImpl) GarantirAdminInicial(email, senha string) error {
+	if email == "" || senha == "" {
+		return nil
+	}
+	operadores, err := s.operadorRepo.ListarTodos()
+	if err != nil {
+		return err
+	}
+	if len(operadores) > 0 {
+		return nil
+	}
+	_, err = s.CriarOperador(CriarOperadorRequest{
+		Nome:  "Administrador",
+		Email: email,
+		Senha: senha,
+		Papel: string(models.PapelAdmin),
+	})
+	return err
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// MockOperadorRepository is a mock implementation of repositories.OperadorRepository
+type MockOperadorRepository struct {
+	mock.Mock
+}
+
+func (m *MockOperadorRepository) Criar(operador *models.Operador) error {
+	args := m.Called(operador)
+	return args.Error(0)
+}
+
+func (m *MockOperadorRepository) BuscarPorID(id string) (*models.Operador, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Operador), args.Error(1)
+}
+
+func (m *MockOperadorRepository) BuscarPorEmail(email string) (*models.Operador, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Operador), args.Error(1)
+}
+
+func (m *MockOperadorRepository) Atualizar(operador *models.Operador) error {
+	args := m.Called(operador)
+	return args.Error(0)
+}
+
+func (m *MockOperadorRepository) ListarTodos() ([]*models.Operador, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.Operador), args.Error(1)
+}
+
+func (m *MockOperadorRepository) WithTx(fn func(tx repositories.OperadorTx) error) error {
+	return fn(m)
+}
+
+func TestOperadorService(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+
+	t.Run("Criar operador com papel válido", func(t *testing.T) {
+		mockRepo := new(MockOperadorRepository)
+		service := NewOperadorService(mockRepo, hasher, novoLimitador())
+
+		mockRepo.On("BuscarPorEmail", "ana@taxiservice.com").Return(nil, errors.New("not found"))
+		mockRepo.On("Criar", mock.AnythingOfType("*models.Operador")).Return(nil)
+
+		o, err := service.CriarOperador(CriarOperadorRequest{Nome: "Ana", Email: "Ana@TaxiService.com", Senha: "MinhaSenh@123", Papel: "revisor"})
+		require.NoError(t, err)
+		assert.Equal(t, models.PapelRevisor, o.Papel)
+		assert.True(t, o.Ativo)
+		assert.True(t, hasher.Comparar(o.Senha, "MinhaSenh@123"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Erro com papel inválido", func(t *testing.T) {
+		service := NewOperadorService(new(MockOperadorRepository), hasher, novoLimitador())
+
+		_, err := service.CriarOperador(CriarOperadorRequest{Nome: "Ana", Email: "ana@taxiservice.com", Senha: "MinhaSenh@123", Papel: "motorista"})
+		assert.Equal(t, apperrors.ErrPapelInvalido, err)
+	})
+
+	t.Run("Login de operador inativo", func(t *testing.T) {
+		mockRepo := new(MockOperadorRepository)
+		service := NewOperadorService(mockRepo, hasher, novoLimitador())
+		hash, _ := hasher.Hash("MinhaSenh@123")
+
+		mockRepo.On("BuscarPorEmail", "ana@taxiservice.com").Return(&models.Operador{ID: "op-1", Senha: hash, Ativo: false}, nil)
+
+		_, err := service.LoginOperador("ana@taxiservice.com", "MinhaSenh@123", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrOperadorInativo, err)
+	})
+
+	t.Run("Login com senha incorreta", func(t *testing.T) {
+		mockRepo := new(MockOperadorRepository)
+		service := NewOperadorService(mockRepo, hasher, novoLimitador())
+		hash, _ := hasher.Hash("MinhaSenh@123")
+
+		mockRepo.On("BuscarPorEmail", "ana@taxiservice.com").Return(&models.Operador{ID: "op-1", Senha: hash, Ativo: true}, nil)
+
+		_, err := service.LoginOperador("ana@taxiservice.com", "errada", "10.0.0.1")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+	})
+
+	t.Run("Login bloqueado após falhas seguidas", func(t *testing.T) {
+		mockRepo := new(MockOperadorRepository)
+		service := NewOperadorService(mockRepo, hasher, novoLimitador())
+		hash, _ := hasher.Hash("MinhaSenh@123")
+
+		mockRepo.On("BuscarPorEmail", "ana@taxiservice.com").Return(&models.Operador{ID: "op-1", Senha: hash, Ativo: true}, nil)
+		mockRepo.On("BuscarPorEmail", "inexistente@taxiservice.com").Return(nil, errors.New("not found"))
+
+		// Emails inexistentes também contam para o limite do IP, sem revelar o cadastro
+		_, err := service.LoginOperador("inexistente@taxiservice.com", "errada", "10.0.0.2")
+		assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+
+		for i := 0; i < 5; i++ {
+			_, err := service.LoginOperador("Ana@TaxiService.com", "errada", "10.0.0.1")
+			assert.Equal(t, apperrors.ErrCredenciaisInvalidas, err)
+		}
+		// Nem a senha correta, de outro IP, entra enquanto a conta estiver bloqueada
+		_, err = service.LoginOperador("ana@taxiservice.com", "MinhaSenh@123", "10.0.0.3")
+		assert.Equal(t, apperrors.ErrLoginBloqueado, err)
+	})
+
+	t.Run("Admin inicial não é recriado quando já existem operadores", func(t *testing.T) {
+		mockRepo := new(MockOperadorRepository)
+		service := NewOperadorService(mockRepo, hasher, novoLimitador())
+
+		mockRepo.On("ListarTodos").Return([]*models.Operador{{ID: "op-1"}}, nil)
+
+		require.NoError(t, service.GarantirAdminInicial("admin@taxiservice.com", "MinhaSenh@123"))
+		mockRepo.AssertNotCalled(t, "Criar", mock.Anything)
+	})
+}
+
+func TestRevisaoRegistraOperador(t *testing.T) {
+	mockRepo := new(MockMotoristaRepository)
+	mockEmail := new(MockEmailService)
+	service := NewMotoristaService(mockRepo, mockEmail, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+	motorista := &models.Motorista{ID: "1", Email: "joao@email.com", Nome: "João"}
+
+	mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+	mockRepo.On("Atualizar", motorista).Return(nil)
+	mockEmail.On("EnviarEmailRejeicao", "joao@email.com", "João", "CNH ilegível", []models.Documento(nil)).Return(nil)
+
+	require.NoError(t, service.RejeitarMotorista("1", "op-1", "CNH ilegível"))
+	require.Len(t, motorista.Revisoes, 1)
+	assert.Equal(t, models.RevisaoRejeitado, motorista.Revisoes[0].Acao)
+	assert.Equal(t, "op-1", motorista.Revisoes[0].OperadorID)
+	assert.Equal(t, "CNH ilegível", motorista.Revisoes[0].Motivo)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// validadeLinkRedefinicao tempo de validade do link de redefinição de senha
+const validadeLinkRedefinicao = time.Hour
+
+// RecuperacaoService define a interface para recuperação de conta por email
+type RecuperacaoService interface {
+	SolicitarRecuperacao(email, ip string) error
+	ValidarLinkRedefinicao(token string) error
+	RedefinirSenha(token, novaSenha, confirmacao string) error
+}
+
+// RecuperacaoServiceImpl implementa RecuperacaoService
+type RecuperacaoServiceImpl struct {
+	motoristaRepo repositories.MotoristaRepository
+	tokenRepo     repositories.TokenRepository
+	emailService  EmailService
+	hasher        auth.PasswordHasher
+	tokenService  *auth.TokenService
+	limitador     *auth.LimitadorLogin
+	appURL        string
+}
+
+// NewRecuperacaoService cria uma nova instância do serviço.
+// limitador é o mesmo do login: as solicitações contam para o limite por IP.
+// appURL é a URL base do frontend usada para montar o link enviado por email.
+func NewRecuperacaoService(motoristaRepo repositories.MotoristaRepository, tokenRepo repositories.TokenRepository, emailService EmailService, hasher auth.PasswordHasher, tokenService *auth.TokenService, limitador *auth.LimitadorLogin, appURL string) RecuperacaoService {
+	return &RecuperacaoServiceImpl{
+		motoristaRepo: motoristaRepo,
+		tokenRepo:     tokenRepo,
+		emailService:  emailService,
+		hasher:        hasher,
+		tokenService:  tokenService,
+		limitador:     limitador,
+		appURL:        strings.TrimRight(appURL, "/"),
+	}
+}
+
+// SolicitarRecuperacao gera um link de redefinição de senha e envia por email.
+// A resposta revela se o email está cadastrado, por isso cada solicitação conta para
+// o limite de tentativas do IP.
+func (s *RecuperacaoServiceImpl) SolicitarRecuperacao(email, ip string) error {
+	if err := s.limitador.RegistrarTentativaIP(ip); err != nil {
+		if err == apperrors.ErrLoginBloqueado {
+			return apperrors.ErrRecuperacaoBloqueada
+		}
+		return fmt.Errorf("erro ao registrar solicitação de recuperação: %w", err)
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if err := models.ValidarEmail(email); err != nil {
+		return err
+	}
+	motorista, err := s.motoristaRepo.BuscarPorEmail(email)
+	if err != nil {
+		return apperrors.ErrEmailNaoCadastrado
+	}
+
+	// Apenas o link mais recente permanece válido
+	agora := time.Now()
+	if err := s.tokenRepo.InvalidarPendentes(motorista.ID, models.FinalidadeRedefinicaoSenha, agora); err != nil {
+		return fmt.Errorf("erro ao invalidar links anteriores: %w", err)
+	}
+
+	token, hash, err := auth.GerarTokenOpaco()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	if err := s.tokenRepo.Criar(&models.TokenUsoUnico{
+		ID:          uuid.New().String(),
+		Hash:        hash,
+		Finalidade:  models.FinalidadeRedefinicaoSenha,
+		MotoristaID: motorista.ID,
+		ExpiraEm:    agora.Add(validadeLinkRedefinicao),
+		CriadoEm:    agora,
+	}); err != nil {
+		return fmt.Errorf("erro ao salvar token: %w", err)
+	}
+
+	link := s.appURL + "/reset-password?token=" + token
+	return s.emailService.EnviarEmailRecuperacao(motorista.Email, motorista.Nome, link)
+}
+
+// ValidarLinkRedefinicao verifica se o link ainda pode ser usado, sem consumi-lo
+func (s *RecuperacaoServiceImpl) ValidarLinkRedefinicao(token string) error {
+	if strings.TrimSpace(token) == "" {
+		return apperrors.ErrLinkInvalido
+	}
+	t, err := s.tokenRepo.BuscarPorHash(auth.HashToken(token))
+	if err != nil || t.Finalidade != models.FinalidadeRedefinicaoSenha || !t.Valido(time.Now()) {
+		return apperrors.ErrLinkInvalido
+	}
+	return nil
+}
+
+// RedefinirSenha troca a senha usando o link recebido por email e encerra as sessões existentes
+func (s *RecuperacaoServiceImpl) RedefinirSenha(token, novaSenha, confirmacao string) error {
+	if err := s.ValidarLinkRedefinicao(token); err != nil {
+		return err
+	}
+
+	novaSenha = strings.TrimSpace(novaSenha)
+	confirmacao = strings.TrimSpace(confirmacao)
+	if novaSenha == "" {
+		return apperrors.ErrCampoObrigatorio
+	}
+	if novaSenha != confirmacao {
+		return apperrors.ErrSenhasNaoConferem
+	}
+	if _, err := models.ValidarForcaSenha(novaSenha); err != nil {
+		return err
+	}
+
+	// Consumir é atômico: apenas uma requisição concorrente usa o mesmo link
+	t, err := s.tokenRepo.Consumir(auth.HashToken(token), models.FinalidadeRedefinicaoSenha, time.Now())
+	if err != nil {
+		return apperrors.ErrLinkInvalido
+	}
+	senhaHash, err := s.hasher.Hash(novaSenha)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+	motorista, err := atualizarMotorista(s.motoristaRepo, t.MotoristaID, func(m *models.Motorista) error {
+		m.Senha = senhaHash
+		m.AtualizadoEm = time.Now()
+		return nil
+	})
+	if err == apperrors.ErrMotoristaNaoEncontrado {
+		return apperrors.ErrLinkInvalido
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao redefinir senha do motorista: %w", err)
+	}
+
+	if err := s.tokenService.RevogarTokens(motorista.ID); err != nil {
+		return fmt.Errorf("erro ao encerrar sessões: %w", err)
+	}
+	return nil
+}
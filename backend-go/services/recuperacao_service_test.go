@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// MockTokenRepository is a mock implementation of repositories.TokenRepository
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) Criar(token *models.TokenUsoUnico) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) BuscarPorHash(hash string) (*models.TokenUsoUnico, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TokenUsoUnico), args.Error(1)
+}
+
+func (m *MockTokenRepository) Consumir(hash, finalidade string, agora time.Time) (*models.TokenUsoUnico, error) {
+	args := m.Called(hash, finalidade)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TokenUsoUnico), args.Error(1)
+}
+
+func (m *MockTokenRepository) InvalidarPendentes(motoristaID, finalidade string, agora time.Time) error {
+	args := m.Called(motoristaID, finalidade)
+	return args.Error(0)
+}
+
+// revogacoesMemoria implementação em memória de auth.RevogacaoStore
+type revogacoesMemoria map[string]int
+
+func (r revogacoesMemoria) Geracao(subject string) (int, error) { return r[subject], nil }
+
+func (r revogacoesMemoria) Revogar(subject string) error {
+	r[subject]++
+	return nil
+}
+
+func TestRecuperacaoService(t *testing.T) {
+	hasher := auth.NewBcryptHasher(bcrypt.MinCost)
+
+	setup := func() (RecuperacaoService, *MockMotoristaRepository, *MockTokenRepository, *MockEmailService, *auth.TokenService) {
+		mockRepo := new(MockMotoristaRepository)
+		mockTokens := new(MockTokenRepository)
+		mockEmail := new(MockEmailService)
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}})
+		service := NewRecuperacaoService(mockRepo, mockTokens, mockEmail, hasher, tokenService, novoLimitador(), "http://localhost:5173/")
+		return service, mockRepo, mockTokens, mockEmail, tokenService
+	}
+
+	t.Run("Solicitar recuperação com email válido", func(t *testing.T) {
+		service, mockRepo, mockTokens, mockEmail, _ := setup()
+		motorista := &models.Motorista{ID: "1", Nome: "José Santos", Email: "jose.santos@email.com"}
+
+		mockRepo.On("BuscarPorEmail", "jose.santos@email.com").Return(motorista, nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeRedefinicaoSenha).Return(nil)
+		mockTokens.On("Criar", mock.AnythingOfType("*models.TokenUsoUnico")).Return(nil)
+		mockEmail.On("EnviarEmailRecuperacao", "jose.santos@email.com", "José Santos", mock.AnythingOfType("string")).Return(nil)
+
+		require.NoError(t, service.SolicitarRecuperacao(" Jose.Santos@email.com ", "10.0.0.1"))
+
+		salvo := mockTokens.Calls[1].Arguments.Get(0).(*models.TokenUsoUnico)
+		link := mockEmail.Calls[0].Arguments.String(2)
+		token := strings.TrimPrefix(link, "http://localhost:5173/reset-password?token=")
+		assert.NotEqual(t, link, token)
+		assert.Equal(t, auth.HashToken(token), salvo.Hash)
+		assert.NotContains(t, salvo.Hash, token)
+		assert.True(t, salvo.ExpiraEm.After(time.Now()))
+	})
+
+	t.Run("Erros de validação do email", func(t *testing.T) {
+		service, mockRepo, _, _, _ := setup()
+		mockRepo.On("BuscarPorEmail", "nao.existe@email.com").Return(nil, errors.New("not found"))
+
+		assert.Equal(t, apperrors.ErrCampoObrigatorio, service.SolicitarRecuperacao("", "10.0.0.1"))
+		assert.Equal(t, apperrors.ErrEmailInvalido, service.SolicitarRecuperacao("email_invalido", "10.0.0.1"))
+		assert.Equal(t, apperrors.ErrEmailNaoCadastrado, service.SolicitarRecuperacao("nao.existe@email.com", "10.0.0.1"))
+	})
+
+	t.Run("Solicitações contam para o limite de tentativas do IP", func(t *testing.T) {
+		service, mockRepo, _, _, _ := setup()
+		mockRepo.On("BuscarPorEmail", mock.Anything).Return(nil, errors.New("not found"))
+
+		var err error
+		for i := 0; i < 30 && err != apperrors.ErrRecuperacaoBloqueada; i++ {
+			err = service.SolicitarRecuperacao(fmt.Sprintf("teste%d@email.com", i), "10.0.0.1")
+		}
+		assert.Equal(t, apperrors.ErrRecuperacaoBloqueada, err)
+		assert.Equal(t, apperrors.ErrEmailNaoCadastrado, service.SolicitarRecuperacao("teste@email.com", "10.0.0.2"))
+	})
+
+	t.Run("Redefinir senha com sucesso revoga sessões", func(t *testing.T) {
+		service, mockRepo, mockTokens, _, tokenService := setup()
+		motorista := &models.Motorista{ID: "1", Senha: "antiga"}
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeRedefinicaoSenha, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour)}
+		sessao, err := tokenService.GerarTokens("1", "motorista")
+		require.NoError(t, err)
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("Consumir", hash, models.FinalidadeRedefinicaoSenha).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+
+		require.NoError(t, service.RedefinirSenha("token-valido", "NovaSenha@123", "NovaSenha@123"))
+		assert.True(t, hasher.Comparar(motorista.Senha, "NovaSenha@123"))
+
+		_, err = tokenService.ValidarToken(sessao.AccessToken, auth.TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+	})
+
+	t.Run("Link inválido, expirado ou já usado", func(t *testing.T) {
+		service, _, mockTokens, _, _ := setup()
+		agora := time.Now()
+		expirado := &models.TokenUsoUnico{Hash: auth.HashToken("expirado"), Finalidade: models.FinalidadeRedefinicaoSenha, ExpiraEm: agora.Add(-time.Minute)}
+		usado := &models.TokenUsoUnico{Hash: auth.HashToken("usado"), Finalidade: models.FinalidadeRedefinicaoSenha, ExpiraEm: agora.Add(time.Hour), UsadoEm: &agora}
+
+		mockTokens.On("BuscarPorHash", auth.HashToken("inexistente")).Return(nil, repositories.ErrTokenNaoEncontrado)
+		mockTokens.On("BuscarPorHash", expirado.Hash).Return(expirado, nil)
+		mockTokens.On("BuscarPorHash", usado.Hash).Return(usado, nil)
+
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.ValidarLinkRedefinicao(""))
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.RedefinirSenha("inexistente", "NovaSenha@123", "NovaSenha@123"))
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.RedefinirSenha("expirado", "NovaSenha@123", "NovaSenha@123"))
+		assert.Equal(t, apperrors.ErrLinkInvalido, service.RedefinirSenha("usado", "NovaSenha@123", "NovaSenha@123"))
+		mockTokens.AssertNotCalled(t, "Consumir", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Validação da nova senha", func(t *testing.T) {
+		service, _, mockTokens, _, _ := setup()
+		registro := &models.TokenUsoUnico{Hash: auth.HashToken("token-valido"), Finalidade: models.FinalidadeRedefinicaoSenha, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour)}
+		mockTokens.On("BuscarPorHash", registro.Hash).Return(registro, nil)
+
+		assert.Equal(t, apperrors.ErrSenhaFraca, service.RedefinirSenha("token-valido", "123456", "123456"))
+		assert.Equal(t, apperrors.ErrSenhasNaoConferem, service.RedefinirSenha("token-valido", "NovaSenha@456", "NovaSenha@789"))
+		assert.Equal(t, apperrors.ErrCampoObrigatorio, service.RedefinirSenha("token-valido", "", "NovaSenha@789"))
+		mockTokens.AssertNotCalled(t, "Consumir", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// tamanhoMaximoDispositivo limita o nome do dispositivo (normalmente o User-Agent)
+const tamanhoMaximoDispositivo = 120
+
+// SessaoService define a interface para as sessões (dispositivos logados) de motoristas
+// ou de operadores
+type SessaoService interface {
+	Criar(subject, dispositivo, ip string) (*auth.TokenPair, string, error)
+	Renovar(refreshToken, ip string) (*auth.TokenPair, error)
+	Listar(subject string) ([]*models.Sessao, error)
+	Revogar(subject, sessaoID string) error
+	RevogarTodas(subject, exceto string) error
+}
+
+// titularSessao dono das sessões de um SessaoService: motoristas ou operadores
+type titularSessao interface {
+	// aceita indica se o papel de um refresh token pertence a este titular
+	aceita(papel string) bool
+	// papelAtual confere se o subject ainda pode receber tokens e retorna o papel dele,
+	// relido do repositório para refletir alterações e desativações
+	papelAtual(subject string) (string, error)
+}
+
+// SessaoServiceImpl implementa SessaoService
+type SessaoServiceImpl struct {
+	sessaoRepo   repositories.SessaoRepository
+	titular      titularSessao
+	tokenService *auth.TokenService
+}
+
+// NewSessaoService cria o serviço de sessões dos motoristas
+func NewSessaoService(sessaoRepo repositories.SessaoRepository, motoristaRepo repositories.MotoristaRepository, tokenService *auth.TokenService) SessaoService {
+	return &SessaoServiceImpl{
+		sessaoRepo:   sessaoRepo,
+		titular:      titularMotorista{motoristaRepo},
+		tokenService: tokenService,
+	}
+}
+
+// NewSessaoOperadorService cria o serviço de sessões dos operadores internos
+func NewSessaoOperadorService(sessaoRepo repositories.SessaoRepository, operadorRepo repositories.OperadorRepository, tokenService *auth.TokenService) SessaoService {
+	return &SessaoServiceImpl{
+		sessaoRepo:   sessaoRepo,
+		titular:      titularOperador{operadorRepo},
+		tokenService: tokenService,
+	}
+}
+
+// titularMotorista sessões de motoristas que ainda podem entrar (validarStatusLogin)
+type titularMotorista struct {
+	motoristaRepo repositories.MotoristaRepository
+}
+
+func (t titularMotorista) aceita(papel string) bool {
+	return papel == string(models.PapelMotorista)
+}
+
+func (t titularMotorista) papelAtual(subject string) (string, error) {
+	motorista, err := t.motoristaRepo.BuscarPorID(subject)
+	if err != nil {
+		return "", apperrors.ErrTokenInvalido
+	}
+	if err := validarStatusLogin(motorista.Status); err != nil {
+		return "", err
+	}
+	return string(models.PapelMotorista), nil
+}
+
+// titularOperador sessões de operadores ativos
+type titularOperador struct {
+	operadorRepo repositories.OperadorRepository
+}
+
+func (t titularOperador) aceita(papel string) bool {
+	return models.PapelOperadorValido(models.Papel(papel))
+}
+
+func (t titularOperador) papelAtual(subject string) (string, error) {
+	operador, err := t.operadorRepo.BuscarPorID(subject)
+	if err != nil || !operador.Ativo {
+		return "", apperrors.ErrTokenInvalido
+	}
+	return string(operador.Papel), nil
+}
+
+// Criar abre uma sessão para o dispositivo e emite os primeiros tokens dela.
+// Retorna também o ID da sessão criada.
+func (s *SessaoServiceImpl) Criar(subject, dispositivo, ip string) (*auth.TokenPair, string, error) {
+	papel, err := s.titular.papelAtual(subject)
+	if err != nil {
+		return nil, "", err
+	}
+	geracao, err := s.tokenService.GeracaoAtual(subject)
+	if err != nil {
+		return nil, "", err
+	}
+	agora := time.Now()
+	sessao := &models.Sessao{
+		ID:           uuid.New().String(),
+		Subject:      subject,
+		Dispositivo:  normalizarDispositivo(dispositivo),
+		IP:           ip,
+		Geracao:      geracao,
+		CriadaEm:     agora,
+		UltimoAcesso: agora,
+	}
+	tokens, err := s.tokenService.GerarTokensSessao(subject, papel, sessao.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	sessao.RefreshID = tokens.RefreshID
+	sessao.ExpiraEm = tokens.RefreshExpiraEm
+	if err := s.sessaoRepo.Criar(sessao); err != nil {
+		return nil, "", fmt.Errorf("erro ao criar sessão: %w", err)
+	}
+	return tokens, sessao.ID, nil
+}
+
+// Renovar troca o refresh token por um novo par e atualiza o último acesso da sessão.
+// Um refresh já substituído indica que o token vazou: a sessão inteira é revogada.
+func (s *SessaoServiceImpl) Renovar(refreshToken, ip string) (*auth.TokenPair, error) {
+	claims, err := s.tokenService.ValidarToken(refreshToken, auth.TokenRefresh)
+	if err != nil {
+		return nil, err
+	}
+	if !s.titular.aceita(claims.Papel) {
+		return nil, apperrors.ErrTokenInvalido
+	}
+	// Garante que o titular ainda existe e pode entrar antes de emitir novos tokens
+	papel, err := s.titular.papelAtual(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Sessao == "" {
+		// Refresh emitido antes das sessões: migra para uma sessão nova
+		tokens, _, err := s.Criar(claims.Subject, "", ip)
+		return tokens, err
+	}
+
+	sessao, err := s.sessaoRepo.BuscarPorID(claims.Sessao)
+	if err != nil {
+		return nil, apperrors.ErrTokenRevogado
+	}
+	agora := time.Now()
+	if sessao.Subject != claims.Subject {
+		return nil, apperrors.ErrTokenInvalido
+	}
+	if sessao.RefreshID != claims.ID {
+		s.revogarPorReuso(sessao, agora)
+		return nil, apperrors.ErrTokenRevogado
+	}
+
+	tokens, err := s.tokenService.GerarTokensSessao(claims.Subject, papel, sessao.ID)
+	if err != nil {
+		return nil, err
+	}
+	sessao.RefreshID = tokens.RefreshID
+	sessao.ExpiraEm = tokens.RefreshExpiraEm
+	sessao.UltimoAcesso = agora
+	if ip != "" {
+		sessao.IP = ip
+	}
+	// A troca só vale se nenhuma renovação concorrente usou o mesmo refresh antes
+	trocado, err := s.sessaoRepo.RotacionarRefresh(sessao, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar sessão: %w", err)
+	}
+	if !trocado {
+		s.revogarPorReuso(sessao, agora)
+		return nil, apperrors.ErrTokenRevogado
+	}
+	return tokens, nil
+}
+
+// revogarPorReuso encerra a sessão cujo refresh foi apresentado depois de já ter sido trocado
+func (s *SessaoServiceImpl) revogarPorReuso(sessao *models.Sessao, agora time.Time) {
+	sessao.RevogadaEm = &agora
+	if err := s.sessaoRepo.Atualizar(sessao); err != nil {
+		fmt.Printf("Erro ao revogar sessão %s após reuso de refresh token: %v\n", sessao.ID, err)
+	}
+}
+
+// Listar retorna as sessões ativas do subject, da mais recente para a mais antiga
+func (s *SessaoServiceImpl) Listar(subject string) ([]*models.Sessao, error) {
+	geracao, err := s.tokenService.GeracaoAtual(subject)
+	if err != nil {
+		return nil, err
+	}
+	sessoes, err := s.sessaoRepo.ListarPorSubject(subject)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %w", err)
+	}
+	agora := time.Now()
+	ativas := []*models.Sessao{}
+	for _, sessao := range sessoes {
+		// Sessões de uma geração anterior tiveram os tokens revogados (ex.: redefinição de senha)
+		if sessao.Ativa(agora) && sessao.Geracao == geracao {
+			ativas = append(ativas, sessao)
+		}
+	}
+	return ativas, nil
+}
+
+// Revogar encerra uma sessão do subject
+func (s *SessaoServiceImpl) Revogar(subject, sessaoID string) error {
+	sessao, err := s.sessaoRepo.BuscarPorID(sessaoID)
+	if errors.Is(err, repositories.ErrSessaoNaoEncontrada) || (err == nil && sessao.Subject != subject) {
+		return apperrors.ErrSessaoNaoEncontrada
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao buscar sessão: %w", err)
+	}
+	agora := time.Now()
+	if !sessao.Ativa(agora) {
+		return nil
+	}
+	sessao.RevogadaEm = &agora
+	if err := s.sessaoRepo.Atualizar(sessao); err != nil {
+		return fmt.Errorf("erro ao revogar sessão: %w", err)
+	}
+	return nil
+}
+
+// RevogarTodas encerra todas as sessões do subject exceto a informada.
+// Sem exceção ("sair de todos os dispositivos") também revoga tokens emitidos fora de sessões.
+func (s *SessaoServiceImpl) RevogarTodas(subject, exceto string) error {
+	if err := s.sessaoRepo.RevogarPorSubject(subject, exceto, time.Now()); err != nil {
+		return fmt.Errorf("erro ao revogar sessões: %w", err)
+	}
+	if exceto == "" {
+		return s.tokenService.RevogarTokens(subject)
+	}
+	return nil
+}
+
+func normalizarDispositivo(dispositivo string) string {
+	dispositivo = strings.TrimSpace(dispositivo)
+	if dispositivo == "" {
+		return "Dispositivo desconhecido"
+	}
+	if r := []rune(dispositivo); len(r) > tamanhoMaximoDispositivo {
+		return string(r[:tamanhoMaximoDispositivo])
+	}
+	return dispositivo
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// sessoesMemoria implementação em memória de repositories.SessaoRepository
+type sessoesMemoria map[string]models.Sessao
+
+func (r sessoesMemoria) Criar(sessao *models.Sessao) error {
+	r[sessao.ID] = *sessao
+	return nil
+}
+
+func (r sessoesMemoria) BuscarPorID(id string) (*models.Sessao, error) {
+	s, ok := r[id]
+	if !ok {
+		return nil, repositories.ErrSessaoNaoEncontrada
+	}
+	return &s, nil
+}
+
+func (r sessoesMemoria) Atualizar(sessao *models.Sessao) error {
+	r[sessao.ID] = *sessao
+	return nil
+}
+
+func (r sessoesMemoria) RotacionarRefresh(sessao *models.Sessao, refreshAnterior string) (bool, error) {
+	atual, ok := r[sessao.ID]
+	if !ok {
+		return false, repositories.ErrSessaoNaoEncontrada
+	}
+	if atual.RefreshID != refreshAnterior || atual.RevogadaEm != nil {
+		return false, nil
+	}
+	r[sessao.ID] = *sessao
+	return true, nil
+}
+
+func (r sessoesMemoria) ListarPorSubject(subject string) ([]*models.Sessao, error) {
+	var sessoes []*models.Sessao
+	for _, s := range r {
+		if s.Subject == subject {
+			s := s
+			sessoes = append(sessoes, &s)
+		}
+	}
+	return sessoes, nil
+}
+
+func (r sessoesMemoria) RevogarPorSubject(subject, exceto string, agora time.Time) error {
+	for id, s := range r {
+		if s.Subject == subject && id != exceto && s.Ativa(agora) {
+			s.RevogadaEm = &agora
+			r[id] = s
+		}
+	}
+	return nil
+}
+
+func (r sessoesMemoria) SessaoAtiva(id string) (bool, error) {
+	s, ok := r[id]
+	return ok && s.Ativa(time.Now()), nil
+}
+
+// sessoesComCorrida executa antes no início da próxima troca de refresh, simulando uma
+// renovação concorrente que chega primeiro
+type sessoesComCorrida struct {
+	sessoesMemoria
+	antes func()
+}
+
+func (r *sessoesComCorrida) RotacionarRefresh(sessao *models.Sessao, refreshAnterior string) (bool, error) {
+	if antes := r.antes; antes != nil {
+		r.antes = nil
+		antes()
+	}
+	return r.sessoesMemoria.RotacionarRefresh(sessao, refreshAnterior)
+}
+
+func TestSessaoService(t *testing.T) {
+	setup := func() (SessaoService, sessoesMemoria, *auth.TokenService) {
+		sessoes := sessoesMemoria{}
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}, Sessoes: sessoes})
+		mockRepo := new(MockMotoristaRepository)
+		mockRepo.On("BuscarPorID", "1").Return(&models.Motorista{ID: "1"}, nil)
+		return NewSessaoService(sessoes, mockRepo, tokenService), sessoes, tokenService
+	}
+
+	t.Run("Login cria sessão com dispositivo e IP", func(t *testing.T) {
+		service, sessoes, tokenService := setup()
+		tokens, id, err := service.Criar("1", "  Pixel 7  ", "10.0.0.1")
+		require.NoError(t, err)
+
+		claims, err := tokenService.ValidarToken(tokens.AccessToken, auth.TokenAcesso)
+		require.NoError(t, err)
+		assert.Equal(t, id, claims.Sessao)
+		assert.Equal(t, "Pixel 7", sessoes[id].Dispositivo)
+		assert.Equal(t, "10.0.0.1", sessoes[id].IP)
+		assert.Equal(t, tokens.RefreshID, sessoes[id].RefreshID)
+	})
+
+	t.Run("Renovar rotaciona o refresh da sessão", func(t *testing.T) {
+		service, sessoes, _ := setup()
+		tokens, id, err := service.Criar("1", "", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, "Dispositivo desconhecido", sessoes[id].Dispositivo)
+
+		novos, err := service.Renovar(tokens.RefreshToken, "10.0.0.2")
+		require.NoError(t, err)
+		assert.Equal(t, novos.RefreshID, sessoes[id].RefreshID)
+		assert.Equal(t, "10.0.0.2", sessoes[id].IP)
+	})
+
+	t.Run("Renovar recusa motorista que não pode entrar", func(t *testing.T) {
+		sessoes := sessoesMemoria{}
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}, Sessoes: sessoes})
+		mockRepo := new(MockMotoristaRepository)
+		motorista := &models.Motorista{ID: "1"}
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		service := NewSessaoService(sessoes, mockRepo, tokenService)
+		tokens, _, err := service.Criar("1", "Pixel 7", "10.0.0.1")
+		require.NoError(t, err)
+
+		motorista.Status = models.StatusEncerrado
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		assert.Equal(t, apperrors.ErrLoginContaEncerrada, err)
+		motorista.Status = models.StatusDocumentosAnalise
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		assert.Equal(t, apperrors.ErrLoginDocumentosEmAnalise, err)
+	})
+
+	t.Run("Reuso de refresh antigo revoga a sessão", func(t *testing.T) {
+		service, sessoes, _ := setup()
+		tokens, id, err := service.Criar("1", "Pixel 7", "10.0.0.1")
+		require.NoError(t, err)
+		novos, err := service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		require.NoError(t, err)
+
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.9")
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		assert.NotNil(t, sessoes[id].RevogadaEm)
+
+		// O refresh legítimo também deixa de valer
+		_, err = service.Renovar(novos.RefreshToken, "10.0.0.1")
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+	})
+
+	t.Run("Renovações concorrentes com o mesmo refresh revogam a sessão", func(t *testing.T) {
+		sessoes := &sessoesComCorrida{sessoesMemoria: sessoesMemoria{}}
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}, Sessoes: sessoes})
+		mockRepo := new(MockMotoristaRepository)
+		mockRepo.On("BuscarPorID", "1").Return(&models.Motorista{ID: "1"}, nil)
+		service := NewSessaoService(sessoes, mockRepo, tokenService)
+		tokens, id, err := service.Criar("1", "Pixel 7", "10.0.0.1")
+		require.NoError(t, err)
+
+		var primeira error
+		sessoes.antes = func() { _, primeira = service.Renovar(tokens.RefreshToken, "10.0.0.1") }
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.9")
+		require.NoError(t, primeira)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		assert.NotNil(t, sessoes.sessoesMemoria[id].RevogadaEm)
+	})
+
+	t.Run("Revogar sessão de um dispositivo", func(t *testing.T) {
+		service, _, tokenService := setup()
+		tokens, id, err := service.Criar("1", "Celular perdido", "10.0.0.1")
+		require.NoError(t, err)
+		_, _, err = service.Criar("1", "Notebook", "10.0.0.2")
+		require.NoError(t, err)
+
+		assert.Equal(t, apperrors.ErrSessaoNaoEncontrada, service.Revogar("2", id))
+		require.NoError(t, service.Revogar("1", id))
+
+		_, err = tokenService.ValidarToken(tokens.AccessToken, auth.TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		ativas, err := service.Listar("1")
+		require.NoError(t, err)
+		require.Len(t, ativas, 1)
+		assert.Equal(t, "Notebook", ativas[0].Dispositivo)
+	})
+
+	t.Run("Revogar todas mantém apenas a sessão atual", func(t *testing.T) {
+		service, _, _ := setup()
+		_, atual, err := service.Criar("1", "Atual", "10.0.0.1")
+		require.NoError(t, err)
+		_, _, err = service.Criar("1", "Outro", "10.0.0.2")
+		require.NoError(t, err)
+
+		require.NoError(t, service.RevogarTodas("1", atual))
+		ativas, err := service.Listar("1")
+		require.NoError(t, err)
+		require.Len(t, ativas, 1)
+		assert.Equal(t, atual, ativas[0].ID)
+	})
+
+	t.Run("Sair de todos os dispositivos revoga também tokens sem sessão", func(t *testing.T) {
+		service, _, tokenService := setup()
+		_, _, err := service.Criar("1", "Celular", "10.0.0.1")
+		require.NoError(t, err)
+		avulso, err := tokenService.GerarTokens("1", string(models.PapelMotorista))
+		require.NoError(t, err)
+
+		require.NoError(t, service.RevogarTodas("1", ""))
+		ativas, err := service.Listar("1")
+		require.NoError(t, err)
+		assert.Empty(t, ativas)
+		_, err = tokenService.ValidarToken(avulso.AccessToken, auth.TokenAcesso)
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+	})
+}
+
+func TestSessaoOperadorService(t *testing.T) {
+	setup := func() (SessaoService, sessoesMemoria, *models.Operador) {
+		sessoes := sessoesMemoria{}
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}, Sessoes: sessoes})
+		operador := &models.Operador{ID: "op-1", Papel: models.PapelRevisor, Ativo: true}
+		mockRepo := new(MockOperadorRepository)
+		mockRepo.On("BuscarPorID", "op-1").Return(operador, nil)
+		return NewSessaoOperadorService(sessoes, mockRepo, tokenService), sessoes, operador
+	}
+
+	t.Run("Renovar rotaciona o refresh e relê o papel", func(t *testing.T) {
+		service, sessoes, operador := setup()
+		tokens, id, err := service.Criar("op-1", "Firefox", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, tokens.RefreshID, sessoes[id].RefreshID)
+
+		operador.Papel = models.PapelSuporte
+		novos, err := service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, novos.RefreshID, sessoes[id].RefreshID)
+
+		// O refresh anterior não vale mais e o reuso revoga a sessão
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.9")
+		assert.Equal(t, apperrors.ErrTokenRevogado, err)
+		assert.NotNil(t, sessoes[id].RevogadaEm)
+	})
+
+	t.Run("Operador desativado não renova", func(t *testing.T) {
+		service, _, operador := setup()
+		tokens, _, err := service.Criar("op-1", "Firefox", "10.0.0.1")
+		require.NoError(t, err)
+
+		operador.Ativo = false
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+
+	t.Run("Refresh de motorista é recusado", func(t *testing.T) {
+		service, sessoes, _ := setup()
+		tokenService := auth.NewTokenService(auth.TokenConfig{Secret: "segredo-de-teste", Revogacoes: revogacoesMemoria{}, Sessoes: sessoes})
+		tokens, err := tokenService.GerarTokensSessao("1", string(models.PapelMotorista), "s-1")
+		require.NoError(t, err)
+		sessoes["s-1"] = models.Sessao{ID: "s-1", Subject: "1", RefreshID: tokens.RefreshID, ExpiraEm: tokens.RefreshExpiraEm}
+
+		_, err = service.Renovar(tokens.RefreshToken, "10.0.0.1")
+		assert.Equal(t, apperrors.ErrTokenInvalido, err)
+	})
+}
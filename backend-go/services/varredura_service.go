@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"taxi_service/internal/scanner"
+	"taxi_service/internal/storage"
+	"taxi_service/repositories"
+)
+
+// prefixoQuarentena arquivos infectados ficam sob quarentena/<chave original>, fora do
+// prefixo <id>/ servido aos motoristas e revisores
+const prefixoQuarentena = "quarentena/"
+
+// verificacoesSimultaneas limite de arquivos enviados ao antivírus ao mesmo tempo
+const verificacoesSimultaneas = 4
+
+// ChaveQuarentena chave do arquivo infectado na quarentena
+func ChaveQuarentena(chave string) string {
+	return prefixoQuarentena + chave
+}
+
+// VarreduraService verifica com o antivírus os arquivos dos documentos enviados, antes
+// que possam ser revisados ou baixados, e põe os infectados em quarentena
+type VarreduraService interface {
+	// Verificar examina o arquivo e registra o resultado nos documentos do motorista
+	Verificar(motoristaID, chave string) error
+	// Agendar executa Verificar em segundo plano, logo após o upload
+	Agendar(motoristaID, chave string)
+	// VerificarPendentes refaz as verificações não concluídas (antivírus indisponível,
+	// servidor reiniciado) dos documentos enviados antes de limite
+	VerificarPendentes(limite time.Time) (int, error)
+}
+
+// VarreduraServiceImpl implementa VarreduraService
+type VarreduraServiceImpl struct {
+	motoristaRepo    repositories.MotoristaRepository
+	motoristaService MotoristaService
+	arquivos         storage.FileStore
+	scanner          scanner.FileScanner
+	vagas            chan struct{}
+}
+
+// NewVarreduraService cria uma nova instância do serviço.
+// arquivos é o armazenamento dos documentos e scanner o antivírus usado.
+func NewVarreduraService(motoristaRepo repositories.MotoristaRepository, motoristaService MotoristaService, arquivos storage.FileStore, fileScanner scanner.FileScanner) VarreduraService {
+	return &VarreduraServiceImpl{
+		motoristaRepo:    motoristaRepo,
+		motoristaService: motoristaService,
+		arquivos:         arquivos,
+		scanner:          fileScanner,
+		vagas:            make(chan struct{}, verificacoesSimultaneas),
+	}
+}
+
+// Verificar envia o arquivo ao antivírus. O infectado é movido para a quarentena antes
+// de o resultado ser registrado: se a mudança falhar, o documento continua em
+// verificação, sem ser servido, e a verificação é refeita por VerificarPendentes.
+func (s *VarreduraServiceImpl) Verificar(motoristaID, chave string) error {
+	origem := chave
+	leitor, _, err := s.arquivos.Abrir(chave)
+	if errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+		// Já em quarentena por uma verificação anterior cujo resultado não foi registrado
+		origem = ChaveQuarentena(chave)
+		leitor, _, err = s.arquivos.Abrir(origem)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo %s: %w", chave, err)
+	}
+	resultado, err := s.scanner.Verificar(leitor)
+	leitor.Close()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar arquivo %s: %w", chave, err)
+	}
+
+	switch {
+	case resultado.Infectado && origem == chave:
+		if err := moverArquivo(s.arquivos, chave, ChaveQuarentena(chave)); err != nil {
+			return fmt.Errorf("erro ao mover arquivo %s para a quarentena: %w", chave, err)
+		}
+	case !resultado.Infectado && origem != chave:
+		if err := moverArquivo(s.arquivos, origem, chave); err != nil {
+			return fmt.Errorf("erro ao retirar arquivo %s da quarentena: %w", chave, err)
+		}
+	}
+	return s.motoristaService.RegistrarVarredura(motoristaID, chave, resultado.Infectado, resultado.Assinatura)
+}
+
+// Agendar verifica o arquivo em segundo plano, com no máximo verificacoesSimultaneas
+// arquivos no antivírus ao mesmo tempo
+func (s *VarreduraServiceImpl) Agendar(motoristaID, chave string) {
+	go func() {
+		s.vagas <- struct{}{}
+		defer func() { <-s.vagas }()
+		if err := s.Verificar(motoristaID, chave); err != nil {
+			fmt.Printf("Erro na verificação antivírus do motorista %s: %v\n", motoristaID, err)
+		}
+	}()
+}
+
+// VerificarPendentes verifica os arquivos dos documentos ainda em verificação enviados
+// antes de limite, inclusive os enviados antes da verificação antivírus, e retorna
+// quantos foram concluídos. Falhas são registradas e o arquivo fica para a próxima execução.
+func (s *VarreduraServiceImpl) VerificarPendentes(limite time.Time) (int, error) {
+	motoristas, err := s.motoristaRepo.ListarTodos()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao listar motoristas: %w", err)
+	}
+	concluidas := 0
+	for _, m := range motoristas {
+		vistas := map[string]bool{}
+		for _, doc := range m.Documentos {
+			if !doc.AguardandoVarredura() || !doc.CriadoEm.Before(limite) || vistas[doc.ChaveArquivo] {
+				continue
+			}
+			vistas[doc.ChaveArquivo] = true
+			if err := s.Verificar(m.ID, doc.ChaveArquivo); err != nil {
+				fmt.Printf("Erro na verificação antivírus do motorista %s: %v\n", m.ID, err)
+				continue
+			}
+			concluidas++
+		}
+	}
+	return concluidas, nil
+}
+
+// AgendarVarreduraPendente executa VerificarPendentes ao iniciar e depois a cada
+// intervalo, para os documentos em verificação há mais de um intervalo. Retorna uma
+// função que interrompe o agendamento.
+func AgendarVarreduraPendente(service VarreduraService, intervalo time.Duration) func() {
+	parar := make(chan struct{})
+	executar := func() {
+		concluidas, err := service.VerificarPendentes(time.Now().Add(-intervalo))
+		if err != nil {
+			fmt.Printf("Erro ao refazer verificações antivírus: %v\n", err)
+		}
+		if concluidas > 0 {
+			fmt.Printf("%d verificação(ões) antivírus pendente(s) concluída(s)\n", concluidas)
+		}
+	}
+	go func() {
+		executar()
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				executar()
+			case <-parar:
+				return
+			}
+		}
+	}()
+	return func() { close(parar) }
+}
+
+// moverArquivo copia o arquivo para a nova chave e remove o original
+func moverArquivo(arquivos storage.FileStore, de, para string) error {
+	leitor, info, err := arquivos.Abrir(de)
+	if err != nil {
+		return err
+	}
+	err = arquivos.Gravar(para, leitor, info.Tamanho, info.ContentType)
+	leitor.Close()
+	if err != nil {
+		return err
+	}
+	return arquivos.Deletar(de)
+}
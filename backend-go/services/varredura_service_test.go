@@ -0,0 +1,173 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/internal/scanner"
+	"taxi_service/internal/storage"
+	"taxi_service/models"
+)
+
+func TestVarreduraService(t *testing.T) {
+	type ambiente struct {
+		motoristas MotoristaService
+		varredura  VarreduraService
+		repo       *MockMotoristaRepository
+		email      *MockEmailService
+		arquivos   storage.FileStore
+		antivirus  *scanner.Simulado
+		motorista  *models.Motorista
+	}
+	// Motorista com email verificado e CNH e CRLV aprovados; falta a selfie
+	setup := func(t *testing.T) *ambiente {
+		a := &ambiente{
+			repo:      new(MockMotoristaRepository),
+			email:     new(MockEmailService),
+			arquivos:  storage.NewLocalFileStore(t.TempDir()),
+			antivirus: &scanner.Simulado{},
+		}
+		a.motoristas = NewMotoristaService(a.repo, a.email, auth.NewBcryptHasher(bcrypt.MinCost), novoLimitador())
+		a.varredura = NewVarreduraService(a.repo, a.motoristas, a.arquivos, a.antivirus)
+		a.motorista = &models.Motorista{
+			ID: "1", Nome: "João", Email: "joao@email.com", EmailVerificado: true, Status: models.StatusAguardandoAprovacao,
+			Documentos: []models.Documento{
+				{ID: "d1", TipoDocumento: "CNH", ChaveArquivo: "1/documentos/cnh.pdf", Status: models.DocumentoStatusAprovado, Varredura: models.VarreduraLimpo},
+				{ID: "d2", TipoDocumento: "CRLV", ChaveArquivo: "1/documentos/crlv.pdf", Status: models.DocumentoStatusAprovado, Varredura: models.VarreduraLimpo},
+			},
+		}
+		a.repo.On("BuscarPorID", "1").Return(a.motorista, nil)
+		a.repo.On("Atualizar", a.motorista).Return(nil)
+		a.repo.On("ListarTodos").Return([]*models.Motorista{a.motorista}, nil)
+		return a
+	}
+	enviar := func(t *testing.T, a *ambiente, chave, conteudo string) {
+		require.NoError(t, a.arquivos.Gravar(chave, strings.NewReader(conteudo), int64(len(conteudo)), "image/jpeg"))
+		require.NoError(t, a.motoristas.UploadDocumento("1", UploadDocumentoRequest{
+			TipoDocumento: "selfie_cnh", ChaveArquivo: chave, Formato: "JPG", Tamanho: int64(len(conteudo)),
+		}))
+	}
+	selfie := func(a *ambiente) models.Documento {
+		return a.motorista.Documentos[2]
+	}
+
+	t.Run("Documento só pode ser revisado depois de limpo", func(t *testing.T) {
+		a := setup(t)
+		a.email.On("EnviarEmailRecebimentoDocumentos", "joao@email.com", "João").Return(nil)
+		a.email.On("EnviarEmailAprovacao", "joao@email.com", "João").Return(nil)
+		enviar(t, a, "1/documentos/selfie.jpg", "\xff\xd8\xff selfie")
+
+		assert.Equal(t, models.VarreduraVerificando, selfie(a).Varredura)
+		assert.Equal(t, models.StatusDocumentosAnalise, a.motorista.Status)
+		_, err := a.motoristas.AprovarDocumento("1", "selfie_cnh", "op-1")
+		assert.Equal(t, apperrors.ErrDocumentoEmVerificacao, err)
+		_, err = a.motoristas.RejeitarDocumento("1", "selfie_cnh", "op-1", models.MotivoIlegivel, "")
+		assert.Equal(t, apperrors.ErrDocumentoEmVerificacao, err)
+		assert.Equal(t, apperrors.ErrDocumentoEmVerificacao, a.motoristas.AprovarMotorista("1", "op-1"))
+
+		require.NoError(t, a.varredura.Verificar("1", "1/documentos/selfie.jpg"))
+		assert.Equal(t, models.VarreduraLimpo, selfie(a).Varredura)
+		assert.Equal(t, models.DocumentoStatusPendente, selfie(a).Status)
+
+		m, err := a.motoristas.AprovarDocumento("1", "selfie_cnh", "op-1")
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusAprovado, m.Status)
+	})
+
+	t.Run("Arquivo infectado vai para a quarentena e o documento é rejeitado", func(t *testing.T) {
+		a := setup(t)
+		a.email.On("EnviarEmailRecebimentoDocumentos", mock.Anything, mock.Anything).Return(nil)
+		a.email.On("EnviarEmailRejeicao", "joao@email.com", "João", "", mock.Anything).Return(nil)
+		enviar(t, a, "1/documentos/selfie.jpg", "\xff\xd8\xff "+scanner.EICAR)
+
+		require.NoError(t, a.varredura.Verificar("1", "1/documentos/selfie.jpg"))
+		doc := selfie(a)
+		assert.Equal(t, models.VarreduraInfectado, doc.Varredura)
+		assert.Equal(t, scanner.AssinaturaEICAR, doc.Ameaca)
+		assert.Equal(t, models.DocumentoStatusRejeitado, doc.Status)
+		assert.Equal(t, models.MotivoArquivoInfectado, doc.MotivoRejeicao)
+		assert.Equal(t, models.StatusRejeitado, a.motorista.Status)
+		revisao := a.motorista.Revisoes[len(a.motorista.Revisoes)-1]
+		assert.Equal(t, models.RevisaoDocumentoRejeitado, revisao.Acao)
+		assert.Equal(t, operadorAntivirus, revisao.OperadorID)
+
+		_, err := a.arquivos.Info("1/documentos/selfie.jpg")
+		assert.ErrorIs(t, err, storage.ErrArquivoNaoEncontrado, "o arquivo infectado não fica onde é servido")
+		_, err = a.arquivos.Info("quarentena/1/documentos/selfie.jpg")
+		assert.NoError(t, err)
+
+		// Sem documentos pendentes, o motorista é avisado para reenviar
+		a.email.AssertCalled(t, "EnviarEmailRejeicao", "joao@email.com", "João", "", mock.Anything)
+		_, err = a.motoristas.AprovarDocumento("1", "selfie_cnh", "op-1")
+		assert.Equal(t, apperrors.ErrDocumentoInfectado, err)
+	})
+
+	t.Run("Falha do antivírus mantém o documento em verificação até a nova tentativa", func(t *testing.T) {
+		a := setup(t)
+		a.email.On("EnviarEmailRecebimentoDocumentos", mock.Anything, mock.Anything).Return(nil)
+		enviar(t, a, "1/documentos/selfie.jpg", "\xff\xd8\xff selfie")
+
+		a.antivirus.Erro = scanner.ErrScannerIndisponivel
+		assert.ErrorIs(t, a.varredura.Verificar("1", "1/documentos/selfie.jpg"), scanner.ErrScannerIndisponivel)
+		assert.Equal(t, models.VarreduraVerificando, selfie(a).Varredura)
+
+		// Documentos recentes ficam com a verificação agendada no upload
+		a.antivirus.Erro = nil
+		concluidas, err := a.varredura.VerificarPendentes(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, concluidas)
+
+		concluidas, err = a.varredura.VerificarPendentes(time.Now().Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 1, concluidas)
+		assert.Equal(t, models.VarreduraLimpo, selfie(a).Varredura)
+	})
+
+	t.Run("Arquivo já em quarentena é registrado como infectado", func(t *testing.T) {
+		a := setup(t)
+		a.email.On("EnviarEmailRecebimentoDocumentos", mock.Anything, mock.Anything).Return(nil)
+		a.email.On("EnviarEmailRejeicao", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		enviar(t, a, "1/documentos/selfie.jpg", "\xff\xd8\xff "+scanner.EICAR)
+		// Quarentena concluída, mas o registro do resultado falhou
+		require.NoError(t, moverArquivo(a.arquivos, "1/documentos/selfie.jpg", ChaveQuarentena("1/documentos/selfie.jpg")))
+
+		require.NoError(t, a.varredura.Verificar("1", "1/documentos/selfie.jpg"))
+		assert.Equal(t, models.VarreduraInfectado, selfie(a).Varredura)
+	})
+
+	t.Run("Documento substituído durante a verificação", func(t *testing.T) {
+		a := setup(t)
+		a.email.On("EnviarEmailRecebimentoDocumentos", mock.Anything, mock.Anything).Return(nil)
+		enviar(t, a, "1/documentos/antiga.jpg", "\xff\xd8\xff antiga")
+		enviar(t, a, "1/documentos/nova.jpg", "\xff\xd8\xff nova")
+
+		assert.ErrorIs(t, a.varredura.Verificar("1", "1/documentos/antiga.jpg"), apperrors.ErrDocumentoNaoEncontrado)
+		assert.Equal(t, models.VarreduraVerificando, selfie(a).Varredura)
+	})
+
+	t.Run("Documento enviado antes da verificação antivírus é verificado antes de ser servido", func(t *testing.T) {
+		a := setup(t)
+		legado := models.Documento{ID: "d3", TipoDocumento: "selfie_cnh", ChaveArquivo: "1/documentos/selfie.jpg", Status: models.DocumentoStatusPendente, CriadoEm: time.Now().Add(-24 * time.Hour)}
+		a.motorista.Documentos = append(a.motorista.Documentos, legado)
+		conteudo := "\xff\xd8\xff selfie"
+		require.NoError(t, a.arquivos.Gravar(legado.ChaveArquivo, strings.NewReader(conteudo), int64(len(conteudo)), "image/jpeg"))
+
+		assert.ErrorIs(t, selfie(a).ValidarDisponibilidade(), apperrors.ErrDocumentoEmVerificacao)
+		_, err := a.motoristas.AprovarDocumento("1", "selfie_cnh", "op-1")
+		assert.Equal(t, apperrors.ErrDocumentoEmVerificacao, err)
+
+		concluidas, err := a.varredura.VerificarPendentes(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, concluidas)
+		assert.Equal(t, models.VarreduraLimpo, selfie(a).Varredura)
+		assert.NoError(t, selfie(a).ValidarDisponibilidade())
+	})
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+// validadeLinkVerificacao tempo de validade do link de confirmação de email
+const validadeLinkVerificacao = 24 * time.Hour
+
+// VerificacaoEmailService define a interface para confirmação de endereços de email
+type VerificacaoEmailService interface {
+	EnviarVerificacao(motoristaID string) error
+	ConfirmarEmail(token string) (*models.Motorista, error)
+}
+
+// VerificacaoEmailServiceImpl implementa VerificacaoEmailService
+type VerificacaoEmailServiceImpl struct {
+	motoristaRepo repositories.MotoristaRepository
+	tokenRepo     repositories.TokenRepository
+	emailService  EmailService
+	appURL        string
+}
+
+// NewVerificacaoEmailService cria uma nova instância do serviço.
+// appURL é a URL base do frontend usada para montar o link enviado por email.
+func NewVerificacaoEmailService(motoristaRepo repositories.MotoristaRepository, tokenRepo repositories.TokenRepository, emailService EmailService, appURL string) VerificacaoEmailService {
+	return &VerificacaoEmailServiceImpl{
+		motoristaRepo: motoristaRepo,
+		tokenRepo:     tokenRepo,
+		emailService:  emailService,
+		appURL:        strings.TrimRight(appURL, "/"),
+	}
+}
+
+// EnviarVerificacao envia o link de confirmação para o email pendente de troca ou,
+// se não houver troca, para o email atual ainda não verificado.
+// Em uma troca, o endereço atual também recebe um aviso.
+func (s *VerificacaoEmailServiceImpl) EnviarVerificacao(motoristaID string) error {
+	motorista, err := s.motoristaRepo.BuscarPorID(motoristaID)
+	if err != nil {
+		return apperrors.ErrMotoristaNaoEncontrado
+	}
+
+	destino := motorista.EmailPendente
+	if destino == "" {
+		if motorista.EmailVerificado {
+			return apperrors.ErrEmailJaVerificado
+		}
+		destino = motorista.Email
+	}
+
+	// Apenas o link mais recente permanece válido
+	agora := time.Now()
+	if err := s.tokenRepo.InvalidarPendentes(motorista.ID, models.FinalidadeVerificacaoEmail, agora); err != nil {
+		return fmt.Errorf("erro ao invalidar links anteriores: %w", err)
+	}
+
+	token, hash, err := auth.GerarTokenOpaco()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar token: %w", err)
+	}
+	if err := s.tokenRepo.Criar(&models.TokenUsoUnico{
+		ID:          uuid.New().String(),
+		Hash:        hash,
+		Finalidade:  models.FinalidadeVerificacaoEmail,
+		MotoristaID: motorista.ID,
+		Email:       destino,
+		ExpiraEm:    agora.Add(validadeLinkVerificacao),
+		CriadoEm:    agora,
+	}); err != nil {
+		return fmt.Errorf("erro ao salvar token: %w", err)
+	}
+
+	link := s.appURL + "/verify-email?token=" + token
+	if err := s.emailService.EnviarEmailVerificacao(destino, motorista.Nome, link); err != nil {
+		return err
+	}
+	if destino != motorista.Email {
+		return s.emailService.EnviarEmailAvisoTrocaEmail(motorista.Email, motorista.Nome, destino)
+	}
+	return nil
+}
+
+// ConfirmarEmail marca o endereço como confirmado, aplicando a troca de email quando o
+// link foi enviado ao endereço pendente, e consome o link de verificação.
+func (s *VerificacaoEmailServiceImpl) ConfirmarEmail(token string) (*models.Motorista, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	hash := auth.HashToken(token)
+	agora := time.Now()
+	t, err := s.tokenRepo.BuscarPorHash(hash)
+	if err != nil || t.Finalidade != models.FinalidadeVerificacaoEmail || !t.Valido(agora) {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	var motorista *models.Motorista
+	err = s.motoristaRepo.WithTx(func(tx repositories.MotoristaTx) error {
+		m, err := tx.BuscarPorID(t.MotoristaID)
+		if err != nil {
+			return apperrors.ErrLinkInvalido
+		}
+
+		switch {
+		case t.Email == m.Email:
+		case t.Email == m.EmailPendente:
+			// O endereço pode ter sido cadastrado por outra conta enquanto a troca estava pendente
+			if outro, err := tx.BuscarPorEmail(t.Email); err == nil && outro.ID != m.ID {
+				return apperrors.ErrEmailJaCadastrado
+			}
+			m.Email = t.Email
+			m.EmailPendente = ""
+		default:
+			// Link de um endereço que já não está associado à conta
+			return apperrors.ErrLinkInvalido
+		}
+
+		m.EmailVerificado = true
+		m.AtualizadoEm = time.Now()
+		if err := tx.Atualizar(m); err != nil {
+			if appErr := erroUnicidadeMotorista(err); appErr != nil {
+				return appErr
+			}
+			return fmt.Errorf("erro ao confirmar email do motorista: %w", err)
+		}
+		motorista = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// O link só é consumido depois que a confirmação foi gravada, para que uma falha na
+	// gravação não o inutilize. Requisições concorrentes com o mesmo link aplicam a mesma
+	// alteração; apenas a que consome o link é confirmada.
+	if _, err := s.tokenRepo.Consumir(hash, models.FinalidadeVerificacaoEmail, agora); err != nil {
+		return nil, apperrors.ErrLinkInvalido
+	}
+	return motorista, nil
+}
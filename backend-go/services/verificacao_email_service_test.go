@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"taxi_service/internal/apperrors"
+	"taxi_service/internal/auth"
+	"taxi_service/models"
+	"taxi_service/repositories"
+)
+
+func TestVerificacaoEmailService(t *testing.T) {
+	setup := func() (VerificacaoEmailService, *MockMotoristaRepository, *MockTokenRepository, *MockEmailService) {
+		mockRepo := new(MockMotoristaRepository)
+		mockTokens := new(MockTokenRepository)
+		mockEmail := new(MockEmailService)
+		service := NewVerificacaoEmailService(mockRepo, mockTokens, mockEmail, "http://localhost:5173/")
+		return service, mockRepo, mockTokens, mockEmail
+	}
+
+	t.Run("Enviar verificação do email de cadastro", func(t *testing.T) {
+		service, mockRepo, mockTokens, mockEmail := setup()
+		motorista := &models.Motorista{ID: "1", Nome: "João Silva", Email: "joao@email.com"}
+
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeVerificacaoEmail).Return(nil)
+		mockTokens.On("Criar", mock.AnythingOfType("*models.TokenUsoUnico")).Return(nil)
+		mockEmail.On("EnviarEmailVerificacao", "joao@email.com", "João Silva", mock.AnythingOfType("string")).Return(nil)
+
+		require.NoError(t, service.EnviarVerificacao("1"))
+
+		salvo := mockTokens.Calls[1].Arguments.Get(0).(*models.TokenUsoUnico)
+		link := mockEmail.Calls[0].Arguments.String(2)
+		token := strings.TrimPrefix(link, "http://localhost:5173/verify-email?token=")
+		assert.NotEqual(t, link, token)
+		assert.Equal(t, auth.HashToken(token), salvo.Hash)
+		assert.Equal(t, "joao@email.com", salvo.Email)
+		mockEmail.AssertNotCalled(t, "EnviarEmailAvisoTrocaEmail", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Troca de email envia link ao novo endereço e aviso ao atual", func(t *testing.T) {
+		service, mockRepo, mockTokens, mockEmail := setup()
+		motorista := &models.Motorista{ID: "1", Nome: "João Silva", Email: "joao@email.com", EmailVerificado: true, EmailPendente: "novo@email.com"}
+
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockTokens.On("InvalidarPendentes", "1", models.FinalidadeVerificacaoEmail).Return(nil)
+		mockTokens.On("Criar", mock.AnythingOfType("*models.TokenUsoUnico")).Return(nil)
+		mockEmail.On("EnviarEmailVerificacao", "novo@email.com", "João Silva", mock.AnythingOfType("string")).Return(nil)
+		mockEmail.On("EnviarEmailAvisoTrocaEmail", "joao@email.com", "João Silva", "novo@email.com").Return(nil)
+
+		require.NoError(t, service.EnviarVerificacao("1"))
+		mockEmail.AssertExpectations(t)
+	})
+
+	t.Run("Email já verificado sem troca pendente", func(t *testing.T) {
+		service, mockRepo, _, _ := setup()
+		mockRepo.On("BuscarPorID", "1").Return(&models.Motorista{ID: "1", Email: "joao@email.com", EmailVerificado: true}, nil)
+
+		assert.Equal(t, apperrors.ErrEmailJaVerificado, service.EnviarVerificacao("1"))
+	})
+
+	t.Run("Confirmar email de cadastro", func(t *testing.T) {
+		service, mockRepo, mockTokens, _ := setup()
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com"}
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeVerificacaoEmail, MotoristaID: "1", Email: "joao@email.com", ExpiraEm: time.Now().Add(time.Hour)}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("Consumir", hash, models.FinalidadeVerificacaoEmail).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(nil)
+
+		m, err := service.ConfirmarEmail("token-valido")
+		require.NoError(t, err)
+		assert.True(t, m.EmailVerificado)
+		mockTokens.AssertCalled(t, "Consumir", hash, models.FinalidadeVerificacaoEmail)
+	})
+
+	t.Run("Falha ao gravar a confirmação não consome o link", func(t *testing.T) {
+		service, mockRepo, mockTokens, _ := setup()
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com"}
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeVerificacaoEmail, MotoristaID: "1", Email: "joao@email.com", ExpiraEm: time.Now().Add(time.Hour)}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("Atualizar", motorista).Return(errors.New("disco cheio"))
+
+		_, err := service.ConfirmarEmail("token-valido")
+		assert.Error(t, err)
+		mockTokens.AssertNotCalled(t, "Consumir", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Confirmar troca aplica o novo email", func(t *testing.T) {
+		service, mockRepo, mockTokens, _ := setup()
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com", EmailVerificado: true, EmailPendente: "novo@email.com"}
+		hash := auth.HashToken("token-valido")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeVerificacaoEmail, MotoristaID: "1", Email: "novo@email.com", ExpiraEm: time.Now().Add(time.Hour)}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockTokens.On("Consumir", hash, models.FinalidadeVerificacaoEmail).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+		mockRepo.On("BuscarPorEmail", "novo@email.com").Return(nil, errors.New("não encontrado"))
+		mockRepo.On("Atualizar", motorista).Return(nil)
+
+		m, err := service.ConfirmarEmail("token-valido")
+		require.NoError(t, err)
+		assert.Equal(t, "novo@email.com", m.Email)
+		assert.Empty(t, m.EmailPendente)
+		assert.True(t, m.EmailVerificado)
+	})
+
+	t.Run("Link de troca substituída é recusado", func(t *testing.T) {
+		service, mockRepo, mockTokens, _ := setup()
+		motorista := &models.Motorista{ID: "1", Email: "joao@email.com", EmailPendente: "outro@email.com"}
+		hash := auth.HashToken("token-antigo")
+		registro := &models.TokenUsoUnico{Hash: hash, Finalidade: models.FinalidadeVerificacaoEmail, MotoristaID: "1", Email: "novo@email.com", ExpiraEm: time.Now().Add(time.Hour)}
+
+		mockTokens.On("BuscarPorHash", hash).Return(registro, nil)
+		mockRepo.On("BuscarPorID", "1").Return(motorista, nil)
+
+		_, err := service.ConfirmarEmail("token-antigo")
+		assert.Equal(t, apperrors.ErrLinkInvalido, err)
+		mockRepo.AssertNotCalled(t, "Atualizar", mock.Anything)
+		mockTokens.AssertNotCalled(t, "Consumir", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Link inválido ou já usado", func(t *testing.T) {
+		service, _, mockTokens, _ := setup()
+		usadoEm := time.Now().Add(-time.Minute)
+		usado := &models.TokenUsoUnico{Hash: auth.HashToken("usado"), Finalidade: models.FinalidadeVerificacaoEmail, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour), UsadoEm: &usadoEm}
+		outraFinalidade := &models.TokenUsoUnico{Hash: auth.HashToken("redefinicao"), Finalidade: models.FinalidadeRedefinicaoSenha, MotoristaID: "1", ExpiraEm: time.Now().Add(time.Hour)}
+		mockTokens.On("BuscarPorHash", auth.HashToken("inexistente")).Return(nil, repositories.ErrTokenNaoEncontrado)
+		mockTokens.On("BuscarPorHash", usado.Hash).Return(usado, nil)
+		mockTokens.On("BuscarPorHash", outraFinalidade.Hash).Return(outraFinalidade, nil)
+
+		_, err := service.ConfirmarEmail("")
+		assert.Equal(t, apperrors.ErrLinkInvalido, err)
+		for _, token := range []string{"inexistente", "usado", "redefinicao"} {
+			_, err = service.ConfirmarEmail(token)
+			assert.Equal(t, apperrors.ErrLinkInvalido, err, token)
+		}
+	})
+}
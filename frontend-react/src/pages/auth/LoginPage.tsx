@@ -16,6 +16,13 @@ interface LoginForm {
   password: string;
 }
 
+interface LoginResponse {
+  motorista?: { id: string };
+  tokens?: { access_token: string; refresh_token: string };
+  desafio?: string;
+  next_step?: 'upload_documentos' | 'painel' | 'dois_fatores';
+}
+
 const schema = yup.object({
   email: yup.string().email('E-mail inválido').required('Obrigatório'),
   password: yup.string().required('Obrigatório')
@@ -24,24 +31,64 @@ const schema = yup.object({
 export default function LoginPage() {
   const navigate = useNavigate();
   const [serverError, setServerError] = useState('');
+  const [desafio, setDesafio] = useState('');
+  const [codigo, setCodigo] = useState('');
   const { register, handleSubmit, formState: { errors } } = useForm<LoginForm>({ resolver: yupResolver(schema) });
 
-  const mutation = useMutation({
-    mutationFn: (data: LoginForm) => api.post('/api/auth/login', data).then((r: { data: { motorista: { id: string } } }) => r.data),
-    onSuccess: (data: { motorista: { id: string } }) => {
-      if (data.motorista?.id) {
-        saveAuth({ motoristaId: data.motorista.id, role: 'user' });
+  const concluirLogin = (data: LoginResponse) => {
+    if (data.next_step === 'dois_fatores' && data.desafio) {
+      setServerError('');
+      setDesafio(data.desafio);
+      return;
+    }
+    if (data.motorista?.id) {
+      saveAuth({
+        motoristaId: data.motorista.id,
+        role: 'user',
+        accessToken: data.tokens?.access_token,
+        refreshToken: data.tokens?.refresh_token
+      });
+      if (data.next_step === 'upload_documentos') {
+        navigate(`/documents/${data.motorista.id}/upload`);
+      } else {
         navigate(`/profile/${data.motorista.id}`);
       }
-    },
-    onError: (err: unknown) => {
-      setServerError('Credenciais inválidas');
-      console.error(err);
     }
+  };
+
+  const onError = (err: unknown) => {
+    const message = (err as { response?: { data?: { message?: string } } }).response?.data?.message;
+    setServerError(message || 'Credenciais inválidas');
+    console.error(err);
+  };
+
+  const mutation = useMutation({
+    mutationFn: (data: LoginForm) => api.post('/api/auth/login', data).then((r: { data: LoginResponse }) => r.data),
+    onSuccess: concluirLogin,
+    onError
+  });
+
+  const segundoFator = useMutation({
+    mutationFn: () => api.post('/api/auth/login/2fa', { desafio, codigo }).then((r: { data: LoginResponse }) => r.data),
+    onSuccess: concluirLogin,
+    onError
   });
 
   const onSubmit = (data: LoginForm) => mutation.mutate(data);
 
+  if (desafio) {
+    return (
+      <Paper sx={{ p: 4, backdropFilter: 'blur(6px)' }}>
+        <Typography variant="h4" mb={2}>Verificação em duas etapas</Typography>
+        <Stack component="form" onSubmit={(e) => { e.preventDefault(); segundoFator.mutate(); }} spacing={2}>
+          {serverError && <AppAlert severity="error" show>{serverError}</AppAlert>}
+          <FormTextField label="Código do aplicativo ou de recuperação" value={codigo} onChange={(e) => setCodigo(e.target.value)} autoFocus />
+          <AppButton type="submit" variant="contained" size="large" loading={segundoFator.isPending}>Verificar</AppButton>
+        </Stack>
+      </Paper>
+    );
+  }
+
   return (
     <Paper sx={{ p: 4, backdropFilter: 'blur(6px)' }}>
       <Typography variant="h4" mb={2}>Entrar</Typography>
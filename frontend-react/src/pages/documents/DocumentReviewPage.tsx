@@ -10,7 +10,7 @@ export default function DocumentReviewPage() {
   const navigate = useNavigate();
   const [status, setStatus] = useState<'approved' | 'rejected' | 'in_review'>('in_review');
   const [error, setError] = useState('');
-  const [docs, setDocs] = useState<Array<{ tipo_documento: string; caminho_arquivo: string; status: string; formato: string; tamanho: number }>>([]);
+  const [docs, setDocs] = useState<Array<{ tipo_documento: string; chave_arquivo: string; status: string; formato: string; tamanho: number }>>([]);
   const [opening, setOpening] = useState<string>('');
 
   const load = async () => {
@@ -71,7 +71,7 @@ export default function DocumentReviewPage() {
         <List dense>
           {docs.map(d => (
             <ListItem key={d.tipo_documento} divider secondaryAction={
-              d.caminho_arquivo ? (
+              d.chave_arquivo ? (
                 <AppButton size="small" variant="outlined" onClick={async () => {
                   if (!id) return;
                   setError('');
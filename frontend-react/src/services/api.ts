@@ -1,10 +1,19 @@
-import axios, { AxiosResponse } from 'axios';
+import axios, { AxiosResponse, InternalAxiosRequestConfig } from 'axios';
+import { loadAuth } from './auth';
 
 const api = axios.create({
   baseURL: (import.meta as ImportMeta).env.VITE_API_URL || 'http://localhost:3000',
   timeout: 10000
 });
 
+api.interceptors.request.use((config: InternalAxiosRequestConfig) => {
+  const { accessToken } = loadAuth();
+  if (accessToken) {
+    config.headers.Authorization = `Bearer ${accessToken}`;
+  }
+  return config;
+});
+
 api.interceptors.response.use(
   (r: AxiosResponse) => r,
   (err: unknown) => {
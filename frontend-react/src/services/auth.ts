@@ -1,6 +1,8 @@
 export interface AuthInfo {
   motoristaId?: string;
   role?: 'user' | 'admin';
+  accessToken?: string;
+  refreshToken?: string;
 }
 
 const STORAGE_KEY = 'ts_auth';